@@ -12,7 +12,9 @@ import (
 	v1 "github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1"
 	"github.com/gloompi/ultimate-service/business/web/auth"
 	"github.com/gloompi/ultimate-service/business/web/v1/mid"
+	"github.com/gloompi/ultimate-service/foundation/blob"
 	"github.com/gloompi/ultimate-service/foundation/web"
+	"github.com/gloompi/ultimate-service/foundation/worker"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
@@ -30,10 +32,13 @@ func WithCORS(origin string) func(opts *Options) {
 }
 
 type APIMuxConfig struct {
-	Shutdown chan os.Signal
-	Log      *zap.SugaredLogger
-	Auth     *auth.Auth
-	DB       *sqlx.DB
+	Shutdown          chan os.Signal
+	Log               *zap.SugaredLogger
+	Auth              *auth.Auth
+	DB                *sqlx.DB
+	Blob              blob.Store
+	MaxAttachmentSize int64
+	Worker            *worker.Worker
 }
 
 // APIMux constructs a http.Handler with all application routes defined.
@@ -78,9 +83,12 @@ func APIMux(cfg APIMuxConfig, options ...func(opts *Options)) http.Handler {
 
 	// Load the v1 routes.
 	v1.Routes(app, v1.Config{
-		Log:  cfg.Log,
-		Auth: cfg.Auth,
-		DB:   cfg.DB,
+		Log:               cfg.Log,
+		Auth:              cfg.Auth,
+		DB:                cfg.DB,
+		Blob:              cfg.Blob,
+		MaxAttachmentSize: cfg.MaxAttachmentSize,
+		Worker:            cfg.Worker,
 	})
 
 	return app
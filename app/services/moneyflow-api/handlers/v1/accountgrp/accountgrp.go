@@ -0,0 +1,197 @@
+// Package accountgrp maintains the group of handlers for account access.
+package accountgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of account endpoints.
+type Handlers struct {
+	Account account.Core
+}
+
+// Create adds a new account to the system.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var na account.NewAccount
+	if err := web.Decode(r, &na); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	// If you are not an admin and looking to add an account for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(na.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	acc, err := h.Account.Create(ctx, na, v.Now)
+	if err != nil {
+		return fmt.Errorf("account[%+v]: %w", &acc, err)
+	}
+
+	return web.Respond(ctx, w, acc, http.StatusCreated)
+}
+
+// Update updates an account in the system.
+func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var upd account.UpdateAccount
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	acc, err := h.Account.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying account[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to update an account you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(acc.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Account.Update(ctx, id, upd, v.Now); err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s] Account[%+v]: %w", id, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Delete removes an account from the system.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	acc, err := h.Account.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			// Don't send StatusNotFound here since the call to Delete
+			// below won't if this account is not found.
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querying account[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to delete an account you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(acc.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Account.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryByID returns an account along with its balance.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	acc, err := h.Account.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to retrieve someone else's account.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(acc.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, acc, http.StatusOK)
+}
+
+// QueryByUserID returns the accounts of a user along with their balances.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's accounts.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	accs, err := h.Account.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, accs, http.StatusOK)
+}
@@ -0,0 +1,220 @@
+// Package attachmentgrp maintains the group of handlers for the files
+// attached to expenses.
+package attachmentgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gloompi/ultimate-service/business/core/attachment"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/household"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// formOverhead is room left in the request body for the parts of the
+// multipart form around the file.
+const formOverhead = 1 << 20
+
+// maxMemory is how much of the form is held in memory while parsing it, the
+// rest goes to temporary files.
+const maxMemory = 1 << 20
+
+// Handlers manages the set of attachment endpoints.
+type Handlers struct {
+	Attachment attachment.Core
+	Expense    expense.Core
+	Household  household.Core
+	MaxSize    int64
+}
+
+// Create attaches the file uploaded as a multipart form, in the file field,
+// to an expense of the authenticated user.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	exp, err := h.authorize(ctx, web.Param(r, "id"), false)
+	if err != nil {
+		return err
+	}
+
+	limit := h.MaxSize + formOverhead
+	if r.ContentLength > limit {
+		return v1Web.NewRequestError(attachment.ErrTooLarge, http.StatusRequestEntityTooLarge)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		return v1Web.NewRequestError(fmt.Errorf("unable to parse form: %w", err), http.StatusBadRequest)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	f, fh, err := r.FormFile("file")
+	if err != nil {
+		return v1Web.NewRequestError(fmt.Errorf("unable to read file: %w", err), http.StatusBadRequest)
+	}
+	defer f.Close()
+
+	na := attachment.NewAttachment{
+		ExpenseID: exp.ID,
+		UserID:    exp.UserID,
+		FileName:  fh.Filename,
+		Size:      fh.Size,
+	}
+
+	att, err := h.Attachment.Create(ctx, na, f, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, attachment.ErrEmpty):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, attachment.ErrTooLarge):
+			return v1Web.NewRequestError(err, http.StatusRequestEntityTooLarge)
+		case errors.Is(err, attachment.ErrUnsupportedType):
+			return v1Web.NewRequestError(err, http.StatusUnsupportedMediaType)
+		default:
+			return fmt.Errorf("expenseID[%s]: %w", exp.ID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, att, http.StatusCreated)
+}
+
+// Query returns the files attached to an expense.
+func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	exp, err := h.authorize(ctx, web.Param(r, "id"), true)
+	if err != nil {
+		return err
+	}
+
+	atts, err := h.Attachment.QueryByExpenseID(ctx, exp.ID)
+	if err != nil {
+		return fmt.Errorf("expenseID[%s]: %w", exp.ID, err)
+	}
+
+	return web.Respond(ctx, w, atts, http.StatusOK)
+}
+
+// Download streams the content of a file attached to an expense.
+func (h Handlers) Download(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	exp, err := h.authorize(ctx, web.Param(r, "id"), true)
+	if err != nil {
+		return err
+	}
+
+	id := web.Param(r, "attachment_id")
+	att, rc, err := h.Attachment.Open(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, attachment.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, attachment.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+	defer rc.Close()
+
+	if att.ExpenseID != exp.ID {
+		return v1Web.NewRequestError(attachment.ErrNotFound, http.StatusNotFound)
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": att.FileName})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	return web.RespondStream(ctx, w, att.ContentType, http.StatusOK, func(w io.Writer) error {
+		_, err := io.Copy(w, rc)
+		return err
+	})
+}
+
+// Delete removes a file attached to an expense.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	exp, err := h.authorize(ctx, web.Param(r, "id"), false)
+	if err != nil {
+		return err
+	}
+
+	id := web.Param(r, "attachment_id")
+	att, err := h.Attachment.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, attachment.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, attachment.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	if att.ExpenseID != exp.ID {
+		return v1Web.NewRequestError(attachment.ErrNotFound, http.StatusNotFound)
+	}
+
+	if err := h.Attachment.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, attachment.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+
+// authorize finds the expense the attachments belong to and checks the
+// authenticated user can get at them. Besides its owner, the members of a
+// household the expense is shared with can see them when shared is set.
+func (h Handlers) authorize(ctx context.Context, expenseID string, shared bool) (expense.Expense, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return expense.Expense{}, v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	exp, err := h.Expense.QueryByID(ctx, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, expense.ErrInvalidID):
+			return expense.Expense{}, v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrNotFound):
+			return expense.Expense{}, v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return expense.Expense{}, fmt.Errorf("ID[%s]: %w", expenseID, err)
+		}
+	}
+
+	if claims.AuthorizedByRole(auth.RoleAdmin) || claims.AuthorizedByUserId(exp.UserID) {
+		return exp, nil
+	}
+
+	if shared {
+		ok, err := h.Household.SharesExpense(ctx, expenseID, claims.Subject)
+		if err != nil {
+			return expense.Expense{}, fmt.Errorf("ID[%s] userID[%s]: %w", expenseID, claims.Subject, err)
+		}
+		if ok {
+			return exp, nil
+		}
+	}
+
+	return expense.Expense{}, v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+}
@@ -0,0 +1,49 @@
+// Package auditgrp maintains the group of handlers for audit log access.
+package auditgrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/audit"
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of audit endpoints.
+type Handlers struct {
+	Audit audit.Core
+}
+
+// Query returns the most recent audit events, up to limit of them. They can
+// be narrowed down to a kind of record with the entity query parameter, to a
+// single record with the id one and to the changes of a user with the actor
+// one.
+func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	values := r.URL.Query()
+
+	limit, err := cursor.ParseLimit(values.Get("limit"))
+	if err != nil {
+		return validate.NewFieldsError("limit", err)
+	}
+
+	var filter audit.QueryFilter
+	if v := values.Get("entity"); v != "" {
+		filter.Entity = &v
+	}
+	if v := values.Get("id"); v != "" {
+		filter.EntityID = &v
+	}
+	if v := values.Get("actor"); v != "" {
+		filter.ActorID = &v
+	}
+
+	evs, err := h.Audit.Query(ctx, filter, limit)
+	if err != nil {
+		return fmt.Errorf("unable to query audit events: %w", err)
+	}
+
+	return web.Respond(ctx, w, evs, http.StatusOK)
+}
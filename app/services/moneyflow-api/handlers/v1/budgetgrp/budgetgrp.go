@@ -0,0 +1,256 @@
+// Package budgetgrp maintains the group of handlers for budget access.
+package budgetgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/budget"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of budget endpoints.
+type Handlers struct {
+	Budget budget.Core
+}
+
+// Create adds a new budget to the system.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var nb budget.NewBudget
+	if err := web.Decode(r, &nb); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	// If you are not an admin and looking to add a budget for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(nb.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	bgt, err := h.Budget.Create(ctx, nb, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidLimit):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, budget.ErrDuplicate):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("budget[%+v]: %w", &bgt, err)
+		}
+	}
+
+	return web.Respond(ctx, w, bgt, http.StatusCreated)
+}
+
+// Update updates a budget in the system.
+func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var upd budget.UpdateBudget
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	bgt, err := h.Budget.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, budget.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying budget[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to update a budget you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(bgt.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Budget.Update(ctx, id, upd, v.Now); err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, budget.ErrInvalidLimit):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, budget.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, budget.ErrDuplicate):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("ID[%s] Budget[%+v]: %w", id, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Delete removes a budget from the system.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	bgt, err := h.Budget.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, budget.ErrNotFound):
+			// Don't send StatusNotFound here since the call to Delete
+			// below won't if this budget is not found.
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querying budget[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to delete a budget you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(bgt.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Budget.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryByID returns a budget by its ID.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	bgt, err := h.Budget.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, budget.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to retrieve someone else's budget.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(bgt.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, bgt, http.StatusOK)
+}
+
+// QueryByUserID returns the budgets of a user.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's budgets.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	bgts, err := h.Budget.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, bgts, http.StatusOK)
+}
+
+// QueryStatusByUserID returns how much of every budget of a user is used in
+// the current month.
+func (h Handlers) QueryStatusByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's budgets.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	sts, err := h.Budget.QueryStatusByUserID(ctx, userID, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, budget.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, sts, http.StatusOK)
+}
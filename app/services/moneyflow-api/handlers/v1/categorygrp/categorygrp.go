@@ -0,0 +1,214 @@
+// Package categorygrp maintains the group of handlers for category access.
+package categorygrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of category endpoints.
+type Handlers struct {
+	Category category.Core
+}
+
+// Create adds a new category to the system.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var nc category.NewCategory
+	if err := web.Decode(r, &nc); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	// If you are not an admin and looking to add a category for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(nc.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	cat, err := h.Category.Create(ctx, nc, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, category.ErrInvalidParent):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrDuplicate):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("category[%+v]: %w", &cat, err)
+		}
+	}
+
+	return web.Respond(ctx, w, cat, http.StatusCreated)
+}
+
+// Update updates a category in the system.
+func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var upd category.UpdateCategory
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	cat, err := h.Category.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying category[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to update a category you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(cat.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Category.Update(ctx, id, upd, v.Now); err != nil {
+		switch {
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrInvalidParent):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, category.ErrReadOnly):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrDuplicate):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("ID[%s] Category[%+v]: %w", id, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Delete removes a category from the system.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	cat, err := h.Category.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			// Don't send StatusNotFound here since the call to Delete
+			// below won't if this category is not found.
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querying category[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to delete a category you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(cat.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Category.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrReadOnly):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrInUse):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryByID returns a category by its ID.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	cat, err := h.Category.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	// Default categories are shared by everyone. If you are not an admin and
+	// looking to retrieve someone else's category.
+	if cat.UserID != "" && !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(cat.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, cat, http.StatusOK)
+}
+
+// QueryByUserID returns the categories available to a user, which are the
+// default ones plus the ones the user added.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's categories.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	cats, err := h.Category.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, cats, http.StatusOK)
+}
@@ -0,0 +1,293 @@
+// Package debtgrp maintains the group of handlers for debt access.
+package debtgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/debt"
+	"github.com/gloompi/ultimate-service/business/sys/amortization"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of debt endpoints.
+type Handlers struct {
+	Debt debt.Core
+}
+
+// Create adds a new debt to the system.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var nd debt.NewDebt
+	if err := web.Decode(r, &nd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	// If you are not an admin and looking to add a debt for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(nd.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	dbt, err := h.Debt.Create(ctx, nd, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, debt.ErrInvalidPrincipal):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("debt[%+v]: %w", &dbt, err)
+		}
+	}
+
+	return web.Respond(ctx, w, dbt, http.StatusCreated)
+}
+
+// Update updates a debt in the system.
+func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	var upd debt.UpdateDebt
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	dbt, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	if err := h.Debt.Update(ctx, dbt.ID, upd, v.Now); err != nil {
+		switch {
+		case errors.Is(err, debt.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, debt.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, debt.ErrInvalidPrincipal):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, debt.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("ID[%s] Debt[%+v]: %w", dbt.ID, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Delete removes a debt and its extra payments from the system.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	dbt, err := h.Debt.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, debt.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, debt.ErrNotFound):
+			// Don't send StatusNotFound here since the call to Delete
+			// below won't if this debt is not found.
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querying debt[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to delete a debt you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(dbt.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Debt.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, debt.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryByID returns a debt by its ID.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	dbt, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, dbt, http.StatusOK)
+}
+
+// QueryByUserID returns the debts of a user.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's debts.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	dbts, err := h.Debt.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, debt.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, dbts, http.StatusOK)
+}
+
+// Pay records an extra payment towards a debt.
+func (h Handlers) Pay(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	var np debt.NewPayment
+	if err := web.Decode(r, &np); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	dbt, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	pmt, err := h.Debt.Pay(ctx, dbt.ID, np, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, debt.ErrInvalidAmount):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, debt.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, debt.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s] Payment[%+v]: %w", dbt.ID, &np, err)
+		}
+	}
+
+	return web.Respond(ctx, w, pmt, http.StatusCreated)
+}
+
+// QuerySchedule returns the amortization schedule of a debt with its extra
+// payments applied.
+func (h Handlers) QuerySchedule(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	dbt, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	sch, err := h.Debt.QuerySchedule(ctx, dbt.ID, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, debt.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, amortization.ErrInvalidLoan):
+			return v1Web.NewRequestError(err, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("ID[%s]: %w", dbt.ID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, sch, http.StatusOK)
+}
+
+// =============================================================================
+
+// queryOwned finds the debt identified by a given ID, as long as the caller
+// owns it or is an admin.
+func (h Handlers) queryOwned(ctx context.Context, id string) (debt.Debt, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return debt.Debt{}, v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	dbt, err := h.Debt.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, debt.ErrInvalidID):
+			return debt.Debt{}, v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, debt.ErrNotFound):
+			return debt.Debt{}, v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return debt.Debt{}, fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to retrieve someone else's debt.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(dbt.UserID) {
+		return debt.Debt{}, v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return dbt, nil
+}
@@ -0,0 +1,114 @@
+// Package duplicategrp maintains the group of handlers for finding and
+// merging duplicate expenses.
+package duplicategrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/duplicate"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of duplicate endpoints.
+type Handlers struct {
+	Duplicate duplicate.Core
+	Expense   expense.Core
+}
+
+// Query returns the pairs of expenses of a user suspected to be duplicates.
+// The user is picked with the user_id query parameter and defaults to the
+// authenticated user.
+func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = claims.Subject
+	}
+
+	// If you are not an admin and looking to retrieve someone else's expenses.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	pairs, err := h.Duplicate.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, duplicate.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, pairs, http.StatusOK)
+}
+
+// Merge keeps one of two duplicate expenses, with the tags and attachments
+// of both, and moves the other to the trash.
+func (h Handlers) Merge(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var m duplicate.Merge
+	if err := web.Decode(r, &m); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	// Both expenses have to belong to the same user, which Merge checks, so
+	// owning the one kept is enough.
+	if m.KeepID != "" {
+		keep, err := h.Expense.QueryByID(ctx, m.KeepID)
+		if err != nil {
+			switch {
+			case errors.Is(err, expense.ErrInvalidID):
+				return v1Web.NewRequestError(err, http.StatusBadRequest)
+			case errors.Is(err, expense.ErrNotFound):
+				return v1Web.NewRequestError(err, http.StatusNotFound)
+			default:
+				return fmt.Errorf("querying expense[%s]: %w", m.KeepID, err)
+			}
+		}
+
+		// If you are not an admin and looking to merge someone else's expenses.
+		if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(keep.UserID) {
+			return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+		}
+	}
+
+	exp, err := h.Duplicate.Merge(ctx, m, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, duplicate.ErrSameExpense):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, duplicate.ErrNotSameOwner):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, expense.ErrVersionConflict):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("Merge[%+v]: %w", &m, err)
+		}
+	}
+
+	return web.Respond(ctx, w, exp, http.StatusOK)
+}
@@ -0,0 +1,159 @@
+package expensegrp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/sys/batch"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// batchErrors lists the errors whose message is given out for an operation
+// of a batch that failed.
+var batchErrors = []error{
+	batch.ErrInvalidOp,
+	batch.ErrMissingData,
+	expense.ErrInvalidID,
+	expense.ErrNotFound,
+	expense.ErrInvalidAmount,
+	expense.ErrDuplicate,
+	expense.ErrVersionConflict,
+	account.ErrInvalidID,
+	account.ErrNotFound,
+	account.ErrCurrencyMismatch,
+	account.ErrNotOwner,
+	category.ErrInvalidID,
+	category.ErrNotFound,
+	category.ErrKindMismatch,
+	category.ErrNotOwner,
+}
+
+// Batch creates, updates and deletes up to batch.MaxOperations expenses within a
+// single transaction. The response reports on each operation; it comes with
+// StatusUnprocessableEntity when an atomic batch failed and nothing was
+// applied.
+func (h Handlers) Batch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var req batch.Request
+	if err := web.Decode(r, &req); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	mode, err := batch.Check(req.Mode, len(req.Operations))
+	if err != nil {
+		return err
+	}
+
+	ops := make([]expense.BatchOperation, len(req.Operations))
+	for i, bop := range req.Operations {
+		op, err := toBatchOperation(bop)
+		if err != nil {
+			return validate.NewFieldsError(fmt.Sprintf("operations[%d].data", i), err)
+		}
+
+		if err := h.authorizeBatch(ctx, claims, op); err != nil {
+			return err
+		}
+
+		ops[i] = op
+	}
+
+	results, err := h.Expense.Batch(ctx, mode, ops, v.Now)
+	status := http.StatusOK
+	if err != nil {
+		if !errors.Is(err, batch.ErrFailed) {
+			return fmt.Errorf("batch: %w", err)
+		}
+		status = http.StatusUnprocessableEntity
+	}
+
+	return web.Respond(ctx, w, batch.NewResponse(mode, results, batchErrors...), status)
+}
+
+// authorizeBatch checks the caller may run the operation. Operations on
+// expenses that can't be found are left for the batch to report.
+func (h Handlers) authorizeBatch(ctx context.Context, claims auth.Claims, op expense.BatchOperation) error {
+	if claims.AuthorizedByRole(auth.RoleAdmin) {
+		return nil
+	}
+
+	switch op.Op {
+	case batch.OpCreate:
+		if op.New != nil && !claims.AuthorizedByUserId(op.New.UserID) {
+			return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+		}
+
+	case batch.OpUpdate, batch.OpDelete:
+		exp, err := h.Expense.QueryByID(ctx, op.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, expense.ErrInvalidID), errors.Is(err, expense.ErrNotFound):
+				return nil
+			default:
+				return fmt.Errorf("querying expense[%s]: %w", op.ID, err)
+			}
+		}
+		if !claims.AuthorizedByUserId(exp.UserID) {
+			return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+		}
+	}
+
+	return nil
+}
+
+// toBatchOperation decodes the data of an operation into what the expense core
+// takes.
+func toBatchOperation(bop batch.Operation) (expense.BatchOperation, error) {
+	op := expense.BatchOperation{
+		Op:      bop.Op,
+		ID:      bop.ID,
+		Version: bop.Version,
+	}
+
+	if len(bop.Data) == 0 {
+		return op, nil
+	}
+
+	switch bop.Op {
+	case batch.OpCreate:
+		op.New = new(expense.NewExpense)
+		if err := decodeData(bop.Data, op.New); err != nil {
+			return expense.BatchOperation{}, err
+		}
+
+	case batch.OpUpdate:
+		op.Update = new(expense.UpdateExpense)
+		if err := decodeData(bop.Data, op.Update); err != nil {
+			return expense.BatchOperation{}, err
+		}
+	}
+
+	return op, nil
+}
+
+// decodeData decodes the data of an operation the way web.Decode decodes a
+// request body.
+func decodeData(data json.RawMessage, val any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(val)
+}
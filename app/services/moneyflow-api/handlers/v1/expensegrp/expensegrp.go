@@ -8,7 +8,14 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
 	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/household"
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
+	"github.com/gloompi/ultimate-service/business/sys/order"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
 	"github.com/gloompi/ultimate-service/business/web/auth"
 	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
 	"github.com/gloompi/ultimate-service/foundation/web"
@@ -16,8 +23,9 @@ import (
 
 // Handlers manages the set of expense endpoints.
 type Handlers struct {
-	Expense expense.Core
-	Auth    *auth.Auth
+	Expense   expense.Core
+	Household household.Core
+	Auth      *auth.Auth
 }
 
 // Create adds a new expense to the system.
@@ -27,14 +35,47 @@ func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return web.NewShutdownError("web value missing from context")
 	}
 
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
 	var ne expense.NewExpense
 	if err := web.Decode(r, &ne); err != nil {
 		return fmt.Errorf("unable to decode payload: %w", err)
 	}
 
+	// If you are not an admin and looking to add an expense for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(ne.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
 	exp, err := h.Expense.Create(ctx, ne, v.Now)
 	if err != nil {
-		return fmt.Errorf("expense[%+v]: %w", &exp, err)
+		switch {
+		case errors.Is(err, expense.ErrInvalidAmount):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrDuplicate):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("expense[%+v]: %w", &exp, err)
+		}
 	}
 
 	return web.Respond(ctx, w, exp, http.StatusCreated)
@@ -57,6 +98,11 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return fmt.Errorf("unable to decode payload: %w", err)
 	}
 
+	version, err := v1Web.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		return v1Web.NewRequestError(err, http.StatusBadRequest)
+	}
+
 	id := web.Param(r, "id")
 
 	exp, err := h.Expense.QueryByID(ctx, id)
@@ -75,12 +121,32 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
 	}
 
-	if err := h.Expense.Update(ctx, id, upd, v.Now); err != nil {
+	if err := h.Expense.Update(ctx, id, upd, version, v.Now); err != nil {
 		switch {
 		case errors.Is(err, expense.ErrInvalidID):
 			return v1Web.NewRequestError(err, http.StatusBadRequest)
 		case errors.Is(err, expense.ErrNotFound):
 			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, expense.ErrVersionConflict):
+			return v1Web.NewRequestError(err, http.StatusPreconditionFailed)
+		case errors.Is(err, expense.ErrInvalidAmount):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
 		default:
 			return fmt.Errorf("ID[%s] Expense[%+v]: %w", id, &upd, err)
 		}
@@ -89,8 +155,13 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
-// Delete removes an expense from the system.
+// Delete moves an expense to the trash.
 func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
 	claims, err := auth.GetClaims(ctx)
 	if err != nil {
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
@@ -118,7 +189,7 @@ func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
 	}
 
-	if err := h.Expense.Delete(ctx, id); err != nil {
+	if err := h.Expense.Delete(ctx, id, v.Now); err != nil {
 		switch {
 		case errors.Is(err, expense.ErrInvalidID):
 			return v1Web.NewRequestError(err, http.StatusBadRequest)
@@ -130,7 +201,91 @@ func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
-// Query returns a list of expenses with paging.
+// Restore takes an expense out of the trash.
+func (h Handlers) Restore(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	exp, err := h.Expense.QueryDeletedByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, expense.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying expense[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to restore an expense you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(exp.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Expense.Restore(ctx, id, v.Now); err != nil {
+		switch {
+		case errors.Is(err, expense.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryPage returns a page of expenses holding up to limit of them, starting
+// right after the cursor query parameter. The expenses are filtered and ordered
+// the same way as Query and the next_cursor of the response fetches the next
+// page.
+func (h Handlers) QueryPage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	limit, err := cursor.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		return validate.NewFieldsError("limit", err)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.Expense.QueryPage(ctx, filter, orderBy, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, cursor.ErrInvalidCursor):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, cursor.ErrOrderMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, order.ErrUnknownField):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("unable to query for expenses: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, page, http.StatusOK)
+}
+
+// Query returns a list of expenses with paging by page number, kept for the
+// clients written before QueryPage. The expenses can be filtered
+// by tag with the tag query parameter, matching any of the tags unless match
+// is all, and by category, currency, min_amount, max_amount, from, to,
+// reoccurrence_type and name. order_by sorts them, as in amount,desc.
 func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	page := web.Param(r, "page")
 	pageNumber, err := strconv.Atoi(page)
@@ -143,9 +298,24 @@ func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Requ
 		return v1Web.NewRequestError(fmt.Errorf("invalid rows format, rows[%s]", rows), http.StatusBadRequest)
 	}
 
-	expenses, err := h.Expense.Query(ctx, pageNumber, rowsPerPage)
+	filter, err := parseFilter(r)
 	if err != nil {
-		return fmt.Errorf("unable to query for expenses: %w", err)
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	expenses, err := h.Expense.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		switch {
+		case errors.Is(err, order.ErrUnknownField):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("unable to query for expenses: %w", err)
+		}
 	}
 
 	return web.Respond(ctx, w, expenses, http.StatusOK)
@@ -153,6 +323,11 @@ func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 // QueryByID returns a expense by its ID.
 func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
 	id := web.Param(r, "id")
 	exp, err := h.Expense.QueryByID(ctx, id)
 	if err != nil {
@@ -166,5 +341,62 @@ func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.
 		}
 	}
 
+	// Besides its owner, the members of a household the expense is shared
+	// with can see it.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(exp.UserID) {
+		shared, err := h.Household.SharesExpense(ctx, id, claims.Subject)
+		if err != nil {
+			return fmt.Errorf("ID[%s] userID[%s]: %w", id, claims.Subject, err)
+		}
+		if !shared {
+			return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+		}
+	}
+
+	w.Header().Set("ETag", v1Web.ETag(exp.Version))
 	return web.Respond(ctx, w, exp, http.StatusOK)
 }
+
+// QueryByUserID returns a list of expenses for a user, which can be filtered
+// and ordered the same way as Query.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's expenses.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	expenses, err := h.Expense.QueryByUserID(ctx, userID, filter, orderBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, expense.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, order.ErrUnknownField):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, fx.ErrRateNotFound):
+			return v1Web.NewRequestError(err, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, expenses, http.StatusOK)
+}
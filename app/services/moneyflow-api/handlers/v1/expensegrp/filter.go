@@ -0,0 +1,118 @@
+package expensegrp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/order"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+)
+
+// errAmountCurrency is returned when an amount bound comes without the
+// currency it is written in.
+var errAmountCurrency = errors.New("amount bounds need a currency")
+
+// parseFilter reads the filter of a list of expenses from the query string.
+// Amount bounds are written in the currency parameter, dates are either
+// days or RFC 3339 timestamps and a to day includes the whole day.
+func parseFilter(r *http.Request) (expense.QueryFilter, error) {
+	values := r.URL.Query()
+
+	tf, err := tag.NewFilter(values["tag"], values.Get("match"))
+	if err != nil {
+		return expense.QueryFilter{}, validate.NewFieldsError("match", err)
+	}
+
+	filter := expense.QueryFilter{
+		Tag: tf,
+	}
+
+	if v := values.Get("category"); v != "" {
+		filter.CategoryID = &v
+	}
+
+	if v := values.Get("currency"); v != "" {
+		v = strings.ToUpper(v)
+		filter.Currency = &v
+	}
+
+	for _, bound := range []struct {
+		field string
+		dest  **int64
+	}{
+		{"min_amount", &filter.MinAmount},
+		{"max_amount", &filter.MaxAmount},
+	} {
+		v := values.Get(bound.field)
+		if v == "" {
+			continue
+		}
+		if filter.Currency == nil {
+			return expense.QueryFilter{}, validate.NewFieldsError(bound.field, errAmountCurrency)
+		}
+		m, err := money.Parse(v, *filter.Currency)
+		if err != nil {
+			return expense.QueryFilter{}, validate.NewFieldsError(bound.field, err)
+		}
+		*bound.dest = &m.Amount
+	}
+
+	if v := values.Get("from"); v != "" {
+		t, _, err := parseDate(v)
+		if err != nil {
+			return expense.QueryFilter{}, validate.NewFieldsError("from", err)
+		}
+		filter.From = &t
+	}
+
+	if v := values.Get("to"); v != "" {
+		t, day, err := parseDate(v)
+		if err != nil {
+			return expense.QueryFilter{}, validate.NewFieldsError("to", err)
+		}
+		if day {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.To = &t
+	}
+
+	if v := values.Get("reoccurrence_type"); v != "" {
+		filter.ReoccurrenceType = &v
+	}
+
+	if v := strings.TrimSpace(values.Get("name")); v != "" {
+		filter.Name = &v
+	}
+
+	return filter, nil
+}
+
+// parseOrder reads the order of a list of expenses from the query string.
+func parseOrder(r *http.Request) (order.By, error) {
+	orderBy, err := order.Parse(r.URL.Query().Get("order_by"), expense.DefaultOrderBy)
+	if err != nil {
+		return order.By{}, validate.NewFieldsError("order_by", err)
+	}
+
+	return orderBy, nil
+}
+
+// parseDate reads either a day or an RFC 3339 timestamp. The second value
+// is true for a day.
+func parseDate(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false, errors.New("date must be either YYYY-MM-DD or RFC 3339")
+	}
+
+	return t, false, nil
+}
@@ -0,0 +1,166 @@
+package expensegrp
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_ParseFilter(t *testing.T) {
+	tags := func(match string, names ...string) tag.Filter {
+		f, err := tag.NewFilter(names, match)
+		if err != nil {
+			t.Fatalf("Building tag filter: %s", err)
+		}
+		return f
+	}
+
+	str := func(v string) *string {
+		return &v
+	}
+
+	amount := func(v int64) *int64 {
+		return &v
+	}
+
+	date := func(v time.Time) *time.Time {
+		return &v
+	}
+
+	tt := []struct {
+		name     string
+		query    string
+		exp      expense.QueryFilter
+		expField string
+		expErr   error
+	}{
+		{
+			name:  "empty",
+			query: "",
+			exp:   expense.QueryFilter{Tag: tags("")},
+		},
+		{
+			name:  "tags",
+			query: "tag=Food&tag=rent,%20Bills&match=all",
+			exp:   expense.QueryFilter{Tag: tags(tag.MatchAll, "food", "rent", "bills")},
+		},
+		{
+			name:     "badMatch",
+			query:    "tag=food&match=some",
+			expField: "match",
+			expErr:   tag.ErrInvalidMatch,
+		},
+		{
+			name:  "fields",
+			query: "category=4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9&reoccurrence_type=Monthly&name=%20%20Rent%20",
+			exp: expense.QueryFilter{
+				Tag:              tags(""),
+				CategoryID:       str("4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9"),
+				ReoccurrenceType: str("Monthly"),
+				Name:             str("Rent"),
+			},
+		},
+		{
+			name:  "blankName",
+			query: "name=%20%20",
+			exp:   expense.QueryFilter{Tag: tags("")},
+		},
+		{
+			name:  "amounts",
+			query: "currency=eur&min_amount=10.5&max_amount=100",
+			exp: expense.QueryFilter{
+				Tag:       tags(""),
+				Currency:  str("EUR"),
+				MinAmount: amount(1050),
+				MaxAmount: amount(10000),
+			},
+		},
+		{
+			name:     "minAmountNoCurrency",
+			query:    "min_amount=10",
+			expField: "min_amount",
+			expErr:   errAmountCurrency,
+		},
+		{
+			name:     "maxAmountNoCurrency",
+			query:    "max_amount=10",
+			expField: "max_amount",
+			expErr:   errAmountCurrency,
+		},
+		{
+			name:     "badAmount",
+			query:    "currency=EUR&min_amount=ten",
+			expField: "min_amount",
+		},
+		{
+			name:  "days",
+			query: "from=2019-01-01&to=2019-01-31",
+			exp: expense.QueryFilter{
+				Tag:  tags(""),
+				From: date(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)),
+				To:   date(time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		{
+			name:  "timestamps",
+			query: "from=2019-01-01T10:00:00Z&to=2019-01-31T18:30:00Z",
+			exp: expense.QueryFilter{
+				Tag:  tags(""),
+				From: date(time.Date(2019, time.January, 1, 10, 0, 0, 0, time.UTC)),
+				To:   date(time.Date(2019, time.January, 31, 18, 30, 0, 0, time.UTC)),
+			},
+		},
+		{
+			name:     "badDate",
+			query:    "to=31/01/2019",
+			expField: "to",
+		},
+	}
+
+	t.Log("Given the need to read the filter of a list of expenses.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s query.", testID, tst.name)
+				{
+					r := httptest.NewRequest("GET", "/v1/expenses?"+tst.query, nil)
+
+					got, err := parseFilter(r)
+					if tst.expField != "" {
+						var fe validate.FieldErrors
+						if !errors.As(err, &fe) || len(fe) != 1 || fe[0].Field != tst.expField {
+							t.Fatalf("\t%s\tTest %d:\tShould refuse the %s field, got %v.", failed, testID, tst.expField, err)
+						}
+						if tst.expErr != nil && fe[0].Error != tst.expErr.Error() {
+							t.Fatalf("\t%s\tTest %d:\tShould get error %q, got %q.", failed, testID, tst.expErr, fe[0].Error)
+						}
+						t.Logf("\t%s\tTest %d:\tShould refuse the %s field.", success, testID, tst.expField)
+						return
+					}
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to parse the filter : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to parse the filter.", success, testID)
+
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected filter. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected filter.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
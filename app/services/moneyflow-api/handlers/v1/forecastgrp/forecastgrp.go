@@ -0,0 +1,78 @@
+// Package forecastgrp maintains the group of handlers for forecast access.
+package forecastgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gloompi/ultimate-service/business/core/forecast"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// defaultMonths is the number of months projected when none is provided.
+const defaultMonths = 12
+
+// Handlers manages the set of forecast endpoints.
+type Handlers struct {
+	Forecast forecast.Core
+}
+
+// QueryByUserID returns the cash-flow forecast of a user. The number of
+// months projected can be set with the months query parameter, and nested
+// categories rolled up into their parents with the rollup one.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's forecast.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	months := defaultMonths
+	if m := r.URL.Query().Get("months"); m != "" {
+		if months, err = strconv.Atoi(m); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid months format [%s]", m), http.StatusBadRequest)
+		}
+	}
+
+	var rollup bool
+	if ru := r.URL.Query().Get("rollup"); ru != "" {
+		if rollup, err = strconv.ParseBool(ru); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid rollup format [%s]", ru), http.StatusBadRequest)
+		}
+	}
+
+	fc, err := h.Forecast.QueryByUserID(ctx, userID, v.Now, months, rollup)
+	if err != nil {
+		switch {
+		case errors.Is(err, forecast.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, forecast.ErrInvalidMonths):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, forecast.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, fx.ErrRateNotFound):
+			return v1Web.NewRequestError(err, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, fc, http.StatusOK)
+}
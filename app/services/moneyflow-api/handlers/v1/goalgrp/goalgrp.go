@@ -0,0 +1,320 @@
+// Package goalgrp maintains the group of handlers for savings goal access.
+package goalgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/goal"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of goal endpoints.
+type Handlers struct {
+	Goal goal.Core
+}
+
+// Create adds a new goal to the system.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var ng goal.NewGoal
+	if err := web.Decode(r, &ng); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	// If you are not an admin and looking to add a goal for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(ng.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	gl, err := h.Goal.Create(ctx, ng, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidTarget):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrInvalidDeadline):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("goal[%+v]: %w", &gl, err)
+		}
+	}
+
+	return web.Respond(ctx, w, gl, http.StatusCreated)
+}
+
+// Update updates a goal in the system.
+func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var upd goal.UpdateGoal
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	gl, err := h.Goal.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying goal[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to update a goal you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(gl.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Goal.Update(ctx, id, upd, v.Now); err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, goal.ErrInvalidTarget):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrInvalidDeadline):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("ID[%s] Goal[%+v]: %w", id, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Delete removes a goal and its contributions from the system.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	gl, err := h.Goal.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrNotFound):
+			// Don't send StatusNotFound here since the call to Delete
+			// below won't if this goal is not found.
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querying goal[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to delete a goal you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(gl.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Goal.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryByID returns a goal by its ID.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	gl, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, gl, http.StatusOK)
+}
+
+// QueryByUserID returns the goals of a user.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's goals.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	gls, err := h.Goal.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, gls, http.StatusOK)
+}
+
+// Contribute records money put towards a goal.
+func (h Handlers) Contribute(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	var nc goal.NewContribution
+	if err := web.Decode(r, &nc); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	gl, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	ctb, err := h.Goal.Contribute(ctx, gl.ID, nc, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidAmount):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s] Contribution[%+v]: %w", gl.ID, &nc, err)
+		}
+	}
+
+	return web.Respond(ctx, w, ctb, http.StatusCreated)
+}
+
+// QueryContributions returns the contributions made to a goal.
+func (h Handlers) QueryContributions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	gl, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	ctbs, err := h.Goal.QueryContributions(ctx, gl.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", gl.ID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, ctbs, http.StatusOK)
+}
+
+// QueryStatus returns how far a goal is from its target, the monthly
+// contribution needed to reach it by the deadline and when it is projected to
+// be reached.
+func (h Handlers) QueryStatus(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	gl, err := h.queryOwned(ctx, web.Param(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	st, err := h.Goal.QueryStatus(ctx, gl.ID, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, fx.ErrRateNotFound):
+			return v1Web.NewRequestError(err, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("ID[%s]: %w", gl.ID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, st, http.StatusOK)
+}
+
+// =============================================================================
+
+// queryOwned finds the goal identified by a given ID, as long as the caller
+// owns it or is an admin.
+func (h Handlers) queryOwned(ctx context.Context, id string) (goal.Goal, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return goal.Goal{}, v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	gl, err := h.Goal.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, goal.ErrInvalidID):
+			return goal.Goal{}, v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, goal.ErrNotFound):
+			return goal.Goal{}, v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return goal.Goal{}, fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to retrieve someone else's goal.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(gl.UserID) {
+		return goal.Goal{}, v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return gl, nil
+}
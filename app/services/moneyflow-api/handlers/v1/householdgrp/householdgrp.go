@@ -0,0 +1,519 @@
+// Package householdgrp maintains the group of handlers for household access.
+package householdgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/household"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of household endpoints.
+type Handlers struct {
+	Household household.Core
+	Expense   expense.Core
+}
+
+// Create adds a new household to the system with the caller as its owner.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var nh household.NewHousehold
+	if err := web.Decode(r, &nh); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	hh, err := h.Household.Create(ctx, nh, claims.Subject, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, user.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("household[%+v]: %w", &hh, err)
+		}
+	}
+
+	return web.Respond(ctx, w, hh, http.StatusCreated)
+}
+
+// Update updates a household in the system.
+func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	var upd household.UpdateHousehold
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	if err := h.authorize(ctx, id, household.RoleOwner); err != nil {
+		return err
+	}
+
+	if err := h.Household.Update(ctx, id, upd, v.Now); err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s] Household[%+v]: %w", id, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Delete removes a household along with its splits and settlements from the
+// system.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id := web.Param(r, "id")
+
+	if err := h.authorize(ctx, id, household.RoleOwner); err != nil {
+		return err
+	}
+
+	if err := h.Household.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryByID returns a household with its members.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id := web.Param(r, "id")
+
+	if err := h.authorize(ctx, id, household.RoleViewer); err != nil {
+		return err
+	}
+
+	hh, err := h.Household.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, hh, http.StatusOK)
+}
+
+// QueryByUserID returns the households a user is a member of.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's households.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	hhs, err := h.Household.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, hhs, http.StatusOK)
+}
+
+// AddMember adds a user to a household.
+func (h Handlers) AddMember(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	var nm household.NewMember
+	if err := web.Decode(r, &nm); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	if err := h.authorize(ctx, id, household.RoleOwner); err != nil {
+		return err
+	}
+
+	mbr, err := h.Household.AddMember(ctx, id, nm, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, household.ErrDuplicate):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		case errors.Is(err, user.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, user.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s] Member[%+v]: %w", id, &nm, err)
+		}
+	}
+
+	return web.Respond(ctx, w, mbr, http.StatusCreated)
+}
+
+// UpdateMember changes the role of a member of a household.
+func (h Handlers) UpdateMember(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var upd household.UpdateMember
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+	userID := web.Param(r, "user_id")
+
+	if err := h.authorize(ctx, id, household.RoleOwner); err != nil {
+		return err
+	}
+
+	if err := h.Household.UpdateMember(ctx, id, userID, upd); err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotMember):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, household.ErrLastOwner):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("ID[%s] userID[%s] Member[%+v]: %w", id, userID, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// RemoveMember takes a user out of a household. Owners can remove anyone and
+// every member can leave on their own.
+func (h Handlers) RemoveMember(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+	userID := web.Param(r, "user_id")
+
+	role := household.RoleOwner
+	if claims.AuthorizedByUserId(userID) {
+		role = household.RoleViewer
+	}
+
+	if err := h.authorize(ctx, id, role); err != nil {
+		return err
+	}
+
+	if err := h.Household.RemoveMember(ctx, id, userID); err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotMember):
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		case errors.Is(err, household.ErrLastOwner):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("ID[%s] userID[%s]: %w", id, userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Split shares one of the caller's expenses with a household.
+func (h Handlers) Split(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var ns household.NewSplit
+	if err := web.Decode(r, &ns); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+	expenseID := web.Param(r, "expense_id")
+
+	if err := h.authorize(ctx, id, household.RoleMember); err != nil {
+		return err
+	}
+
+	exp, err := h.Expense.QueryByID(ctx, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, expense.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying expense[%s]: %w", expenseID, err)
+		}
+	}
+
+	// If you are not an admin and looking to split someone else's expense.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(exp.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	spl, err := h.Household.Split(ctx, id, expenseID, ns, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, household.ErrNotMember):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrInsufficientRole):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, household.ErrInvalidSplit):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s] expenseID[%s] Split[%+v]: %w", id, expenseID, &ns, err)
+		}
+	}
+
+	return web.Respond(ctx, w, spl, http.StatusOK)
+}
+
+// Unsplit stops sharing one of the caller's expenses with a household.
+func (h Handlers) Unsplit(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+	expenseID := web.Param(r, "expense_id")
+
+	if err := h.authorize(ctx, id, household.RoleMember); err != nil {
+		return err
+	}
+
+	exp, err := h.Expense.QueryByID(ctx, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, expense.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, expense.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying expense[%s]: %w", expenseID, err)
+		}
+	}
+
+	// If you are not an admin and looking to unshare someone else's expense.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(exp.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Household.Unsplit(ctx, id, expenseID); err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrSplitNotFound):
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("ID[%s] expenseID[%s]: %w", id, expenseID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QuerySplits returns the expenses shared with a household.
+func (h Handlers) QuerySplits(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id := web.Param(r, "id")
+
+	if err := h.authorize(ctx, id, household.RoleViewer); err != nil {
+		return err
+	}
+
+	spls, err := h.Household.QuerySplits(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, spls, http.StatusOK)
+}
+
+// QueryBalances returns who owes whom in a household.
+func (h Handlers) QueryBalances(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	id := web.Param(r, "id")
+
+	if err := h.authorize(ctx, id, household.RoleViewer); err != nil {
+		return err
+	}
+
+	bals, err := h.Household.QueryBalances(ctx, id, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, bals, http.StatusOK)
+}
+
+// Settle records money paid back to the caller by another member of a
+// household. Owners can record it between any two members.
+func (h Handlers) Settle(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var ns household.NewSettlement
+	if err := web.Decode(r, &ns); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	// Only the member who got paid back can say so, unless you own the
+	// household.
+	role := household.RoleMember
+	if claims.Subject != ns.ToUserID {
+		role = household.RoleOwner
+	}
+
+	if err := h.authorize(ctx, id, role); err != nil {
+		return err
+	}
+
+	stl, err := h.Household.Settle(ctx, id, ns, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotMember):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrInvalidAmount):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s] Settlement[%+v]: %w", id, &ns, err)
+		}
+	}
+
+	return web.Respond(ctx, w, stl, http.StatusCreated)
+}
+
+// QuerySettlements returns the settlements of a household.
+func (h Handlers) QuerySettlements(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id := web.Param(r, "id")
+
+	if err := h.authorize(ctx, id, household.RoleViewer); err != nil {
+		return err
+	}
+
+	stls, err := h.Household.QuerySettlements(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, stls, http.StatusOK)
+}
+
+// =============================================================================
+
+// authorize makes sure the caller is an admin or a member of the household
+// identified by a given ID with at least the given role.
+func (h Handlers) authorize(ctx context.Context, id string, role string) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if claims.AuthorizedByRole(auth.RoleAdmin) {
+		return nil
+	}
+
+	if err := h.Household.Check(ctx, id, claims.Subject, role); err != nil {
+		switch {
+		case errors.Is(err, household.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, household.ErrNotMember):
+			// Outsiders can't tell a household they don't belong to
+			// from one that doesn't exist.
+			return v1Web.NewRequestError(household.ErrNotFound, http.StatusNotFound)
+		case errors.Is(err, household.ErrInsufficientRole):
+			return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+		default:
+			return fmt.Errorf("ID[%s] userID[%s]: %w", id, claims.Subject, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package importgrp maintains the group of handlers for statement imports.
+package importgrp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/statement"
+	"github.com/gloompi/ultimate-service/business/sys/importers"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// maxUploadSize is the largest statement that can be uploaded.
+const maxUploadSize = 10 << 20
+
+// Handlers manages the set of import endpoints.
+type Handlers struct {
+	Statement statement.Core
+}
+
+// CSV imports a CSV bank statement as incomes and expenses of the
+// authenticated user. The statement is uploaded as a multipart form with the
+// file in the file field and the column mapping as JSON in the mapping
+// field. With the dry_run query parameter nothing is stored.
+func (h Handlers) CSV(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var m struct {
+		importers.CSV
+		statement.Options
+	}
+
+	return h.importStatement(ctx, w, r, "mapping", &m, func() (importers.Importer, statement.Options) {
+		return m.CSV, m.Options
+	})
+}
+
+// OFX imports an OFX bank statement as incomes and expenses of the
+// authenticated user, the same way as CSV. The options field is optional.
+func (h Handlers) OFX(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var opts statement.Options
+
+	return h.importStatement(ctx, w, r, "options", &opts, func() (importers.Importer, statement.Options) {
+		return importers.OFX{}, opts
+	})
+}
+
+// QIF imports a QIF bank statement as incomes and expenses of the
+// authenticated user, the same way as CSV. The options field holds the
+// currency of the statement.
+func (h Handlers) QIF(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var opts struct {
+		importers.QIF
+		statement.Options
+	}
+
+	return h.importStatement(ctx, w, r, "options", &opts, func() (importers.Importer, statement.Options) {
+		return opts.QIF, opts.Options
+	})
+}
+
+// =============================================================================
+
+// importStatement reads the uploaded statement with the importer built from
+// the JSON document in the field, and imports it for the authenticated user.
+func (h Handlers) importStatement(ctx context.Context, w http.ResponseWriter, r *http.Request, field string, val any, importer func() (importers.Importer, statement.Options)) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var dryRun bool
+	if dr := r.URL.Query().Get("dry_run"); dr != "" {
+		if dryRun, err = strconv.ParseBool(dr); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid dry_run format [%s]", dr), http.StatusBadRequest)
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return v1Web.NewRequestError(fmt.Errorf("unable to parse form: %w", err), http.StatusBadRequest)
+	}
+
+	if doc := r.FormValue(field); doc != "" {
+		if err := json.Unmarshal([]byte(doc), val); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("unable to decode %s: %w", field, err), http.StatusBadRequest)
+		}
+	}
+
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		return v1Web.NewRequestError(fmt.Errorf("unable to read file: %w", err), http.StatusBadRequest)
+	}
+	defer f.Close()
+
+	imp, opts := importer()
+
+	recs, err := imp.Import(f)
+	if err != nil {
+		if validate.IsFieldErrors(err) {
+			return fmt.Errorf("parsing statement: %w", err)
+		}
+		return v1Web.NewRequestError(fmt.Errorf("unable to parse statement: %w", err), http.StatusBadRequest)
+	}
+
+	userID := claims.Subject
+
+	res, err := h.Statement.Import(ctx, userID, opts, recs, dryRun, v.Now)
+	if err != nil {
+		switch {
+		case errors.Is(err, statement.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, statement.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	return web.Respond(ctx, w, res, status)
+}
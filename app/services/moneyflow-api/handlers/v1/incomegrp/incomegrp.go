@@ -8,7 +8,13 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/fx"
 	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
+	"github.com/gloompi/ultimate-service/business/sys/order"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
 	"github.com/gloompi/ultimate-service/business/web/auth"
 	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
 	"github.com/gloompi/ultimate-service/foundation/web"
@@ -27,14 +33,47 @@ func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return web.NewShutdownError("web value missing from context")
 	}
 
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
 	var ni income.NewIncome
 	if err := web.Decode(r, &ni); err != nil {
 		return fmt.Errorf("unable to decode payload: %w", err)
 	}
 
+	// If you are not an admin and looking to add an income for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(ni.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
 	inc, err := h.Income.Create(ctx, ni, v.Now)
 	if err != nil {
-		return fmt.Errorf("income[%+v]: %w", &inc, err)
+		switch {
+		case errors.Is(err, income.ErrInvalidAmount):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, income.ErrDuplicate):
+			return v1Web.NewRequestError(err, http.StatusConflict)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("income[%+v]: %w", &inc, err)
+		}
 	}
 
 	return web.Respond(ctx, w, inc, http.StatusCreated)
@@ -57,6 +96,11 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return fmt.Errorf("unable to decode payload: %w", err)
 	}
 
+	version, err := v1Web.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		return v1Web.NewRequestError(err, http.StatusBadRequest)
+	}
+
 	id := web.Param(r, "id")
 
 	inc, err := h.Income.QueryByID(ctx, id)
@@ -72,16 +116,36 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 	}
 
 	// If you are not an admin and looking to update a income you don't own.
-	if !claims.AuthorizedByRole(auth.RoleAdmin) && claims.AuthorizedByUserId(inc.UserID) {
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(inc.UserID) {
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
 	}
 
-	if err := h.Income.Update(ctx, id, upd, v.Now); err != nil {
+	if err := h.Income.Update(ctx, id, upd, version, v.Now); err != nil {
 		switch {
 		case errors.Is(err, income.ErrInvalidID):
 			return v1Web.NewRequestError(err, http.StatusBadRequest)
 		case errors.Is(err, income.ErrNotFound):
 			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, income.ErrVersionConflict):
+			return v1Web.NewRequestError(err, http.StatusPreconditionFailed)
+		case errors.Is(err, income.ErrInvalidAmount):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrCurrencyMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, account.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
+		case errors.Is(err, category.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrKindMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, category.ErrNotOwner):
+			return v1Web.NewRequestError(err, http.StatusForbidden)
 		default:
 			return fmt.Errorf("ID[%s] Income[%+v]: %w", id, &upd, err)
 		}
@@ -90,8 +154,13 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
-// Delete removes an income from the system.
+// Delete moves an income to the trash.
 func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
 	claims, err := auth.GetClaims(ctx)
 	if err != nil {
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
@@ -119,7 +188,7 @@ func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
 	}
 
-	if err := h.Income.Delete(ctx, id); err != nil {
+	if err := h.Income.Delete(ctx, id, v.Now); err != nil {
 		switch {
 		case errors.Is(err, income.ErrInvalidID):
 			return v1Web.NewRequestError(err, http.StatusBadRequest)
@@ -131,7 +200,91 @@ func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
-// Query returns a list of incomes with paging.
+// Restore takes an income out of the trash.
+func (h Handlers) Restore(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	inc, err := h.Income.QueryDeletedByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, income.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, income.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying income[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to restore an income you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(inc.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Income.Restore(ctx, id, v.Now); err != nil {
+		switch {
+		case errors.Is(err, income.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryPage returns a page of incomes holding up to limit of them, starting
+// right after the cursor query parameter. The incomes are filtered and ordered
+// the same way as Query and the next_cursor of the response fetches the next
+// page.
+func (h Handlers) QueryPage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	limit, err := cursor.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		return validate.NewFieldsError("limit", err)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.Income.QueryPage(ctx, filter, orderBy, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, cursor.ErrInvalidCursor):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, cursor.ErrOrderMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, order.ErrUnknownField):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("unable to query for incomes: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, page, http.StatusOK)
+}
+
+// Query returns a list of incomes with paging by page number, kept for the
+// clients written before QueryPage. The incomes can be filtered
+// by tag with the tag query parameter, matching any of the tags unless match
+// is all, and by category, currency, min_amount, max_amount, from, to,
+// reoccurrence_type and name. order_by sorts them, as in amount,desc.
 func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	page := web.Param(r, "page")
 	pageNumber, err := strconv.Atoi(page)
@@ -144,9 +297,24 @@ func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Requ
 		return v1Web.NewRequestError(fmt.Errorf("invalid rows format, rows[%s]", rows), http.StatusBadRequest)
 	}
 
-	incomes, err := h.Income.Query(ctx, pageNumber, rowsPerPage)
+	filter, err := parseFilter(r)
 	if err != nil {
-		return fmt.Errorf("unable to query for incomes: %w", err)
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	incomes, err := h.Income.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		switch {
+		case errors.Is(err, order.ErrUnknownField):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("unable to query for incomes: %w", err)
+		}
 	}
 
 	return web.Respond(ctx, w, incomes, http.StatusOK)
@@ -167,10 +335,12 @@ func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.
 		}
 	}
 
+	w.Header().Set("ETag", v1Web.ETag(inc.Version))
 	return web.Respond(ctx, w, inc, http.StatusOK)
 }
 
-// QueryByUserID returns a list of incomes for a user.
+// QueryByUserID returns a list of incomes for a user, which can be filtered
+// and ordered the same way as Query.
 func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	claims, err := auth.GetClaims(ctx)
 	if err != nil {
@@ -184,13 +354,27 @@ func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *h
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
 	}
 
-	incomes, err := h.Income.QueryByUserID(ctx, userID)
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	incomes, err := h.Income.QueryByUserID(ctx, userID, filter, orderBy)
 	if err != nil {
 		switch {
 		case errors.Is(err, income.ErrInvalidID):
 			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, order.ErrUnknownField):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
 		case errors.Is(err, income.ErrNotFound):
 			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, fx.ErrRateNotFound):
+			return v1Web.NewRequestError(err, http.StatusUnprocessableEntity)
 		default:
 			return fmt.Errorf("userID[%s]: %w", userID, err)
 		}
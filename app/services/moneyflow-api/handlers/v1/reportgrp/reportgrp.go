@@ -0,0 +1,94 @@
+// Package reportgrp maintains the group of handlers for report access.
+package reportgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/report"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of report endpoints.
+type Handlers struct {
+	Report report.Core
+}
+
+// Summary returns the income and expense summary of the user in the user_id
+// query parameter. The period is set with the from and to query parameters,
+// defaulting to the start of the year up to now, and the buckets with the
+// group_by one, defaulting to month. Nested categories can be rolled up into
+// their parents with the rollup query parameter.
+func (h Handlers) Summary(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	qs := r.URL.Query()
+	userID := qs.Get("user_id")
+
+	// If you are not an admin and looking to retrieve someone else's report.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	now := v.Now.UTC()
+	from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	if s := qs.Get("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid from format [%s]", s), http.StatusBadRequest)
+		}
+	}
+
+	to := now
+	if s := qs.Get("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid to format [%s]", s), http.StatusBadRequest)
+		}
+	}
+
+	groupBy := report.GroupByMonth
+	if s := qs.Get("group_by"); s != "" {
+		groupBy = s
+	}
+
+	var rollup bool
+	if ru := qs.Get("rollup"); ru != "" {
+		if rollup, err = strconv.ParseBool(ru); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid rollup format [%s]", ru), http.StatusBadRequest)
+		}
+	}
+
+	sum, err := h.Report.QuerySummary(ctx, userID, groupBy, from, to, rollup)
+	if err != nil {
+		switch {
+		case errors.Is(err, report.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, report.ErrInvalidGroupBy):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, report.ErrInvalidRange):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, report.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, fx.ErrRateNotFound):
+			return v1Web.NewRequestError(err, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, sum, http.StatusOK)
+}
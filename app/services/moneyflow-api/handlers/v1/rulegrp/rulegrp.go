@@ -0,0 +1,275 @@
+// Package rulegrp maintains the group of handlers for categorization rules.
+package rulegrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/rule"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+	"github.com/gloompi/ultimate-service/foundation/worker"
+)
+
+// Handlers manages the set of rule endpoints.
+type Handlers struct {
+	Rule     rule.Core
+	Worker   *worker.Worker
+	ApplyJob string
+}
+
+// Create adds a new rule to the system.
+func (h Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var nr rule.NewRule
+	if err := web.Decode(r, &nr); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	// If you are not an admin and looking to add a rule for someone else.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(nr.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	rl, err := h.Rule.Create(ctx, nr, v.Now)
+	if err != nil {
+		if reqErr := categoryError(err); reqErr != nil {
+			return reqErr
+		}
+		return fmt.Errorf("rule[%+v]: %w", &nr, err)
+	}
+
+	return web.Respond(ctx, w, rl, http.StatusCreated)
+}
+
+// Update modifies data about a rule.
+func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var upd rule.UpdateRule
+	if err := web.Decode(r, &upd); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	id := web.Param(r, "id")
+
+	rl, err := h.Rule.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, rule.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rule.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querying rule[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to update a rule you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(rl.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Rule.Update(ctx, id, upd, v.Now); err != nil {
+		if reqErr := categoryError(err); reqErr != nil {
+			return reqErr
+		}
+		switch {
+		case errors.Is(err, rule.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rule.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s] Rule[%+v]: %w", id, &upd, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Delete removes a rule from the system.
+func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	rl, err := h.Rule.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, rule.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rule.ErrNotFound):
+			// Don't send StatusNotFound here since the call to Delete
+			// below won't if this rule is not found.
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querying rule[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to delete a rule you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(rl.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.Rule.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, rule.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryByID returns a rule by its ID.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+
+	rl, err := h.Rule.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, rule.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rule.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to retrieve someone else's rule.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(rl.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, rl, http.StatusOK)
+}
+
+// QueryByUserID returns the rules of a user in the order they run.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's rules.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	rls, err := h.Rule.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, rule.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, rls, http.StatusOK)
+}
+
+// Apply starts running the rules of a user over every income and expense it
+// already has, in the background. It responds with the key of the work right
+// away.
+func (h Handlers) Apply(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to run someone else's rules.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	if _, err := h.Rule.QueryByUserID(ctx, userID); err != nil {
+		switch {
+		case errors.Is(err, rule.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	if h.Worker == nil {
+		return errors.New("worker is not configured")
+	}
+
+	// The work outlives the request, so it only keeps who asked for it to
+	// record the changes it makes under their name.
+	workCtx := auth.SetClaims(context.Background(), claims)
+
+	workKey, err := h.Worker.Start(workCtx, v.TraceID, h.ApplyJob, userID)
+	if err != nil {
+		return fmt.Errorf("userID[%s]: %w", userID, err)
+	}
+
+	resp := struct {
+		WorkKey string `json:"work_key"`
+	}{
+		WorkKey: workKey,
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusAccepted)
+}
+
+// =============================================================================
+
+// categoryError turns the errors about the category of a rule into request
+// errors, nil when the error is about something else.
+func categoryError(err error) error {
+	switch {
+	case errors.Is(err, category.ErrInvalidID):
+		return v1Web.NewRequestError(err, http.StatusBadRequest)
+	case errors.Is(err, category.ErrNotFound):
+		return v1Web.NewRequestError(err, http.StatusBadRequest)
+	case errors.Is(err, category.ErrKindMismatch):
+		return v1Web.NewRequestError(err, http.StatusBadRequest)
+	case errors.Is(err, category.ErrNotOwner):
+		return v1Web.NewRequestError(err, http.StatusForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package transactiongrp maintains the group of handlers for transaction access.
+package transactiongrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of transaction endpoints.
+type Handlers struct {
+	Transaction transaction.Core
+}
+
+// QueryByID returns a transaction by its ID.
+func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	id := web.Param(r, "id")
+	trn, err := h.Transaction.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, transaction.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", id, err)
+		}
+	}
+
+	// If you are not an admin and looking to retrieve a transaction you don't own.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(trn.UserID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, trn, http.StatusOK)
+}
+
+// QueryByUserID returns the ledger of a user. The range can be narrowed down
+// with the from and to query parameters in the YYYY-MM-DD format.
+func (h Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "user_id")
+
+	// If you are not an admin and looking to retrieve someone else's ledger.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && !claims.AuthorizedByUserId(userID) {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	var from time.Time
+	if s := r.URL.Query().Get("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid from format [%s]", s), http.StatusBadRequest)
+		}
+	}
+
+	to := v.Now
+	if s := r.URL.Query().Get("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			return v1Web.NewRequestError(fmt.Errorf("invalid to format [%s]", s), http.StatusBadRequest)
+		}
+	}
+
+	trns, err := h.Transaction.QueryByUserID(ctx, userID, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, trns, http.StatusOK)
+}
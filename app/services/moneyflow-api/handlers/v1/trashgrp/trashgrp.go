@@ -0,0 +1,46 @@
+// Package trashgrp maintains the group of handlers for trash access.
+package trashgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/core/trash"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+	"github.com/gloompi/ultimate-service/foundation/web"
+)
+
+// Handlers manages the set of trash endpoints.
+type Handlers struct {
+	Trash trash.Core
+}
+
+// Query returns the incomes and expenses of the caller that are in the trash.
+// Admins also get the users that are in the trash.
+func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	trs, err := h.Trash.QueryByUserID(ctx, claims.Subject)
+	if err != nil {
+		switch {
+		case errors.Is(err, trash.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("userID[%s]: %w", claims.Subject, err)
+		}
+	}
+
+	if claims.AuthorizedByRole(auth.RoleAdmin) {
+		if trs.Users, err = h.Trash.QueryUsers(ctx); err != nil {
+			return fmt.Errorf("querying users: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, trs, http.StatusOK)
+}
@@ -5,10 +5,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
+	"github.com/gloompi/ultimate-service/business/core/balance"
+	"github.com/gloompi/ultimate-service/business/core/export"
 	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
 	"github.com/gloompi/ultimate-service/business/web/auth"
 	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
 	"github.com/gloompi/ultimate-service/foundation/web"
@@ -16,8 +21,10 @@ import (
 
 // Handlers manages the set of user endpoints.
 type Handlers struct {
-	User user.Core
-	Auth *auth.Auth
+	User     user.Core
+	Exporter export.Core
+	Balance  balance.Core
+	Auth     *auth.Auth
 }
 
 // Create adds a new user to the system.
@@ -60,6 +67,11 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return fmt.Errorf("unable to decode payload: %w", err)
 	}
 
+	version, err := v1Web.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		return v1Web.NewRequestError(err, http.StatusBadRequest)
+	}
+
 	userID := web.Param(r, "id")
 
 	// If you are not an admin and looking to retrieve someone other than yourself.
@@ -67,12 +79,14 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
 	}
 
-	if err := h.User.Update(ctx, userID, upd, v.Now); err != nil {
+	if err := h.User.Update(ctx, userID, upd, version, v.Now); err != nil {
 		switch {
 		case errors.Is(err, user.ErrInvalidID):
 			return v1Web.NewRequestError(err, http.StatusBadRequest)
 		case errors.Is(err, user.ErrNotFound):
 			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, user.ErrVersionConflict):
+			return v1Web.NewRequestError(err, http.StatusPreconditionFailed)
 		default:
 			return fmt.Errorf("ID[%s] User[%+v]: %w", userID, &upd, err)
 		}
@@ -81,8 +95,13 @@ func (h Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
-// Delete removes a user from the system.
+// Delete moves a user to the trash.
 func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
 	claims, err := auth.GetClaims(ctx)
 	if err != nil {
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
@@ -95,10 +114,39 @@ func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
 	}
 
-	if err := h.User.Delete(ctx, userID); err != nil {
+	if err := h.User.Delete(ctx, userID, v.Now); err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, user.ErrNotFound):
+			// Don't send StatusNotFound here, deleting a user that is
+			// already gone leaves the system as the caller wants it.
+			return v1Web.NewRequestError(err, http.StatusNoContent)
+		default:
+			return fmt.Errorf("ID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Restore takes a user out of the trash.
+func (h Handlers) Restore(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	userID := web.Param(r, "id")
+
+	if err := h.User.Restore(ctx, userID, v.Now); err != nil {
 		switch {
 		case errors.Is(err, user.ErrInvalidID):
 			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, user.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, user.ErrUniqueEmail):
+			return v1Web.NewRequestError(err, http.StatusConflict)
 		default:
 			return fmt.Errorf("ID[%s]: %w", userID, err)
 		}
@@ -107,7 +155,33 @@ func (h Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
-// Query returns a list of users with paging.
+// QueryPage returns a page of users holding up to limit of them, starting
+// right after the cursor query parameter. The next_cursor of the response
+// fetches the next page.
+func (h Handlers) QueryPage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	limit, err := cursor.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		return validate.NewFieldsError("limit", err)
+	}
+
+	page, err := h.User.QueryPage(ctx, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, cursor.ErrInvalidCursor):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, cursor.ErrOrderMismatch):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("unable to query for users: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, page, http.StatusOK)
+}
+
+// Query returns a list of users with paging by page number. It is kept for
+// the clients written before QueryPage, which doesn't skip or repeat users
+// added while paging.
 func (h Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	page := web.Param(r, "page")
 	pageNumber, err := strconv.Atoi(page)
@@ -154,9 +228,58 @@ func (h Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.
 		}
 	}
 
+	w.Header().Set("ETag", v1Web.ETag(usr.Version))
 	return web.Respond(ctx, w, usr, http.StatusOK)
 }
 
+// Export streams a zip archive with the profile and every record of a user.
+// The files are JSON documents unless the format query parameter is csv.
+func (h Handlers) Export(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "id")
+
+	// If you are not an admin and looking to export someone other than yourself.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && claims.Subject != userID {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	format := export.FormatJSON
+	if f := r.URL.Query().Get("format"); f != "" {
+		format = f
+	}
+	if format != export.FormatJSON && format != export.FormatCSV {
+		return v1Web.NewRequestError(export.ErrInvalidFormat, http.StatusBadRequest)
+	}
+
+	arc, err := h.Exporter.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, export.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, export.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", userID, err)
+		}
+	}
+
+	filename := fmt.Sprintf("moneyflow-%s-%s.zip", userID, v.Now.Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	return web.RespondStream(ctx, w, "application/zip", http.StatusOK, func(w io.Writer) error {
+		return arc.WriteZip(w, format, v.Now)
+	})
+}
+
 // Token provides an API token for the authenticated user.
 func (h Handlers) Token(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	v, err := web.GetValues(ctx)
@@ -196,3 +319,32 @@ func (h Handlers) Token(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 	return web.Respond(ctx, w, tkn, http.StatusOK)
 }
+
+// QueryBalance returns the income, expense and net of a user per currency.
+func (h Handlers) QueryBalance(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	userID := web.Param(r, "id")
+
+	// If you are not an admin and looking to retrieve someone else's balance.
+	if !claims.AuthorizedByRole(auth.RoleAdmin) && claims.Subject != userID {
+		return v1Web.NewRequestError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	bal, err := h.Balance.QueryByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, balance.ErrInvalidID):
+			return v1Web.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, balance.ErrNotFound):
+			return v1Web.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, bal, http.StatusOK)
+}
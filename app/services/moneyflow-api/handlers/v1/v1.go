@@ -5,24 +5,65 @@ package v1
 import (
 	"net/http"
 
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/accountgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/attachmentgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/auditgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/budgetgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/categorygrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/debtgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/duplicategrp"
 	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/expensegrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/forecastgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/goalgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/householdgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/importgrp"
 	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/incomegrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/reportgrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/rulegrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/taggrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/transactiongrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/transfergrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/trashgrp"
 	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers/v1/usergrp"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/scheduler"
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/attachment"
+	"github.com/gloompi/ultimate-service/business/core/audit"
+	"github.com/gloompi/ultimate-service/business/core/balance"
+	"github.com/gloompi/ultimate-service/business/core/budget"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/debt"
+	"github.com/gloompi/ultimate-service/business/core/duplicate"
 	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/export"
+	"github.com/gloompi/ultimate-service/business/core/forecast"
+	"github.com/gloompi/ultimate-service/business/core/goal"
+	"github.com/gloompi/ultimate-service/business/core/household"
 	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/report"
+	"github.com/gloompi/ultimate-service/business/core/rule"
+	"github.com/gloompi/ultimate-service/business/core/statement"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/core/trash"
 	"github.com/gloompi/ultimate-service/business/core/user"
 	"github.com/gloompi/ultimate-service/business/web/auth"
 	"github.com/gloompi/ultimate-service/business/web/v1/mid"
+	"github.com/gloompi/ultimate-service/foundation/blob"
 	"github.com/gloompi/ultimate-service/foundation/web"
+	"github.com/gloompi/ultimate-service/foundation/worker"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Log  *zap.SugaredLogger
-	Auth *auth.Auth
-	DB   *sqlx.DB
+	Log               *zap.SugaredLogger
+	Auth              *auth.Auth
+	DB                *sqlx.DB
+	Blob              blob.Store
+	MaxAttachmentSize int64
+	Worker            *worker.Worker
 }
 
 // Routes binds all the version 1 routes.
@@ -34,13 +75,19 @@ func Routes(app *web.App, cfg Config) {
 
 	// Register user management and authentication endpoints.
 	ugh := usergrp.Handlers{
-		User: user.NewCore(cfg.Log, cfg.DB),
-		Auth: cfg.Auth,
+		User:     user.NewCore(cfg.Log, cfg.DB),
+		Exporter: export.NewCore(cfg.Log, cfg.DB),
+		Balance:  balance.NewCore(cfg.Log, cfg.DB),
+		Auth:     cfg.Auth,
 	}
 	app.Handle(http.MethodGet, version, "/users/token", ugh.Token)
+	app.Handle(http.MethodGet, version, "/users", ugh.QueryPage, authen, admin)
 	app.Handle(http.MethodGet, version, "/users/:page/:rows", ugh.Query, authen, admin)
 	app.Handle(http.MethodGet, version, "/users/:id", ugh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/users/:id/export", ugh.Export, authen)
+	app.Handle(http.MethodGet, version, "/users/:id/balance", ugh.QueryBalance, authen)
 	app.Handle(http.MethodPost, version, "/users", ugh.Create)
+	app.Handle(http.MethodPost, version, "/users/:id/restore", ugh.Restore, authen, admin)
 	app.Handle(http.MethodPut, version, "/users/:id", ugh.Update, authen)
 	app.Handle(http.MethodDelete, version, "/users/:id", ugh.Delete, authen)
 
@@ -48,20 +95,195 @@ func Routes(app *web.App, cfg Config) {
 	igh := incomegrp.Handlers{
 		Income: income.NewCore(cfg.Log, cfg.DB),
 	}
+	app.Handle(http.MethodGet, version, "/incomes", igh.QueryPage, authen, admin)
 	app.Handle(http.MethodGet, version, "/incomes/:page/:rows", igh.Query, authen, admin)
 	app.Handle(http.MethodGet, version, "/incomes/:id", igh.QueryByID, authen, admin)
 	app.Handle(http.MethodGet, version, "/incomes/user/:user_id", igh.QueryByUserID, authen)
 	app.Handle(http.MethodPost, version, "/incomes", igh.Create, authen)
+	app.Handle(http.MethodPost, version, "/incomes:batch", igh.Batch, authen)
+	app.Handle(http.MethodPost, version, "/incomes/:id/restore", igh.Restore, authen)
 	app.Handle(http.MethodPut, version, "/incomes/:id", igh.Update, authen)
 	app.Handle(http.MethodDelete, version, "/incomes/:id", igh.Delete, authen)
 
 	// Register expense management endpoints.
 	egh := expensegrp.Handlers{
-		Expense: expense.NewCore(cfg.Log, cfg.DB),
+		Expense:   expense.NewCore(cfg.Log, cfg.DB),
+		Household: household.NewCore(cfg.Log, cfg.DB),
 	}
+	app.Handle(http.MethodGet, version, "/expenses", egh.QueryPage, authen, admin)
 	app.Handle(http.MethodGet, version, "/expenses/:page/:rows", egh.Query, authen, admin)
-	app.Handle(http.MethodGet, version, "/expenses/:id", egh.QueryByID, authen, admin)
+	app.Handle(http.MethodGet, version, "/expenses/:id", egh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/expenses/user/:user_id", egh.QueryByUserID, authen)
 	app.Handle(http.MethodPost, version, "/expenses", egh.Create, authen)
+	app.Handle(http.MethodPost, version, "/expenses:batch", egh.Batch, authen)
+	app.Handle(http.MethodPost, version, "/expenses/:id/restore", egh.Restore, authen)
 	app.Handle(http.MethodPut, version, "/expenses/:id", egh.Update, authen)
 	app.Handle(http.MethodDelete, version, "/expenses/:id", egh.Delete, authen)
+
+	// Register expense attachment endpoints.
+	atgh := attachmentgrp.Handlers{
+		Attachment: attachment.NewCore(cfg.Log, cfg.DB, cfg.Blob, cfg.MaxAttachmentSize),
+		Expense:    expense.NewCore(cfg.Log, cfg.DB),
+		Household:  household.NewCore(cfg.Log, cfg.DB),
+		MaxSize:    cfg.MaxAttachmentSize,
+	}
+	app.Handle(http.MethodGet, version, "/expenses/:id/attachments", atgh.Query, authen)
+	app.Handle(http.MethodGet, version, "/expenses/:id/attachments/:attachment_id", atgh.Download, authen)
+	app.Handle(http.MethodPost, version, "/expenses/:id/attachments", atgh.Create, authen)
+	app.Handle(http.MethodDelete, version, "/expenses/:id/attachments/:attachment_id", atgh.Delete, authen)
+
+	// Register duplicate expense endpoints.
+	dugh := duplicategrp.Handlers{
+		Duplicate: duplicate.NewCore(cfg.Log, cfg.DB, cfg.Blob),
+		Expense:   expense.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/duplicates", dugh.Query, authen)
+	app.Handle(http.MethodPost, version, "/duplicates/merge", dugh.Merge, authen)
+
+	// Register transaction ledger endpoints.
+	tgh := transactiongrp.Handlers{
+		Transaction: transaction.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/transactions/:id", tgh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/transactions/user/:user_id", tgh.QueryByUserID, authen)
+
+	// Register cash-flow forecast endpoints.
+	fgh := forecastgrp.Handlers{
+		Forecast: forecast.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/forecast/user/:user_id", fgh.QueryByUserID, authen)
+
+	// Register budget management endpoints.
+	bgh := budgetgrp.Handlers{
+		Budget: budget.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/budgets/:id", bgh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/budgets/user/:user_id", bgh.QueryByUserID, authen)
+	app.Handle(http.MethodGet, version, "/budgets/user/:user_id/status", bgh.QueryStatusByUserID, authen)
+	app.Handle(http.MethodPost, version, "/budgets", bgh.Create, authen)
+	app.Handle(http.MethodPut, version, "/budgets/:id", bgh.Update, authen)
+	app.Handle(http.MethodDelete, version, "/budgets/:id", bgh.Delete, authen)
+
+	// Register account management and transfer endpoints.
+	acc := account.NewCore(cfg.Log, cfg.DB)
+
+	agh := accountgrp.Handlers{
+		Account: acc,
+	}
+	app.Handle(http.MethodGet, version, "/accounts/:id", agh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/accounts/user/:user_id", agh.QueryByUserID, authen)
+	app.Handle(http.MethodPost, version, "/accounts", agh.Create, authen)
+	app.Handle(http.MethodPut, version, "/accounts/:id", agh.Update, authen)
+	app.Handle(http.MethodDelete, version, "/accounts/:id", agh.Delete, authen)
+
+	tfgh := transfergrp.Handlers{
+		Account: acc,
+	}
+	app.Handle(http.MethodGet, version, "/transfers/:id", tfgh.QueryByID, authen)
+	app.Handle(http.MethodPost, version, "/transfers", tfgh.Create, authen)
+
+	// Register category management endpoints.
+	cgh := categorygrp.Handlers{
+		Category: category.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/categories/:id", cgh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/categories/user/:user_id", cgh.QueryByUserID, authen)
+	app.Handle(http.MethodPost, version, "/categories", cgh.Create, authen)
+	app.Handle(http.MethodPut, version, "/categories/:id", cgh.Update, authen)
+	app.Handle(http.MethodDelete, version, "/categories/:id", cgh.Delete, authen)
+
+	// Register tag management endpoints.
+	tggh := taggrp.Handlers{
+		Tag: tag.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/tags/:id", tggh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/tags/user/:user_id", tggh.QueryByUserID, authen)
+	app.Handle(http.MethodPost, version, "/tags", tggh.Create, authen)
+	app.Handle(http.MethodPost, version, "/tags/:id/merge", tggh.Merge, authen)
+	app.Handle(http.MethodPut, version, "/tags/:id", tggh.Update, authen)
+	app.Handle(http.MethodDelete, version, "/tags/:id", tggh.Delete, authen)
+
+	// Register categorization rule endpoints.
+	rugh := rulegrp.Handlers{
+		Rule:     rule.NewCore(cfg.Log, cfg.DB),
+		Worker:   cfg.Worker,
+		ApplyJob: scheduler.JobApplyRules,
+	}
+	app.Handle(http.MethodGet, version, "/rules/:id", rugh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/rules/user/:user_id", rugh.QueryByUserID, authen)
+	app.Handle(http.MethodPost, version, "/rules", rugh.Create, authen)
+	app.Handle(http.MethodPost, version, "/rules/user/:user_id/apply", rugh.Apply, authen)
+	app.Handle(http.MethodPut, version, "/rules/:id", rugh.Update, authen)
+	app.Handle(http.MethodDelete, version, "/rules/:id", rugh.Delete, authen)
+
+	// Register summary report endpoints.
+	rgh := reportgrp.Handlers{
+		Report: report.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/reports/summary", rgh.Summary, authen)
+
+	// Register statement import endpoints.
+	imgh := importgrp.Handlers{
+		Statement: statement.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodPost, version, "/imports/csv", imgh.CSV, authen)
+	app.Handle(http.MethodPost, version, "/imports/ofx", imgh.OFX, authen)
+	app.Handle(http.MethodPost, version, "/imports/qif", imgh.QIF, authen)
+
+	// Register savings goal endpoints.
+	glgh := goalgrp.Handlers{
+		Goal: goal.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/goals/:id", glgh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/goals/:id/status", glgh.QueryStatus, authen)
+	app.Handle(http.MethodGet, version, "/goals/:id/contributions", glgh.QueryContributions, authen)
+	app.Handle(http.MethodGet, version, "/goals/user/:user_id", glgh.QueryByUserID, authen)
+	app.Handle(http.MethodPost, version, "/goals", glgh.Create, authen)
+	app.Handle(http.MethodPost, version, "/goals/:id/contributions", glgh.Contribute, authen)
+	app.Handle(http.MethodPut, version, "/goals/:id", glgh.Update, authen)
+	app.Handle(http.MethodDelete, version, "/goals/:id", glgh.Delete, authen)
+
+	// Register debt and amortization endpoints.
+	dgh := debtgrp.Handlers{
+		Debt: debt.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/debts/:id", dgh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/debts/:id/schedule", dgh.QuerySchedule, authen)
+	app.Handle(http.MethodGet, version, "/debts/user/:user_id", dgh.QueryByUserID, authen)
+	app.Handle(http.MethodPost, version, "/debts", dgh.Create, authen)
+	app.Handle(http.MethodPost, version, "/debts/:id/payments", dgh.Pay, authen)
+	app.Handle(http.MethodPut, version, "/debts/:id", dgh.Update, authen)
+	app.Handle(http.MethodDelete, version, "/debts/:id", dgh.Delete, authen)
+
+	// Register household and split expense endpoints.
+	hgh := householdgrp.Handlers{
+		Household: household.NewCore(cfg.Log, cfg.DB),
+		Expense:   expense.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/households/:id", hgh.QueryByID, authen)
+	app.Handle(http.MethodGet, version, "/households/:id/expenses", hgh.QuerySplits, authen)
+	app.Handle(http.MethodGet, version, "/households/:id/balances", hgh.QueryBalances, authen)
+	app.Handle(http.MethodGet, version, "/households/:id/settlements", hgh.QuerySettlements, authen)
+	app.Handle(http.MethodGet, version, "/households/user/:user_id", hgh.QueryByUserID, authen)
+	app.Handle(http.MethodPost, version, "/households", hgh.Create, authen)
+	app.Handle(http.MethodPost, version, "/households/:id/members", hgh.AddMember, authen)
+	app.Handle(http.MethodPost, version, "/households/:id/settlements", hgh.Settle, authen)
+	app.Handle(http.MethodPut, version, "/households/:id", hgh.Update, authen)
+	app.Handle(http.MethodPut, version, "/households/:id/members/:user_id", hgh.UpdateMember, authen)
+	app.Handle(http.MethodPut, version, "/households/:id/expenses/:expense_id/split", hgh.Split, authen)
+	app.Handle(http.MethodDelete, version, "/households/:id", hgh.Delete, authen)
+	app.Handle(http.MethodDelete, version, "/households/:id/members/:user_id", hgh.RemoveMember, authen)
+	app.Handle(http.MethodDelete, version, "/households/:id/expenses/:expense_id/split", hgh.Unsplit, authen)
+
+	// Register trash endpoints.
+	trgh := trashgrp.Handlers{
+		Trash: trash.NewCore(cfg.Log, cfg.DB, cfg.Blob),
+	}
+	app.Handle(http.MethodGet, version, "/trash", trgh.Query, authen)
+
+	// Register audit log endpoints.
+	augh := auditgrp.Handlers{
+		Audit: audit.NewCore(cfg.Log, cfg.DB),
+	}
+	app.Handle(http.MethodGet, version, "/audit", augh.Query, authen, admin)
 }
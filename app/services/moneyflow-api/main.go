@@ -14,8 +14,10 @@ import (
 
 	"github.com/ardanlabs/conf/v3"
 	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/handlers"
+	"github.com/gloompi/ultimate-service/app/services/moneyflow-api/scheduler"
 	"github.com/gloompi/ultimate-service/business/sys/database"
 	"github.com/gloompi/ultimate-service/business/web/auth"
+	"github.com/gloompi/ultimate-service/foundation/blob"
 	"github.com/gloompi/ultimate-service/foundation/keystore"
 	"github.com/gloompi/ultimate-service/foundation/logger"
 	"go.opentelemetry.io/otel"
@@ -93,6 +95,22 @@ func run(log *zap.SugaredLogger) error {
 			MaxOpenConns int    `conf:"default:0"`
 			DisableTLS   bool   `conf:"default:true"`
 		}
+		Blob struct {
+			Kind            string `conf:"default:local"`
+			Root            string `conf:"default:zarf/blobs/"`
+			Endpoint        string `conf:"default:http://localhost:9000"`
+			Region          string `conf:"default:us-east-1"`
+			Bucket          string `conf:"default:moneyflow"`
+			AccessKeyID     string
+			SecretAccessKey string `conf:"mask"`
+		}
+		Attachments struct {
+			MaxSize int64 `conf:"default:10485760"`
+		}
+		Scheduler struct {
+			Interval       time.Duration `conf:"default:1h"`
+			TrashRetention time.Duration `conf:"default:720h"`
+		}
 		Zipkin struct {
 			ReporterURI string  `conf:"default:http://localhost:9411/api/v2/spans"`
 			ServiceName string  `conf:"default:moneyflow-api"`
@@ -168,6 +186,30 @@ func run(log *zap.SugaredLogger) error {
 		db.Close()
 	}()
 
+	// =========================================================================
+	// Start Blob Store
+
+	log.Infow("startup", "status", "initializing blob store", "kind", cfg.Blob.Kind)
+
+	var bs blob.Store
+	switch cfg.Blob.Kind {
+	case "local":
+		bs, err = blob.NewLocal(cfg.Blob.Root)
+	case "s3":
+		bs, err = blob.NewS3(blob.S3Config{
+			Endpoint:        cfg.Blob.Endpoint,
+			Region:          cfg.Blob.Region,
+			Bucket:          cfg.Blob.Bucket,
+			AccessKeyID:     cfg.Blob.AccessKeyID,
+			SecretAccessKey: cfg.Blob.SecretAccessKey,
+		})
+	default:
+		err = fmt.Errorf("unknown kind %q", cfg.Blob.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("constructing blob store: %w", err)
+	}
+
 	// =========================================================================
 	// Start Tracing Support
 
@@ -183,6 +225,30 @@ func run(log *zap.SugaredLogger) error {
 	}
 	defer traceProvider.Shutdown(context.Background())
 
+	// =========================================================================
+	// Start Scheduler
+
+	log.Infow("startup", "status", "initializing scheduler", "interval", cfg.Scheduler.Interval, "retention", cfg.Scheduler.TrashRetention)
+
+	sched := scheduler.New(scheduler.Config{
+		Log:       log,
+		DB:        db,
+		Blob:      bs,
+		Interval:  cfg.Scheduler.Interval,
+		Retention: cfg.Scheduler.TrashRetention,
+	})
+	sched.Start()
+	defer func() {
+		log.Infow("shutdown", "status", "stopping scheduler")
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Web.ShutdownTimeout)
+		defer cancel()
+
+		if err := sched.Shutdown(ctx); err != nil {
+			log.Errorw("shutdown", "status", "scheduler did not stop cleanly", "ERROR", err)
+		}
+	}()
+
 	// =========================================================================
 	// Start Debug Service
 
@@ -214,10 +280,13 @@ func run(log *zap.SugaredLogger) error {
 
 	// Construct the mux for the API calls.
 	apiMux := handlers.APIMux(handlers.APIMuxConfig{
-		Shutdown: shutdown,
-		Log:      log,
-		Auth:     auth,
-		DB:       db,
+		Shutdown:          shutdown,
+		Log:               log,
+		Auth:              auth,
+		DB:                db,
+		Blob:              bs,
+		MaxAttachmentSize: cfg.Attachments.MaxSize,
+		Worker:            sched.Worker(),
 	})
 
 	// Construct a server to service the requests against the mux.
@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/debt"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/core/trash"
+	"github.com/gloompi/ultimate-service/foundation/worker"
+	"go.uber.org/zap"
+)
+
+// Set of job keys registered with the worker.
+const (
+	JobMaterialize  = "materialize"
+	JobDebtPayments = "debt_payments"
+	JobPurgeTrash   = "purge_trash"
+	JobApplyRules   = "apply_rules"
+)
+
+// materialize constructs the job that posts the due occurrences of every
+// income and expense into the transaction ledger.
+func materialize(log *zap.SugaredLogger, trn transaction.Core) worker.JobFunc {
+	return func(ctx context.Context, traceID string, payload any) {
+		log.Infow("job started", "traceid", traceID, "job", JobMaterialize)
+
+		n, err := trn.Materialize(ctx, time.Now().UTC())
+		if err != nil {
+			log.Errorw("job failed", "traceid", traceID, "job", JobMaterialize, "ERROR", err)
+			return
+		}
+
+		log.Infow("job completed", "traceid", traceID, "job", JobMaterialize, "transactions", n)
+	}
+}
+
+// debtPayments constructs the job that posts the installments of every debt
+// that fell due into the transaction ledger.
+func debtPayments(log *zap.SugaredLogger, dbt debt.Core) worker.JobFunc {
+	return func(ctx context.Context, traceID string, payload any) {
+		log.Infow("job started", "traceid", traceID, "job", JobDebtPayments)
+
+		n, err := dbt.Materialize(ctx, time.Now().UTC())
+		if err != nil {
+			log.Errorw("job failed", "traceid", traceID, "job", JobDebtPayments, "ERROR", err)
+			return
+		}
+
+		log.Infow("job completed", "traceid", traceID, "job", JobDebtPayments, "installments", n)
+	}
+}
+
+// purgeTrash constructs the job that removes for good whatever has been in the
+// trash for longer than the retention period.
+func purgeTrash(log *zap.SugaredLogger, trs trash.Core, retention time.Duration) worker.JobFunc {
+	return func(ctx context.Context, traceID string, payload any) {
+		log.Infow("job started", "traceid", traceID, "job", JobPurgeTrash)
+
+		before := time.Now().UTC().Add(-retention)
+		if err := trs.Purge(ctx, before); err != nil {
+			log.Errorw("job failed", "traceid", traceID, "job", JobPurgeTrash, "ERROR", err)
+			return
+		}
+
+		log.Infow("job completed", "traceid", traceID, "job", JobPurgeTrash, "before", before)
+	}
+}
+
+// applyRules constructs the job that runs the rules of a user over every
+// income and expense it has. It only runs on demand, with the ID of the user
+// as the payload.
+func applyRules(log *zap.SugaredLogger, inc income.Core, exp expense.Core) worker.JobFunc {
+	return func(ctx context.Context, traceID string, payload any) {
+		userID, ok := payload.(string)
+		if !ok {
+			log.Errorw("job failed", "traceid", traceID, "job", JobApplyRules, "ERROR", "payload is not a user ID")
+			return
+		}
+
+		log.Infow("job started", "traceid", traceID, "job", JobApplyRules, "userid", userID)
+
+		now := time.Now().UTC()
+
+		incs, err := inc.ApplyRules(ctx, userID, now)
+		if err != nil {
+			log.Errorw("job failed", "traceid", traceID, "job", JobApplyRules, "userid", userID, "ERROR", err)
+			return
+		}
+
+		exps, err := exp.ApplyRules(ctx, userID, now)
+		if err != nil {
+			log.Errorw("job failed", "traceid", traceID, "job", JobApplyRules, "userid", userID, "ERROR", err)
+			return
+		}
+
+		log.Infow("job completed", "traceid", traceID, "job", JobApplyRules, "userid", userID, "incomes", incs, "expenses", exps)
+	}
+}
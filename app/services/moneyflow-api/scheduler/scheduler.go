@@ -0,0 +1,103 @@
+// Package scheduler runs the background jobs of the service on a fixed
+// interval on top of the worker package.
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/debt"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/core/trash"
+	"github.com/gloompi/ultimate-service/foundation/blob"
+	"github.com/gloompi/ultimate-service/foundation/worker"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Config contains all the mandatory systems required by the scheduler.
+type Config struct {
+	Log       *zap.SugaredLogger
+	DB        *sqlx.DB
+	Blob      blob.Store
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+// Scheduler starts the set of scheduled jobs every time the interval elapses.
+type Scheduler struct {
+	log      *zap.SugaredLogger
+	interval time.Duration
+	worker   *worker.Worker
+	jobs     []string
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New constructs a Scheduler with all the jobs of the service registered.
+func New(cfg Config) *Scheduler {
+	registry := map[string]worker.JobFunc{
+		JobMaterialize:  materialize(cfg.Log, transaction.NewCore(cfg.Log, cfg.DB)),
+		JobDebtPayments: debtPayments(cfg.Log, debt.NewCore(cfg.Log, cfg.DB)),
+		JobPurgeTrash:   purgeTrash(cfg.Log, trash.NewCore(cfg.Log, cfg.DB, cfg.Blob), cfg.Retention),
+		JobApplyRules:   applyRules(cfg.Log, income.NewCore(cfg.Log, cfg.DB), expense.NewCore(cfg.Log, cfg.DB)),
+	}
+
+	return &Scheduler{
+		log:      cfg.Log,
+		interval: cfg.Interval,
+		worker:   worker.New(registry),
+		jobs:     []string{JobMaterialize, JobDebtPayments, JobPurgeTrash},
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Worker returns the worker running the jobs, so the jobs that aren't on the
+// schedule can be started on demand.
+func (s *Scheduler) Worker() *worker.Worker {
+	return s.worker
+}
+
+// Start launches a goroutine that runs the scheduled jobs right away and then
+// again each time the interval elapses.
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.run()
+
+			select {
+			case <-ticker.C:
+			case <-s.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the ticker and waits for the running jobs to complete.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	close(s.shutdown)
+	s.wg.Wait()
+
+	return s.worker.Shutdown(ctx)
+}
+
+// run hands every scheduled job over to the worker.
+func (s *Scheduler) run() {
+	for _, job := range s.jobs {
+		traceID := uuid.NewString()
+		if _, err := s.worker.Start(context.Background(), traceID, job, nil); err != nil {
+			s.log.Errorw("scheduler", "traceid", traceID, "job", job, "ERROR", err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/export"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"go.uber.org/zap"
+)
+
+// Export writes a zip archive with the profile and every record of a user,
+// to answer a data access request.
+func Export(log *zap.SugaredLogger, cfg database.Config, userID string, format string, path string) error {
+	if userID == "" {
+		fmt.Println("help: export --user <user_id> [--format json|csv] [--out <path to .zip file>]")
+		return ErrHelp
+	}
+
+	if format == "" {
+		format = export.FormatJSON
+	}
+	if path == "" {
+		path = fmt.Sprintf("moneyflow-%s.zip", userID)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	core := export.NewCore(log, db)
+
+	arc, err := core.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("query archive: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer f.Close()
+
+	if err := arc.WriteZip(f, format, time.Now()); err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing file: %w", err)
+	}
+
+	fmt.Println("archive written:", path)
+	return nil
+}
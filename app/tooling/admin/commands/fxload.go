@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"go.uber.org/zap"
+)
+
+// FXLoad loads exchange rates from an ECB reference rates XML file or a CSV
+// file into the database.
+func FXLoad(log *zap.SugaredLogger, cfg database.Config, path string) error {
+	if path == "" {
+		fmt.Println("help: fxload <path to .xml or .csv file>")
+		return ErrHelp
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	var nrs []fx.NewRate
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		nrs, err = fx.ParseECB(f)
+	default:
+		nrs, err = fx.ParseCSV(f)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing file: %w", err)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	core := fx.NewCore(log, db)
+
+	if err := core.Save(ctx, nrs); err != nil {
+		return fmt.Errorf("saving rates: %w", err)
+	}
+
+	fmt.Println("rates loaded:", len(nrs))
+	return nil
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/statement"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/importers"
+	"go.uber.org/zap"
+)
+
+// ImportCSV imports a CSV bank statement as incomes and expenses of a user,
+// with the columns laid out as the JSON mapping file describes.
+func ImportCSV(log *zap.SugaredLogger, cfg database.Config, userID string, path string, mappingPath string, dryRun bool) error {
+	if userID == "" || path == "" || mappingPath == "" {
+		fmt.Println("help: import-csv <user_id> <path to .csv file> <path to mapping .json file> [dry-run]")
+		return ErrHelp
+	}
+
+	var m struct {
+		importers.CSV
+		statement.Options
+	}
+	if err := readJSON(mappingPath, &m); err != nil {
+		return fmt.Errorf("reading mapping: %w", err)
+	}
+
+	return importStatement(log, cfg, userID, path, m.CSV, m.Options, dryRun)
+}
+
+// ImportStatement imports an OFX or QIF bank statement as incomes and
+// expenses of a user. The format is told by the extension of the file. The
+// optional JSON options file holds the currency of QIF statements.
+func ImportStatement(log *zap.SugaredLogger, cfg database.Config, userID string, path string, optionsPath string, dryRun bool) error {
+	if userID == "" || path == "" {
+		fmt.Println("help: import <user_id> <path to .ofx, .qfx or .qif file> [path to options .json file] [dry-run]")
+		return ErrHelp
+	}
+
+	var opts struct {
+		importers.QIF
+		statement.Options
+	}
+	if optionsPath != "" {
+		if err := readJSON(optionsPath, &opts); err != nil {
+			return fmt.Errorf("reading options: %w", err)
+		}
+	}
+
+	var imp importers.Importer
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ofx", ".qfx":
+		imp = importers.OFX{}
+	case ".qif":
+		imp = opts.QIF
+	default:
+		return fmt.Errorf("unknown statement format [%s]", filepath.Ext(path))
+	}
+
+	return importStatement(log, cfg, userID, path, imp, opts.Options, dryRun)
+}
+
+// =============================================================================
+
+// importStatement reads the statement with the importer and imports it for
+// the user.
+func importStatement(log *zap.SugaredLogger, cfg database.Config, userID string, path string, imp importers.Importer, opts statement.Options, dryRun bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	recs, err := imp.Import(f)
+	if err != nil {
+		return fmt.Errorf("parsing file: %w", err)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	core := statement.NewCore(log, db)
+
+	res, err := core.Import(ctx, userID, opts, recs, dryRun, time.Now())
+	if err != nil {
+		return fmt.Errorf("importing statement: %w", err)
+	}
+
+	for _, row := range res.Rows {
+		if row.Error != "" {
+			fmt.Printf("line %d: %s\n", row.Line, row.Error)
+		}
+	}
+
+	fmt.Printf("rows: %d imported: %d skipped: %d failed: %d dry run: %t\n", res.Total, res.Imported, res.Skipped, res.Failed, res.DryRun)
+	return nil
+}
+
+// readJSON decodes the JSON document in the file into the value.
+func readJSON(path string, val any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, val)
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 
@@ -127,6 +128,45 @@ func processCommands(args conf.Args, log *zap.SugaredLogger, dbConfig database.C
 			return fmt.Errorf("generating token: %w", err)
 		}
 
+	case "fxload":
+		path := args.Num(1)
+		if err := commands.FXLoad(log, dbConfig, path); err != nil {
+			return fmt.Errorf("loading exchange rates: %w", err)
+		}
+
+	case "import-csv":
+		userID := args.Num(1)
+		path := args.Num(2)
+		mappingPath := args.Num(3)
+		dryRun := args.Num(4) == "dry-run"
+		if err := commands.ImportCSV(log, dbConfig, userID, path, mappingPath, dryRun); err != nil {
+			return fmt.Errorf("importing csv statement: %w", err)
+		}
+
+	case "import":
+		userID := args.Num(1)
+		path := args.Num(2)
+		optionsPath := args.Num(3)
+		dryRun := args.Num(4) == "dry-run"
+		if optionsPath == "dry-run" {
+			optionsPath, dryRun = "", true
+		}
+		if err := commands.ImportStatement(log, dbConfig, userID, path, optionsPath, dryRun); err != nil {
+			return fmt.Errorf("importing statement: %w", err)
+		}
+
+	case "export":
+		fs := flag.NewFlagSet("export", flag.ContinueOnError)
+		userID := fs.String("user", "", "ID of the user to export")
+		format := fs.String("format", "", "format of the files, json or csv")
+		path := fs.String("out", "", "path of the zip file to write")
+		if err := fs.Parse(args[1:]); err != nil {
+			return fmt.Errorf("parsing export flags: %w", err)
+		}
+		if err := commands.Export(log, dbConfig, *userID, *format, *path); err != nil {
+			return fmt.Errorf("exporting user: %w", err)
+		}
+
 	default:
 		fmt.Println("migrate: create the schema in the database")
 		fmt.Println("seed: add data to the database")
@@ -134,6 +174,10 @@ func processCommands(args conf.Args, log *zap.SugaredLogger, dbConfig database.C
 		fmt.Println("users: get a list of users from the database")
 		fmt.Println("genkey: generate a set of private/public key files")
 		fmt.Println("gentoken: generate a JWT for a user with claims")
+		fmt.Println("fxload: load exchange rates from an ECB xml or csv file")
+		fmt.Println("import-csv: import a csv bank statement as incomes and expenses of a user")
+		fmt.Println("import: import an ofx or qif bank statement as incomes and expenses of a user")
+		fmt.Println("export: write a zip archive with every record of a user")
 		fmt.Println("provide a command to get more help.")
 		return commands.ErrHelp
 	}
@@ -0,0 +1,317 @@
+// Package account provides a core business API for the accounts money is
+// kept in, their balances and the transfers between them.
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account/db"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound          = errors.New("account not found")
+	ErrTransferNotFound  = errors.New("transfer not found")
+	ErrInvalidID         = errors.New("ID is not in its proper form")
+	ErrNotOwner          = errors.New("account belongs to another user")
+	ErrCurrencyMismatch  = errors.New("currency does not match the account")
+	ErrInvalidAmount     = errors.New("amount must be greater than zero")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+)
+
+// Core manages the set of APIs for account access.
+type Core struct {
+	store db.Store
+	fx    fx.Core
+}
+
+// NewCore constructs a core for account api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store: db.NewStore(log, sqlxDB),
+		fx:    fx.NewCore(log, sqlxDB),
+	}
+}
+
+// Create adds an Account to the database. It returns the created Account with
+// fields like ID and DateCreated populated.
+func (c Core) Create(ctx context.Context, na NewAccount, now time.Time) (Account, error) {
+	if err := validate.Check(na); err != nil {
+		return Account{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	dbAcc := db.Account{
+		ID:             validate.GenerateID(),
+		UserID:         na.UserID,
+		Name:           na.Name,
+		Type:           na.Type,
+		Currency:       na.OpeningBalance.Currency,
+		OpeningBalance: na.OpeningBalance.Amount,
+		Balance:        na.OpeningBalance.Amount,
+		DateCreated:    now,
+		DateUpdated:    now,
+	}
+
+	if err := c.store.Create(ctx, dbAcc); err != nil {
+		return Account{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toAccount(dbAcc), nil
+}
+
+// Update modifies data about an Account. It will error if the specified ID is
+// invalid or does not reference an existing Account.
+func (c Core) Update(ctx context.Context, accountID string, ua UpdateAccount, now time.Time) error {
+	if err := validate.CheckID(accountID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(ua); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbAcc, err := c.store.QueryByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("updating account accountID[%s]: %w", accountID, err)
+	}
+
+	if ua.Name != nil {
+		dbAcc.Name = *ua.Name
+	}
+	if ua.Type != nil {
+		dbAcc.Type = *ua.Type
+	}
+	if ua.OpeningBalance != nil {
+		if ua.OpeningBalance.Currency != dbAcc.Currency {
+			return ErrCurrencyMismatch
+		}
+		dbAcc.OpeningBalance = ua.OpeningBalance.Amount
+	}
+	dbAcc.DateUpdated = now
+
+	if err := c.store.Update(ctx, dbAcc); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the account identified by a given ID. Incomes, expenses and
+// transactions of the account are kept, but no longer linked to it.
+func (c Core) Delete(ctx context.Context, accountID string) error {
+	if err := validate.CheckID(accountID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := c.store.Delete(ctx, accountID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the account identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, accountID string) (Account, error) {
+	if err := validate.CheckID(accountID); err != nil {
+		return Account{}, ErrInvalidID
+	}
+
+	dbAcc, err := c.store.QueryByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Account{}, ErrNotFound
+		}
+		return Account{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toAccount(dbAcc), nil
+}
+
+// QueryByUserID finds the accounts identified by a given User ID.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Account, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbAccs, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toAccountSlice(dbAccs), nil
+}
+
+// Check verifies that money of a user in the given currency can be booked on
+// the account identified by a given ID.
+func (c Core) Check(ctx context.Context, accountID string, userID string, currency string) error {
+	acc, err := c.QueryByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if acc.UserID != userID {
+		return ErrNotOwner
+	}
+
+	if acc.Balance.Currency != currency {
+		return ErrCurrencyMismatch
+	}
+
+	return nil
+}
+
+// Transfer moves money between two accounts of the same user. The transfer
+// and both of its legs in the ledger are stored in a single transaction, with
+// both accounts locked so concurrent transfers can't overdraw them. Only
+// credit cards can go below zero.
+func (c Core) Transfer(ctx context.Context, nt NewTransfer, now time.Time) (Transfer, error) {
+	if err := validate.Check(nt); err != nil {
+		return Transfer{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if !nt.Amount.IsPositive() {
+		return Transfer{}, ErrInvalidAmount
+	}
+
+	from, err := c.QueryByID(ctx, nt.FromAccountID)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("from: %w", err)
+	}
+
+	to, err := c.QueryByID(ctx, nt.ToAccountID)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("to: %w", err)
+	}
+
+	if from.UserID != to.UserID {
+		return Transfer{}, ErrNotOwner
+	}
+
+	if nt.Amount.Currency != from.Balance.Currency {
+		return Transfer{}, ErrCurrencyMismatch
+	}
+
+	date := nt.Date
+	if date.IsZero() {
+		date = now
+	}
+
+	toAmount, err := c.fx.NewConverter().Convert(ctx, nt.Amount, to.Balance.Currency, date)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("converting: %w", err)
+	}
+
+	dbTrf := db.Transfer{
+		ID:            validate.GenerateID(),
+		UserID:        from.UserID,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Currency:      nt.Amount.Currency,
+		Amount:        nt.Amount.Amount,
+		ToCurrency:    toAmount.Currency,
+		ToAmount:      toAmount.Amount,
+		DateOccurred:  date,
+		DateCreated:   now,
+	}
+
+	legs := []db.Leg{
+		{
+			ID:           validate.GenerateID(),
+			UserID:       dbTrf.UserID,
+			SourceType:   transaction.SourceTransferOut,
+			SourceID:     dbTrf.ID,
+			AccountID:    sql.NullString{String: from.ID, Valid: true},
+			Name:         "Transfer to " + to.Name,
+			Currency:     dbTrf.Currency,
+			Amount:       dbTrf.Amount,
+			DateOccurred: date,
+			DateCreated:  now,
+		},
+		{
+			ID:           validate.GenerateID(),
+			UserID:       dbTrf.UserID,
+			SourceType:   transaction.SourceTransferIn,
+			SourceID:     dbTrf.ID,
+			AccountID:    sql.NullString{String: to.ID, Valid: true},
+			Name:         "Transfer from " + from.Name,
+			Currency:     dbTrf.ToCurrency,
+			Amount:       dbTrf.ToAmount,
+			DateOccurred: date,
+			DateCreated:  now,
+		},
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		store := c.store.Tran(tx)
+
+		// Always lock in the same order so two opposite transfers running at
+		// the same time can't deadlock.
+		ids := []string{from.ID, to.ID}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if err := store.Lock(ctx, id); err != nil {
+				return fmt.Errorf("lock: %w", err)
+			}
+		}
+
+		if from.Type != TypeCreditCard {
+			dbFrom, err := store.QueryByID(ctx, from.ID)
+			if err != nil {
+				return fmt.Errorf("query balance: %w", err)
+			}
+
+			if dbFrom.Balance < dbTrf.Amount {
+				return ErrInsufficientFunds
+			}
+		}
+
+		if err := store.CreateTransfer(ctx, dbTrf); err != nil {
+			return fmt.Errorf("create transfer: %w", err)
+		}
+
+		for _, leg := range legs {
+			if err := store.CreateLeg(ctx, leg); err != nil {
+				return fmt.Errorf("create leg: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return Transfer{}, fmt.Errorf("tran: %w", err)
+	}
+
+	return toTransfer(dbTrf), nil
+}
+
+// QueryTransferByID finds the transfer identified by a given ID.
+func (c Core) QueryTransferByID(ctx context.Context, transferID string) (Transfer, error) {
+	if err := validate.CheckID(transferID); err != nil {
+		return Transfer{}, ErrInvalidID
+	}
+
+	dbTrf, err := c.store.QueryTransferByID(ctx, transferID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Transfer{}, ErrTransferNotFound
+		}
+		return Transfer{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toTransfer(dbTrf), nil
+}
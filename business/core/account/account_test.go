@@ -0,0 +1,200 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/data/tests"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/foundation/docker"
+	"github.com/google/go-cmp/cmp"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+// Seeded user and category the accounts and expenses are added for.
+const (
+	userID  = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+	taxesID = "4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9"
+)
+
+func eur(amount int64) money.Money {
+	return money.Money{Amount: amount, Currency: "EUR"}
+}
+
+func Test_Account(t *testing.T) {
+	log, db, teardown := tests.NewUnit(t, c, "testaccount")
+	t.Cleanup(teardown)
+
+	core := account.NewCore(log, db)
+	expCore := expense.NewCore(log, db)
+	trnCore := transaction.NewCore(log, db)
+
+	ctx := context.Background()
+	now := time.Date(2019, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+	checking, err := core.Create(ctx, account.NewAccount{UserID: userID, Name: "Test Checking", Type: account.TypeChecking, OpeningBalance: eur(10000)}, now)
+	if err != nil {
+		t.Fatalf("Creating checking account: %s", err)
+	}
+	savings, err := core.Create(ctx, account.NewAccount{UserID: userID, Name: "Test Savings", Type: account.TypeSavings, OpeningBalance: eur(0)}, now)
+	if err != nil {
+		t.Fatalf("Creating savings account: %s", err)
+	}
+
+	// balances gets the balance of the checking and savings accounts.
+	balances := func() (int64, int64, error) {
+		from, err := core.QueryByID(ctx, checking.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+		to, err := core.QueryByID(ctx, savings.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+		return from.Balance.Amount, to.Balance.Amount, nil
+	}
+
+	t.Log("Given the need to move money between accounts.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen transferring money the account has.", testID)
+		{
+			nt := account.NewTransfer{
+				FromAccountID: checking.ID,
+				ToAccountID:   savings.ID,
+				Amount:        eur(4000),
+			}
+
+			trf, err := core.Transfer(ctx, nt, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to transfer : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to transfer.", tests.Success, testID)
+
+			saved, err := core.QueryTransferByID(ctx, trf.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the transfer by ID : %s.", tests.Failed, testID, err)
+			}
+			if diff := cmp.Diff(trf, saved); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould get back the same transfer. Diff:\n%s", tests.Failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back the same transfer.", tests.Success, testID)
+
+			from, to, err := balances()
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the balances : %s.", tests.Failed, testID, err)
+			}
+			if from != 6000 || to != 4000 {
+				t.Fatalf("\t%s\tTest %d:\tShould move the money : got %d and %d, exp 6000 and 4000.", tests.Failed, testID, from, to)
+			}
+			t.Logf("\t%s\tTest %d:\tShould move the money.", tests.Success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen transferring more money than the account has.", testID)
+		{
+			nt := account.NewTransfer{
+				FromAccountID: checking.ID,
+				ToAccountID:   savings.ID,
+				Amount:        eur(7000),
+			}
+
+			if _, err := core.Transfer(ctx, nt, now); !errors.Is(err, account.ErrInsufficientFunds) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse the transfer : got %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse the transfer.", tests.Success, testID)
+
+			from, to, err := balances()
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the balances : %s.", tests.Failed, testID, err)
+			}
+			if from != 6000 || to != 4000 {
+				t.Fatalf("\t%s\tTest %d:\tShould leave the balances alone : got %d and %d, exp 6000 and 4000.", tests.Failed, testID, from, to)
+			}
+			t.Logf("\t%s\tTest %d:\tShould leave the balances alone.", tests.Success, testID)
+
+			trns, err := trnCore.QueryByUserID(ctx, userID, now, now.Add(time.Second))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the ledger : %s.", tests.Failed, testID, err)
+			}
+			if len(trns) != 2 {
+				t.Fatalf("\t%s\tTest %d:\tShould only have the legs of the first transfer : got %d.", tests.Failed, testID, len(trns))
+			}
+			t.Logf("\t%s\tTest %d:\tShould only have the legs of the first transfer.", tests.Success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen transferring money in another currency.", testID)
+		{
+			nt := account.NewTransfer{
+				FromAccountID: checking.ID,
+				ToAccountID:   savings.ID,
+				Amount:        money.Money{Amount: 1000, Currency: "USD"},
+			}
+
+			if _, err := core.Transfer(ctx, nt, now); !errors.Is(err, account.ErrCurrencyMismatch) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse the transfer : got %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse the transfer.", tests.Success, testID)
+
+			if err := core.Check(ctx, checking.ID, userID, "USD"); !errors.Is(err, account.ErrCurrencyMismatch) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse to book USD on the account : got %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse to book USD on the account.", tests.Success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen an expense is posted to the account.", testID)
+		{
+			ne := expense.NewExpense{
+				Name:             "Electricity bill",
+				CategoryID:       taxesID,
+				Amount:           eur(1500),
+				Reoccurrence:     1,
+				ReoccurrenceType: recurrence.TypeOnce,
+				DurationType:     recurrence.DurationNone,
+				UserID:           userID,
+				AccountID:        checking.ID,
+			}
+
+			if _, err := expCore.Create(ctx, ne, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an expense : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create an expense.", tests.Success, testID)
+
+			if _, err := trnCore.Materialize(ctx, now.Add(time.Hour)); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to post the ledger : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to post the ledger.", tests.Success, testID)
+
+			from, _, err := balances()
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the balances : %s.", tests.Failed, testID, err)
+			}
+			if from != 4500 {
+				t.Fatalf("\t%s\tTest %d:\tShould take the expense off the balance : got %d, exp 4500.", tests.Failed, testID, from)
+			}
+			t.Logf("\t%s\tTest %d:\tShould take the expense off the balance.", tests.Success, testID)
+		}
+	}
+}
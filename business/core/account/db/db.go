@@ -0,0 +1,242 @@
+// Package db contains account related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for account access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// selectAccounts selects accounts along with their balance, which is the
+// opening balance plus the signed sum of every transaction posted to them.
+// Postings of incomes and expenses in the trash are left out.
+const selectAccounts = `
+	SELECT
+		a.account_id, a.user_id, a.name, a.type, a.currency, a.opening_balance, a.date_created, a.date_updated,
+		a.opening_balance + COALESCE((
+			SELECT
+				SUM(CASE WHEN t.source_type IN ('expense', 'debt_payment', 'transfer_out') THEN -t.amount ELSE t.amount END)
+			FROM
+				transactions AS t
+			WHERE
+				t.account_id = a.account_id AND
+				NOT EXISTS (SELECT 1 FROM incomes AS i WHERE t.source_type = 'income' AND i.income_id = t.source_id AND i.deleted_at IS NOT NULL) AND
+				NOT EXISTS (SELECT 1 FROM expenses AS e WHERE t.source_type = 'expense' AND e.expense_id = t.source_id AND e.deleted_at IS NOT NULL)
+		), 0) AS balance
+	FROM
+		accounts AS a`
+
+// Create adds an Account to the database.
+func (s Store) Create(ctx context.Context, acc Account) error {
+	const q = `
+	INSERT INTO accounts
+		(account_id, user_id, name, type, currency, opening_balance, date_created, date_updated)
+	VALUES
+		(:account_id, :user_id, :name, :type, :currency, :opening_balance, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, acc); err != nil {
+		return fmt.Errorf("inserting account: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about an Account.
+func (s Store) Update(ctx context.Context, acc Account) error {
+	const q = `
+	UPDATE
+		accounts
+	SET
+		"name" = :name,
+		"type" = :type,
+		"opening_balance" = :opening_balance,
+		"date_updated" = :date_updated
+	WHERE
+		account_id = :account_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, acc); err != nil {
+		return fmt.Errorf("updating account accountID[%s]: %w", acc.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the account identified by a given ID.
+func (s Store) Delete(ctx context.Context, accountID string) error {
+	data := struct {
+		AccountID string `db:"account_id"`
+	}{
+		AccountID: accountID,
+	}
+
+	const q = `
+	DELETE FROM
+		accounts
+	WHERE
+		account_id = :account_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting account accountID[%s]: %w", accountID, err)
+	}
+
+	return nil
+}
+
+// Lock takes a row lock on the account identified by a given ID until the
+// transaction the store runs in ends. It's a no-op outside a transaction.
+func (s Store) Lock(ctx context.Context, accountID string) error {
+	data := struct {
+		AccountID string `db:"account_id"`
+	}{
+		AccountID: accountID,
+	}
+
+	const q = `
+	SELECT
+		account_id
+	FROM
+		accounts
+	WHERE
+		account_id = :account_id
+	FOR UPDATE`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("locking account accountID[%s]: %w", accountID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the account identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, accountID string) (Account, error) {
+	data := struct {
+		AccountID string `db:"account_id"`
+	}{
+		AccountID: accountID,
+	}
+
+	const q = selectAccounts + `
+	WHERE
+		a.account_id = :account_id`
+
+	var acc Account
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &acc); err != nil {
+		return Account{}, fmt.Errorf("selecting account accountID[%q]: %w", accountID, err)
+	}
+
+	return acc, nil
+}
+
+// QueryByUserID finds the accounts of a given User ID.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Account, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = selectAccounts + `
+	WHERE
+		a.user_id = :user_id
+	ORDER BY
+		a.date_created`
+
+	var accs []Account
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &accs); err != nil {
+		return nil, fmt.Errorf("selecting accounts userID[%s]: %w", userID, err)
+	}
+
+	return accs, nil
+}
+
+// CreateTransfer adds a Transfer to the database.
+func (s Store) CreateTransfer(ctx context.Context, trf Transfer) error {
+	const q = `
+	INSERT INTO transfers
+		(transfer_id, user_id, from_account_id, to_account_id, currency, amount, to_currency, to_amount, date_occurred, date_created)
+	VALUES
+		(:transfer_id, :user_id, :from_account_id, :to_account_id, :currency, :amount, :to_currency, :to_amount, :date_occurred, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, trf); err != nil {
+		return fmt.Errorf("inserting transfer: %w", err)
+	}
+
+	return nil
+}
+
+// CreateLeg posts one side of a transfer in the transactions ledger.
+func (s Store) CreateLeg(ctx context.Context, leg Leg) error {
+	const q = `
+	INSERT INTO transactions
+		(transaction_id, user_id, source_type, source_id, account_id, name, currency, amount, date_occurred, date_created)
+	VALUES
+		(:transaction_id, :user_id, :source_type, :source_id, :account_id, :name, :currency, :amount, :date_occurred, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, leg); err != nil {
+		return fmt.Errorf("inserting leg %s transferID[%s]: %w", leg.SourceType, leg.SourceID, err)
+	}
+
+	return nil
+}
+
+// QueryTransferByID finds the transfer identified by a given ID.
+func (s Store) QueryTransferByID(ctx context.Context, transferID string) (Transfer, error) {
+	data := struct {
+		TransferID string `db:"transfer_id"`
+	}{
+		TransferID: transferID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		transfers
+	WHERE
+		transfer_id = :transfer_id`
+
+	var trf Transfer
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &trf); err != nil {
+		return Transfer{}, fmt.Errorf("selecting transfer transferID[%q]: %w", transferID, err)
+	}
+
+	return trf, nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Account represents a place money is kept in, like a wallet or a bank account.
+type Account struct {
+	ID             string    `db:"account_id"`      // Unique identifier.
+	UserID         string    `db:"user_id"`         // ID of the user who owns the account.
+	Name           string    `db:"name"`            // Display name of the account.
+	Type           string    `db:"type"`            // Type of account (cash, checking, savings, credit_card).
+	Currency       string    `db:"currency"`        // Currency the account is kept in.
+	OpeningBalance int64     `db:"opening_balance"` // Balance before any transaction, in the minor unit of the currency.
+	Balance        int64     `db:"balance"`         // Opening balance plus every posted transaction. Only filled on select.
+	DateCreated    time.Time `db:"date_created"`    // When the account was added.
+	DateUpdated    time.Time `db:"date_updated"`    // When the account record was last modified.
+}
+
+// Transfer represents money moved from one account to another.
+type Transfer struct {
+	ID            string    `db:"transfer_id"`     // Unique identifier.
+	UserID        string    `db:"user_id"`         // ID of the user who owns both accounts.
+	FromAccountID string    `db:"from_account_id"` // ID of the account the money left.
+	ToAccountID   string    `db:"to_account_id"`   // ID of the account the money arrived in.
+	Currency      string    `db:"currency"`        // Currency of the account the money left.
+	Amount        int64     `db:"amount"`          // Amount that left, in the minor unit of the currency.
+	ToCurrency    string    `db:"to_currency"`     // Currency of the account the money arrived in.
+	ToAmount      int64     `db:"to_amount"`       // Amount that arrived, in the minor unit of the currency.
+	DateOccurred  time.Time `db:"date_occurred"`   // When the money moved.
+	DateCreated   time.Time `db:"date_created"`    // When the transfer was added.
+}
+
+// Leg represents the transaction one side of a transfer posts in the ledger.
+type Leg struct {
+	ID           string         `db:"transaction_id"` // Unique identifier.
+	UserID       string         `db:"user_id"`        // ID of the user who owns the transaction.
+	SourceType   string         `db:"source_type"`    // Side of the transfer (transfer_in, transfer_out).
+	SourceID     string         `db:"source_id"`      // ID of the transfer.
+	AccountID    sql.NullString `db:"account_id"`     // ID of the account the money moved in or out of.
+	Name         string         `db:"name"`           // Display name of the transaction.
+	Currency     string         `db:"currency"`       // Currency of the transaction.
+	Amount       int64          `db:"amount"`         // Amount of money of the transaction.
+	DateOccurred time.Time      `db:"date_occurred"`  // When the money moved.
+	DateCreated  time.Time      `db:"date_created"`   // When the transaction was added.
+}
@@ -0,0 +1,106 @@
+package account
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Set of account types.
+const (
+	TypeCash       = "cash"
+	TypeChecking   = "checking"
+	TypeSavings    = "savings"
+	TypeCreditCard = "credit_card"
+)
+
+// Account represents a place money is kept in, like a wallet or a bank account.
+type Account struct {
+	ID             string      `json:"id"`              // Unique identifier.
+	UserID         string      `json:"user_id"`         // ID of the user who owns the account.
+	Name           string      `json:"name"`            // Display name of the account.
+	Type           string      `json:"type"`            // Type of account (cash, checking, savings, credit_card).
+	OpeningBalance money.Money `json:"opening_balance"` // Balance before any transaction.
+	Balance        money.Money `json:"balance"`         // Opening balance plus every posted transaction.
+	DateCreated    time.Time   `json:"date_created"`    // When the account was added.
+	DateUpdated    time.Time   `json:"date_updated"`    // When the account record was last modified.
+}
+
+// NewAccount is what we require from clients when adding an Account. The
+// currency of the opening balance is the currency of the account.
+type NewAccount struct {
+	UserID         string      `json:"user_id" validate:"required"`
+	Name           string      `json:"name" validate:"required"`
+	Type           string      `json:"type" validate:"required,oneof=cash checking savings credit_card"`
+	OpeningBalance money.Money `json:"opening_balance"`
+}
+
+// UpdateAccount defines what information may be provided to modify an
+// existing Account. All fields are optional so clients can send just the
+// fields they want changed. The currency of an account can't be changed.
+type UpdateAccount struct {
+	Name           *string      `json:"name" validate:"omitempty,min=1"`
+	Type           *string      `json:"type" validate:"omitempty,oneof=cash checking savings credit_card"`
+	OpeningBalance *money.Money `json:"opening_balance"`
+}
+
+// Transfer represents money moved from one account to another. When both
+// accounts are kept in different currencies the amount is converted at the
+// exchange rate of the day the money moved.
+type Transfer struct {
+	ID            string      `json:"id"`              // Unique identifier.
+	UserID        string      `json:"user_id"`         // ID of the user who owns both accounts.
+	FromAccountID string      `json:"from_account_id"` // ID of the account the money left.
+	ToAccountID   string      `json:"to_account_id"`   // ID of the account the money arrived in.
+	Amount        money.Money `json:"amount"`          // Amount that left the source account.
+	ToAmount      money.Money `json:"to_amount"`       // Amount that arrived in the destination account.
+	DateOccurred  time.Time   `json:"date_occurred"`   // When the money moved.
+	DateCreated   time.Time   `json:"date_created"`    // When the transfer was added.
+}
+
+// NewTransfer is what we require from clients when moving money between
+// accounts. The amount must be in the currency of the source account. When
+// no date is provided the money moves right away.
+type NewTransfer struct {
+	FromAccountID string      `json:"from_account_id" validate:"required"`
+	ToAccountID   string      `json:"to_account_id" validate:"required,nefield=FromAccountID"`
+	Amount        money.Money `json:"amount"`
+	Date          time.Time   `json:"date"`
+}
+
+// =============================================================================
+
+func toAccount(dbAcc db.Account) Account {
+	return Account{
+		ID:             dbAcc.ID,
+		UserID:         dbAcc.UserID,
+		Name:           dbAcc.Name,
+		Type:           dbAcc.Type,
+		OpeningBalance: money.Money{Amount: dbAcc.OpeningBalance, Currency: dbAcc.Currency},
+		Balance:        money.Money{Amount: dbAcc.Balance, Currency: dbAcc.Currency},
+		DateCreated:    dbAcc.DateCreated,
+		DateUpdated:    dbAcc.DateUpdated,
+	}
+}
+
+func toAccountSlice(dbAccs []db.Account) []Account {
+	accs := make([]Account, len(dbAccs))
+	for i, dbAcc := range dbAccs {
+		accs[i] = toAccount(dbAcc)
+	}
+	return accs
+}
+
+func toTransfer(dbTrf db.Transfer) Transfer {
+	return Transfer{
+		ID:            dbTrf.ID,
+		UserID:        dbTrf.UserID,
+		FromAccountID: dbTrf.FromAccountID,
+		ToAccountID:   dbTrf.ToAccountID,
+		Amount:        money.Money{Amount: dbTrf.Amount, Currency: dbTrf.Currency},
+		ToAmount:      money.Money{Amount: dbTrf.ToAmount, Currency: dbTrf.ToCurrency},
+		DateOccurred:  dbTrf.DateOccurred,
+		DateCreated:   dbTrf.DateCreated,
+	}
+}
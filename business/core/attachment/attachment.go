@@ -0,0 +1,287 @@
+// Package attachment provides a core business API to attach files, like
+// receipts, to expenses. The content of the files is kept in a blob store and
+// the database only holds what is known about them.
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gloompi/ultimate-service/business/core/attachment/db"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/gloompi/ultimate-service/foundation/blob"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for attachment operations.
+var (
+	ErrNotFound        = errors.New("attachment not found")
+	ErrInvalidID       = errors.New("ID is not in its proper form")
+	ErrEmpty           = errors.New("file is empty")
+	ErrTooLarge        = errors.New("file is too large")
+	ErrUnsupportedType = errors.New("file type is not supported")
+)
+
+// sniffLen is how many bytes http.DetectContentType looks at.
+const sniffLen = 512
+
+// maxFileNameLen is the longest file name kept, in characters.
+const maxFileNameLen = 255
+
+// contentTypes are the media types files can be of: images and PDFs, which
+// is what receipts come as.
+var contentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// Core manages the set of APIs for attachment access.
+type Core struct {
+	store   db.Store
+	blob    blob.Store
+	maxSize int64
+}
+
+// NewCore constructs a core for attachment api access. Files larger than
+// maxSize bytes are refused; zero doesn't limit their size.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB, bs blob.Store, maxSize int64) Core {
+	return Core{
+		store:   db.NewStore(log, sqlxDB),
+		blob:    bs,
+		maxSize: maxSize,
+	}
+}
+
+// Tran returns a new Core running every query within the transaction, so
+// files can be moved along with other changes.
+func (c Core) Tran(tx sqlx.ExtContext) Core {
+	return Core{
+		store:   c.store.Tran(tx),
+		blob:    c.blob,
+		maxSize: c.maxSize,
+	}
+}
+
+// Create attaches the file read from r to an expense. The type of the file is
+// sniffed from its content rather than trusted from the client.
+func (c Core) Create(ctx context.Context, na NewAttachment, r io.Reader, now time.Time) (Attachment, error) {
+	if err := validate.Check(na); err != nil {
+		return Attachment{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	switch {
+	case na.Size <= 0:
+		return Attachment{}, ErrEmpty
+	case c.maxSize > 0 && na.Size > c.maxSize:
+		return Attachment{}, ErrTooLarge
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Attachment{}, fmt.Errorf("reading file: %w", err)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !contentTypes[contentType] {
+		return Attachment{}, ErrUnsupportedType
+	}
+
+	dbAtt := db.Attachment{
+		ID:          validate.GenerateID(),
+		ExpenseID:   na.ExpenseID,
+		UserID:      na.UserID,
+		FileName:    cleanFileName(na.FileName),
+		ContentType: contentType,
+		Size:        na.Size,
+		DateCreated: now,
+	}
+	dbAtt.BlobKey = blobKey(dbAtt.ExpenseID, dbAtt.ID)
+
+	// The blob goes first so a row never points at content that isn't there.
+	content := io.MultiReader(bytes.NewReader(head), r)
+	if err := c.blob.Put(ctx, dbAtt.BlobKey, content, dbAtt.Size, dbAtt.ContentType); err != nil {
+		return Attachment{}, fmt.Errorf("put blob: %w", err)
+	}
+
+	if err := c.store.Create(ctx, dbAtt); err != nil {
+		if delErr := c.blob.Delete(ctx, dbAtt.BlobKey); delErr != nil {
+			return Attachment{}, fmt.Errorf("create: %w, delete blob key[%s]: %v", err, dbAtt.BlobKey, delErr)
+		}
+		return Attachment{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toAttachment(dbAtt), nil
+}
+
+// QueryByID finds the attachment identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, attachmentID string) (Attachment, error) {
+	if err := validate.CheckID(attachmentID); err != nil {
+		return Attachment{}, ErrInvalidID
+	}
+
+	dbAtt, err := c.store.QueryByID(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Attachment{}, ErrNotFound
+		}
+		return Attachment{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toAttachment(dbAtt), nil
+}
+
+// QueryByExpenseID gets the attachments of an expense, oldest first.
+func (c Core) QueryByExpenseID(ctx context.Context, expenseID string) ([]Attachment, error) {
+	if err := validate.CheckID(expenseID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbAtts, err := c.store.QueryByExpenseID(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toAttachmentSlice(dbAtts), nil
+}
+
+// Open opens the content of the attachment identified by a given ID for
+// reading. The caller has to close it.
+func (c Core) Open(ctx context.Context, attachmentID string) (Attachment, io.ReadCloser, error) {
+	if err := validate.CheckID(attachmentID); err != nil {
+		return Attachment{}, nil, ErrInvalidID
+	}
+
+	dbAtt, err := c.store.QueryByID(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Attachment{}, nil, ErrNotFound
+		}
+		return Attachment{}, nil, fmt.Errorf("query: %w", err)
+	}
+
+	rc, err := c.blob.Get(ctx, dbAtt.BlobKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return Attachment{}, nil, ErrNotFound
+		}
+		return Attachment{}, nil, fmt.Errorf("get blob: %w", err)
+	}
+
+	return toAttachment(dbAtt), rc, nil
+}
+
+// Delete removes the attachment identified by a given ID along with its
+// content.
+func (c Core) Delete(ctx context.Context, attachmentID string) error {
+	if err := validate.CheckID(attachmentID); err != nil {
+		return ErrInvalidID
+	}
+
+	dbAtt, err := c.store.QueryByID(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("query: %w", err)
+	}
+
+	return c.remove(ctx, dbAtt)
+}
+
+// Move attaches every file of an expense to another one. Their content stays
+// where it is in the blob store.
+func (c Core) Move(ctx context.Context, fromExpenseID string, toExpenseID string) error {
+	if err := validate.CheckID(fromExpenseID); err != nil {
+		return ErrInvalidID
+	}
+	if err := validate.CheckID(toExpenseID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := c.store.Move(ctx, fromExpenseID, toExpenseID); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	return nil
+}
+
+// Purge removes the attachments of the expenses, and of the expenses of the
+// users, that were moved to the trash before the given time. It has to run
+// before they are purged, since the rows go along with them but their
+// content would be left behind in the blob store.
+func (c Core) Purge(ctx context.Context, before time.Time) error {
+	dbAtts, err := c.store.QueryPurgeable(ctx, before)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	for _, dbAtt := range dbAtts {
+		if err := c.remove(ctx, dbAtt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// remove deletes the content of an attachment and then its row, so a failure
+// leaves the row behind to try again rather than an orphaned blob.
+func (c Core) remove(ctx context.Context, dbAtt db.Attachment) error {
+	if err := c.blob.Delete(ctx, dbAtt.BlobKey); err != nil {
+		return fmt.Errorf("delete blob attachmentID[%s]: %w", dbAtt.ID, err)
+	}
+
+	if err := c.store.Delete(ctx, dbAtt.ID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// blobKey returns the key the content of an attachment is stored under.
+func blobKey(expenseID string, attachmentID string) string {
+	return "attachments/" + expenseID + "/" + attachmentID
+}
+
+// cleanFileName keeps the last element of the name a client sent, without
+// control characters and cut to a sane length.
+func cleanFileName(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if utf8.RuneCountInString(name) > maxFileNameLen {
+		name = string([]rune(name)[:maxFileNameLen])
+	}
+
+	if name == "" || name == "." || name == ".." {
+		return "attachment"
+	}
+
+	return name
+}
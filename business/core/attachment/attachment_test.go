@@ -0,0 +1,64 @@
+package attachment_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/attachment"
+	"github.com/gloompi/ultimate-service/foundation/blob"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_CreateRefused(t *testing.T) {
+	bs, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct the blob store : %s.", err)
+	}
+
+	// Files are refused before the database is needed.
+	core := attachment.NewCore(nil, nil, bs, 16)
+
+	tt := []struct {
+		name    string
+		content string
+		size    int64
+		expErr  error
+	}{
+		{name: "empty", content: "", size: 0, expErr: attachment.ErrEmpty},
+		{name: "too large", content: strings.Repeat("x", 17), size: 17, expErr: attachment.ErrTooLarge},
+		{name: "text", content: "just some text", size: 14, expErr: attachment.ErrUnsupportedType},
+		{name: "html", content: "<html></html>", size: 13, expErr: attachment.ErrUnsupportedType},
+	}
+
+	t.Log("Given the need to only attach receipts of a sane size.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen attaching a %s file.", testID, tst.name)
+				{
+					na := attachment.NewAttachment{
+						ExpenseID: "5cf37266-3473-4006-984f-9325122678b7",
+						UserID:    "45b5fbd3-755f-4379-8f07-a58d4a30fa2f",
+						FileName:  tst.name,
+						Size:      tst.size,
+					}
+
+					_, err := core.Create(context.Background(), na, strings.NewReader(tst.content), time.Now())
+					if !errors.Is(err, tst.expErr) {
+						t.Fatalf("\t%s\tTest %d:\tShould get error %q, got %v.", failed, testID, tst.expErr, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
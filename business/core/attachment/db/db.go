@@ -0,0 +1,175 @@
+// Package db contains attachment related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for attachment access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Create adds an Attachment to the database.
+func (s Store) Create(ctx context.Context, att Attachment) error {
+	const q = `
+	INSERT INTO attachments
+		(attachment_id, expense_id, user_id, file_name, content_type, size, blob_key, date_created)
+	VALUES
+		(:attachment_id, :expense_id, :user_id, :file_name, :content_type, :size, :blob_key, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, att); err != nil {
+		return fmt.Errorf("inserting attachment: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the attachment identified by a given ID.
+func (s Store) Delete(ctx context.Context, attachmentID string) error {
+	data := struct {
+		AttachmentID string `db:"attachment_id"`
+	}{
+		AttachmentID: attachmentID,
+	}
+
+	const q = `
+	DELETE FROM
+		attachments
+	WHERE
+		attachment_id = :attachment_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting attachment attachmentID[%s]: %w", attachmentID, err)
+	}
+
+	return nil
+}
+
+// Move attaches every file of an expense to another one.
+func (s Store) Move(ctx context.Context, fromExpenseID string, toExpenseID string) error {
+	data := struct {
+		FromExpenseID string `db:"from_expense_id"`
+		ToExpenseID   string `db:"to_expense_id"`
+	}{
+		FromExpenseID: fromExpenseID,
+		ToExpenseID:   toExpenseID,
+	}
+
+	const q = `
+	UPDATE
+		attachments
+	SET
+		"expense_id" = :to_expense_id
+	WHERE
+		expense_id = :from_expense_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("moving attachments expenseID[%s]: %w", fromExpenseID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the attachment identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, attachmentID string) (Attachment, error) {
+	data := struct {
+		AttachmentID string `db:"attachment_id"`
+	}{
+		AttachmentID: attachmentID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		attachments
+	WHERE
+		attachment_id = :attachment_id`
+
+	var att Attachment
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &att); err != nil {
+		return Attachment{}, fmt.Errorf("selecting attachment attachmentID[%q]: %w", attachmentID, err)
+	}
+
+	return att, nil
+}
+
+// QueryByExpenseID gets the attachments of an expense, oldest first.
+func (s Store) QueryByExpenseID(ctx context.Context, expenseID string) ([]Attachment, error) {
+	data := struct {
+		ExpenseID string `db:"expense_id"`
+	}{
+		ExpenseID: expenseID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		attachments
+	WHERE
+		expense_id = :expense_id
+	ORDER BY
+		date_created, attachment_id`
+
+	var atts []Attachment
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &atts); err != nil {
+		return nil, fmt.Errorf("selecting attachments expenseID[%s]: %w", expenseID, err)
+	}
+
+	return atts, nil
+}
+
+// QueryPurgeable gets the attachments of the expenses, and of the expenses of
+// the users, that were moved to the trash before the given time.
+func (s Store) QueryPurgeable(ctx context.Context, before time.Time) ([]Attachment, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	SELECT
+		a.*
+	FROM
+		attachments AS a
+	JOIN
+		expenses AS e USING (expense_id)
+	JOIN
+		users AS u ON u.user_id = e.user_id
+	WHERE
+		e.deleted_at < :before OR
+		u.deleted_at < :before`
+
+	var atts []Attachment
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &atts); err != nil {
+		return nil, fmt.Errorf("selecting purgeable attachments: %w", err)
+	}
+
+	return atts, nil
+}
@@ -0,0 +1,15 @@
+package db
+
+import "time"
+
+// Attachment represents a file attached to an expense, like a receipt.
+type Attachment struct {
+	ID          string    `db:"attachment_id"` // Unique identifier.
+	ExpenseID   string    `db:"expense_id"`    // ID of the expense the file is attached to.
+	UserID      string    `db:"user_id"`       // ID of the user who owns the expense.
+	FileName    string    `db:"file_name"`     // Name of the file as it was uploaded.
+	ContentType string    `db:"content_type"`  // Media type sniffed from the content of the file.
+	Size        int64     `db:"size"`          // Size of the file in bytes.
+	BlobKey     string    `db:"blob_key"`      // Key the content of the file is stored under in the blob store.
+	DateCreated time.Time `db:"date_created"`  // When the file was attached.
+}
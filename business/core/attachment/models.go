@@ -0,0 +1,48 @@
+package attachment
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/attachment/db"
+)
+
+// Attachment represents a file attached to an expense, like a receipt.
+type Attachment struct {
+	ID          string    `json:"id"`           // Unique identifier.
+	ExpenseID   string    `json:"expense_id"`   // ID of the expense the file is attached to.
+	UserID      string    `json:"user_id"`      // ID of the user who owns the expense.
+	FileName    string    `json:"file_name"`    // Name of the file as it was uploaded.
+	ContentType string    `json:"content_type"` // Media type sniffed from the content of the file.
+	Size        int64     `json:"size"`         // Size of the file in bytes.
+	DateCreated time.Time `json:"date_created"` // When the file was attached.
+}
+
+// NewAttachment contains information needed to attach a file to an expense.
+type NewAttachment struct {
+	ExpenseID string `json:"expense_id" validate:"required,uuid"`
+	UserID    string `json:"user_id" validate:"required,uuid"`
+	FileName  string `json:"file_name"`
+	Size      int64  `json:"size"`
+}
+
+// =============================================================================
+
+func toAttachment(dbAtt db.Attachment) Attachment {
+	return Attachment{
+		ID:          dbAtt.ID,
+		ExpenseID:   dbAtt.ExpenseID,
+		UserID:      dbAtt.UserID,
+		FileName:    dbAtt.FileName,
+		ContentType: dbAtt.ContentType,
+		Size:        dbAtt.Size,
+		DateCreated: dbAtt.DateCreated,
+	}
+}
+
+func toAttachmentSlice(dbAtts []db.Attachment) []Attachment {
+	atts := make([]Attachment, len(dbAtts))
+	for i, dbAtt := range dbAtts {
+		atts[i] = toAttachment(dbAtt)
+	}
+	return atts
+}
@@ -0,0 +1,162 @@
+// Package audit provides a core business API to record who changed users,
+// incomes and expenses, when and how.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/audit/db"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/gloompi/ultimate-service/business/web/auth"
+	"github.com/gloompi/ultimate-service/foundation/web"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of entities changes are recorded for.
+const (
+	EntityUser    = "user"
+	EntityIncome  = "income"
+	EntityExpense = "expense"
+)
+
+// Set of actions recorded.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// Core manages the set of APIs for audit access.
+type Core struct {
+	store db.Store
+}
+
+// NewCore constructs a core for audit api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store: db.NewStore(log, sqlxDB),
+	}
+}
+
+// Tran returns a new Core running every query within the transaction, so an
+// event is recorded along with the change it describes.
+func (c Core) Tran(tx sqlx.ExtContext) Core {
+	return Core{
+		store: c.store.Tran(tx),
+	}
+}
+
+// Record adds an event for a change made to a record. The before and after
+// values are the record as the API returns it, nil when the record did not
+// exist on that side of the change. The actor and the trace ID are taken from
+// the context; changes made outside of a request have no actor.
+func (c Core) Record(ctx context.Context, entity string, entityID string, action string, before any, after any, now time.Time) error {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	var actorID sql.NullString
+	if claims, err := auth.GetClaims(ctx); err == nil {
+		actorID = sql.NullString{String: claims.Subject, Valid: true}
+	}
+
+	dbEv := db.Event{
+		ID:          validate.GenerateID(),
+		Entity:      entity,
+		EntityID:    entityID,
+		Action:      action,
+		ActorID:     actorID,
+		TraceID:     web.GetTraceID(ctx),
+		Changes:     string(changes),
+		DateCreated: now,
+	}
+
+	if err := c.store.Create(ctx, dbEv); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	return nil
+}
+
+// Query gets the most recent events that pass the filter, up to limit of them.
+func (c Core) Query(ctx context.Context, filter QueryFilter, limit int) ([]Event, error) {
+	if err := validate.Check(filter); err != nil {
+		return nil, fmt.Errorf("validating filter: %w", err)
+	}
+
+	dbFilter := db.Filter{
+		Entity:   filter.Entity,
+		EntityID: filter.EntityID,
+		ActorID:  filter.ActorID,
+	}
+
+	dbEvs, err := c.store.Query(ctx, dbFilter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toEventSlice(dbEvs), nil
+}
+
+// Diff returns the JSON object of the fields that differ between before and
+// after, each holding both of its values. A field missing on one side, such
+// as every field of a created or deleted record, only holds the other value.
+func Diff(before any, after any) (json.RawMessage, error) {
+	bf, err := fields(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %w", err)
+	}
+
+	af, err := fields(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %w", err)
+	}
+
+	changes := make(map[string]Change)
+	for name, b := range bf {
+		if a, ok := af[name]; !ok || !bytes.Equal(a, b) {
+			changes[name] = Change{Before: b, After: af[name]}
+		}
+	}
+	for name, a := range af {
+		if _, ok := bf[name]; !ok {
+			changes[name] = Change{After: a}
+		}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// =============================================================================
+
+// fields breaks the JSON representation of v into its fields.
+func fields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fs); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
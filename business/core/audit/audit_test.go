@@ -0,0 +1,79 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/core/audit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Diff(t *testing.T) {
+	type record struct {
+		Name   string   `json:"name"`
+		Amount int64    `json:"amount"`
+		Tags   []string `json:"tags"`
+		Secret string   `json:"-"`
+	}
+
+	rec := record{Name: "Rent", Amount: 120000, Tags: []string{"home"}, Secret: "a"}
+
+	tt := []struct {
+		name   string
+		before any
+		after  any
+		exp    string
+	}{
+		{
+			name:  "create",
+			after: rec,
+			exp:   `{"amount":{"after":120000},"name":{"after":"Rent"},"tags":{"after":["home"]}}`,
+		},
+		{
+			name:   "delete",
+			before: rec,
+			exp:    `{"amount":{"before":120000},"name":{"before":"Rent"},"tags":{"before":["home"]}}`,
+		},
+		{
+			name:   "update",
+			before: rec,
+			after:  record{Name: "Rent", Amount: 125000, Tags: []string{"home", "fixed"}, Secret: "b"},
+			exp:    `{"amount":{"before":120000,"after":125000},"tags":{"before":["home"],"after":["home","fixed"]}}`,
+		},
+		{
+			name:   "unchanged",
+			before: rec,
+			after:  rec,
+			exp:    `{}`,
+		},
+	}
+
+	t.Log("Given the need to record what a change did to a record.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen diffing a %s.", testID, tst.name)
+				{
+					got, err := audit.Diff(tst.before, tst.after)
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to diff the record : %s.", failed, testID, err)
+					}
+					if diff := cmp.Diff(tst.exp, string(got)); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected changes. Diff:\n%s", failed, testID, diff)
+					}
+					if !json.Valid(got) {
+						t.Fatalf("\t%s\tTest %d:\tShould get valid JSON.", failed, testID)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected changes.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package db contains audit event related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for audit event access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Create adds an Event to the database.
+func (s Store) Create(ctx context.Context, ev Event) error {
+	const q = `
+	INSERT INTO audit_events
+		(event_id, entity, entity_id, action, actor_id, trace_id, changes, date_created)
+	VALUES
+		(:event_id, :entity, :entity_id, :action, :actor_id, :trace_id, CAST(:changes AS JSONB), :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, ev); err != nil {
+		return fmt.Errorf("inserting audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Query gets the most recent events that pass the filter, up to limit of them.
+func (s Store) Query(ctx context.Context, filter Filter, limit int) ([]Event, error) {
+	data := map[string]any{
+		"limit": limit,
+	}
+
+	wc := []string{"TRUE"}
+	if filter.Entity != nil {
+		data["entity"] = *filter.Entity
+		wc = append(wc, "entity = :entity")
+	}
+	if filter.EntityID != nil {
+		data["entity_id"] = *filter.EntityID
+		wc = append(wc, "entity_id = :entity_id")
+	}
+	if filter.ActorID != nil {
+		data["actor_id"] = *filter.ActorID
+		wc = append(wc, "actor_id = :actor_id")
+	}
+
+	q := `
+	SELECT
+		*
+	FROM
+		audit_events
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t") + `
+	ORDER BY
+		date_created DESC, event_id
+	LIMIT :limit`
+
+	var evs []Event
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &evs); err != nil {
+		return nil, fmt.Errorf("selecting audit events: %w", err)
+	}
+
+	return evs, nil
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Event represents a change made to a record.
+type Event struct {
+	ID          string         `db:"event_id"`     // Unique identifier.
+	Entity      string         `db:"entity"`       // Kind of record changed.
+	EntityID    string         `db:"entity_id"`    // ID of the record changed.
+	Action      string         `db:"action"`       // What was done to the record.
+	ActorID     sql.NullString `db:"actor_id"`     // ID of the user who made the change, if any.
+	TraceID     string         `db:"trace_id"`     // Trace ID of the request that made the change.
+	Changes     string         `db:"changes"`      // JSON object of the fields changed.
+	DateCreated time.Time      `db:"date_created"` // When the change was made.
+}
+
+// Filter holds the fields events can be filtered by. The fields left nil
+// don't filter anything.
+type Filter struct {
+	Entity   *string
+	EntityID *string
+	ActorID  *string
+}
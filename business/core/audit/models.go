@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/audit/db"
+)
+
+// Event represents a change made to a user, an income or an expense.
+type Event struct {
+	ID          string          `json:"id"`                 // Unique identifier.
+	Entity      string          `json:"entity"`             // Kind of record changed.
+	EntityID    string          `json:"entity_id"`          // ID of the record changed.
+	Action      string          `json:"action"`             // What was done to the record.
+	ActorID     string          `json:"actor_id,omitempty"` // ID of the user who made the change, empty when it was not made by a user.
+	TraceID     string          `json:"trace_id"`           // Trace ID of the request that made the change.
+	Changes     json.RawMessage `json:"changes"`            // Fields changed, by name.
+	DateCreated time.Time       `json:"date_created"`       // When the change was made.
+}
+
+// Change represents the values of a field before and after a change.
+type Change struct {
+	Before json.RawMessage `json:"before,omitempty"` // Value before the change, missing when there was none.
+	After  json.RawMessage `json:"after,omitempty"`  // Value after the change, missing when there is none.
+}
+
+// QueryFilter holds the available fields a query can be filtered on. The
+// fields left nil don't filter anything. The JSON names match the query
+// parameters of the audit endpoint so validation errors point at them.
+type QueryFilter struct {
+	Entity   *string `json:"entity" validate:"omitempty,oneof=user income expense"`
+	EntityID *string `json:"id" validate:"omitempty,uuid"`
+	ActorID  *string `json:"actor" validate:"omitempty,uuid"`
+}
+
+// =============================================================================
+
+func toEvent(dbEv db.Event) Event {
+	return Event{
+		ID:          dbEv.ID,
+		Entity:      dbEv.Entity,
+		EntityID:    dbEv.EntityID,
+		Action:      dbEv.Action,
+		ActorID:     dbEv.ActorID.String,
+		TraceID:     dbEv.TraceID,
+		Changes:     json.RawMessage(dbEv.Changes),
+		DateCreated: dbEv.DateCreated,
+	}
+}
+
+func toEventSlice(dbEvs []db.Event) []Event {
+	evs := make([]Event, len(dbEvs))
+	for i, dbEv := range dbEvs {
+		evs[i] = toEvent(dbEv)
+	}
+	return evs
+}
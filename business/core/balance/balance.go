@@ -0,0 +1,136 @@
+// Package balance provides a core business API to sum up the incomes and
+// expenses of a user currency by currency.
+package balance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for balance operations.
+var (
+	ErrNotFound  = errors.New("user not found")
+	ErrInvalidID = errors.New("ID is not in its proper form")
+)
+
+// Core manages the set of APIs for balance access.
+type Core struct {
+	user    user.Core
+	income  income.Core
+	expense expense.Core
+}
+
+// NewCore constructs a core for balance api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		user:    user.NewCore(log, sqlxDB),
+		income:  income.NewCore(log, sqlxDB),
+		expense: expense.NewCore(log, sqlxDB),
+	}
+}
+
+// QueryByUserID sums up the incomes and expenses of a user per currency. No
+// amount is converted, so the balance never depends on the exchange rates
+// being known.
+func (c Core) QueryByUserID(ctx context.Context, userID string) (Balance, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Balance{}, ErrInvalidID
+	}
+
+	if _, err := c.user.QueryByID(ctx, userID); err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return Balance{}, ErrNotFound
+		}
+		return Balance{}, fmt.Errorf("query user: %w", err)
+	}
+
+	incs, err := c.income.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return Balance{}, fmt.Errorf("query incomes: %w", err)
+	}
+
+	exps, err := c.expense.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return Balance{}, fmt.Errorf("query expenses: %w", err)
+	}
+
+	incAmounts := make([]money.Money, len(incs))
+	for i, inc := range incs {
+		incAmounts[i] = inc.Amount
+	}
+
+	expAmounts := make([]money.Money, len(exps))
+	for i, exp := range exps {
+		expAmounts[i] = exp.Amount
+	}
+
+	curs, err := combine(incAmounts, expAmounts)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	bal := Balance{
+		UserID:     userID,
+		Currencies: curs,
+	}
+
+	return bal, nil
+}
+
+// =============================================================================
+
+// combine totals the incomes and expenses of every currency either of them
+// is in, along with the difference between both.
+func combine(incs []money.Money, exps []money.Money) ([]Currency, error) {
+	incTotals, err := money.Totals(incs)
+	if err != nil {
+		return nil, fmt.Errorf("income totals: %w", err)
+	}
+
+	expTotals, err := money.Totals(exps)
+	if err != nil {
+		return nil, fmt.Errorf("expense totals: %w", err)
+	}
+
+	byCurrency := make(map[string]*Currency)
+	get := func(cur string) *Currency {
+		if bc, exists := byCurrency[cur]; exists {
+			return bc
+		}
+		bc := Currency{
+			Currency: cur,
+			Income:   money.Zero(cur),
+			Expense:  money.Zero(cur),
+		}
+		byCurrency[cur] = &bc
+		return &bc
+	}
+
+	for _, m := range incTotals {
+		get(m.Currency).Income = m
+	}
+	for _, m := range expTotals {
+		get(m.Currency).Expense = m
+	}
+
+	curs := make([]Currency, 0, len(byCurrency))
+	for _, bc := range byCurrency {
+		if bc.Net, err = bc.Income.Sub(bc.Expense); err != nil {
+			return nil, fmt.Errorf("net currency[%s]: %w", bc.Currency, err)
+		}
+		curs = append(curs, *bc)
+	}
+	sort.Slice(curs, func(i, j int) bool { return curs[i].Currency < curs[j].Currency })
+
+	return curs, nil
+}
@@ -0,0 +1,19 @@
+package balance
+
+import (
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Balance represents what a user has earned and spent.
+type Balance struct {
+	UserID     string     `json:"user_id"`    // ID of the user.
+	Currencies []Currency `json:"currencies"` // Totals of every currency the user has records in.
+}
+
+// Currency represents the totals of a user in a single currency.
+type Currency struct {
+	Currency string      `json:"currency"` // ISO 4217 code of the currency.
+	Income   money.Money `json:"income"`   // Total income.
+	Expense  money.Money `json:"expense"`  // Total expense.
+	Net      money.Money `json:"net"`      // Income minus expense.
+}
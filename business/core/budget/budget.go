@@ -0,0 +1,260 @@
+// Package budget provides a core business API for monthly spending limits
+// per expense category and the tracking of how much of them has been used.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/budget/db"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound     = errors.New("budget not found")
+	ErrInvalidID    = errors.New("ID is not in its proper form")
+	ErrInvalidLimit = errors.New("limit must be greater than zero")
+	ErrDuplicate    = errors.New("budget already exists for the category and currency")
+)
+
+// Core manages the set of APIs for budget access.
+type Core struct {
+	store    db.Store
+	expense  expense.Core
+	category category.Core
+}
+
+// NewCore constructs a core for budget api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store:    db.NewStore(log, sqlxDB),
+		expense:  expense.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+	}
+}
+
+// Create adds a Budget to the database. A user can only have one budget per
+// category and currency.
+func (c Core) Create(ctx context.Context, nb NewBudget, now time.Time) (Budget, error) {
+	if err := validate.Check(nb); err != nil {
+		return Budget{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if !nb.Limit.IsPositive() {
+		return Budget{}, ErrInvalidLimit
+	}
+
+	if err := c.category.Check(ctx, nb.CategoryID, nb.UserID, category.KindExpense); err != nil {
+		return Budget{}, fmt.Errorf("category: %w", err)
+	}
+
+	dbBgt := db.Budget{
+		ID:          validate.GenerateID(),
+		UserID:      nb.UserID,
+		CategoryID:  nb.CategoryID,
+		Currency:    nb.Limit.Currency,
+		Amount:      nb.Limit.Amount,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.store.Create(ctx, dbBgt); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return Budget{}, fmt.Errorf("create: %w", ErrDuplicate)
+		}
+		return Budget{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toBudget(dbBgt), nil
+}
+
+// Update modifies data about a Budget. It will error if the specified ID is
+// invalid or does not reference an existing Budget.
+func (c Core) Update(ctx context.Context, budgetID string, ub UpdateBudget, now time.Time) error {
+	if err := validate.CheckID(budgetID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(ub); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbBgt, err := c.store.QueryByID(ctx, budgetID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("updating budget budgetID[%s]: %w", budgetID, err)
+	}
+
+	if ub.CategoryID != nil {
+		if err := c.category.Check(ctx, *ub.CategoryID, dbBgt.UserID, category.KindExpense); err != nil {
+			return fmt.Errorf("category: %w", err)
+		}
+		dbBgt.CategoryID = *ub.CategoryID
+	}
+	if ub.Limit != nil {
+		if !ub.Limit.IsPositive() {
+			return ErrInvalidLimit
+		}
+		dbBgt.Currency = ub.Limit.Currency
+		dbBgt.Amount = ub.Limit.Amount
+	}
+	dbBgt.DateUpdated = now
+
+	if err := c.store.Update(ctx, dbBgt); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("update: %w", ErrDuplicate)
+		}
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the budget identified by a given ID.
+func (c Core) Delete(ctx context.Context, budgetID string) error {
+	if err := validate.CheckID(budgetID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := c.store.Delete(ctx, budgetID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the budget identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, budgetID string) (Budget, error) {
+	if err := validate.CheckID(budgetID); err != nil {
+		return Budget{}, ErrInvalidID
+	}
+
+	dbBgt, err := c.store.QueryByID(ctx, budgetID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Budget{}, ErrNotFound
+		}
+		return Budget{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toBudget(dbBgt), nil
+}
+
+// QueryByUserID finds the budgets identified by a given User ID.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Budget, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbBgts, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toBudgetSlice(dbBgts), nil
+}
+
+// QueryStatusByUserID reports how much of every budget of a user is used in
+// the month of now. Expenses of the categories nested in the one of a budget
+// count towards it as well. Every occurrence of a recurring expense that falls within
+// the month counts, including the ones still to come, since that money is
+// already committed.
+func (c Core) QueryStatusByUserID(ctx context.Context, userID string, now time.Time) ([]Status, error) {
+	bgts, err := c.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exps, err := c.expense.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query expenses: %w", err)
+	}
+
+	h, err := c.category.QueryHierarchyByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query categories: %w", err)
+	}
+
+	sts := make([]Status, len(bgts))
+	for i, bgt := range bgts {
+		st, err := StatusOf(bgt, exps, h, now)
+		if err != nil {
+			return nil, fmt.Errorf("budgetID[%s]: %w", bgt.ID, err)
+		}
+		sts[i] = st
+	}
+
+	return sts, nil
+}
+
+// StatusOf adds up the expenses of the budget category, its children and
+// currency that occur within the month of now and compares them against the
+// limit.
+func StatusOf(bgt Budget, exps []expense.Expense, h category.Hierarchy, now time.Time) (Status, error) {
+	now = now.UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	spent := money.Zero(bgt.Limit.Currency)
+
+	for _, exp := range exps {
+		if !h.Within(exp.CategoryID, bgt.CategoryID) || exp.Amount.Currency != bgt.Limit.Currency {
+			continue
+		}
+
+		rule := recurrence.Rule{
+			Start:        exp.DateCreated,
+			Type:         exp.ReoccurrenceType,
+			Interval:     exp.Reoccurrence,
+			Duration:     exp.Duration,
+			DurationType: exp.DurationType,
+		}
+
+		n := len(rule.Between(from, to))
+		if n == 0 {
+			continue
+		}
+
+		amount, err := exp.Amount.Mul(int64(n))
+		if err != nil {
+			return Status{}, err
+		}
+
+		if spent, err = spent.Add(amount); err != nil {
+			return Status{}, err
+		}
+	}
+
+	remaining, err := bgt.Limit.Sub(spent)
+	if err != nil {
+		return Status{}, err
+	}
+
+	// Limits are always positive, so there is no division by zero. The
+	// percentage is rounded to two decimals.
+	percent := float64(spent.Amount) / float64(bgt.Limit.Amount) * 100
+
+	st := Status{
+		Budget:      bgt,
+		Period:      from.Format("2006-01"),
+		Spent:       spent,
+		Remaining:   remaining,
+		PercentUsed: math.Round(percent*100) / 100,
+		Overspent:   remaining.IsNegative(),
+	}
+
+	return st, nil
+}
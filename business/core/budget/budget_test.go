@@ -0,0 +1,159 @@
+package budget_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/budget"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_StatusOf(t *testing.T) {
+	eur := func(amount int64) money.Money {
+		return money.Money{Amount: amount, Currency: "EUR"}
+	}
+
+	once := func(categoryID string, amount money.Money, date time.Time) expense.Expense {
+		return expense.Expense{CategoryID: categoryID, Amount: amount, ReoccurrenceType: recurrence.TypeOnce, DateCreated: date}
+	}
+
+	h := category.NewHierarchy([]category.Category{
+		{ID: "housing", Kind: category.KindExpense},
+		{ID: "rent", ParentID: "housing", Kind: category.KindExpense},
+		{ID: "food", Kind: category.KindExpense},
+	})
+
+	bgt := budget.Budget{ID: "b1", CategoryID: "housing", Limit: eur(100000)}
+	now := time.Date(2019, time.March, 15, 12, 0, 0, 0, time.UTC)
+	march := time.Date(2019, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name      string
+		exps      []expense.Expense
+		spent     int64
+		percent   float64
+		overspent bool
+	}{
+		{
+			name: "nothing",
+		},
+		{
+			name:    "once",
+			exps:    []expense.Expense{once("housing", eur(25000), march)},
+			spent:   25000,
+			percent: 25,
+		},
+		{
+			name:    "child",
+			exps:    []expense.Expense{once("rent", eur(30000), march)},
+			spent:   30000,
+			percent: 30,
+		},
+		{
+			name: "ignored",
+			exps: []expense.Expense{
+				once("food", eur(30000), march),
+				once("housing", money.Money{Amount: 30000, Currency: "USD"}, march),
+				once("housing", eur(30000), march.AddDate(0, -1, 0)),
+				once("housing", eur(30000), march.AddDate(0, 1, 0)),
+			},
+		},
+		{
+			name: "monthly",
+			exps: []expense.Expense{{
+				CategoryID:       "rent",
+				Amount:           eur(40000),
+				Reoccurrence:     1,
+				ReoccurrenceType: recurrence.TypeMonthly,
+				DurationType:     recurrence.DurationNone,
+				DateCreated:      time.Date(2018, time.November, 28, 0, 0, 0, 0, time.UTC),
+			}},
+			spent:   40000,
+			percent: 40,
+		},
+		{
+			name: "dailyToCome",
+			exps: []expense.Expense{{
+				CategoryID:       "housing",
+				Amount:           eur(1000),
+				Reoccurrence:     1,
+				ReoccurrenceType: recurrence.TypeDaily,
+				DurationType:     recurrence.DurationNone,
+				DateCreated:      time.Date(2019, time.February, 20, 0, 0, 0, 0, time.UTC),
+			}},
+			spent:   31000,
+			percent: 31,
+		},
+		{
+			name: "ended",
+			exps: []expense.Expense{{
+				CategoryID:       "housing",
+				Amount:           eur(40000),
+				Reoccurrence:     1,
+				Duration:         2,
+				ReoccurrenceType: recurrence.TypeMonthly,
+				DurationType:     recurrence.DurationMonths,
+				DateCreated:      time.Date(2019, time.January, 5, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name:    "atLimit",
+			exps:    []expense.Expense{once("housing", eur(60000), march), once("rent", eur(40000), march)},
+			spent:   100000,
+			percent: 100,
+		},
+		{
+			name:      "overspent",
+			exps:      []expense.Expense{once("housing", eur(60000), march), once("rent", eur(60000), march)},
+			spent:     120000,
+			percent:   120,
+			overspent: true,
+		},
+		{
+			name:    "rounded",
+			exps:    []expense.Expense{once("housing", eur(33333), march)},
+			spent:   33333,
+			percent: 33.33,
+		},
+	}
+
+	t.Log("Given the need to know how much of a budget is used in a month.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling %s expenses.", testID, tst.name)
+				{
+					got, err := budget.StatusOf(bgt, tst.exps, h, now)
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to get the status : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to get the status.", success, testID)
+
+					exp := budget.Status{
+						Budget:      bgt,
+						Period:      "2019-03",
+						Spent:       eur(tst.spent),
+						Remaining:   eur(bgt.Limit.Amount - tst.spent),
+						PercentUsed: tst.percent,
+						Overspent:   tst.overspent,
+					}
+					if diff := cmp.Diff(exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected status. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected status.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package db contains budget related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for budget access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// Create adds a Budget to the database.
+func (s Store) Create(ctx context.Context, bgt Budget) error {
+	const q = `
+	INSERT INTO budgets
+		(budget_id, user_id, category_id, currency, amount, date_created, date_updated)
+	VALUES
+		(:budget_id, :user_id, :category_id, :currency, :amount, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, bgt); err != nil {
+		return fmt.Errorf("inserting budget: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about a Budget.
+func (s Store) Update(ctx context.Context, bgt Budget) error {
+	const q = `
+	UPDATE
+		budgets
+	SET
+		"category_id" = :category_id,
+		"currency" = :currency,
+		"amount" = :amount,
+		"date_updated" = :date_updated
+	WHERE
+		budget_id = :budget_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, bgt); err != nil {
+		return fmt.Errorf("updating budget budgetID[%s]: %w", bgt.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the budget identified by a given ID.
+func (s Store) Delete(ctx context.Context, budgetID string) error {
+	data := struct {
+		BudgetID string `db:"budget_id"`
+	}{
+		BudgetID: budgetID,
+	}
+
+	const q = `
+	DELETE FROM
+		budgets
+	WHERE
+		budget_id = :budget_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting budget budgetID[%s]: %w", budgetID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the budget identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, budgetID string) (Budget, error) {
+	data := struct {
+		BudgetID string `db:"budget_id"`
+	}{
+		BudgetID: budgetID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		budgets
+	WHERE
+		budget_id = :budget_id`
+
+	var bgt Budget
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &bgt); err != nil {
+		return Budget{}, fmt.Errorf("selecting budget budgetID[%q]: %w", budgetID, err)
+	}
+
+	return bgt, nil
+}
+
+// QueryByUserID finds the budgets of a given User ID.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Budget, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		b.*
+	FROM
+		budgets AS b
+	JOIN
+		categories AS c USING (category_id)
+	WHERE
+		b.user_id = :user_id
+	ORDER BY
+		c.name, b.currency`
+
+	var bgts []Budget
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &bgts); err != nil {
+		return nil, fmt.Errorf("selecting budgets userID[%s]: %w", userID, err)
+	}
+
+	return bgts, nil
+}
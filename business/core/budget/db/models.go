@@ -0,0 +1,14 @@
+package db
+
+import "time"
+
+// Budget represents a monthly spending limit of a user for a category.
+type Budget struct {
+	ID          string    `db:"budget_id"`    // Unique identifier.
+	UserID      string    `db:"user_id"`      // ID of the user who owns the budget.
+	CategoryID  string    `db:"category_id"`  // ID of the expense category the budget applies to.
+	Currency    string    `db:"currency"`     // Currency of the limit.
+	Amount      int64     `db:"amount"`       // Monthly limit in the minor unit of the currency.
+	DateCreated time.Time `db:"date_created"` // When the budget was added.
+	DateUpdated time.Time `db:"date_updated"` // When the budget record was last modified.
+}
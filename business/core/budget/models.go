@@ -0,0 +1,67 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/budget/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Budget represents a monthly spending limit of a user for an expense
+// category in a given currency.
+type Budget struct {
+	ID          string      `json:"id"`           // Unique identifier.
+	UserID      string      `json:"user_id"`      // ID of the user who owns the budget.
+	CategoryID  string      `json:"category_id"`  // ID of the expense category the budget applies to.
+	Limit       money.Money `json:"limit"`        // Most that can be spent on the category and its children in a month.
+	DateCreated time.Time   `json:"date_created"` // When the budget was added.
+	DateUpdated time.Time   `json:"date_updated"` // When the budget record was last modified.
+}
+
+// NewBudget is what we require from clients when adding a Budget.
+type NewBudget struct {
+	UserID     string      `json:"user_id" validate:"required"`
+	CategoryID string      `json:"category_id" validate:"required"`
+	Limit      money.Money `json:"limit"`
+}
+
+// UpdateBudget defines what information may be provided to modify an
+// existing Budget. All fields are optional so clients can send just the
+// fields they want changed. It uses pointer fields so we can differentiate
+// between a field that was not provided and a field that was provided as
+// explicitly blank.
+type UpdateBudget struct {
+	CategoryID *string      `json:"category_id" validate:"omitempty,min=1"`
+	Limit      *money.Money `json:"limit"`
+}
+
+// Status represents how much of a budget has been used in a period.
+type Status struct {
+	Budget      Budget      `json:"budget"`       // Budget the status is for.
+	Period      string      `json:"period"`       // Month of the period (YYYY-MM).
+	Spent       money.Money `json:"spent"`        // Expenses of the category and its children within the period.
+	Remaining   money.Money `json:"remaining"`    // Limit minus spent, negative when overspent.
+	PercentUsed float64     `json:"percent_used"` // Spent as a percentage of the limit.
+	Overspent   bool        `json:"overspent"`    // Spent went over the limit.
+}
+
+// =============================================================================
+
+func toBudget(dbBgt db.Budget) Budget {
+	return Budget{
+		ID:          dbBgt.ID,
+		UserID:      dbBgt.UserID,
+		CategoryID:  dbBgt.CategoryID,
+		Limit:       money.Money{Amount: dbBgt.Amount, Currency: dbBgt.Currency},
+		DateCreated: dbBgt.DateCreated,
+		DateUpdated: dbBgt.DateUpdated,
+	}
+}
+
+func toBudgetSlice(dbBgts []db.Budget) []Budget {
+	bgts := make([]Budget, len(dbBgts))
+	for i, dbBgt := range dbBgts {
+		bgts[i] = toBudget(dbBgt)
+	}
+	return bgts
+}
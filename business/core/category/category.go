@@ -0,0 +1,267 @@
+// Package category provides a core business API for the nested categories
+// incomes and expenses are grouped by. Every user shares a default set of
+// categories and can add their own on top of it.
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/category/db"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound      = errors.New("category not found")
+	ErrInvalidID     = errors.New("ID is not in its proper form")
+	ErrInvalidParent = errors.New("parent category must be of the same kind and not nested in the category")
+	ErrDuplicate     = errors.New("category already exists")
+	ErrReadOnly      = errors.New("default categories can't be changed")
+	ErrInUse         = errors.New("category is in use")
+	ErrNotOwner      = errors.New("category belongs to another user")
+	ErrKindMismatch  = errors.New("category is not of the expected kind")
+)
+
+// Core manages the set of APIs for category access.
+type Core struct {
+	store db.Store
+}
+
+// NewCore constructs a core for category api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store: db.NewStore(log, sqlxDB),
+	}
+}
+
+// Create adds a Category to the database. Names are unique per kind among the
+// categories sharing a parent, regardless of case.
+func (c Core) Create(ctx context.Context, nc NewCategory, now time.Time) (Category, error) {
+	if err := validate.Check(nc); err != nil {
+		return Category{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	dbCats, err := c.store.QueryByUserID(ctx, nc.UserID)
+	if err != nil {
+		return Category{}, fmt.Errorf("query categories: %w", err)
+	}
+
+	dbCat := db.Category{
+		ID:          validate.GenerateID(),
+		UserID:      sql.NullString{String: nc.UserID, Valid: true},
+		ParentID:    sql.NullString{String: nc.ParentID, Valid: nc.ParentID != ""},
+		Name:        strings.TrimSpace(nc.Name),
+		Kind:        nc.Kind,
+		Icon:        nc.Icon,
+		Color:       nc.Color,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := checkPlacement(toCategorySlice(dbCats), toCategory(dbCat)); err != nil {
+		return Category{}, err
+	}
+
+	if err := c.store.Create(ctx, dbCat); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return Category{}, fmt.Errorf("create: %w", ErrDuplicate)
+		}
+		return Category{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toCategory(dbCat), nil
+}
+
+// Update modifies data about a Category. It will error if the specified ID is
+// invalid, does not reference an existing Category or references a default
+// one.
+func (c Core) Update(ctx context.Context, categoryID string, uc UpdateCategory, now time.Time) error {
+	if err := validate.CheckID(categoryID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(uc); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbCat, err := c.store.QueryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("updating category categoryID[%s]: %w", categoryID, err)
+	}
+
+	if !dbCat.UserID.Valid {
+		return ErrReadOnly
+	}
+
+	if uc.ParentID != nil {
+		dbCat.ParentID = sql.NullString{String: *uc.ParentID, Valid: *uc.ParentID != ""}
+	}
+	if uc.Name != nil {
+		dbCat.Name = strings.TrimSpace(*uc.Name)
+	}
+	if uc.Icon != nil {
+		dbCat.Icon = *uc.Icon
+	}
+	if uc.Color != nil {
+		dbCat.Color = *uc.Color
+	}
+	dbCat.DateUpdated = now
+
+	dbCats, err := c.store.QueryByUserID(ctx, dbCat.UserID.String)
+	if err != nil {
+		return fmt.Errorf("query categories: %w", err)
+	}
+
+	if err := checkPlacement(toCategorySlice(dbCats), toCategory(dbCat)); err != nil {
+		return err
+	}
+
+	if err := c.store.Update(ctx, dbCat); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("update: %w", ErrDuplicate)
+		}
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the category identified by a given ID. Categories that have
+// children or are used by incomes and expenses can't be removed, while the
+// budgets set on them are removed along with them.
+func (c Core) Delete(ctx context.Context, categoryID string) error {
+	if err := validate.CheckID(categoryID); err != nil {
+		return ErrInvalidID
+	}
+
+	dbCat, err := c.store.QueryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("deleting category categoryID[%s]: %w", categoryID, err)
+	}
+
+	if !dbCat.UserID.Valid {
+		return ErrReadOnly
+	}
+
+	if err := c.store.Delete(ctx, categoryID); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) {
+			return fmt.Errorf("delete: %w", ErrInUse)
+		}
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the category identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, categoryID string) (Category, error) {
+	if err := validate.CheckID(categoryID); err != nil {
+		return Category{}, ErrInvalidID
+	}
+
+	dbCat, err := c.store.QueryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Category{}, ErrNotFound
+		}
+		return Category{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toCategory(dbCat), nil
+}
+
+// QueryByUserID finds the categories available to a given User ID, which are
+// the default ones plus the ones the user added.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Category, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbCats, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toCategorySlice(dbCats), nil
+}
+
+// QueryHierarchyByUserID returns how the categories available to a given
+// User ID are nested.
+func (c Core) QueryHierarchyByUserID(ctx context.Context, userID string) (Hierarchy, error) {
+	cats, err := c.QueryByUserID(ctx, userID)
+	if err != nil {
+		return Hierarchy{}, err
+	}
+
+	return NewHierarchy(cats), nil
+}
+
+// Check verifies that money of a user of the given kind can be booked on the
+// category identified by a given ID.
+func (c Core) Check(ctx context.Context, categoryID string, userID string, kind string) error {
+	cat, err := c.QueryByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+
+	if cat.UserID != "" && cat.UserID != userID {
+		return ErrNotOwner
+	}
+
+	if cat.Kind != kind {
+		return ErrKindMismatch
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// checkPlacement verifies that the category fits among the categories of its
+// user. The parent has to be one of them, of the same kind and not nested in
+// the category itself, and no sibling can share the name.
+func checkPlacement(cats []Category, cat Category) error {
+	if cat.ParentID != "" {
+		var parent *Category
+		for i := range cats {
+			if cats[i].ID == cat.ParentID {
+				parent = &cats[i]
+				break
+			}
+		}
+
+		if parent == nil || parent.Kind != cat.Kind {
+			return ErrInvalidParent
+		}
+
+		if NewHierarchy(cats).Within(cat.ParentID, cat.ID) {
+			return ErrInvalidParent
+		}
+	}
+
+	for _, sibling := range cats {
+		if sibling.ID == cat.ID || sibling.Kind != cat.Kind || sibling.ParentID != cat.ParentID {
+			continue
+		}
+
+		if strings.EqualFold(sibling.Name, cat.Name) {
+			return ErrDuplicate
+		}
+	}
+
+	return nil
+}
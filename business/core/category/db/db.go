@@ -0,0 +1,154 @@
+// Package db contains category related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for category access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// Create adds a Category to the database.
+func (s Store) Create(ctx context.Context, cat Category) error {
+	const q = `
+	INSERT INTO categories
+		(category_id, user_id, parent_id, name, kind, icon, color, date_created, date_updated)
+	VALUES
+		(:category_id, :user_id, :parent_id, :name, :kind, :icon, :color, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, cat); err != nil {
+		return fmt.Errorf("inserting category: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about a Category.
+func (s Store) Update(ctx context.Context, cat Category) error {
+	const q = `
+	UPDATE
+		categories
+	SET
+		"parent_id" = :parent_id,
+		"name" = :name,
+		"icon" = :icon,
+		"color" = :color,
+		"date_updated" = :date_updated
+	WHERE
+		category_id = :category_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, cat); err != nil {
+		return fmt.Errorf("updating category categoryID[%s]: %w", cat.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the category identified by a given ID.
+func (s Store) Delete(ctx context.Context, categoryID string) error {
+	data := struct {
+		CategoryID string `db:"category_id"`
+	}{
+		CategoryID: categoryID,
+	}
+
+	const q = `
+	DELETE FROM
+		categories
+	WHERE
+		category_id = :category_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting category categoryID[%s]: %w", categoryID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the category identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, categoryID string) (Category, error) {
+	data := struct {
+		CategoryID string `db:"category_id"`
+	}{
+		CategoryID: categoryID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		categories
+	WHERE
+		category_id = :category_id`
+
+	var cat Category
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &cat); err != nil {
+		return Category{}, fmt.Errorf("selecting category categoryID[%q]: %w", categoryID, err)
+	}
+
+	return cat, nil
+}
+
+// QueryByUserID finds the categories available to a given User ID, which are
+// the default ones plus the ones the user added.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Category, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		categories
+	WHERE
+		user_id IS NULL OR user_id = :user_id
+	ORDER BY
+		kind, name`
+
+	var cats []Category
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &cats); err != nil {
+		return nil, fmt.Errorf("selecting categories userID[%s]: %w", userID, err)
+	}
+
+	return cats, nil
+}
@@ -0,0 +1,19 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Category represents a category incomes and expenses are grouped by.
+type Category struct {
+	ID          string         `db:"category_id"`  // Unique identifier.
+	UserID      sql.NullString `db:"user_id"`      // ID of the user who owns the category, null for the default ones.
+	ParentID    sql.NullString `db:"parent_id"`    // ID of the category it is nested in, null for top-level ones.
+	Name        string         `db:"name"`         // Display name of the category.
+	Kind        string         `db:"kind"`         // Kind of money movement it groups (income, expense).
+	Icon        string         `db:"icon"`         // Name of the icon shown with the category.
+	Color       string         `db:"color"`        // Hex color shown with the category.
+	DateCreated time.Time      `db:"date_created"` // When the category was added.
+	DateUpdated time.Time      `db:"date_updated"` // When the category record was last modified.
+}
@@ -0,0 +1,55 @@
+package category
+
+// Hierarchy knows how a set of categories is nested, so amounts booked on a
+// child category can be rolled up into its parents.
+type Hierarchy struct {
+	parents map[string]string
+}
+
+// NewHierarchy constructs the hierarchy of the given categories.
+func NewHierarchy(cats []Category) Hierarchy {
+	parents := make(map[string]string, len(cats))
+	for _, cat := range cats {
+		if cat.ParentID != "" {
+			parents[cat.ID] = cat.ParentID
+		}
+	}
+
+	return Hierarchy{
+		parents: parents,
+	}
+}
+
+// Root returns the ID of the top-level category the category identified by a
+// given ID is nested in. A top-level or unknown category is its own root.
+func (h Hierarchy) Root(categoryID string) string {
+	// Every step moves one level up, so there can't be more steps than
+	// nested categories unless the data has a cycle.
+	for i := 0; i < len(h.parents); i++ {
+		parentID, exists := h.parents[categoryID]
+		if !exists {
+			break
+		}
+		categoryID = parentID
+	}
+
+	return categoryID
+}
+
+// Within reports whether the category identified by a given ID is the
+// ancestor or is nested in it at any depth.
+func (h Hierarchy) Within(categoryID string, ancestorID string) bool {
+	for i := 0; i <= len(h.parents); i++ {
+		if categoryID == ancestorID {
+			return true
+		}
+
+		parentID, exists := h.parents[categoryID]
+		if !exists {
+			return false
+		}
+		categoryID = parentID
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package category_test
+
+import (
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// cats is a small tree with two levels of nesting below Housing.
+var cats = []category.Category{
+	{ID: "housing", Kind: category.KindExpense},
+	{ID: "rent", ParentID: "housing", Kind: category.KindExpense},
+	{ID: "deposit", ParentID: "rent", Kind: category.KindExpense},
+	{ID: "food", Kind: category.KindExpense},
+	{ID: "salary", Kind: category.KindIncome},
+}
+
+func Test_Root(t *testing.T) {
+	tt := []struct {
+		name string
+		id   string
+		exp  string
+	}{
+		{name: "topLevel", id: "housing", exp: "housing"},
+		{name: "child", id: "rent", exp: "housing"},
+		{name: "grandchild", id: "deposit", exp: "housing"},
+		{name: "unknown", id: "travel", exp: "travel"},
+	}
+
+	h := category.NewHierarchy(cats)
+
+	t.Log("Given the need to roll categories up into their top-level parent.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s category.", testID, tst.name)
+				{
+					if got := h.Root(tst.id); got != tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get root %q, got %q.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected root.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_Within(t *testing.T) {
+	tt := []struct {
+		name     string
+		id       string
+		ancestor string
+		exp      bool
+	}{
+		{name: "self", id: "food", ancestor: "food", exp: true},
+		{name: "child", id: "rent", ancestor: "housing", exp: true},
+		{name: "grandchild", id: "deposit", ancestor: "housing", exp: true},
+		{name: "parent", id: "housing", ancestor: "rent", exp: false},
+		{name: "sibling", id: "food", ancestor: "housing", exp: false},
+	}
+
+	h := category.NewHierarchy(cats)
+
+	t.Log("Given the need to know if a category is nested in another.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s category.", testID, tst.name)
+				{
+					if got := h.Within(tst.id, tst.ancestor); got != tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get %t, got %t.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected answer.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+
+	t.Log("Given the need to stop on categories nested in each other.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen handling a cycle.", testID)
+		{
+			cycle := category.NewHierarchy([]category.Category{
+				{ID: "a", ParentID: "b"},
+				{ID: "b", ParentID: "a"},
+			})
+
+			if cycle.Within("a", "c") {
+				t.Fatalf("\t%s\tTest %d:\tShould not find an unrelated ancestor.", failed, testID)
+			}
+			cycle.Root("a")
+			t.Logf("\t%s\tTest %d:\tShould return without looping forever.", success, testID)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package category
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/category/db"
+)
+
+// Set of kinds of money movement a category can group.
+const (
+	KindIncome  = "income"
+	KindExpense = "expense"
+)
+
+// Set of default categories records fall back to when no other category is
+// known for them, like the ones imported from a bank statement or the
+// payments of a debt.
+const (
+	OtherIncomeID   = "d557d02e-b4d5-4519-bf93-9ed3ab72d68f"
+	OtherExpensesID = "a498b021-f4ed-4f7f-9695-9e789e3ddf78"
+	LoansID         = "f0b3c1d2-7e4a-4c5b-9d6e-1a2b3c4d5e6f"
+)
+
+// Category represents a category incomes and expenses are grouped by.
+// Categories without a user are the default ones every user shares.
+type Category struct {
+	ID          string    `json:"id"`                  // Unique identifier.
+	UserID      string    `json:"user_id,omitempty"`   // ID of the user who owns the category, empty for the default ones.
+	ParentID    string    `json:"parent_id,omitempty"` // ID of the category it is nested in, empty for top-level ones.
+	Name        string    `json:"name"`                // Display name of the category.
+	Kind        string    `json:"kind"`                // Kind of money movement it groups (income, expense).
+	Icon        string    `json:"icon"`                // Name of the icon shown with the category.
+	Color       string    `json:"color"`               // Hex color shown with the category.
+	DateCreated time.Time `json:"date_created"`        // When the category was added.
+	DateUpdated time.Time `json:"date_updated"`        // When the category record was last modified.
+}
+
+// NewCategory is what we require from clients when adding a Category.
+type NewCategory struct {
+	UserID   string `json:"user_id" validate:"required"`
+	ParentID string `json:"parent_id"`
+	Name     string `json:"name" validate:"required"`
+	Kind     string `json:"kind" validate:"required,oneof=income expense"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color" validate:"omitempty,hexcolor"`
+}
+
+// UpdateCategory defines what information may be provided to modify an
+// existing Category. All fields are optional so clients can send just the
+// fields they want changed. It uses pointer fields so we can differentiate
+// between a field that was not provided and a field that was provided as
+// explicitly blank. An empty parent moves the category to the top level.
+type UpdateCategory struct {
+	ParentID *string `json:"parent_id"`
+	Name     *string `json:"name" validate:"omitempty,min=1"`
+	Icon     *string `json:"icon"`
+	Color    *string `json:"color" validate:"omitempty,hexcolor"`
+}
+
+// =============================================================================
+
+func toCategory(dbCat db.Category) Category {
+	return Category{
+		ID:          dbCat.ID,
+		UserID:      dbCat.UserID.String,
+		ParentID:    dbCat.ParentID.String,
+		Name:        dbCat.Name,
+		Kind:        dbCat.Kind,
+		Icon:        dbCat.Icon,
+		Color:       dbCat.Color,
+		DateCreated: dbCat.DateCreated,
+		DateUpdated: dbCat.DateUpdated,
+	}
+}
+
+func toCategorySlice(dbCats []db.Category) []Category {
+	cats := make([]Category, len(dbCats))
+	for i, dbCat := range dbCats {
+		cats[i] = toCategory(dbCat)
+	}
+	return cats
+}
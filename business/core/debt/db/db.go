@@ -0,0 +1,264 @@
+// Package db contains debt related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for debt access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// Create adds a Debt to the database.
+func (s Store) Create(ctx context.Context, dbt Debt) error {
+	const q = `
+	INSERT INTO debts
+		(debt_id, user_id, account_id, category_id, name, currency, principal, interest_rate, term, frequency, start_date, date_created, date_updated)
+	VALUES
+		(:debt_id, :user_id, :account_id, :category_id, :name, :currency, :principal, :interest_rate, :term, :frequency, :start_date, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbt); err != nil {
+		return fmt.Errorf("inserting debt: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about a Debt.
+func (s Store) Update(ctx context.Context, dbt Debt) error {
+	const q = `
+	UPDATE
+		debts
+	SET
+		"account_id" = :account_id,
+		"category_id" = :category_id,
+		"name" = :name,
+		"principal" = :principal,
+		"interest_rate" = :interest_rate,
+		"term" = :term,
+		"frequency" = :frequency,
+		"start_date" = :start_date,
+		"date_updated" = :date_updated
+	WHERE
+		debt_id = :debt_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbt); err != nil {
+		return fmt.Errorf("updating debt debtID[%s]: %w", dbt.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the debt identified by a given ID along with its extra
+// payments.
+func (s Store) Delete(ctx context.Context, debtID string) error {
+	data := struct {
+		DebtID string `db:"debt_id"`
+	}{
+		DebtID: debtID,
+	}
+
+	const q = `
+	DELETE FROM
+		debts
+	WHERE
+		debt_id = :debt_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting debt debtID[%s]: %w", debtID, err)
+	}
+
+	return nil
+}
+
+// Query retrieves every debt from the database.
+func (s Store) Query(ctx context.Context) ([]Debt, error) {
+	const q = `
+	SELECT
+		*
+	FROM
+		debts
+	ORDER BY
+		debt_id`
+
+	var dbts []Debt
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, struct{}{}, &dbts); err != nil {
+		return nil, fmt.Errorf("selecting debts: %w", err)
+	}
+
+	return dbts, nil
+}
+
+// QueryByID finds the debt identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, debtID string) (Debt, error) {
+	data := struct {
+		DebtID string `db:"debt_id"`
+	}{
+		DebtID: debtID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		debts
+	WHERE
+		debt_id = :debt_id`
+
+	var dbt Debt
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbt); err != nil {
+		return Debt{}, fmt.Errorf("selecting debt debtID[%q]: %w", debtID, err)
+	}
+
+	return dbt, nil
+}
+
+// QueryByUserID finds the debts of a given User ID.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Debt, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		debts
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		start_date, name`
+
+	var dbts []Debt
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbts); err != nil {
+		return nil, fmt.Errorf("selecting debts userID[%s]: %w", userID, err)
+	}
+
+	return dbts, nil
+}
+
+// CreatePayment adds an extra Payment to the database.
+func (s Store) CreatePayment(ctx context.Context, pmt Payment) error {
+	const q = `
+	INSERT INTO debt_payments
+		(payment_id, debt_id, amount, date_paid, date_created)
+	VALUES
+		(:payment_id, :debt_id, :amount, :date_paid, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, pmt); err != nil {
+		return fmt.Errorf("inserting payment: %w", err)
+	}
+
+	return nil
+}
+
+// QueryPayments finds the extra payments of a given Debt ID, the oldest
+// first.
+func (s Store) QueryPayments(ctx context.Context, debtID string) ([]Payment, error) {
+	data := struct {
+		DebtID string `db:"debt_id"`
+	}{
+		DebtID: debtID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		debt_payments
+	WHERE
+		debt_id = :debt_id
+	ORDER BY
+		date_paid, date_created`
+
+	var pmts []Payment
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &pmts); err != nil {
+		return nil, fmt.Errorf("selecting payments debtID[%s]: %w", debtID, err)
+	}
+
+	return pmts, nil
+}
+
+// CreatePosting posts a payment of a debt in the transactions ledger. A
+// payment that was already posted for the same source and date is silently
+// skipped.
+func (s Store) CreatePosting(ctx context.Context, pst Posting) error {
+	const q = `
+	INSERT INTO transactions
+		(transaction_id, user_id, source_type, source_id, account_id, name, category_id, currency, amount, date_occurred, date_created)
+	VALUES
+		(:transaction_id, :user_id, :source_type, :source_id, :account_id, :name, :category_id, :currency, :amount, :date_occurred, :date_created)
+	ON CONFLICT (source_type, source_id, date_occurred) DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, pst); err != nil {
+		return fmt.Errorf("inserting posting sourceID[%s]: %w", pst.SourceID, err)
+	}
+
+	return nil
+}
+
+// DeletePostings removes the payments of the debt identified by a given ID
+// from the transactions ledger, both the installments and the extra payments.
+// The postings have no foreign key on their source, so they have to go before
+// the debt does.
+func (s Store) DeletePostings(ctx context.Context, debtID string) error {
+	data := struct {
+		DebtID string `db:"debt_id"`
+	}{
+		DebtID: debtID,
+	}
+
+	const q = `
+	DELETE FROM
+		transactions
+	WHERE
+		source_type = 'debt_payment' AND
+		(
+			source_id = :debt_id OR
+			source_id IN (SELECT payment_id FROM debt_payments WHERE debt_id = :debt_id)
+		)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting postings debtID[%s]: %w", debtID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Debt represents a loan of a user repaid in installments.
+type Debt struct {
+	ID           string         `db:"debt_id"`       // Unique identifier.
+	UserID       string         `db:"user_id"`       // ID of the user who owes the debt.
+	AccountID    sql.NullString `db:"account_id"`    // ID of the account the installments are paid from.
+	CategoryID   string         `db:"category_id"`   // ID of the expense category of the installments.
+	Name         string         `db:"name"`          // Display name of the debt.
+	Currency     string         `db:"currency"`      // Currency of the debt.
+	Principal    int64          `db:"principal"`     // Amount borrowed in the minor unit of the currency.
+	InterestRate float64        `db:"interest_rate"` // Annual interest rate as a percentage.
+	Term         int            `db:"term"`          // Number of installments.
+	Frequency    string         `db:"frequency"`     // How often an installment is due.
+	StartDate    time.Time      `db:"start_date"`    // Date of the first installment.
+	DateCreated  time.Time      `db:"date_created"`  // When the debt was added.
+	DateUpdated  time.Time      `db:"date_updated"`  // When the debt record was last modified.
+}
+
+// Payment represents an extra payment made on top of the installments.
+type Payment struct {
+	ID          string    `db:"payment_id"`   // Unique identifier.
+	DebtID      string    `db:"debt_id"`      // ID of the debt paid off.
+	Amount      int64     `db:"amount"`       // Amount in the minor unit of the debt currency.
+	DatePaid    time.Time `db:"date_paid"`    // When the money was paid.
+	DateCreated time.Time `db:"date_created"` // When the payment was recorded.
+}
+
+// Posting represents a payment of a debt in the transactions ledger.
+type Posting struct {
+	ID           string         `db:"transaction_id"` // Unique identifier.
+	UserID       string         `db:"user_id"`        // ID of the user who owns the transaction.
+	SourceType   string         `db:"source_type"`    // Always debt_payment.
+	SourceID     string         `db:"source_id"`      // ID of the debt for installments, of the payment for extra payments.
+	AccountID    sql.NullString `db:"account_id"`     // ID of the account the money left.
+	Name         string         `db:"name"`           // Display name of the transaction.
+	CategoryID   string         `db:"category_id"`    // ID of the category of the debt.
+	Currency     string         `db:"currency"`       // Currency of the transaction.
+	Amount       int64          `db:"amount"`         // Amount of money of the transaction.
+	DateOccurred time.Time      `db:"date_occurred"`  // When the money moved.
+	DateCreated  time.Time      `db:"date_created"`   // When the transaction was added.
+}
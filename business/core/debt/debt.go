@@ -0,0 +1,397 @@
+// Package debt provides a core business API for loans repaid in installments,
+// their amortization schedules and the extra payments made towards them.
+package debt
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/debt/db"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/sys/amortization"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound         = errors.New("debt not found")
+	ErrInvalidID        = errors.New("ID is not in its proper form")
+	ErrInvalidPrincipal = errors.New("principal must be greater than zero")
+	ErrInvalidAmount    = errors.New("payment must be greater than zero")
+	ErrCurrencyMismatch = errors.New("currency does not match the debt")
+)
+
+// Core manages the set of APIs for debt access.
+type Core struct {
+	store    db.Store
+	account  account.Core
+	category category.Core
+}
+
+// NewCore constructs a core for debt api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store:    db.NewStore(log, sqlxDB),
+		account:  account.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+	}
+}
+
+// Create adds a Debt to the database. It returns the created Debt with fields
+// like ID and DateCreated populated.
+func (c Core) Create(ctx context.Context, nd NewDebt, now time.Time) (Debt, error) {
+	if err := validate.Check(nd); err != nil {
+		return Debt{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if !nd.Principal.IsPositive() {
+		return Debt{}, ErrInvalidPrincipal
+	}
+
+	if nd.CategoryID == "" {
+		nd.CategoryID = category.LoansID
+	}
+
+	if err := c.category.Check(ctx, nd.CategoryID, nd.UserID, category.KindExpense); err != nil {
+		return Debt{}, fmt.Errorf("category: %w", err)
+	}
+
+	if nd.AccountID != "" {
+		if err := c.account.Check(ctx, nd.AccountID, nd.UserID, nd.Principal.Currency); err != nil {
+			return Debt{}, fmt.Errorf("account: %w", err)
+		}
+	}
+
+	dbDbt := db.Debt{
+		ID:           validate.GenerateID(),
+		UserID:       nd.UserID,
+		AccountID:    sql.NullString{String: nd.AccountID, Valid: nd.AccountID != ""},
+		CategoryID:   nd.CategoryID,
+		Name:         nd.Name,
+		Currency:     nd.Principal.Currency,
+		Principal:    nd.Principal.Amount,
+		InterestRate: nd.InterestRate,
+		Term:         nd.Term,
+		Frequency:    nd.Frequency,
+		StartDate:    nd.StartDate,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	if err := c.store.Create(ctx, dbDbt); err != nil {
+		return Debt{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toDebt(dbDbt), nil
+}
+
+// Update modifies data about a Debt. It will error if the specified ID is
+// invalid or does not reference an existing Debt. The currency of a debt
+// can't change since its extra payments are kept in it.
+func (c Core) Update(ctx context.Context, debtID string, ud UpdateDebt, now time.Time) error {
+	if err := validate.CheckID(debtID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(ud); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbDbt, err := c.store.QueryByID(ctx, debtID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("updating debt debtID[%s]: %w", debtID, err)
+	}
+
+	if ud.Name != nil {
+		dbDbt.Name = *ud.Name
+	}
+	if ud.CategoryID != nil {
+		if err := c.category.Check(ctx, *ud.CategoryID, dbDbt.UserID, category.KindExpense); err != nil {
+			return fmt.Errorf("category: %w", err)
+		}
+		dbDbt.CategoryID = *ud.CategoryID
+	}
+	if ud.AccountID != nil {
+		if *ud.AccountID != "" {
+			if err := c.account.Check(ctx, *ud.AccountID, dbDbt.UserID, dbDbt.Currency); err != nil {
+				return fmt.Errorf("account: %w", err)
+			}
+		}
+		dbDbt.AccountID = sql.NullString{String: *ud.AccountID, Valid: *ud.AccountID != ""}
+	}
+	if ud.Principal != nil {
+		if ud.Principal.Currency != dbDbt.Currency {
+			return ErrCurrencyMismatch
+		}
+		if !ud.Principal.IsPositive() {
+			return ErrInvalidPrincipal
+		}
+		dbDbt.Principal = ud.Principal.Amount
+	}
+	if ud.InterestRate != nil {
+		dbDbt.InterestRate = *ud.InterestRate
+	}
+	if ud.Term != nil {
+		dbDbt.Term = *ud.Term
+	}
+	if ud.Frequency != nil {
+		dbDbt.Frequency = *ud.Frequency
+	}
+	if ud.StartDate != nil {
+		dbDbt.StartDate = *ud.StartDate
+	}
+	dbDbt.DateUpdated = now
+
+	if err := c.store.Update(ctx, dbDbt); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the debt identified by a given ID along with its extra
+// payments and every payment of it posted in the ledger.
+func (c Core) Delete(ctx context.Context, debtID string) error {
+	if err := validate.CheckID(debtID); err != nil {
+		return ErrInvalidID
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		store := c.store.Tran(tx)
+
+		if err := store.DeletePostings(ctx, debtID); err != nil {
+			return fmt.Errorf("postings: %w", err)
+		}
+
+		if err := store.Delete(ctx, debtID); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the debt identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, debtID string) (Debt, error) {
+	if err := validate.CheckID(debtID); err != nil {
+		return Debt{}, ErrInvalidID
+	}
+
+	dbDbt, err := c.store.QueryByID(ctx, debtID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Debt{}, ErrNotFound
+		}
+		return Debt{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toDebt(dbDbt), nil
+}
+
+// QueryByUserID finds the debts identified by a given User ID.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Debt, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbDbts, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toDebtSlice(dbDbts), nil
+}
+
+// Pay records an extra payment towards the debt identified by a given ID. The
+// payment is posted in the ledger right away and shortens the schedule of the
+// debt.
+func (c Core) Pay(ctx context.Context, debtID string, np NewPayment, now time.Time) (Payment, error) {
+	dbt, err := c.QueryByID(ctx, debtID)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	if err := validate.Check(np); err != nil {
+		return Payment{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if !np.Amount.IsPositive() {
+		return Payment{}, ErrInvalidAmount
+	}
+
+	if np.Amount.Currency != dbt.Principal.Currency {
+		return Payment{}, ErrCurrencyMismatch
+	}
+
+	date := np.Date
+	if date.IsZero() {
+		date = now
+	}
+
+	dbPmt := db.Payment{
+		ID:          validate.GenerateID(),
+		DebtID:      dbt.ID,
+		Amount:      np.Amount.Amount,
+		DatePaid:    date,
+		DateCreated: now,
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).CreatePayment(ctx, dbPmt); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		if err := c.store.Tran(tx).CreatePosting(ctx, postingOf(dbt, dbPmt.ID, np.Amount.Amount, date, now)); err != nil {
+			return fmt.Errorf("post: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return Payment{}, fmt.Errorf("tran: %w", err)
+	}
+
+	return toPayment(dbPmt, dbt.Principal.Currency), nil
+}
+
+// QuerySchedule computes the amortization schedule of the debt identified by
+// a given ID, with its extra payments applied.
+func (c Core) QuerySchedule(ctx context.Context, debtID string, now time.Time) (Schedule, error) {
+	dbt, err := c.QueryByID(ctx, debtID)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return c.schedule(ctx, dbt, now)
+}
+
+// QueryScheduleByUserID computes the amortization schedule of every debt of a
+// given User ID.
+func (c Core) QueryScheduleByUserID(ctx context.Context, userID string, now time.Time) ([]Schedule, error) {
+	dbts, err := c.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schs := make([]Schedule, len(dbts))
+	for i, dbt := range dbts {
+		if schs[i], err = c.schedule(ctx, dbt, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return schs, nil
+}
+
+// Materialize walks the schedule of every debt and posts one transaction per
+// installment that fell due up to now. Installments that were already posted
+// are skipped, so it is safe to call this as often as needed. It returns the
+// number of installments that were processed.
+func (c Core) Materialize(ctx context.Context, now time.Time) (int, error) {
+	dbDbts, err := c.store.Query(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("query debts: %w", err)
+	}
+
+	var total int
+	for _, dbt := range toDebtSlice(dbDbts) {
+		sch, err := c.schedule(ctx, dbt, now)
+		if err != nil {
+			return total, err
+		}
+
+		var due []amortization.Payment
+		for _, pmt := range sch.Payments {
+			if pmt.Date.After(now) {
+				break
+			}
+			due = append(due, pmt)
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		tran := func(tx sqlx.ExtContext) error {
+			for _, pmt := range due {
+				if err := c.store.Tran(tx).CreatePosting(ctx, postingOf(dbt, dbt.ID, pmt.Payment.Amount, pmt.Date, now)); err != nil {
+					return fmt.Errorf("post: %w", err)
+				}
+			}
+			return nil
+		}
+
+		if err := c.store.WithinTran(ctx, tran); err != nil {
+			return total, fmt.Errorf("tran debt[%s]: %w", dbt.ID, err)
+		}
+
+		total += len(due)
+	}
+
+	return total, nil
+}
+
+// =============================================================================
+
+// schedule loads the extra payments of the debt and computes its schedule.
+func (c Core) schedule(ctx context.Context, dbt Debt, now time.Time) (Schedule, error) {
+	dbPmts, err := c.store.QueryPayments(ctx, dbt.ID)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("query payments: %w", err)
+	}
+	pmts := toPaymentSlice(dbPmts, dbt.Principal.Currency)
+
+	extras := make([]amortization.Extra, len(pmts))
+	for i, pmt := range pmts {
+		extras[i] = amortization.Extra{Date: pmt.DatePaid, Amount: pmt.Amount}
+	}
+
+	sch, err := loanOf(dbt).Schedule(extras...)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("debtID[%s]: %w", dbt.ID, err)
+	}
+
+	balance := dbt.Principal
+	for _, pmt := range sch.Payments {
+		if pmt.Date.After(now) {
+			break
+		}
+		balance = pmt.Balance
+	}
+
+	return Schedule{Debt: dbt, Extras: pmts, Balance: balance, Schedule: sch}, nil
+}
+
+// postingOf builds the ledger transaction of a payment of the debt.
+func postingOf(dbt Debt, sourceID string, amount int64, date time.Time, now time.Time) db.Posting {
+	return db.Posting{
+		ID:           validate.GenerateID(),
+		UserID:       dbt.UserID,
+		SourceType:   transaction.SourceDebtPayment,
+		SourceID:     sourceID,
+		AccountID:    sql.NullString{String: dbt.AccountID, Valid: dbt.AccountID != ""},
+		Name:         dbt.Name,
+		CategoryID:   dbt.CategoryID,
+		Currency:     dbt.Principal.Currency,
+		Amount:       amount,
+		DateOccurred: date,
+		DateCreated:  now,
+	}
+}
@@ -0,0 +1,137 @@
+package debt
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/debt/db"
+	"github.com/gloompi/ultimate-service/business/sys/amortization"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Debt represents a loan, like a mortgage or a car loan, a user repays in
+// installments.
+type Debt struct {
+	ID           string      `json:"id"`                   // Unique identifier.
+	UserID       string      `json:"user_id"`              // ID of the user who owes the debt.
+	AccountID    string      `json:"account_id,omitempty"` // ID of the account the installments are paid from.
+	CategoryID   string      `json:"category_id"`          // ID of the expense category of the installments.
+	Name         string      `json:"name"`                 // Display name of the debt.
+	Principal    money.Money `json:"principal"`            // Amount borrowed.
+	InterestRate float64     `json:"interest_rate"`        // Annual interest rate as a percentage, like 4.5.
+	Term         int         `json:"term"`                 // Number of installments.
+	Frequency    string      `json:"frequency"`            // How often an installment is due.
+	StartDate    time.Time   `json:"start_date"`           // Date of the first installment.
+	DateCreated  time.Time   `json:"date_created"`         // When the debt was added.
+	DateUpdated  time.Time   `json:"date_updated"`         // When the debt record was last modified.
+}
+
+// NewDebt is what we require from clients when adding a Debt. The category
+// defaults to Loans.
+type NewDebt struct {
+	UserID       string      `json:"user_id" validate:"required"`
+	AccountID    string      `json:"account_id"`
+	CategoryID   string      `json:"category_id"`
+	Name         string      `json:"name" validate:"required"`
+	Principal    money.Money `json:"principal"`
+	InterestRate float64     `json:"interest_rate" validate:"gte=0,lte=100"`
+	Term         int         `json:"term" validate:"required,min=1,max=1560"`
+	Frequency    string      `json:"frequency" validate:"required,oneof=weekly biweekly monthly quarterly yearly"`
+	StartDate    time.Time   `json:"start_date" validate:"required"`
+}
+
+// UpdateDebt defines what information may be provided to modify an existing
+// Debt. All fields are optional so clients can send just the fields they want
+// changed. It uses pointer fields so we can differentiate between a field that
+// was not provided and a field that was provided as explicitly blank. An empty
+// account ID unlinks the debt from its account.
+type UpdateDebt struct {
+	AccountID    *string      `json:"account_id"`
+	CategoryID   *string      `json:"category_id" validate:"omitempty,min=1"`
+	Name         *string      `json:"name" validate:"omitempty,min=1"`
+	Principal    *money.Money `json:"principal"`
+	InterestRate *float64     `json:"interest_rate" validate:"omitempty,gte=0,lte=100"`
+	Term         *int         `json:"term" validate:"omitempty,min=1,max=1560"`
+	Frequency    *string      `json:"frequency" validate:"omitempty,oneof=weekly biweekly monthly quarterly yearly"`
+	StartDate    *time.Time   `json:"start_date"`
+}
+
+// Payment represents money paid towards a debt on top of its installments.
+type Payment struct {
+	ID          string      `json:"id"`           // Unique identifier.
+	DebtID      string      `json:"debt_id"`      // ID of the debt paid off.
+	Amount      money.Money `json:"amount"`       // Amount paid.
+	DatePaid    time.Time   `json:"date_paid"`    // When the money was paid.
+	DateCreated time.Time   `json:"date_created"` // When the payment was recorded.
+}
+
+// NewPayment is what we require from clients when recording an extra Payment.
+// The date defaults to the time of the request.
+type NewPayment struct {
+	Amount money.Money `json:"amount"`
+	Date   time.Time   `json:"date"`
+}
+
+// Schedule represents the installments of a debt, taking its extra payments
+// into account.
+type Schedule struct {
+	Debt    Debt        `json:"debt"`           // Debt the schedule is for.
+	Extras  []Payment   `json:"extra_payments"` // Extra payments applied to the schedule.
+	Balance money.Money `json:"balance"`        // Principal still owed as of now.
+	amortization.Schedule
+}
+
+// =============================================================================
+
+func toDebt(dbDbt db.Debt) Debt {
+	return Debt{
+		ID:           dbDbt.ID,
+		UserID:       dbDbt.UserID,
+		AccountID:    dbDbt.AccountID.String,
+		CategoryID:   dbDbt.CategoryID,
+		Name:         dbDbt.Name,
+		Principal:    money.Money{Amount: dbDbt.Principal, Currency: dbDbt.Currency},
+		InterestRate: dbDbt.InterestRate,
+		Term:         dbDbt.Term,
+		Frequency:    dbDbt.Frequency,
+		StartDate:    dbDbt.StartDate,
+		DateCreated:  dbDbt.DateCreated,
+		DateUpdated:  dbDbt.DateUpdated,
+	}
+}
+
+func toDebtSlice(dbDbts []db.Debt) []Debt {
+	dbts := make([]Debt, len(dbDbts))
+	for i, dbDbt := range dbDbts {
+		dbts[i] = toDebt(dbDbt)
+	}
+	return dbts
+}
+
+func toPayment(dbPmt db.Payment, currency string) Payment {
+	return Payment{
+		ID:          dbPmt.ID,
+		DebtID:      dbPmt.DebtID,
+		Amount:      money.Money{Amount: dbPmt.Amount, Currency: currency},
+		DatePaid:    dbPmt.DatePaid,
+		DateCreated: dbPmt.DateCreated,
+	}
+}
+
+func toPaymentSlice(dbPmts []db.Payment, currency string) []Payment {
+	pmts := make([]Payment, len(dbPmts))
+	for i, dbPmt := range dbPmts {
+		pmts[i] = toPayment(dbPmt, currency)
+	}
+	return pmts
+}
+
+// loanOf builds the amortization loan from the terms of a debt.
+func loanOf(dbt Debt) amortization.Loan {
+	return amortization.Loan{
+		Principal: dbt.Principal,
+		Rate:      dbt.InterestRate,
+		Term:      dbt.Term,
+		Frequency: dbt.Frequency,
+		Start:     dbt.StartDate,
+	}
+}
@@ -0,0 +1,178 @@
+// Package duplicate provides a core business API to find the expenses users
+// entered twice and merge them back into one.
+package duplicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/attachment"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/household"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/dedupe"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/gloompi/ultimate-service/foundation/blob"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for duplicate operations.
+var (
+	ErrInvalidID    = errors.New("ID is not in its proper form")
+	ErrSameExpense  = errors.New("expense can't be merged into itself")
+	ErrNotSameOwner = errors.New("expenses belong to different users")
+)
+
+// Core manages the set of APIs for duplicate access.
+type Core struct {
+	log        *zap.SugaredLogger
+	tr         database.Transactor
+	expense    expense.Core
+	household  household.Core
+	attachment attachment.Core
+}
+
+// NewCore constructs a core for duplicate api access. The blob store holds
+// the content of the attachments moved by merges.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB, bs blob.Store) Core {
+	return Core{
+		log:        log,
+		tr:         sqlxDB,
+		expense:    expense.NewCore(log, sqlxDB),
+		household:  household.NewCore(log, sqlxDB),
+		attachment: attachment.NewCore(log, sqlxDB, bs, 0),
+	}
+}
+
+// QueryByUserID finds the pairs of expenses of a user suspected to be
+// duplicates, the most recent first. See the dedupe package for what makes
+// two expenses alike.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Pair, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	exps, err := c.expense.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	byID := make(map[string]expense.Expense, len(exps))
+	recs := make([]dedupe.Record, len(exps))
+	for i, exp := range exps {
+		byID[exp.ID] = exp
+		recs[i] = dedupe.Record{
+			ID:     exp.ID,
+			Name:   exp.Name,
+			Amount: exp.Amount,
+			Date:   exp.DateCreated,
+		}
+	}
+
+	found := dedupe.Find(recs)
+	pairs := make([]Pair, len(found))
+	for i, p := range found {
+		pairs[i] = Pair{
+			First:  byID[p.First.ID],
+			Second: byID[p.Second.ID],
+			Score:  p.Score,
+		}
+	}
+
+	return pairs, nil
+}
+
+// Merge keeps one of two expenses of a user and moves the other to the
+// trash, so the money is only counted once. The one kept gets the tags and
+// the attachments of the other, and the way the other was shared with a
+// household when the one kept is not shared, all at once. It returns the
+// expense kept.
+func (c Core) Merge(ctx context.Context, m Merge, now time.Time) (expense.Expense, error) {
+	if err := validate.Check(m); err != nil {
+		return expense.Expense{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if m.KeepID == m.DropID {
+		return expense.Expense{}, ErrSameExpense
+	}
+
+	keep, err := c.expense.QueryByID(ctx, m.KeepID)
+	if err != nil {
+		return expense.Expense{}, fmt.Errorf("keep: %w", err)
+	}
+
+	drop, err := c.expense.QueryByID(ctx, m.DropID)
+	if err != nil {
+		return expense.Expense{}, fmt.Errorf("drop: %w", err)
+	}
+
+	if keep.UserID != drop.UserID {
+		return expense.Expense{}, ErrNotSameOwner
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		exp := c.expense.Tran(tx)
+
+		if tags, changed := mergeTags(keep.Tags, drop.Tags); changed {
+			upd := expense.UpdateExpense{
+				CategoryID: &keep.CategoryID,
+				Amount:     &keep.Amount,
+				Tags:       &tags,
+			}
+
+			if err := exp.Update(ctx, keep.ID, upd, keep.Version, now); err != nil {
+				return fmt.Errorf("update: %w", err)
+			}
+		}
+
+		if err := c.attachment.Tran(tx).Move(ctx, drop.ID, keep.ID); err != nil {
+			return fmt.Errorf("attachments: %w", err)
+		}
+
+		if err := c.household.Tran(tx).MoveSplit(ctx, drop.ID, keep.ID); err != nil {
+			return fmt.Errorf("split: %w", err)
+		}
+
+		if err := exp.Delete(ctx, drop.ID, now); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.tr, tran); err != nil {
+		return expense.Expense{}, fmt.Errorf("tran: %w", err)
+	}
+
+	exp, err := c.expense.QueryByID(ctx, keep.ID)
+	if err != nil {
+		return expense.Expense{}, fmt.Errorf("query: %w", err)
+	}
+
+	return exp, nil
+}
+
+// =============================================================================
+
+// mergeTags adds the tags of the expense dropped the one kept doesn't have
+// yet, regardless of case, and reports whether there were any.
+func mergeTags(keep []string, drop []string) ([]string, bool) {
+	seen := make(map[string]bool, len(keep))
+	for _, name := range keep {
+		seen[strings.ToLower(name)] = true
+	}
+
+	tags := append([]string(nil), keep...)
+	for _, name := range drop {
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			tags = append(tags, name)
+		}
+	}
+
+	return tags, len(tags) > len(keep)
+}
@@ -0,0 +1,215 @@
+package duplicate_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/duplicate"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/household"
+	"github.com/gloompi/ultimate-service/business/core/report"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/data/tests"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/foundation/blob"
+	"github.com/gloompi/ultimate-service/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+// Seeded users, account and category the expenses are booked with.
+const (
+	userID     = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+	adminID    = "5cf37266-3473-4006-984f-9325122678b7"
+	checkingID = "c1e2a3b4-5d6e-4f70-8a91-b2c3d4e5f607"
+	taxesID    = "4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9"
+)
+
+// totals is what the ledger and the household say about the user at some
+// point.
+type totals struct {
+	expense int64 // EUR expense of the January report.
+	balance int64 // Balance of the checking account.
+	net     int64 // What the household owes the user.
+}
+
+func Test_Merge(t *testing.T) {
+	log, db, teardown := tests.NewUnit(t, c, "testduplicate")
+	t.Cleanup(teardown)
+
+	bs, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Creating blob store: %s", err)
+	}
+
+	core := duplicate.NewCore(log, db, bs)
+	expCore := expense.NewCore(log, db)
+	accCore := account.NewCore(log, db)
+	repCore := report.NewCore(log, db)
+	trnCore := transaction.NewCore(log, db)
+	hhCore := household.NewCore(log, db)
+
+	ctx := context.Background()
+	now := time.Date(2019, time.January, 15, 12, 0, 0, 0, time.UTC)
+	from := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	hh, err := hhCore.Create(ctx, household.NewHousehold{Name: "Flat"}, userID, now)
+	if err != nil {
+		t.Fatalf("Creating household: %s", err)
+	}
+	if _, err := hhCore.AddMember(ctx, hh.ID, household.NewMember{UserID: adminID, Role: household.RoleMember}, now); err != nil {
+		t.Fatalf("Adding member: %s", err)
+	}
+
+	create := func(name string, amount int64, shared bool) (expense.Expense, error) {
+		ne := expense.NewExpense{
+			Name:             name,
+			CategoryID:       taxesID,
+			Amount:           money.Money{Amount: amount, Currency: "EUR"},
+			Reoccurrence:     1,
+			ReoccurrenceType: recurrence.TypeOnce,
+			DurationType:     recurrence.DurationNone,
+			UserID:           userID,
+			AccountID:        checkingID,
+		}
+
+		exp, err := expCore.Create(ctx, ne, now)
+		if err != nil {
+			return expense.Expense{}, err
+		}
+
+		if shared {
+			if _, err := hhCore.Split(ctx, hh.ID, exp.ID, household.NewSplit{Method: household.MethodEqual}, now); err != nil {
+				return expense.Expense{}, err
+			}
+		}
+
+		return exp, nil
+	}
+
+	query := func() (totals, error) {
+		sum, err := repCore.QuerySummary(ctx, userID, report.GroupByCurrency, from, to, false)
+		if err != nil {
+			return totals{}, fmt.Errorf("summary: %w", err)
+		}
+
+		acc, err := accCore.QueryByID(ctx, checkingID)
+		if err != nil {
+			return totals{}, fmt.Errorf("account: %w", err)
+		}
+
+		bals, err := hhCore.QueryBalances(ctx, hh.ID, now)
+		if err != nil {
+			return totals{}, fmt.Errorf("balances: %w", err)
+		}
+
+		tot := totals{balance: acc.Balance.Amount}
+		for _, bkt := range sum.Buckets {
+			if bkt.Key == "EUR" {
+				tot.expense = bkt.Expense.Amount
+			}
+		}
+		for _, bal := range bals.Members {
+			if bal.UserID == userID && bal.Net.Currency == "EUR" {
+				tot.net = bal.Net.Amount
+			}
+		}
+		return tot, nil
+	}
+
+	t.Log("Given the need to merge expenses entered twice.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen merging expenses that were counted twice.", testID)
+		{
+			// Both streaming expenses are shared, only the dropped internet
+			// expense is.
+			keepA, err := create("Netflix", 1200, true)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an expense : %s.", tests.Failed, testID, err)
+			}
+			dropA, err := create("Netflix", 1200, true)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an expense : %s.", tests.Failed, testID, err)
+			}
+			keepB, err := create("Internet", 3000, false)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an expense : %s.", tests.Failed, testID, err)
+			}
+			dropB, err := create("Internet", 3000, true)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an expense : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create the expenses.", tests.Success, testID)
+
+			if len(dropA.Duplicates) != 1 || dropA.Duplicates[0] != keepA.ID {
+				t.Fatalf("\t%s\tTest %d:\tShould flag the second expense as a duplicate : got %v.", tests.Failed, testID, dropA.Duplicates)
+			}
+			t.Logf("\t%s\tTest %d:\tShould flag the second expense as a duplicate.", tests.Success, testID)
+
+			if _, err := trnCore.Materialize(ctx, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to post the ledger : %s.", tests.Failed, testID, err)
+			}
+
+			before, err := query()
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the totals : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to query the totals.", tests.Success, testID)
+
+			for _, m := range []duplicate.Merge{{KeepID: keepA.ID, DropID: dropA.ID}, {KeepID: keepB.ID, DropID: dropB.ID}} {
+				if _, err := core.Merge(ctx, m, now); err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to merge : %s.", tests.Failed, testID, err)
+				}
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to merge.", tests.Success, testID)
+
+			after, err := query()
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the totals : %s.", tests.Failed, testID, err)
+			}
+
+			// The user paid both and is owed half of each by the other member.
+			exp := totals{
+				expense: before.expense - 1200 - 3000,
+				balance: before.balance + 1200 + 3000,
+				net:     before.net - 600,
+			}
+			if after != exp {
+				t.Fatalf("\t%s\tTest %d:\tShould only count the kept expenses : got %+v, exp %+v.", tests.Failed, testID, after, exp)
+			}
+			t.Logf("\t%s\tTest %d:\tShould only count the kept expenses.", tests.Success, testID)
+
+			spls, err := hhCore.QuerySplits(ctx, hh.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the splits : %s.", tests.Failed, testID, err)
+			}
+
+			shared := make(map[string]bool)
+			for _, spl := range spls {
+				shared[spl.ExpenseID] = true
+			}
+			if len(spls) != 2 || !shared[keepA.ID] || !shared[keepB.ID] {
+				t.Fatalf("\t%s\tTest %d:\tShould only share the kept expenses : got %v.", tests.Failed, testID, shared)
+			}
+			t.Logf("\t%s\tTest %d:\tShould only share the kept expenses.", tests.Success, testID)
+		}
+	}
+}
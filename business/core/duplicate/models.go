@@ -0,0 +1,18 @@
+package duplicate
+
+import (
+	"github.com/gloompi/ultimate-service/business/core/expense"
+)
+
+// Pair represents two expenses suspected to be the same one entered twice.
+type Pair struct {
+	First  expense.Expense `json:"first"`  // The earlier of the two.
+	Second expense.Expense `json:"second"` // The later of the two.
+	Score  float64         `json:"score"`  // How alike their names are, from 0 to 1.
+}
+
+// Merge is what we require from clients when merging two expenses.
+type Merge struct {
+	KeepID string `json:"keep_id" validate:"required"`
+	DropID string `json:"drop_id" validate:"required"`
+}
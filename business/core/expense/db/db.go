@@ -4,8 +4,12 @@ package db
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
 	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/order"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
@@ -30,7 +34,7 @@ func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
 // WithinTran runs passed function and do commit/rollback at the end.
 func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
 	if s.isWithinTran {
-		fn(s.db)
+		return fn(s.db)
 	}
 	return database.WithinTran(ctx, s.log, s.tr, fn)
 }
@@ -45,14 +49,57 @@ func (s Store) Tran(tx sqlx.ExtContext) Store {
 	}
 }
 
+// WithinSavepoint runs passed function within a savepoint of the transaction
+// the store runs in, so a failure only rolls back what the function did.
+func (s Store) WithinSavepoint(ctx context.Context, fn func() error) error {
+	return database.WithinSavepoint(ctx, s.log, s.db, fn)
+}
+
+// selectExpenses selects expenses along with the names of their tags.
+const selectExpenses = `
+	SELECT
+		e.*,
+		ARRAY(
+			SELECT
+				t.name
+			FROM
+				transaction_tags AS tt
+			JOIN
+				tags AS t USING (tag_id)
+			WHERE
+				tt.expense_id = e.expense_id
+			ORDER BY
+				t.name
+		) AS tags
+	FROM
+		expenses AS e`
+
+// whereTagged matches the expenses that have any of the :tags, or all of them
+// with :match_all. Every expense matches when there are no tags.
+const whereTagged = `
+	(
+		COALESCE(CARDINALITY(CAST(:tags AS TEXT[])), 0) = 0 OR
+		(
+			SELECT
+				COUNT(DISTINCT LOWER(t.name))
+			FROM
+				transaction_tags AS tt
+			JOIN
+				tags AS t USING (tag_id)
+			WHERE
+				tt.expense_id = e.expense_id AND
+				LOWER(t.name) = ANY(CAST(:tags AS TEXT[]))
+		) >= CASE WHEN :match_all THEN CARDINALITY(CAST(:tags AS TEXT[])) ELSE 1 END
+	)`
+
 // Create adds an Expense to the database. It returns the created Expense with
 // fields like ID and DateCreated populated.
 func (s Store) Create(ctx context.Context, exp Expense) error {
 	const q = `
 	INSERT INTO expenses
-		(expense_id, user_id, name, category, currency, amount, reocurrence, duration, reocurrence_type, duration_type, date_created, date_updated)
+		(expense_id, user_id, name, category_id, currency, amount, reoccurrence, duration, reoccurrence_type, duration_type, account_id, external_id, date_created, date_updated, version)
 	VALUES
-		(:expense_id, :user_id, :name, :category, :currency, :amount, :reocurrence, :duration, :reocurrence_type, :duration_type, :date_created, :date_updated)`
+		(:expense_id, :user_id, :name, :category_id, :currency, :amount, :reoccurrence, :duration, :reoccurrence_type, :duration_type, :account_id, :external_id, :date_created, :date_updated, :version)`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, exp); err != nil {
 		return fmt.Errorf("inserting expense: %w", err)
@@ -61,45 +108,56 @@ func (s Store) Create(ctx context.Context, exp Expense) error {
 	return nil
 }
 
-// Update modifies data about a Expense. It will error if the specified ID is
-// invalid or does not reference an existing Expense.
+// Update modifies data about a Expense that is still at the version it was
+// read at, and moves it to the next version. It returns ErrDBNotFound when
+// the Expense is gone or was changed in the meantime.
 func (s Store) Update(ctx context.Context, exp Expense) error {
 	const q = `
 	UPDATE
 		expenses
 	SET
 		"name" = :name,
-		"category" = :category,
+		"category_id" = :category_id,
 		"currency" = :currency,
 		"amount" = :amount,
-		"reocurrence" = :reocurrence,
+		"reoccurrence" = :reoccurrence,
 		"duration" = :duration,
-		"reocurrence_type" = :reocurrence_type,
+		"reoccurrence_type" = :reoccurrence_type,
 		"duration_type" = :duration_type,
-		"date_updated" = :date_updated
+		"account_id" = :account_id,
+		"date_updated" = :date_updated,
+		"version" = version + 1
 	WHERE
-		expense_id = :expense_id`
+		expense_id = :expense_id AND
+		version = :version AND
+		deleted_at IS NULL`
 
-	if err := database.NamedExecContext(ctx, s.log, s.db, q, exp); err != nil {
+	if err := database.NamedExecRowContext(ctx, s.log, s.db, q, exp); err != nil {
 		return fmt.Errorf("updating expense expenseID[%s]: %w", exp.ID, err)
 	}
 
 	return nil
 }
 
-// Delete removes the expense identified by a given ID.
-func (s Store) Delete(ctx context.Context, expenseID string) error {
+// Delete moves the expense identified by a given ID to the trash. It stays
+// there until it is restored or purged.
+func (s Store) Delete(ctx context.Context, expenseID string, now time.Time) error {
 	data := struct {
-		ExpenseID string `db:"expense_id"`
+		ExpenseID string    `db:"expense_id"`
+		DeletedAt time.Time `db:"deleted_at"`
 	}{
 		ExpenseID: expenseID,
+		DeletedAt: now,
 	}
 
 	const q = `
-	DELETE FROM
+	UPDATE
 		expenses
+	SET
+		"deleted_at" = :deleted_at
 	WHERE
-		expense_id = :expense_id`
+		expense_id = :expense_id AND
+		deleted_at IS NULL`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
 		return fmt.Errorf("deleting expense expenseID[%s]: %w", expenseID, err)
@@ -108,23 +166,96 @@ func (s Store) Delete(ctx context.Context, expenseID string) error {
 	return nil
 }
 
-// Query gets all Expenses from the database.
-func (s Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Expense, error) {
+// Restore takes the expense identified by a given ID out of the trash.
+func (s Store) Restore(ctx context.Context, expenseID string) error {
 	data := struct {
-		Offset      int `db:"offset"`
-		RowsPerPage int `db:"rows_per_page"`
+		ExpenseID string `db:"expense_id"`
 	}{
-		Offset:      (pageNumber - 1) * rowsPerPage,
-		RowsPerPage: rowsPerPage,
+		ExpenseID: expenseID,
 	}
 
 	const q = `
-	SELECT
-		*
-	FROM
+	UPDATE
 		expenses
+	SET
+		"deleted_at" = NULL
+	WHERE
+		expense_id = :expense_id AND
+		deleted_at IS NOT NULL`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("restoring expense expenseID[%s]: %w", expenseID, err)
+	}
+
+	return nil
+}
+
+// PurgePostings removes the ledger postings of the expenses that were moved to
+// the trash before the given time. The postings have no foreign key on their
+// source, so they have to go before the expenses do.
+func (s Store) PurgePostings(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
+		transactions
+	WHERE
+		source_type = 'expense' AND
+		source_id IN (SELECT expense_id FROM expenses WHERE deleted_at < :before)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("purging expense postings: %w", err)
+	}
+
+	return nil
+}
+
+// Purge removes for good the expenses that were moved to the trash before the
+// given time.
+func (s Store) Purge(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
+		expenses
+	WHERE
+		deleted_at < :before`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("purging expenses: %w", err)
+	}
+
+	return nil
+}
+
+// Query gets the Expenses from the database that pass the filter, in the
+// given order.
+func (s Store) Query(ctx context.Context, filter Filter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Expense, error) {
+	data := map[string]any{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	wc := applyFilter(filter, data)
+
+	orderByClause, err := order.Clause(orderBy, orderByFields, "e.expense_id")
+	if err != nil {
+		return nil, fmt.Errorf("ordering: %w", err)
+	}
+
+	q := selectExpenses + `
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t") + `
 	ORDER BY
-		date_created
+		` + orderByClause + `
 	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
 
 	var exps []Expense
@@ -135,6 +266,76 @@ func (s Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Ex
 	return exps, nil
 }
 
+// QueryAfter gets up to limit Expenses from the database that pass the filter,
+// in the given order, starting right after the cursor. A nil cursor starts
+// from the first Expense. The returned cursor points at the last Expense and is
+// nil when there are no more of them.
+func (s Store) QueryAfter(ctx context.Context, filter Filter, orderBy order.By, after *cursor.Cursor, limit int) ([]Expense, *cursor.Cursor, error) {
+	data := map[string]any{
+		"limit": limit + 1,
+	}
+
+	wc := applyFilter(filter, data)
+
+	orderByClause, err := order.Clause(orderBy, orderByFields, "e.expense_id")
+	if err != nil {
+		return nil, nil, fmt.Errorf("ordering: %w", err)
+	}
+
+	if after != nil {
+		data["cursor_value"] = after.Value
+		data["cursor_id"] = after.ID
+		wc = append(wc, after.Clause(orderByFields[orderBy.Field], "e.expense_id"))
+	}
+
+	q := selectExpenses + `
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t") + `
+	ORDER BY
+		` + orderByClause + `
+	FETCH FIRST :limit ROWS ONLY`
+
+	var exps []Expense
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &exps); err != nil {
+		return nil, nil, fmt.Errorf("selecting expenses: %w", err)
+	}
+
+	// One extra expense was asked for to know if there is another page.
+	if len(exps) <= limit {
+		return exps, nil, nil
+	}
+
+	exps = exps[:limit]
+	last := exps[limit-1]
+	next := cursor.New(orderBy, sortValue(last, orderBy.Field), last.ID)
+
+	return exps, &next, nil
+}
+
+// Count returns the number of Expenses in the database that pass the filter.
+func (s Store) Count(ctx context.Context, filter Filter) (int, error) {
+	data := map[string]any{}
+
+	wc := applyFilter(filter, data)
+
+	q := `
+	SELECT
+		COUNT(*) AS count
+	FROM
+		expenses AS e
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t")
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("counting expenses: %w", err)
+	}
+
+	return count.Count, nil
+}
+
 // QueryByID finds the expense identified by a given ID.
 func (s Store) QueryByID(ctx context.Context, expenseID string) (Expense, error) {
 	data := struct {
@@ -143,13 +344,10 @@ func (s Store) QueryByID(ctx context.Context, expenseID string) (Expense, error)
 		ExpenseID: expenseID,
 	}
 
-	const q = `
-	SELECT
-		*
-	FROM
-		expenses
+	const q = selectExpenses + `
 	WHERE
-		expense_id = :expense_id`
+		e.expense_id = :expense_id AND
+		e.deleted_at IS NULL`
 
 	var exp Expense
 	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &exp); err != nil {
@@ -159,25 +357,134 @@ func (s Store) QueryByID(ctx context.Context, expenseID string) (Expense, error)
 	return exp, nil
 }
 
-// QueryByUserID finds the expense identified by a given User ID.
-func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Expense, error) {
+// QueryByExternalID finds the expense of a user identified by the ID it is
+// known by at the bank it was imported from.
+func (s Store) QueryByExternalID(ctx context.Context, userID string, externalID string) (Expense, error) {
+	data := struct {
+		UserID     string `db:"user_id"`
+		ExternalID string `db:"external_id"`
+	}{
+		UserID:     userID,
+		ExternalID: externalID,
+	}
+
+	const q = selectExpenses + `
+	WHERE
+		e.user_id = :user_id AND
+		e.external_id = :external_id AND
+		e.deleted_at IS NULL`
+
+	var exp Expense
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &exp); err != nil {
+		return Expense{}, fmt.Errorf("selecting expense externalID[%q]: %w", externalID, err)
+	}
+
+	return exp, nil
+}
+
+// QueryByAmount finds the expenses of a user of exactly the amount of the
+// currency added between the given times.
+func (s Store) QueryByAmount(ctx context.Context, userID string, currency string, amount int64, from time.Time, to time.Time) ([]Expense, error) {
+	data := struct {
+		UserID   string    `db:"user_id"`
+		Currency string    `db:"currency"`
+		Amount   int64     `db:"amount"`
+		From     time.Time `db:"from"`
+		To       time.Time `db:"to"`
+	}{
+		UserID:   userID,
+		Currency: currency,
+		Amount:   amount,
+		From:     from,
+		To:       to,
+	}
+
+	const q = selectExpenses + `
+	WHERE
+		e.user_id = :user_id AND
+		e.currency = :currency AND
+		e.amount = :amount AND
+		e.date_created BETWEEN :from AND :to AND
+		e.deleted_at IS NULL
+	ORDER BY
+		e.date_created, e.expense_id`
+
+	var exps []Expense
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &exps); err != nil {
+		return nil, fmt.Errorf("selecting expenses by amount userID[%s]: %w", userID, err)
+	}
+
+	return exps, nil
+}
+
+// QueryByUserID finds the expenses of a given User ID that pass the filter,
+// in the given order.
+func (s Store) QueryByUserID(ctx context.Context, userID string, filter Filter, orderBy order.By) ([]Expense, error) {
+	data := map[string]any{
+		"user_id": userID,
+	}
+
+	wc := append([]string{"e.user_id = :user_id"}, applyFilter(filter, data)...)
+
+	orderByClause, err := order.Clause(orderBy, orderByFields, "e.expense_id")
+	if err != nil {
+		return nil, fmt.Errorf("ordering: %w", err)
+	}
+
+	q := selectExpenses + `
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t") + `
+	ORDER BY
+		` + orderByClause
+
+	var exps []Expense
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &exps); err != nil {
+		return nil, fmt.Errorf("selecting expenses userID[%s]: %w", userID, err)
+	}
+
+	return exps, nil
+}
+
+// QueryDeletedByID finds the expense in the trash identified by a given ID.
+func (s Store) QueryDeletedByID(ctx context.Context, expenseID string) (Expense, error) {
+	data := struct {
+		ExpenseID string `db:"expense_id"`
+	}{
+		ExpenseID: expenseID,
+	}
+
+	const q = selectExpenses + `
+	WHERE
+		e.expense_id = :expense_id AND
+		e.deleted_at IS NOT NULL`
+
+	var exp Expense
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &exp); err != nil {
+		return Expense{}, fmt.Errorf("selecting deleted expense expenseID[%q]: %w", expenseID, err)
+	}
+
+	return exp, nil
+}
+
+// QueryDeleted finds the expenses of a given User ID that are in the trash, the
+// most recently deleted first.
+func (s Store) QueryDeleted(ctx context.Context, userID string) ([]Expense, error) {
 	data := struct {
 		UserID string `db:"user_id"`
 	}{
 		UserID: userID,
 	}
 
-	const q = `
-	SELECT
-		*
-	FROM
-		expenses
+	const q = selectExpenses + `
 	WHERE
-		user_id = :user_id`
+		e.user_id = :user_id AND
+		e.deleted_at IS NOT NULL
+	ORDER BY
+		e.deleted_at DESC`
 
 	var exps []Expense
 	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &exps); err != nil {
-		return nil, fmt.Errorf("selecting expenses userID[%s]: %w", userID, err)
+		return nil, fmt.Errorf("selecting deleted expenses userID[%s]: %w", userID, err)
 	}
 
 	return exps, nil
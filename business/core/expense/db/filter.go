@@ -0,0 +1,109 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Filter narrows down the expenses selected by Query and QueryByUserID. The
+// fields left nil don't filter anything.
+type Filter struct {
+	Tags             []string
+	MatchAll         bool
+	CategoryID       *string
+	Currency         *string
+	MinAmount        *int64
+	MaxAmount        *int64
+	From             *time.Time
+	To               *time.Time
+	ReoccurrenceType *string
+	Name             *string
+}
+
+// orderByFields is the whitelist of the fields expenses can be ordered by
+// along with the column behind each of them.
+var orderByFields = map[string]string{
+	"date_created":      "e.date_created",
+	"name":              "e.name",
+	"amount":            "e.amount",
+	"currency":          "e.currency",
+	"category_id":       "e.category_id",
+	"reoccurrence_type": "e.reoccurrence_type",
+}
+
+// sortValue returns the value of the field a expense is ordered by, the way
+// it is kept in a cursor.
+func sortValue(exp Expense, field string) string {
+	switch field {
+	case "name":
+		return exp.Name
+	case "amount":
+		return strconv.FormatInt(exp.Amount, 10)
+	case "currency":
+		return exp.Currency
+	case "category_id":
+		return exp.CategoryID
+	case "reoccurrence_type":
+		return exp.ReoccurrenceType
+	default:
+		return exp.DateCreated.Format(time.RFC3339Nano)
+	}
+}
+
+// applyFilter returns the conditions of a WHERE clause selecting the expenses
+// that pass the filter, adding the values they need to data. Only named
+// parameters end up in the conditions, never the values themselves.
+func applyFilter(filter Filter, data map[string]any) []string {
+	data["tags"] = pq.StringArray(filter.Tags)
+	data["match_all"] = filter.MatchAll
+	wc := []string{"e.deleted_at IS NULL", whereTagged}
+
+	if filter.CategoryID != nil {
+		data["category_id"] = *filter.CategoryID
+		wc = append(wc, "e.category_id = :category_id")
+	}
+
+	if filter.Currency != nil {
+		data["currency"] = *filter.Currency
+		wc = append(wc, "e.currency = :currency")
+	}
+
+	if filter.MinAmount != nil {
+		data["min_amount"] = *filter.MinAmount
+		wc = append(wc, "e.amount >= :min_amount")
+	}
+
+	if filter.MaxAmount != nil {
+		data["max_amount"] = *filter.MaxAmount
+		wc = append(wc, "e.amount <= :max_amount")
+	}
+
+	if filter.From != nil {
+		data["from"] = *filter.From
+		wc = append(wc, "e.date_created >= :from")
+	}
+
+	if filter.To != nil {
+		data["to"] = *filter.To
+		wc = append(wc, "e.date_created < :to")
+	}
+
+	if filter.ReoccurrenceType != nil {
+		data["reoccurrence_type"] = *filter.ReoccurrenceType
+		wc = append(wc, "e.reoccurrence_type = :reoccurrence_type")
+	}
+
+	if filter.Name != nil {
+		data["name"] = "%" + likeEscaper.Replace(*filter.Name) + "%"
+		wc = append(wc, "e.name ILIKE :name")
+	}
+
+	return wc
+}
+
+// likeEscaper escapes the characters with a meaning in a LIKE pattern so a
+// name search matches them literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
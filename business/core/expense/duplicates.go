@@ -0,0 +1,43 @@
+package expense
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/core/expense/db"
+	"github.com/gloompi/ultimate-service/business/sys/dedupe"
+)
+
+// suspectDuplicates returns the IDs of the expenses of the user the expense
+// about to be added looks like a duplicate of.
+func (c Core) suspectDuplicates(ctx context.Context, dbExp db.Expense) ([]string, error) {
+	from := dbExp.DateCreated.Add(-dedupe.Window)
+	to := dbExp.DateCreated.Add(dedupe.Window)
+
+	dbExps, err := c.store.QueryByAmount(ctx, dbExp.UserID, dbExp.Currency, dbExp.Amount, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	rec := toRecord(toExpense(dbExp))
+
+	var ids []string
+	for _, dbCand := range dbExps {
+		if _, ok := dedupe.Match(rec, toRecord(toExpense(dbCand))); ok {
+			ids = append(ids, dbCand.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+// toRecord returns what is compared of an expense to spot duplicates. The
+// date it was added is the date the money moved for the ones imported.
+func toRecord(exp Expense) dedupe.Record {
+	return dedupe.Record{
+		ID:     exp.ID,
+		Name:   exp.Name,
+		Amount: exp.Amount,
+		Date:   exp.DateCreated,
+	}
+}
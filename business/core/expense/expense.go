@@ -3,12 +3,24 @@ package expense
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/audit"
+	"github.com/gloompi/ultimate-service/business/core/category"
 	"github.com/gloompi/ultimate-service/business/core/expense/db"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/rule"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
 	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/order"
 	"github.com/gloompi/ultimate-service/business/sys/validate"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
@@ -16,54 +28,157 @@ import (
 
 // Set of error variables for CRUD operations.
 var (
-	ErrNotFound  = errors.New("expense not found")
-	ErrInvalidID = errors.New("ID is not in its proper form")
+	ErrNotFound        = errors.New("expense not found")
+	ErrInvalidID       = errors.New("ID is not in its proper form")
+	ErrInvalidAmount   = errors.New("amount can't be negative")
+	ErrDuplicate       = errors.New("expense with the same external ID already exists")
+	ErrVersionConflict = errors.New("expense was changed by someone else")
 )
 
 // Core manages the set of APIs for expense access.
 type Core struct {
-	store db.Store
+	store    db.Store
+	user     user.Core
+	fx       fx.Core
+	account  account.Core
+	category category.Core
+	tag      tag.Core
+	audit    audit.Core
+	rule     rule.Core
 }
 
 // NewCore constructs a core for expense api access.
 func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
 	return Core{
-		store: db.NewStore(log, sqlxDB),
+		store:    db.NewStore(log, sqlxDB),
+		user:     user.NewCore(log, sqlxDB),
+		fx:       fx.NewCore(log, sqlxDB),
+		account:  account.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+		tag:      tag.NewCore(log, sqlxDB),
+		audit:    audit.NewCore(log, sqlxDB),
+		rule:     rule.NewCore(log, sqlxDB),
+	}
+}
+
+// Tran returns a new Core running every write within the transaction, so
+// several expenses can be changed all at once.
+func (c Core) Tran(tx sqlx.ExtContext) Core {
+	return Core{
+		store:    c.store.Tran(tx),
+		user:     c.user,
+		fx:       c.fx,
+		account:  c.account,
+		category: c.category,
+		tag:      c.tag.Tran(tx),
+		audit:    c.audit.Tran(tx),
+		rule:     c.rule,
 	}
 }
 
 // Create adds an Expense to the database. It returns the created Expense with
 // fields like ID and DateCreated populated.
+// The rules of the user run over it before, see rule.Evaluate. The expenses
+// it looks like a duplicate of are listed with it, but it is added anyway.
 func (c Core) Create(ctx context.Context, ne NewExpense, now time.Time) (Expense, error) {
 	if err := validate.Check(ne); err != nil {
 		return Expense{}, fmt.Errorf("validating data: %w", err)
 	}
 
+	if ne.Amount.IsNegative() {
+		return Expense{}, ErrInvalidAmount
+	}
+
+	// The rules of the user get to pick the category and add tags first.
+	out, err := c.rule.Apply(ctx, ne.UserID, rule.Subject{
+		Kind:       rule.KindExpense,
+		Name:       ne.Name,
+		Amount:     ne.Amount,
+		AccountID:  ne.AccountID,
+		CategoryID: ne.CategoryID,
+		Tags:       ne.Tags,
+	})
+	if err != nil {
+		return Expense{}, fmt.Errorf("rules: %w", err)
+	}
+	ne.CategoryID, ne.Tags = out.CategoryID, out.Tags
+
+	if err := c.category.Check(ctx, ne.CategoryID, ne.UserID, category.KindExpense); err != nil {
+		return Expense{}, fmt.Errorf("category: %w", err)
+	}
+
+	if ne.AccountID != "" {
+		if err := c.account.Check(ctx, ne.AccountID, ne.UserID, ne.Amount.Currency); err != nil {
+			return Expense{}, fmt.Errorf("account: %w", err)
+		}
+	}
+
+	// Records added after the fact, like imported ones, start on the day they
+	// happened so the ledger posts them then.
+	start := now
+	if !ne.Date.IsZero() {
+		start = ne.Date
+	}
+
 	dbExp := db.Expense{
 		ID:               validate.GenerateID(),
 		Name:             ne.Name,
-		Category:         ne.Category,
-		Currency:         ne.Currency,
-		Amount:           ne.Amount,
+		CategoryID:       ne.CategoryID,
+		Currency:         ne.Amount.Currency,
+		Amount:           ne.Amount.Amount,
 		Reoccurrence:     ne.Reoccurrence,
 		Duration:         ne.Duration,
 		ReoccurrenceType: ne.ReoccurrenceType,
 		DurationType:     ne.DurationType,
 		UserID:           ne.UserID,
-		DateCreated:      now,
+		AccountID:        sql.NullString{String: ne.AccountID, Valid: ne.AccountID != ""},
+		ExternalID:       sql.NullString{String: ne.ExternalID, Valid: ne.ExternalID != ""},
+		DateCreated:      start,
 		DateUpdated:      now,
+		Version:          1,
 	}
 
-	if err := c.store.Create(ctx, dbExp); err != nil {
-		return Expense{}, fmt.Errorf("create: %w", err)
+	dups, err := c.suspectDuplicates(ctx, dbExp)
+	if err != nil {
+		return Expense{}, fmt.Errorf("duplicates: %w", err)
 	}
 
-	return toExpense(dbExp), nil
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Create(ctx, dbExp); err != nil {
+			if errors.Is(err, database.ErrDBDuplicatedEntry) {
+				return fmt.Errorf("create: %w", ErrDuplicate)
+			}
+			return fmt.Errorf("create: %w", err)
+		}
+
+		tags, err := c.tag.Tran(tx).Set(ctx, dbExp.UserID, transaction.SourceExpense, dbExp.ID, ne.Tags, now)
+		if err != nil {
+			return fmt.Errorf("tags: %w", err)
+		}
+		dbExp.Tags = tags
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityExpense, dbExp.ID, audit.ActionCreate, nil, toExpense(dbExp), now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return Expense{}, fmt.Errorf("tran: %w", err)
+	}
+
+	exp := toExpense(dbExp)
+	exp.Duplicates = dups
+
+	return exp, nil
 }
 
 // Update modifies data about a Expense. It will error if the specified ID is
-// invalid or does not reference an existing Expense.
-func (c Core) Update(ctx context.Context, expenseID string, ue UpdateExpense, now time.Time) error {
+// invalid or does not reference an existing Expense. When version is not zero the
+// Expense must still be at that version, and it must not change while it is
+// being updated in any case, or ErrVersionConflict is returned.
+func (c Core) Update(ctx context.Context, expenseID string, ue UpdateExpense, version int64, now time.Time) error {
 	if err := validate.CheckID(expenseID); err != nil {
 		return ErrInvalidID
 	}
@@ -79,18 +194,26 @@ func (c Core) Update(ctx context.Context, expenseID string, ue UpdateExpense, no
 		}
 		return fmt.Errorf("updating expense expenseID[%s]: %w", expenseID, err)
 	}
+	if version != 0 && dbExp.Version != version {
+		return ErrVersionConflict
+	}
+	before := toExpense(dbExp)
 
 	if ue.Name != nil {
 		dbExp.Name = *ue.Name
 	}
-	if ue.Category != nil {
-		dbExp.Category = *ue.Category
-	}
-	if ue.Currency != nil {
-		dbExp.Currency = *ue.Currency
+	if ue.CategoryID != nil {
+		if err := c.category.Check(ctx, *ue.CategoryID, dbExp.UserID, category.KindExpense); err != nil {
+			return fmt.Errorf("category: %w", err)
+		}
+		dbExp.CategoryID = *ue.CategoryID
 	}
 	if ue.Amount != nil {
-		dbExp.Amount = *ue.Amount
+		if ue.Amount.IsNegative() {
+			return ErrInvalidAmount
+		}
+		dbExp.Currency = ue.Amount.Currency
+		dbExp.Amount = ue.Amount.Amount
 	}
 	if ue.Reoccurrence != nil {
 		dbExp.Reoccurrence = *ue.Reoccurrence
@@ -104,31 +227,140 @@ func (c Core) Update(ctx context.Context, expenseID string, ue UpdateExpense, no
 	if ue.DurationType != nil {
 		dbExp.DurationType = *ue.DurationType
 	}
+	if ue.AccountID != nil {
+		dbExp.AccountID = sql.NullString{String: *ue.AccountID, Valid: *ue.AccountID != ""}
+	}
 	dbExp.DateUpdated = now
 
-	if err := c.store.Update(ctx, dbExp); err != nil {
-		return fmt.Errorf("update: %w", err)
+	// The account must still be able to hold the expense when either side changed.
+	if dbExp.AccountID.Valid && (ue.AccountID != nil || ue.Amount != nil) {
+		if err := c.account.Check(ctx, dbExp.AccountID.String, dbExp.UserID, dbExp.Currency); err != nil {
+			return fmt.Errorf("account: %w", err)
+		}
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Update(ctx, dbExp); err != nil {
+			if errors.Is(err, database.ErrDBNotFound) {
+				return ErrVersionConflict
+			}
+			return fmt.Errorf("update: %w", err)
+		}
+		dbExp.Version++
+
+		if ue.Tags != nil {
+			tags, err := c.tag.Tran(tx).Set(ctx, dbExp.UserID, transaction.SourceExpense, dbExp.ID, *ue.Tags, now)
+			if err != nil {
+				return fmt.Errorf("tags: %w", err)
+			}
+			dbExp.Tags = tags
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityExpense, dbExp.ID, audit.ActionUpdate, before, toExpense(dbExp), now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes the expense identified by a given ID.
-func (c Core) Delete(ctx context.Context, expenseID string) error {
-	if err := validate.CheckID(expenseID); err != nil {
-		return ErrInvalidID
+// Delete moves the expense identified by a given ID to the trash. It stays
+// there until it is restored or purged.
+func (c Core) Delete(ctx context.Context, expenseID string, now time.Time) error {
+	before, err := c.QueryByID(ctx, expenseID)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.DeletedAt = &now
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Delete(ctx, expenseID, now); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityExpense, expenseID, audit.ActionDelete, before, after, now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// Restore takes the expense identified by a given ID out of the trash.
+func (c Core) Restore(ctx context.Context, expenseID string, now time.Time) error {
+	before, err := c.QueryDeletedByID(ctx, expenseID)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.DeletedAt = nil
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Restore(ctx, expenseID); err != nil {
+			return fmt.Errorf("restore: %w", err)
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityExpense, expenseID, audit.ActionRestore, before, after, now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// Purge removes for good the expenses that were moved to the trash before the
+// given time, along with their postings in the ledger.
+func (c Core) Purge(ctx context.Context, before time.Time) error {
+	tran := func(tx sqlx.ExtContext) error {
+		store := c.store.Tran(tx)
+
+		if err := store.PurgePostings(ctx, before); err != nil {
+			return fmt.Errorf("postings: %w", err)
+		}
+
+		if err := store.Purge(ctx, before); err != nil {
+			return fmt.Errorf("purge: %w", err)
+		}
+
+		return nil
 	}
 
-	if err := c.store.Delete(ctx, expenseID); err != nil {
-		return fmt.Errorf("delete: %w", err)
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
 	}
 
 	return nil
 }
 
-// Query gets all Expense from the database.
-func (c Core) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Expense, error) {
-	dbExps, err := c.store.Query(ctx, pageNumber, rowsPerPage)
+// Query gets the Expenses from the database that pass the filter, in the
+// given order.
+func (c Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Expense, error) {
+	if err := validate.Check(filter); err != nil {
+		return nil, fmt.Errorf("validating filter: %w", err)
+	}
+
+	dbExps, err := c.store.Query(ctx, toDBFilter(filter), orderBy, pageNumber, rowsPerPage)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
@@ -136,6 +368,40 @@ func (c Core) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Exp
 	return toExpenseSlice(dbExps), nil
 }
 
+// QueryPage gets a page of the Expenses that pass the filter, in the given
+// order, starting right after the cursor token. An empty token starts from
+// the first Expense.
+func (c Core) QueryPage(ctx context.Context, filter QueryFilter, orderBy order.By, token string, limit int) (cursor.Page[Expense], error) {
+	if err := validate.Check(filter); err != nil {
+		return cursor.Page[Expense]{}, fmt.Errorf("validating filter: %w", err)
+	}
+
+	after, err := cursor.Decode(token, orderBy)
+	if err != nil {
+		return cursor.Page[Expense]{}, err
+	}
+
+	dbExps, next, err := c.store.QueryAfter(ctx, toDBFilter(filter), orderBy, after, limit)
+	if err != nil {
+		return cursor.Page[Expense]{}, fmt.Errorf("query: %w", err)
+	}
+
+	total, err := c.store.Count(ctx, toDBFilter(filter))
+	if err != nil {
+		return cursor.Page[Expense]{}, fmt.Errorf("count: %w", err)
+	}
+
+	page := cursor.Page[Expense]{
+		Items: toExpenseSlice(dbExps),
+		Total: total,
+	}
+	if next != nil {
+		page.NextCursor = next.Encode()
+	}
+
+	return page, nil
+}
+
 // QueryByID finds the expense identified by a given ID.
 func (c Core) QueryByID(ctx context.Context, expenseID string) (Expense, error) {
 	if err := validate.CheckID(expenseID); err != nil {
@@ -153,16 +419,119 @@ func (c Core) QueryByID(ctx context.Context, expenseID string) (Expense, error)
 	return toExpense(dbExp), nil
 }
 
-// QueryByUserID finds the expenses identified by a given User ID.
-func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Expense, error) {
+// QueryByExternalID finds the expense of a user identified by the ID it is
+// known by at the bank it was imported from.
+func (c Core) QueryByExternalID(ctx context.Context, userID string, externalID string) (Expense, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Expense{}, ErrInvalidID
+	}
+
+	dbExp, err := c.store.QueryByExternalID(ctx, userID, externalID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Expense{}, ErrNotFound
+		}
+		return Expense{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toExpense(dbExp), nil
+}
+
+// QueryByUserID finds the expenses identified by a given User ID that pass
+// the filter, in the given order, along with their totals per currency. The
+// total is converted into the base currency of the user, using the exchange
+// rate of the date each expense was added.
+func (c Core) QueryByUserID(ctx context.Context, userID string, filter QueryFilter, orderBy order.By) (ExpensesByUser, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return ExpensesByUser{}, ErrInvalidID
+	}
+
+	if err := validate.Check(filter); err != nil {
+		return ExpensesByUser{}, fmt.Errorf("validating filter: %w", err)
+	}
+
+	usr, err := c.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return ExpensesByUser{}, ErrNotFound
+		}
+		return ExpensesByUser{}, fmt.Errorf("query user: %w", err)
+	}
+
+	dbExps, err := c.store.QueryByUserID(ctx, userID, toDBFilter(filter), orderBy)
+	if err != nil {
+		return ExpensesByUser{}, fmt.Errorf("query: %w", err)
+	}
+
+	exps := ExpensesByUser{
+		Expenses: toExpenseSlice(dbExps),
+		Total:    money.Zero(usr.BaseCurrency),
+	}
+
+	amounts := make([]money.Money, len(exps.Expenses))
+	cv := c.fx.NewConverter()
+	for i, exp := range exps.Expenses {
+		amounts[i] = exp.Amount
+
+		amount, err := cv.Convert(ctx, exp.Amount, usr.BaseCurrency, exp.DateCreated)
+		if err != nil {
+			return ExpensesByUser{}, fmt.Errorf("converting expenseID[%s]: %w", exp.ID, err)
+		}
+
+		if exps.Total, err = exps.Total.Add(amount); err != nil {
+			return ExpensesByUser{}, fmt.Errorf("adding expenseID[%s]: %w", exp.ID, err)
+		}
+	}
+
+	if exps.Totals, err = money.Totals(amounts); err != nil {
+		return ExpensesByUser{}, fmt.Errorf("totals: %w", err)
+	}
+
+	return exps, nil
+}
+
+// QueryAllByUserID finds every expense of a user. Unlike QueryByUserID there
+// is no total, so it never depends on the exchange rates being known.
+func (c Core) QueryAllByUserID(ctx context.Context, userID string) ([]Expense, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbExps, err := c.store.QueryByUserID(ctx, userID, db.Filter{}, DefaultOrderBy)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toExpenseSlice(dbExps), nil
+}
+
+// QueryDeletedByID finds the expense in the trash identified by a given ID.
+func (c Core) QueryDeletedByID(ctx context.Context, expenseID string) (Expense, error) {
+	if err := validate.CheckID(expenseID); err != nil {
+		return Expense{}, ErrInvalidID
+	}
+
+	dbExp, err := c.store.QueryDeletedByID(ctx, expenseID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Expense{}, ErrNotFound
+		}
+		return Expense{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toExpense(dbExp), nil
+}
+
+// QueryDeleted finds the expenses of a user that are in the trash.
+func (c Core) QueryDeleted(ctx context.Context, userID string) ([]Expense, error) {
 	if err := validate.CheckID(userID); err != nil {
 		return nil, ErrInvalidID
 	}
 
-	dbInc, err := c.store.QueryByUserID(ctx, userID)
+	dbExps, err := c.store.QueryDeleted(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
 
-	return toExpenseSlice(dbInc), nil
+	return toExpenseSlice(dbExps), nil
 }
@@ -1,39 +1,49 @@
 package expense
 
 import (
+	"database/sql"
 	"time"
-	"unsafe"
 
 	"github.com/gloompi/ultimate-service/business/core/expense/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
 )
 
 // Expense represents an individual expense.
 type Expense struct {
-	ID               string    `db:"id"`                // Unique identifier.
-	Name             string    `db:"name"`              // Display name of the expense.
-	Category         string    `db:"category"`          // Category of the expense.
-	Currency         string    `db:"currency"`          // Currency of the expense.
-	Amount           int       `db:"amount"`            // Amount of money of the expense.
-	Reoccurrence     int       `db:"reoccurrence"`      // Execute transaction each day, week, month.
-	Duration         int       `db:"duration"`          // Range of time transaction needs to be happening.
-	ReoccurrenceType string    `db:"reoccurrence_type"` // Type of reoccurrence (Monthly, Daily, Once).
-	DurationType     string    `db:"duration_type"`     // Type of duration (Months, Days).
-	UserID           string    `db:"user_id"`           // ID of the user who created the expense.
-	DateCreated      time.Time `db:"date_created"`      // When the expense was added.
-	DateUpdated      time.Time `db:"date_updated"`      // When the expense record was last modified.
+	ID               string      `json:"id"`                    // Unique identifier.
+	Name             string      `json:"name"`                  // Display name of the expense.
+	CategoryID       string      `json:"category_id"`           // ID of the category of the expense.
+	Amount           money.Money `json:"amount"`                // Amount of money of the expense.
+	Reoccurrence     int         `json:"reoccurrence"`          // Execute transaction each day, week, month.
+	Duration         int         `json:"duration"`              // Range of time transaction needs to be happening.
+	ReoccurrenceType string      `json:"reoccurrence_type"`     // Type of reoccurrence (Monthly, Daily, Once).
+	DurationType     string      `json:"duration_type"`         // Type of duration (Months, Days).
+	UserID           string      `json:"user_id"`               // ID of the user who created the expense.
+	AccountID        string      `json:"account_id,omitempty"`  // ID of the account the money moves in or out of.
+	ExternalID       string      `json:"external_id,omitempty"` // ID the expense is known by at the bank it was imported from.
+	Tags             []string    `json:"tags"`                  // Names of the tags of the expense.
+	DateCreated      time.Time   `json:"date_created"`          // When the expense was added.
+	DateUpdated      time.Time   `json:"date_updated"`          // When the expense record was last modified.
+	DeletedAt        *time.Time  `json:"deleted_at,omitempty"`  // When the expense was moved to the trash.
+	Version          int64       `json:"version"`               // Incremented by every update, the ETag of the expense.
+	Duplicates       []string    `json:"duplicates,omitempty"`  // IDs of the expenses this one may duplicate. Only filled on create.
 }
 
-// NewExpense is what we require from clients when adding a Expense.
+// NewExpense is what we require from clients when adding a Expense. The date is
+// when it first happens, when no date is provided it happens right away.
 type NewExpense struct {
-	Name             string `json:"name" validate:"required"`
-	Category         string `json:"category" validate:"required"`
-	Currency         string `json:"currency" validate:"required"`
-	Amount           int    `json:"amount" validate:"omitempty,gte=1"`
-	Reoccurrence     int    `json:"reoccurrence" validate:"omitempty,gte=1"`
-	Duration         int    `json:"duration" validate:"omitempty,gte=1"`
-	ReoccurrenceType string `json:"reoccurrence_type"`
-	DurationType     string `json:"duration_type"`
-	UserID           string `json:"user_id" validate:"required"`
+	Name             string      `json:"name" validate:"required"`
+	CategoryID       string      `json:"category_id" validate:"required"`
+	Amount           money.Money `json:"amount"`
+	Reoccurrence     int         `json:"reoccurrence" validate:"omitempty,gte=1"`
+	Duration         int         `json:"duration" validate:"omitempty,gte=1"`
+	ReoccurrenceType string      `json:"reoccurrence_type"`
+	DurationType     string      `json:"duration_type"`
+	UserID           string      `json:"user_id" validate:"required"`
+	AccountID        string      `json:"account_id"`
+	ExternalID       string      `json:"external_id" validate:"omitempty,max=255"`
+	Tags             []string    `json:"tags" validate:"dive,max=50"`
+	Date             time.Time   `json:"date"`
 }
 
 // UpdateExpense defines what information may be provided to modify an
@@ -43,21 +53,46 @@ type NewExpense struct {
 // explicitly blank. Normally we do not want to use pointers to basic types but
 // we make exceptions around marshalling/unmarshalling.
 type UpdateExpense struct {
-	Name             *string `json:"name"`
-	Category         *string `json:"category" validate:"required"`
-	Currency         *string `json:"currency" validate:"required"`
-	Amount           *int    `json:"amount" validate:"required"`
-	Reoccurrence     *int    `json:"reoccurrence"`
-	Duration         *int    `json:"duration"`
-	ReoccurrenceType *string `json:"reoccurrence_type"`
-	DurationType     *string `json:"duration_type"`
+	Name             *string      `json:"name"`
+	CategoryID       *string      `json:"category_id" validate:"required"`
+	Amount           *money.Money `json:"amount" validate:"required"`
+	Reoccurrence     *int         `json:"reoccurrence"`
+	Duration         *int         `json:"duration"`
+	ReoccurrenceType *string      `json:"reoccurrence_type"`
+	DurationType     *string      `json:"duration_type"`
+	AccountID        *string      `json:"account_id"`
+	Tags             *[]string    `json:"tags" validate:"omitempty,dive,max=50"`
+}
+
+// ExpensesByUser represents the expenses of a user along with their totals
+// per currency and their total converted into the base currency of the user.
+type ExpensesByUser struct {
+	Expenses []Expense     `json:"expenses"` // List of expenses.
+	Totals   []money.Money `json:"totals"`   // Total expense per currency.
+	Total    money.Money   `json:"total"`    // Total expense in the base currency of the user.
 }
 
 // =============================================================================
 
 func toExpense(dbExp db.Expense) Expense {
-	iu := (*Expense)(unsafe.Pointer(&dbExp))
-	return *iu
+	return Expense{
+		ID:               dbExp.ID,
+		Name:             dbExp.Name,
+		CategoryID:       dbExp.CategoryID,
+		Amount:           money.Money{Amount: dbExp.Amount, Currency: dbExp.Currency},
+		Reoccurrence:     dbExp.Reoccurrence,
+		Duration:         dbExp.Duration,
+		ReoccurrenceType: dbExp.ReoccurrenceType,
+		DurationType:     dbExp.DurationType,
+		UserID:           dbExp.UserID,
+		AccountID:        dbExp.AccountID.String,
+		ExternalID:       dbExp.ExternalID.String,
+		Tags:             dbExp.Tags,
+		DateCreated:      dbExp.DateCreated,
+		DateUpdated:      dbExp.DateUpdated,
+		DeletedAt:        deletedAt(dbExp.DeletedAt),
+		Version:          dbExp.Version,
+	}
 }
 
 func toExpenseSlice(dbExps []db.Expense) []Expense {
@@ -67,3 +102,12 @@ func toExpenseSlice(dbExps []db.Expense) []Expense {
 	}
 	return exps
 }
+
+// deletedAt returns when a expense was moved to the trash, or nil when it is
+// not in the trash.
+func deletedAt(nt sql.NullTime) *time.Time {
+	if !nt.Valid {
+		return nil
+	}
+	return &nt.Time
+}
@@ -0,0 +1,65 @@
+package expense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/rule"
+)
+
+// ApplyRules runs the rules of the user over every expense it has, the same
+// way they run over the ones added, and updates those that end up with a
+// different category or more tags. An expense changed meanwhile is left for
+// the next run. It returns how many expenses were updated.
+func (c Core) ApplyRules(ctx context.Context, userID string, now time.Time) (int, error) {
+	rls, err := c.rule.QueryByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("query rules: %w", err)
+	}
+	if len(rls) == 0 {
+		return 0, nil
+	}
+
+	exps, err := c.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("query expenses: %w", err)
+	}
+
+	var n int
+	for _, exp := range exps {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		out := rule.Evaluate(rls, rule.Subject{
+			Kind:       rule.KindExpense,
+			Name:       exp.Name,
+			Amount:     exp.Amount,
+			AccountID:  exp.AccountID,
+			CategoryID: exp.CategoryID,
+			Tags:       exp.Tags,
+		})
+		if !out.Changed {
+			continue
+		}
+
+		// The amount goes along since an update always carries it.
+		upd := UpdateExpense{
+			CategoryID: &out.CategoryID,
+			Amount:     &exp.Amount,
+			Tags:       &out.Tags,
+		}
+
+		if err := c.Update(ctx, exp.ID, upd, exp.Version, now); err != nil {
+			if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return n, fmt.Errorf("update expenseID[%s]: %w", exp.ID, err)
+		}
+		n++
+	}
+
+	return n, nil
+}
@@ -0,0 +1,152 @@
+// Package export provides a core business API for exporting every record of
+// a user into a zip archive, to hand it over on request or when they leave.
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of formats the files of an archive can be written in.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Set of error variables for export operations.
+var (
+	ErrNotFound      = errors.New("user not found")
+	ErrInvalidID     = errors.New("ID is not in its proper form")
+	ErrInvalidFormat = errors.New("format must be json or csv")
+)
+
+// Core manages the set of APIs for export access.
+type Core struct {
+	user     user.Core
+	income   income.Core
+	expense  expense.Core
+	category category.Core
+	tag      tag.Core
+	account  account.Core
+}
+
+// NewCore constructs a core for export api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		user:     user.NewCore(log, sqlxDB),
+		income:   income.NewCore(log, sqlxDB),
+		expense:  expense.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+		tag:      tag.NewCore(log, sqlxDB),
+		account:  account.NewCore(log, sqlxDB),
+	}
+}
+
+// QueryByUserID gathers every record of a user into an archive.
+func (c Core) QueryByUserID(ctx context.Context, userID string) (Archive, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Archive{}, ErrInvalidID
+	}
+
+	usr, err := c.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return Archive{}, ErrNotFound
+		}
+		return Archive{}, fmt.Errorf("query user: %w", err)
+	}
+
+	arc := Archive{
+		Profile: toProfile(usr),
+	}
+
+	if arc.Incomes, err = c.income.QueryAllByUserID(ctx, userID); err != nil {
+		return Archive{}, fmt.Errorf("query incomes: %w", err)
+	}
+
+	if arc.Expenses, err = c.expense.QueryAllByUserID(ctx, userID); err != nil {
+		return Archive{}, fmt.Errorf("query expenses: %w", err)
+	}
+
+	if arc.Accounts, err = c.account.QueryByUserID(ctx, userID); err != nil {
+		return Archive{}, fmt.Errorf("query accounts: %w", err)
+	}
+
+	if arc.Categories, err = c.category.QueryByUserID(ctx, userID); err != nil {
+		return Archive{}, fmt.Errorf("query categories: %w", err)
+	}
+
+	if arc.Tags, err = c.tag.QueryByUserID(ctx, userID); err != nil {
+		return Archive{}, fmt.Errorf("query tags: %w", err)
+	}
+
+	return arc, nil
+}
+
+// WriteZip writes the archive as a zip file with one file per kind of record,
+// either as JSON documents or as CSV tables.
+func (arc Archive) WriteZip(w io.Writer, format string, now time.Time) error {
+	if format != FormatJSON && format != FormatCSV {
+		return ErrInvalidFormat
+	}
+
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		val  any
+		rows func() [][]string
+	}{
+		{"profile", arc.Profile, arc.profileRows},
+		{"incomes", arc.Incomes, arc.incomeRows},
+		{"expenses", arc.Expenses, arc.expenseRows},
+		{"accounts", arc.Accounts, arc.accountRows},
+		{"categories", arc.Categories, arc.categoryRows},
+		{"tags", arc.Tags, arc.tagRows},
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name + "." + format,
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", file.name, err)
+		}
+
+		switch format {
+		case FormatJSON:
+			enc := json.NewEncoder(fw)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(file.val)
+		default:
+			err = csv.NewWriter(fw).WriteAll(file.rows())
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", file.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("closing zip: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/export"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_WriteZip(t *testing.T) {
+	now := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	arc := export.Archive{
+		Profile: export.Profile{
+			ID:           "45b5fbd3-755f-4379-8f07-a58d4a30fa2f",
+			Name:         "User Gopher",
+			Email:        "user@example.com",
+			Roles:        []string{"USER"},
+			BaseCurrency: "USD",
+			DateCreated:  now,
+			DateUpdated:  now,
+		},
+		Incomes: []income.Income{
+			{
+				ID:          "72f8b983-3eb4-48db-9ed0-e45cc6bd716b",
+				Name:        "Salary",
+				Amount:      money.Money{Amount: 250000, Currency: "USD"},
+				Tags:        []string{"job", "monthly"},
+				DateCreated: now,
+				DateUpdated: now,
+			},
+		},
+	}
+
+	t.Log("Given the need to export the records of a user.")
+	{
+		for testID, format := range []string{export.FormatJSON, export.FormatCSV} {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen writing the %s format.", testID, format)
+				{
+					var buf bytes.Buffer
+					if err := arc.WriteZip(&buf, format, now); err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to write the archive : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to write the archive.", success, testID)
+
+					zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to read the archive back : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to read the archive back.", success, testID)
+
+					files := make(map[string]string)
+					var names []string
+					for _, f := range zr.File {
+						rc, err := f.Open()
+						if err != nil {
+							t.Fatalf("\t%s\tTest %d:\tShould be able to open %s : %s.", failed, testID, f.Name, err)
+						}
+						data, err := io.ReadAll(rc)
+						rc.Close()
+						if err != nil {
+							t.Fatalf("\t%s\tTest %d:\tShould be able to read %s : %s.", failed, testID, f.Name, err)
+						}
+						files[f.Name] = string(data)
+						names = append(names, f.Name)
+					}
+					sort.Strings(names)
+
+					exp := []string{"accounts", "categories", "expenses", "incomes", "profile", "tags"}
+					for i := range exp {
+						exp[i] += "." + format
+					}
+					if diff := cmp.Diff(exp, names); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get back a file per kind of record. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get back a file per kind of record.", success, testID)
+
+					if strings.Contains(strings.ToLower(files["profile."+format]), "password") {
+						t.Fatalf("\t%s\tTest %d:\tShould not export the password hash.", failed, testID)
+					}
+					t.Logf("\t%s\tTest %d:\tShould not export the password hash.", success, testID)
+
+					if format != export.FormatCSV {
+						return
+					}
+
+					rows, err := csv.NewReader(strings.NewReader(files["incomes.csv"])).ReadAll()
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to read the incomes : %s.", failed, testID, err)
+					}
+					if len(rows) != 2 || rows[1][1] != "Salary" || rows[1][3] != "USD" || rows[1][4] != "2500.00" || rows[1][11] != "job;monthly" {
+						t.Fatalf("\t%s\tTest %d:\tShould get back the income : got %v.", failed, testID, rows)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get back the income.", success, testID)
+				}
+			}
+			t.Run(format, tf)
+		}
+	}
+
+	t.Log("Given the need to reject unknown formats.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen writing the xml format.", testID)
+		{
+			if err := arc.WriteZip(io.Discard, "xml", now); !errors.Is(err, export.ErrInvalidFormat) {
+				t.Fatalf("\t%s\tTest %d:\tShould get back ErrInvalidFormat : got %v.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back ErrInvalidFormat.", success, testID)
+		}
+	}
+}
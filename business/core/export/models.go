@@ -0,0 +1,130 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/core/user"
+)
+
+// Archive represents every record of a user.
+type Archive struct {
+	Profile    Profile             `json:"profile"`    // Profile of the user.
+	Incomes    []income.Income     `json:"incomes"`    // Incomes of the user.
+	Expenses   []expense.Expense   `json:"expenses"`   // Expenses of the user.
+	Accounts   []account.Account   `json:"accounts"`   // Accounts of the user.
+	Categories []category.Category `json:"categories"` // Categories of the user along with the default ones.
+	Tags       []tag.Tag           `json:"tags"`       // Tags of the user.
+}
+
+// Profile represents the user an archive belongs to. It leaves out the
+// password hash, which is of no use outside of the service.
+type Profile struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Roles        []string  `json:"roles"`
+	BaseCurrency string    `json:"base_currency"`
+	DateCreated  time.Time `json:"date_created"`
+	DateUpdated  time.Time `json:"date_updated"`
+}
+
+// =============================================================================
+
+func toProfile(usr user.User) Profile {
+	return Profile{
+		ID:           usr.ID,
+		Name:         usr.Name,
+		Email:        usr.Email,
+		Roles:        usr.Roles,
+		BaseCurrency: usr.BaseCurrency,
+		DateCreated:  usr.DateCreated,
+		DateUpdated:  usr.DateUpdated,
+	}
+}
+
+// timeFormat is the layout of the dates in the CSV tables.
+const timeFormat = time.RFC3339
+
+func (arc Archive) profileRows() [][]string {
+	p := arc.Profile
+	return [][]string{
+		{"id", "name", "email", "roles", "base_currency", "date_created", "date_updated"},
+		{p.ID, p.Name, p.Email, strings.Join(p.Roles, ";"), p.BaseCurrency, p.DateCreated.Format(timeFormat), p.DateUpdated.Format(timeFormat)},
+	}
+}
+
+func (arc Archive) incomeRows() [][]string {
+	rows := [][]string{
+		{"id", "name", "category_id", "currency", "amount", "reoccurrence", "duration", "reoccurrence_type", "duration_type", "account_id", "external_id", "tags", "date_created", "date_updated"},
+	}
+	for _, inc := range arc.Incomes {
+		rows = append(rows, []string{
+			inc.ID, inc.Name, inc.CategoryID, inc.Amount.Currency, inc.Amount.Decimal(),
+			strconv.Itoa(inc.Reoccurrence), strconv.Itoa(inc.Duration), inc.ReoccurrenceType, inc.DurationType,
+			inc.AccountID, inc.ExternalID, strings.Join(inc.Tags, ";"),
+			inc.DateCreated.Format(timeFormat), inc.DateUpdated.Format(timeFormat),
+		})
+	}
+	return rows
+}
+
+func (arc Archive) expenseRows() [][]string {
+	rows := [][]string{
+		{"id", "name", "category_id", "currency", "amount", "reoccurrence", "duration", "reoccurrence_type", "duration_type", "account_id", "external_id", "tags", "date_created", "date_updated"},
+	}
+	for _, exp := range arc.Expenses {
+		rows = append(rows, []string{
+			exp.ID, exp.Name, exp.CategoryID, exp.Amount.Currency, exp.Amount.Decimal(),
+			strconv.Itoa(exp.Reoccurrence), strconv.Itoa(exp.Duration), exp.ReoccurrenceType, exp.DurationType,
+			exp.AccountID, exp.ExternalID, strings.Join(exp.Tags, ";"),
+			exp.DateCreated.Format(timeFormat), exp.DateUpdated.Format(timeFormat),
+		})
+	}
+	return rows
+}
+
+func (arc Archive) accountRows() [][]string {
+	rows := [][]string{
+		{"id", "name", "type", "currency", "opening_balance", "balance", "date_created", "date_updated"},
+	}
+	for _, acc := range arc.Accounts {
+		rows = append(rows, []string{
+			acc.ID, acc.Name, acc.Type, acc.Balance.Currency, acc.OpeningBalance.Decimal(), acc.Balance.Decimal(),
+			acc.DateCreated.Format(timeFormat), acc.DateUpdated.Format(timeFormat),
+		})
+	}
+	return rows
+}
+
+func (arc Archive) categoryRows() [][]string {
+	rows := [][]string{
+		{"id", "user_id", "parent_id", "name", "kind", "icon", "color", "date_created", "date_updated"},
+	}
+	for _, cat := range arc.Categories {
+		rows = append(rows, []string{
+			cat.ID, cat.UserID, cat.ParentID, cat.Name, cat.Kind, cat.Icon, cat.Color,
+			cat.DateCreated.Format(timeFormat), cat.DateUpdated.Format(timeFormat),
+		})
+	}
+	return rows
+}
+
+func (arc Archive) tagRows() [][]string {
+	rows := [][]string{
+		{"id", "name", "date_created", "date_updated"},
+	}
+	for _, tg := range arc.Tags {
+		rows = append(rows, []string{
+			tg.ID, tg.Name,
+			tg.DateCreated.Format(timeFormat), tg.DateUpdated.Format(timeFormat),
+		})
+	}
+	return rows
+}
@@ -0,0 +1,305 @@
+// Package forecast provides a core business API for projecting the cash flow
+// of a user from their recurring incomes and expenses and the installments of
+// their debts.
+package forecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/debt"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// MaxMonths is the longest period a forecast can cover.
+const MaxMonths = 120
+
+// Set of error variables for forecast operations.
+var (
+	ErrNotFound      = errors.New("user not found")
+	ErrInvalidID     = errors.New("ID is not in its proper form")
+	ErrInvalidMonths = fmt.Errorf("months must be between 1 and %d", MaxMonths)
+)
+
+// Core manages the set of APIs for forecast access.
+type Core struct {
+	income   income.Core
+	expense  expense.Core
+	category category.Core
+	debt     debt.Core
+	user     user.Core
+	fx       fx.Core
+}
+
+// NewCore constructs a core for forecast api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		income:   income.NewCore(log, sqlxDB),
+		expense:  expense.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+		debt:     debt.NewCore(log, sqlxDB),
+		user:     user.NewCore(log, sqlxDB),
+		fx:       fx.NewCore(log, sqlxDB),
+	}
+}
+
+// QueryByUserID simulates the recurring incomes and expenses and the debt
+// installments of a user month by month, starting with the month of now. With
+// rollup the amounts of nested categories are reported under their top-level
+// parent.
+func (c Core) QueryByUserID(ctx context.Context, userID string, now time.Time, months int, rollup bool) (Forecast, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Forecast{}, ErrInvalidID
+	}
+
+	if months < 1 || months > MaxMonths {
+		return Forecast{}, ErrInvalidMonths
+	}
+
+	usr, err := c.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return Forecast{}, ErrNotFound
+		}
+		return Forecast{}, fmt.Errorf("query user: %w", err)
+	}
+
+	incs, err := c.income.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return Forecast{}, fmt.Errorf("query incomes: %w", err)
+	}
+
+	exps, err := c.expense.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return Forecast{}, fmt.Errorf("query expenses: %w", err)
+	}
+
+	schs, err := c.debt.QueryScheduleByUserID(ctx, userID, now)
+	if err != nil {
+		return Forecast{}, fmt.Errorf("query debts: %w", err)
+	}
+
+	categoryOf := func(categoryID string) string { return categoryID }
+	if rollup {
+		h, err := c.category.QueryHierarchyByUserID(ctx, userID)
+		if err != nil {
+			return Forecast{}, fmt.Errorf("query categories: %w", err)
+		}
+		categoryOf = h.Root
+	}
+
+	entries := make([]Entry, 0, len(incs)+len(exps))
+	for _, inc := range incs {
+		entries = append(entries, Entry{
+			Rule:     ruleOf(inc.DateCreated, inc.ReoccurrenceType, inc.Reoccurrence, inc.Duration, inc.DurationType),
+			Category: categoryOf(inc.CategoryID),
+			Amount:   inc.Amount,
+		})
+	}
+	for _, exp := range exps {
+		entries = append(entries, Entry{
+			Rule:     ruleOf(exp.DateCreated, exp.ReoccurrenceType, exp.Reoccurrence, exp.Duration, exp.DurationType),
+			Category: categoryOf(exp.CategoryID),
+			Amount:   exp.Amount.Neg(),
+		})
+	}
+
+	// Every installment of a debt happens once, since the last one can differ.
+	for _, sch := range schs {
+		for _, pmt := range sch.Payments {
+			entries = append(entries, Entry{
+				Rule:     recurrence.Rule{Start: pmt.Date, Type: recurrence.TypeOnce},
+				Category: categoryOf(sch.Debt.CategoryID),
+				Amount:   pmt.Payment.Neg(),
+			})
+		}
+	}
+
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	periods, err := Simulate(entries, start, months)
+	if err != nil {
+		return Forecast{}, fmt.Errorf("simulate: %w", err)
+	}
+
+	fc := Forecast{
+		UserID:   userID,
+		Months:   months,
+		Currency: usr.BaseCurrency,
+		Periods:  periods,
+	}
+
+	// Future months use the latest rate known at the start of the period.
+	cv := c.fx.NewConverter()
+	balance := money.Zero(usr.BaseCurrency)
+	for i, period := range fc.Periods {
+		total, err := convert(ctx, cv, period.Currencies, usr.BaseCurrency, start.AddDate(0, i, 0))
+		if err != nil {
+			return Forecast{}, fmt.Errorf("converting month[%s]: %w", period.Month, err)
+		}
+
+		if balance, err = balance.Add(total.Net); err != nil {
+			return Forecast{}, fmt.Errorf("balance month[%s]: %w", period.Month, err)
+		}
+		total.Balance = balance
+		fc.Periods[i].Total = total
+	}
+
+	return fc, nil
+}
+
+// Simulate expands every entry month by month for the given number of months
+// from the start and accumulates the results per currency.
+func Simulate(entries []Entry, start time.Time, months int) ([]Period, error) {
+	periods := make([]Period, months)
+	balances := make(map[string]money.Money)
+
+	for i := range periods {
+		from := start.AddDate(0, i, 0)
+		to := start.AddDate(0, i+1, 0)
+
+		period := Period{
+			Month:      from.Format("2006-01"),
+			Currencies: make(map[string]Projection),
+		}
+
+		for _, e := range entries {
+			n := len(e.Rule.Between(from, to))
+			if n == 0 {
+				continue
+			}
+
+			cur := e.Amount.Currency
+			prj, exists := period.Currencies[cur]
+			if !exists {
+				prj = newProjection(cur)
+			}
+
+			amount, err := e.Amount.Mul(int64(n))
+			if err != nil {
+				return nil, err
+			}
+
+			if amount.IsPositive() {
+				prj.Income, err = prj.Income.Add(amount)
+			} else {
+				prj.Expense, err = prj.Expense.Sub(amount)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if prj.Net, err = prj.Net.Add(amount); err != nil {
+				return nil, err
+			}
+
+			category, exists := prj.Categories[e.Category]
+			if !exists {
+				category = money.Zero(cur)
+			}
+			if prj.Categories[e.Category], err = category.Add(amount); err != nil {
+				return nil, err
+			}
+
+			period.Currencies[cur] = prj
+		}
+
+		for cur, prj := range period.Currencies {
+			balance, exists := balances[cur]
+			if !exists {
+				balance = money.Zero(cur)
+			}
+
+			var err error
+			if balances[cur], err = balance.Add(prj.Net); err != nil {
+				return nil, err
+			}
+		}
+
+		// Currencies without movements this month still carry their balance.
+		for cur, balance := range balances {
+			prj, exists := period.Currencies[cur]
+			if !exists {
+				prj = newProjection(cur)
+			}
+			prj.Balance = balance
+			period.Currencies[cur] = prj
+		}
+
+		periods[i] = period
+	}
+
+	return periods, nil
+}
+
+// =============================================================================
+
+// convert adds up the projections of every currency into a single projection
+// in the base currency. The balance is left for the caller to accumulate.
+func convert(ctx context.Context, cv *fx.Converter, prjs map[string]Projection, base string, date time.Time) (Projection, error) {
+	total := newProjection(base)
+
+	for _, prj := range prjs {
+		income, err := cv.Convert(ctx, prj.Income, base, date)
+		if err != nil {
+			return Projection{}, err
+		}
+		if total.Income, err = total.Income.Add(income); err != nil {
+			return Projection{}, err
+		}
+
+		expense, err := cv.Convert(ctx, prj.Expense, base, date)
+		if err != nil {
+			return Projection{}, err
+		}
+		if total.Expense, err = total.Expense.Add(expense); err != nil {
+			return Projection{}, err
+		}
+
+		for cat, amount := range prj.Categories {
+			amount, err := cv.Convert(ctx, amount, base, date)
+			if err != nil {
+				return Projection{}, err
+			}
+
+			category, exists := total.Categories[cat]
+			if !exists {
+				category = money.Zero(base)
+			}
+			if total.Categories[cat], err = category.Add(amount); err != nil {
+				return Projection{}, err
+			}
+		}
+	}
+
+	net, err := total.Income.Sub(total.Expense)
+	if err != nil {
+		return Projection{}, err
+	}
+	total.Net = net
+
+	return total, nil
+}
+
+// ruleOf builds the reoccurrence rule from the fields of an income or expense.
+func ruleOf(start time.Time, typ string, interval int, duration int, durationType string) recurrence.Rule {
+	return recurrence.Rule{
+		Start:        start,
+		Type:         typ,
+		Interval:     interval,
+		Duration:     duration,
+		DurationType: durationType,
+	}
+}
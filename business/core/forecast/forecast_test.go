@@ -0,0 +1,148 @@
+package forecast_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/forecast"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Simulate(t *testing.T) {
+	eur := func(amount int64) money.Money {
+		return money.Money{Amount: amount, Currency: "EUR"}
+	}
+	usd := func(amount int64) money.Money {
+		return money.Money{Amount: amount, Currency: "USD"}
+	}
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	// prj builds the expected projection of a currency in a month.
+	prj := func(income money.Money, expense money.Money, net money.Money, balance money.Money, cats map[string]money.Money) forecast.Projection {
+		if cats == nil {
+			cats = map[string]money.Money{}
+		}
+		return forecast.Projection{Income: income, Expense: expense, Net: net, Balance: balance, Categories: cats}
+	}
+
+	entries := []forecast.Entry{
+		{
+			Rule:     recurrence.Rule{Start: date(2018, time.December, 15), Type: recurrence.TypeMonthly, Interval: 1, DurationType: recurrence.DurationNone},
+			Category: "salary",
+			Amount:   eur(300000),
+		},
+		{
+			Rule:     recurrence.Rule{Start: date(2019, time.January, 31), Type: recurrence.TypeMonthly, Interval: 2, DurationType: recurrence.DurationNone},
+			Category: "gym",
+			Amount:   eur(-3000),
+		},
+		{
+			Rule:     recurrence.Rule{Start: date(2019, time.January, 1), Type: recurrence.TypeMonthly, Interval: 1, Duration: 2, DurationType: recurrence.DurationMonths},
+			Category: "rent",
+			Amount:   eur(-100000),
+		},
+		{
+			Rule:     recurrence.Rule{Start: date(2019, time.February, 25), Type: recurrence.TypeDaily, Interval: 1, Duration: 10, DurationType: recurrence.DurationDays},
+			Category: "food",
+			Amount:   eur(-300),
+		},
+		{
+			Rule:     recurrence.Rule{Start: date(2019, time.March, 10), Type: recurrence.TypeOnce},
+			Category: "food",
+			Amount:   usd(-5000),
+		},
+	}
+
+	exp := []forecast.Period{
+		{
+			Month: "2019-01",
+			Currencies: map[string]forecast.Projection{
+				"EUR": prj(eur(300000), eur(103000), eur(197000), eur(197000), map[string]money.Money{
+					"salary": eur(300000),
+					"gym":    eur(-3000),
+					"rent":   eur(-100000),
+				}),
+			},
+		},
+		{
+			Month: "2019-02",
+			Currencies: map[string]forecast.Projection{
+				"EUR": prj(eur(300000), eur(101200), eur(198800), eur(395800), map[string]money.Money{
+					"salary": eur(300000),
+					"rent":   eur(-100000),
+					"food":   eur(-1200),
+				}),
+			},
+		},
+		{
+			Month: "2019-03",
+			Currencies: map[string]forecast.Projection{
+				"EUR": prj(eur(300000), eur(4800), eur(295200), eur(691000), map[string]money.Money{
+					"salary": eur(300000),
+					"gym":    eur(-3000),
+					"food":   eur(-1800),
+				}),
+				"USD": prj(usd(0), usd(5000), usd(-5000), usd(-5000), map[string]money.Money{
+					"food": usd(-5000),
+				}),
+			},
+		},
+		{
+			Month: "2019-04",
+			Currencies: map[string]forecast.Projection{
+				"EUR": prj(eur(300000), eur(0), eur(300000), eur(991000), map[string]money.Money{
+					"salary": eur(300000),
+				}),
+				"USD": prj(usd(0), usd(0), usd(0), usd(-5000), nil),
+			},
+		},
+	}
+
+	t.Log("Given the need to project recurring movements month by month.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen simulating monthly, daily and one-off movements with and without a duration.", testID)
+		{
+			got, err := forecast.Simulate(entries, date(2019, time.January, 1), len(exp))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to simulate : %s.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to simulate.", success, testID)
+
+			if diff := cmp.Diff(exp, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould expand every movement into the months it happens in. Diff:\n%s", failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould expand every movement into the months it happens in.", success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen simulating past the end of a duration.", testID)
+		{
+			rent := entries[2:3]
+
+			got, err := forecast.Simulate(rent, date(2019, time.March, 1), 2)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to simulate : %s.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to simulate.", success, testID)
+
+			for _, period := range got {
+				if len(period.Currencies) != 0 {
+					t.Fatalf("\t%s\tTest %d:\tShould stop at the end of the duration : got %v in %s.", failed, testID, period.Currencies, period.Month)
+				}
+			}
+			t.Logf("\t%s\tTest %d:\tShould stop at the end of the duration.", success, testID)
+		}
+	}
+}
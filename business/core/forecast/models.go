@@ -0,0 +1,54 @@
+package forecast
+
+import (
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+)
+
+// Forecast represents the projected cash flow of a user for a number of
+// months ahead.
+type Forecast struct {
+	UserID   string   `json:"user_id"`  // ID of the user the forecast belongs to.
+	Months   int      `json:"months"`   // Number of months projected.
+	Currency string   `json:"currency"` // Base currency of the user the totals are converted into.
+	Periods  []Period `json:"periods"`  // Projection of every month.
+}
+
+// Period represents the projection of a single month, broken down by
+// currency and converted into the base currency of the user.
+type Period struct {
+	Month      string                `json:"month"`      // Month of the period (YYYY-MM).
+	Currencies map[string]Projection `json:"currencies"` // Projection per currency.
+	Total      Projection            `json:"total"`      // Projection of all currencies in the base currency.
+}
+
+// Projection represents the projected money movements of one currency within
+// a period.
+type Projection struct {
+	Income     money.Money            `json:"income"`     // Total income of the period.
+	Expense    money.Money            `json:"expense"`    // Total expense of the period.
+	Net        money.Money            `json:"net"`        // Income minus expense of the period.
+	Balance    money.Money            `json:"balance"`    // Accumulated net since the start of the forecast.
+	Categories map[string]money.Money `json:"categories"` // Net amount per category ID.
+}
+
+// Entry represents a recurring money movement taking part in a simulation.
+// Incomes have a positive amount and expenses a negative one.
+type Entry struct {
+	Rule     recurrence.Rule // When the movement happens.
+	Category string          // ID of the category it is reported under.
+	Amount   money.Money     // Amount of a single occurrence.
+}
+
+// =============================================================================
+
+// newProjection constructs an empty projection in the currency.
+func newProjection(currency string) Projection {
+	return Projection{
+		Income:     money.Zero(currency),
+		Expense:    money.Zero(currency),
+		Net:        money.Zero(currency),
+		Balance:    money.Zero(currency),
+		Categories: make(map[string]money.Money),
+	}
+}
@@ -0,0 +1,96 @@
+// Package db contains exchange rate related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for exchange rate access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// Save adds a Rate to the database, replacing the rate already stored for
+// the same currency and date.
+func (s Store) Save(ctx context.Context, rate Rate) error {
+	const q = `
+	INSERT INTO exchange_rates
+		(currency, rate_date, rate)
+	VALUES
+		(:currency, :rate_date, :rate)
+	ON CONFLICT (currency, rate_date) DO UPDATE SET
+		rate = EXCLUDED.rate`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rate); err != nil {
+		return fmt.Errorf("saving rate currency[%s] date[%s]: %w", rate.Currency, rate.Date.Format("2006-01-02"), err)
+	}
+
+	return nil
+}
+
+// QueryByDate finds the rate of a currency that was in effect on a given
+// date, which is the latest rate published on or before it.
+func (s Store) QueryByDate(ctx context.Context, currency string, date time.Time) (Rate, error) {
+	data := struct {
+		Currency string    `db:"currency"`
+		Date     time.Time `db:"rate_date"`
+	}{
+		Currency: currency,
+		Date:     date,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		exchange_rates
+	WHERE
+		currency = :currency AND
+		rate_date <= :rate_date
+	ORDER BY
+		rate_date DESC
+	LIMIT 1`
+
+	var rate Rate
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &rate); err != nil {
+		return Rate{}, fmt.Errorf("selecting rate currency[%s] date[%s]: %w", currency, date.Format("2006-01-02"), err)
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,11 @@
+package db
+
+import "time"
+
+// Rate represents the exchange rate of a currency against the euro on a
+// given date.
+type Rate struct {
+	Currency string    `db:"currency"`  // Currency the rate is for.
+	Date     time.Time `db:"rate_date"` // Date the rate was published.
+	Rate     float64   `db:"rate"`      // Units of the currency one euro buys.
+}
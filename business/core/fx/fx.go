@@ -0,0 +1,165 @@
+// Package fx provides a core business API for exchange rates and the
+// conversion of amounts between currencies at the rate of a given date.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/fx/db"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// BaseCurrency is the currency all the stored rates are quoted against. It
+// matches the reference rates published by the European Central Bank.
+const BaseCurrency = "EUR"
+
+// Set of error variables for exchange rate operations.
+var (
+	ErrRateNotFound = errors.New("exchange rate not found")
+)
+
+// Core manages the set of APIs for exchange rate access.
+type Core struct {
+	store db.Store
+}
+
+// NewCore constructs a core for exchange rate api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store: db.NewStore(log, sqlxDB),
+	}
+}
+
+// Save stores a set of exchange rates in a single transaction. Rates that
+// already exist for the same currency and date are replaced.
+func (c Core) Save(ctx context.Context, nrs []NewRate) error {
+	for i, nr := range nrs {
+		if err := validate.Check(nr); err != nil {
+			return fmt.Errorf("validating rate %d: %w", i, err)
+		}
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		for _, nr := range nrs {
+			dbRate := db.Rate{
+				Currency: nr.Currency,
+				Date:     day(nr.Date),
+				Rate:     nr.Rate,
+			}
+
+			if err := c.store.Tran(tx).Save(ctx, dbRate); err != nil {
+				return fmt.Errorf("save: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByDate finds the rate of a currency that was in effect on a given date.
+func (c Core) QueryByDate(ctx context.Context, currency string, date time.Time) (Rate, error) {
+	if currency == BaseCurrency {
+		return Rate{Currency: BaseCurrency, Date: day(date), Rate: 1}, nil
+	}
+
+	dbRate, err := c.store.QueryByDate(ctx, currency, date)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Rate{}, fmt.Errorf("currency[%s] date[%s]: %w", currency, date.Format("2006-01-02"), ErrRateNotFound)
+		}
+		return Rate{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toRate(dbRate), nil
+}
+
+// NewConverter constructs a Converter backed by this core.
+func (c Core) NewConverter() *Converter {
+	return &Converter{
+		core:  c,
+		rates: make(map[rateKey]*big.Rat),
+	}
+}
+
+// =============================================================================
+
+// Converter converts amounts between currencies. It remembers the rates it
+// has looked up, so converting a list of amounts only queries the database
+// once per currency and date. A Converter is not safe for concurrent use.
+type Converter struct {
+	core  Core
+	rates map[rateKey]*big.Rat
+}
+
+// rateKey identifies a cached rate.
+type rateKey struct {
+	currency string
+	date     string
+}
+
+// Convert converts an amount into another currency using the rates in effect
+// on the given date. The result is rounded to the minor unit of the target
+// currency.
+func (cv *Converter) Convert(ctx context.Context, m money.Money, to string, date time.Time) (money.Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	fromRate, err := cv.rate(ctx, m.Currency, date)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	toRate, err := cv.rate(ctx, to, date)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	// Both rates are quoted against the euro, so the cross rate is the ratio
+	// between them.
+	return m.Convert(to, new(big.Rat).Quo(toRate, fromRate))
+}
+
+// rate returns the rate of the currency on the date, going to the database
+// only when it is not cached yet.
+func (cv *Converter) rate(ctx context.Context, currency string, date time.Time) (*big.Rat, error) {
+	key := rateKey{currency: currency, date: date.Format("2006-01-02")}
+	if rate, exists := cv.rates[key]; exists {
+		return rate, nil
+	}
+
+	r, err := cv.core.QueryByDate(ctx, currency, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Going through the shortest decimal form keeps the rate exactly as it
+	// was published instead of its binary approximation.
+	rate, ok := new(big.Rat).SetString(strconv.FormatFloat(r.Rate, 'f', -1, 64))
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid rate currency[%s] rate[%v]", currency, r.Rate)
+	}
+
+	cv.rates[key] = rate
+	return rate, nil
+}
+
+// day truncates a time value to midnight UTC of the same date.
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
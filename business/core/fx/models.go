@@ -0,0 +1,30 @@
+package fx
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/gloompi/ultimate-service/business/core/fx/db"
+)
+
+// Rate represents the exchange rate of a currency against the euro on a
+// given date.
+type Rate struct {
+	Currency string    `json:"currency"` // Currency the rate is for.
+	Date     time.Time `json:"date"`     // Date the rate was published.
+	Rate     float64   `json:"rate"`     // Units of the currency one euro buys.
+}
+
+// NewRate is what we require when loading an exchange rate.
+type NewRate struct {
+	Currency string    `json:"currency" validate:"required,iso4217"`
+	Date     time.Time `json:"date" validate:"required"`
+	Rate     float64   `json:"rate" validate:"gt=0"`
+}
+
+// =============================================================================
+
+func toRate(dbRate db.Rate) Rate {
+	r := (*Rate)(unsafe.Pointer(&dbRate))
+	return *r
+}
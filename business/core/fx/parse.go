@@ -0,0 +1,108 @@
+package fx
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ecbEnvelope is the document published by the European Central Bank for
+// the daily, 90 days and historical euro reference rates.
+// https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB reads the rates out of an ECB euro reference rates XML document.
+func ParseECB(r io.Reader) ([]NewRate, error) {
+	var env ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("decoding xml: %w", err)
+	}
+
+	var nrs []NewRate
+	for _, d := range env.Days {
+		date, err := time.Parse("2006-01-02", d.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date format [%s]", d.Time)
+		}
+
+		for _, rt := range d.Rates {
+			rate, err := strconv.ParseFloat(rt.Rate, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate format currency[%s] rate[%s]", rt.Currency, rt.Rate)
+			}
+
+			nrs = append(nrs, NewRate{
+				Currency: rt.Currency,
+				Date:     date,
+				Rate:     rate,
+			})
+		}
+	}
+
+	if len(nrs) == 0 {
+		return nil, errors.New("no rates found in document")
+	}
+
+	return nrs, nil
+}
+
+// ParseCSV reads the rates out of a CSV document with a date, currency and
+// rate column, where the rate is the units of the currency one euro buys.
+// A header row is allowed.
+//
+//	date,currency,rate
+//	2022-06-01,USD,1.0708
+func ParseCSV(r io.Reader) ([]NewRate, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+
+	var nrs []NewRate
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv: %w", err)
+		}
+
+		date, err := time.Parse("2006-01-02", rec[0])
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid date format [%s]", line, rec[0])
+		}
+
+		rate, err := strconv.ParseFloat(rec[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate format [%s]", line, rec[2])
+		}
+
+		nrs = append(nrs, NewRate{
+			Currency: strings.ToUpper(rec[1]),
+			Date:     date,
+			Rate:     rate,
+		})
+	}
+
+	if len(nrs) == 0 {
+		return nil, errors.New("no rates found in document")
+	}
+
+	return nrs, nil
+}
@@ -0,0 +1,80 @@
+package fx_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+const ecbDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2022-06-01">
+			<Cube currency="USD" rate="1.0708"/>
+			<Cube currency="JPY" rate="138.69"/>
+		</Cube>
+		<Cube time="2022-05-31">
+			<Cube currency="USD" rate="1.0774"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const csvDoc = `date,currency,rate
+2022-06-01,usd,1.0708
+2022-06-01,JPY,138.69
+2022-05-31,USD,1.0774
+`
+
+func Test_Parse(t *testing.T) {
+	exp := []fx.NewRate{
+		{Currency: "USD", Date: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), Rate: 1.0708},
+		{Currency: "JPY", Date: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), Rate: 138.69},
+		{Currency: "USD", Date: time.Date(2022, time.May, 31, 0, 0, 0, 0, time.UTC), Rate: 1.0774},
+	}
+
+	t.Log("Given the need to load exchange rates from files.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen handling an ECB reference rates document.", testID)
+		{
+			got, err := fx.ParseECB(strings.NewReader(ecbDoc))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to parse the document : %s.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to parse the document.", success, testID)
+
+			if diff := cmp.Diff(exp, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould get back the same rates. Diff:\n%s", failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back the same rates.", success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen handling a CSV document.", testID)
+		{
+			got, err := fx.ParseCSV(strings.NewReader(csvDoc))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to parse the document : %s.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to parse the document.", success, testID)
+
+			if diff := cmp.Diff(exp, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould get back the same rates. Diff:\n%s", failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back the same rates.", success, testID)
+		}
+	}
+}
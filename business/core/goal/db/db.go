@@ -0,0 +1,204 @@
+// Package db contains goal related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for goal access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// selectGoals selects goals along with the sum of their contributions.
+const selectGoals = `
+	SELECT
+		g.goal_id, g.user_id, g.account_id, g.name, g.currency, g.target, g.deadline, g.date_created, g.date_updated,
+		COALESCE((
+			SELECT
+				SUM(c.amount)
+			FROM
+				goal_contributions AS c
+			WHERE
+				c.goal_id = g.goal_id
+		), 0) AS saved
+	FROM
+		goals AS g`
+
+// Create adds a Goal to the database.
+func (s Store) Create(ctx context.Context, gl Goal) error {
+	const q = `
+	INSERT INTO goals
+		(goal_id, user_id, account_id, name, currency, target, deadline, date_created, date_updated)
+	VALUES
+		(:goal_id, :user_id, :account_id, :name, :currency, :target, :deadline, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, gl); err != nil {
+		return fmt.Errorf("inserting goal: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about a Goal.
+func (s Store) Update(ctx context.Context, gl Goal) error {
+	const q = `
+	UPDATE
+		goals
+	SET
+		"account_id" = :account_id,
+		"name" = :name,
+		"target" = :target,
+		"deadline" = :deadline,
+		"date_updated" = :date_updated
+	WHERE
+		goal_id = :goal_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, gl); err != nil {
+		return fmt.Errorf("updating goal goalID[%s]: %w", gl.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the goal identified by a given ID along with its
+// contributions.
+func (s Store) Delete(ctx context.Context, goalID string) error {
+	data := struct {
+		GoalID string `db:"goal_id"`
+	}{
+		GoalID: goalID,
+	}
+
+	const q = `
+	DELETE FROM
+		goals
+	WHERE
+		goal_id = :goal_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting goal goalID[%s]: %w", goalID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the goal identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, goalID string) (Goal, error) {
+	data := struct {
+		GoalID string `db:"goal_id"`
+	}{
+		GoalID: goalID,
+	}
+
+	const q = selectGoals + `
+	WHERE
+		g.goal_id = :goal_id`
+
+	var gl Goal
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &gl); err != nil {
+		return Goal{}, fmt.Errorf("selecting goal goalID[%q]: %w", goalID, err)
+	}
+
+	return gl, nil
+}
+
+// QueryByUserID finds the goals of a given User ID, the closest deadline
+// first.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Goal, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = selectGoals + `
+	WHERE
+		g.user_id = :user_id
+	ORDER BY
+		g.deadline, g.name`
+
+	var gls []Goal
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &gls); err != nil {
+		return nil, fmt.Errorf("selecting goals userID[%s]: %w", userID, err)
+	}
+
+	return gls, nil
+}
+
+// CreateContribution adds a Contribution to the database.
+func (s Store) CreateContribution(ctx context.Context, ctb Contribution) error {
+	const q = `
+	INSERT INTO goal_contributions
+		(contribution_id, goal_id, amount, date_contributed, date_created)
+	VALUES
+		(:contribution_id, :goal_id, :amount, :date_contributed, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, ctb); err != nil {
+		return fmt.Errorf("inserting contribution: %w", err)
+	}
+
+	return nil
+}
+
+// QueryContributions finds the contributions of a given Goal ID, the most
+// recent first.
+func (s Store) QueryContributions(ctx context.Context, goalID string) ([]Contribution, error) {
+	data := struct {
+		GoalID string `db:"goal_id"`
+	}{
+		GoalID: goalID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		goal_contributions
+	WHERE
+		goal_id = :goal_id
+	ORDER BY
+		date_contributed DESC, date_created DESC`
+
+	var ctbs []Contribution
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &ctbs); err != nil {
+		return nil, fmt.Errorf("selecting contributions goalID[%s]: %w", goalID, err)
+	}
+
+	return ctbs, nil
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Goal represents a savings goal of a user.
+type Goal struct {
+	ID          string         `db:"goal_id"`      // Unique identifier.
+	UserID      string         `db:"user_id"`      // ID of the user who owns the goal.
+	AccountID   sql.NullString `db:"account_id"`   // ID of the account the savings are kept in.
+	Name        string         `db:"name"`         // Display name of the goal.
+	Currency    string         `db:"currency"`     // Currency of the target and contributions.
+	Target      int64          `db:"target"`       // Amount to save in the minor unit of the currency.
+	Deadline    time.Time      `db:"deadline"`     // When the target should be reached.
+	Saved       int64          `db:"saved"`        // Sum of the contributions, computed on select.
+	DateCreated time.Time      `db:"date_created"` // When the goal was added.
+	DateUpdated time.Time      `db:"date_updated"` // When the goal record was last modified.
+}
+
+// Contribution represents money put towards or taken out of a goal.
+type Contribution struct {
+	ID              string    `db:"contribution_id"`  // Unique identifier.
+	GoalID          string    `db:"goal_id"`          // ID of the goal contributed to.
+	Amount          int64     `db:"amount"`           // Amount in the minor unit of the goal currency.
+	DateContributed time.Time `db:"date_contributed"` // When the money was set aside.
+	DateCreated     time.Time `db:"date_created"`     // When the contribution was recorded.
+}
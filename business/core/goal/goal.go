@@ -0,0 +1,374 @@
+// Package goal provides a core business API for savings goals, the
+// contributions made towards them and the projection of when they are
+// reached.
+package goal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/forecast"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/goal/db"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound         = errors.New("goal not found")
+	ErrInvalidID        = errors.New("ID is not in its proper form")
+	ErrInvalidTarget    = errors.New("target must be greater than zero")
+	ErrInvalidDeadline  = errors.New("deadline must be in the future")
+	ErrInvalidAmount    = errors.New("contribution can't be zero")
+	ErrCurrencyMismatch = errors.New("currency does not match the goal")
+)
+
+// Core manages the set of APIs for goal access.
+type Core struct {
+	store    db.Store
+	account  account.Core
+	forecast forecast.Core
+	fx       fx.Core
+}
+
+// NewCore constructs a core for goal api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store:    db.NewStore(log, sqlxDB),
+		account:  account.NewCore(log, sqlxDB),
+		forecast: forecast.NewCore(log, sqlxDB),
+		fx:       fx.NewCore(log, sqlxDB),
+	}
+}
+
+// Create adds a Goal to the database. It returns the created Goal with fields
+// like ID and DateCreated populated.
+func (c Core) Create(ctx context.Context, ng NewGoal, now time.Time) (Goal, error) {
+	if err := validate.Check(ng); err != nil {
+		return Goal{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if !ng.Target.IsPositive() {
+		return Goal{}, ErrInvalidTarget
+	}
+
+	if !ng.Deadline.After(now) {
+		return Goal{}, ErrInvalidDeadline
+	}
+
+	if ng.AccountID != "" {
+		if err := c.account.Check(ctx, ng.AccountID, ng.UserID, ng.Target.Currency); err != nil {
+			return Goal{}, fmt.Errorf("account: %w", err)
+		}
+	}
+
+	dbGl := db.Goal{
+		ID:          validate.GenerateID(),
+		UserID:      ng.UserID,
+		AccountID:   sql.NullString{String: ng.AccountID, Valid: ng.AccountID != ""},
+		Name:        ng.Name,
+		Currency:    ng.Target.Currency,
+		Target:      ng.Target.Amount,
+		Deadline:    ng.Deadline,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.store.Create(ctx, dbGl); err != nil {
+		return Goal{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toGoal(dbGl), nil
+}
+
+// Update modifies data about a Goal. It will error if the specified ID is
+// invalid or does not reference an existing Goal. The currency of a goal
+// can't change since its contributions are kept in it.
+func (c Core) Update(ctx context.Context, goalID string, ug UpdateGoal, now time.Time) error {
+	if err := validate.CheckID(goalID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(ug); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbGl, err := c.store.QueryByID(ctx, goalID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("updating goal goalID[%s]: %w", goalID, err)
+	}
+
+	if ug.Name != nil {
+		dbGl.Name = *ug.Name
+	}
+	if ug.Target != nil {
+		if ug.Target.Currency != dbGl.Currency {
+			return ErrCurrencyMismatch
+		}
+		if !ug.Target.IsPositive() {
+			return ErrInvalidTarget
+		}
+		dbGl.Target = ug.Target.Amount
+	}
+	if ug.Deadline != nil {
+		if !ug.Deadline.After(now) {
+			return ErrInvalidDeadline
+		}
+		dbGl.Deadline = *ug.Deadline
+	}
+	if ug.AccountID != nil {
+		if *ug.AccountID != "" {
+			if err := c.account.Check(ctx, *ug.AccountID, dbGl.UserID, dbGl.Currency); err != nil {
+				return fmt.Errorf("account: %w", err)
+			}
+		}
+		dbGl.AccountID = sql.NullString{String: *ug.AccountID, Valid: *ug.AccountID != ""}
+	}
+	dbGl.DateUpdated = now
+
+	if err := c.store.Update(ctx, dbGl); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the goal identified by a given ID along with its
+// contributions.
+func (c Core) Delete(ctx context.Context, goalID string) error {
+	if err := validate.CheckID(goalID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := c.store.Delete(ctx, goalID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the goal identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, goalID string) (Goal, error) {
+	if err := validate.CheckID(goalID); err != nil {
+		return Goal{}, ErrInvalidID
+	}
+
+	dbGl, err := c.store.QueryByID(ctx, goalID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Goal{}, ErrNotFound
+		}
+		return Goal{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toGoal(dbGl), nil
+}
+
+// QueryByUserID finds the goals identified by a given User ID.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Goal, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbGls, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toGoalSlice(dbGls), nil
+}
+
+// Contribute records money put towards the goal identified by a given ID. A
+// negative amount takes money out of the goal.
+func (c Core) Contribute(ctx context.Context, goalID string, nc NewContribution, now time.Time) (Contribution, error) {
+	gl, err := c.QueryByID(ctx, goalID)
+	if err != nil {
+		return Contribution{}, err
+	}
+
+	if err := validate.Check(nc); err != nil {
+		return Contribution{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if nc.Amount.IsZero() {
+		return Contribution{}, ErrInvalidAmount
+	}
+
+	if nc.Amount.Currency != gl.Target.Currency {
+		return Contribution{}, ErrCurrencyMismatch
+	}
+
+	date := nc.Date
+	if date.IsZero() {
+		date = now
+	}
+
+	dbCtb := db.Contribution{
+		ID:              validate.GenerateID(),
+		GoalID:          gl.ID,
+		Amount:          nc.Amount.Amount,
+		DateContributed: date,
+		DateCreated:     now,
+	}
+
+	if err := c.store.CreateContribution(ctx, dbCtb); err != nil {
+		return Contribution{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toContribution(dbCtb, gl.Target.Currency), nil
+}
+
+// QueryContributions finds the contributions made to the goal identified by a
+// given ID.
+func (c Core) QueryContributions(ctx context.Context, goalID string) ([]Contribution, error) {
+	gl, err := c.QueryByID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbCtbs, err := c.store.QueryContributions(ctx, gl.ID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toContributionSlice(dbCtbs, gl.Target.Currency), nil
+}
+
+// QueryStatus reports how far the goal identified by a given ID is from its
+// target as of now. The projected completion date assumes the net of the
+// recurring incomes and expenses of the user, converted into the currency of
+// the goal, is saved every month.
+func (c Core) QueryStatus(ctx context.Context, goalID string, now time.Time) (Status, error) {
+	gl, err := c.QueryByID(ctx, goalID)
+	if err != nil {
+		return Status{}, err
+	}
+
+	fc, err := c.forecast.QueryByUserID(ctx, gl.UserID, now, forecast.MaxMonths, false)
+	if err != nil {
+		return Status{}, fmt.Errorf("forecast: %w", err)
+	}
+
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// Future months use the latest rate known at the start of the period.
+	cv := c.fx.NewConverter()
+	nets := make([]money.Money, len(fc.Periods))
+	for i, period := range fc.Periods {
+		net := money.Zero(gl.Target.Currency)
+		for _, prj := range period.Currencies {
+			amount, err := cv.Convert(ctx, prj.Net, gl.Target.Currency, start.AddDate(0, i, 0))
+			if err != nil {
+				return Status{}, fmt.Errorf("converting month[%s]: %w", period.Month, err)
+			}
+			if net, err = net.Add(amount); err != nil {
+				return Status{}, fmt.Errorf("net month[%s]: %w", period.Month, err)
+			}
+		}
+		nets[i] = net
+	}
+
+	st, err := StatusOf(gl, nets, now)
+	if err != nil {
+		return Status{}, fmt.Errorf("goalID[%s]: %w", gl.ID, err)
+	}
+
+	return st, nil
+}
+
+// StatusOf compares the saved amount of the goal against its target as of
+// now. Nets holds the projected net cash flow of every month from the one of
+// now onwards.
+func StatusOf(gl Goal, nets []money.Money, now time.Time) (Status, error) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	remaining, err := gl.Target.Sub(gl.Saved)
+	if err != nil {
+		return Status{}, err
+	}
+	if remaining.IsNegative() {
+		remaining = money.Zero(remaining.Currency)
+	}
+
+	// Targets are always positive, so there is no division by zero. The
+	// percentage is rounded to two decimals.
+	percent := float64(gl.Saved.Amount) / float64(gl.Target.Amount) * 100
+
+	st := Status{
+		Goal:            gl,
+		Remaining:       remaining,
+		PercentReached:  math.Round(percent*100) / 100,
+		Reached:         remaining.IsZero(),
+		MonthsLeft:      monthsLeft(now, gl.Deadline),
+		RequiredMonthly: remaining,
+	}
+
+	if st.Reached {
+		st.OnTrack = true
+		return st, nil
+	}
+
+	// Spread the remaining amount evenly over the months left, with the
+	// leftover minor units rounding the monthly contribution up.
+	if st.MonthsLeft > 1 {
+		ratios := make([]int64, st.MonthsLeft)
+		for i := range ratios {
+			ratios[i] = 1
+		}
+
+		shares, err := remaining.Allocate(ratios...)
+		if err != nil {
+			return Status{}, err
+		}
+		st.RequiredMonthly = shares[0]
+	}
+
+	// Walk the projected months until their accumulated net covers what is
+	// left to save.
+	saved := money.Zero(remaining.Currency)
+	for i, net := range nets {
+		if saved, err = saved.Add(net); err != nil {
+			return Status{}, err
+		}
+
+		if saved.Amount >= remaining.Amount {
+			date := start.AddDate(0, i+1, -1)
+			st.ProjectedDate = &date
+			st.OnTrack = !date.After(gl.Deadline)
+			break
+		}
+	}
+
+	return st, nil
+}
+
+// =============================================================================
+
+// monthsLeft counts the months started between now and the deadline, so a
+// deadline later within the current month still leaves one month.
+func monthsLeft(now time.Time, deadline time.Time) int {
+	if !deadline.After(now) {
+		return 0
+	}
+
+	months := (deadline.Year()-now.Year())*12 + int(deadline.Month()-now.Month())
+	if now.AddDate(0, months, 0).Before(deadline) {
+		months++
+	}
+
+	return months
+}
@@ -0,0 +1,142 @@
+package goal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/goal"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_StatusOf(t *testing.T) {
+	eur := func(amount int64) money.Money {
+		return money.Money{Amount: amount, Currency: "EUR"}
+	}
+
+	// months projects the same net for a year.
+	months := func(net int64) []money.Money {
+		nets := make([]money.Money, 12)
+		for i := range nets {
+			nets[i] = eur(net)
+		}
+		return nets
+	}
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	ptr := func(t time.Time) *time.Time {
+		return &t
+	}
+
+	now := time.Date(2019, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name string
+		gl   goal.Goal
+		nets []money.Money
+		exp  goal.Status
+	}{
+		{
+			name: "reached",
+			gl:   goal.Goal{Target: eur(100000), Saved: eur(120000), Deadline: date(2019, time.December, 31)},
+			nets: months(10000),
+			exp: goal.Status{
+				Remaining:       eur(0),
+				PercentReached:  120,
+				Reached:         true,
+				MonthsLeft:      10,
+				RequiredMonthly: eur(0),
+				OnTrack:         true,
+			},
+		},
+		{
+			name: "spread",
+			gl:   goal.Goal{Target: eur(100000), Saved: eur(0), Deadline: date(2019, time.May, 31)},
+			nets: months(50000),
+			exp: goal.Status{
+				Remaining:       eur(100000),
+				MonthsLeft:      3,
+				RequiredMonthly: eur(33334),
+				ProjectedDate:   ptr(date(2019, time.April, 30)),
+				OnTrack:         true,
+			},
+		},
+		{
+			name: "projectedLate",
+			gl:   goal.Goal{Target: eur(100000), Saved: eur(33333), Deadline: date(2019, time.May, 31)},
+			nets: months(20000),
+			exp: goal.Status{
+				Remaining:       eur(66667),
+				PercentReached:  33.33,
+				MonthsLeft:      3,
+				RequiredMonthly: eur(22223),
+				ProjectedDate:   ptr(date(2019, time.June, 30)),
+			},
+		},
+		{
+			name: "pastDeadline",
+			gl:   goal.Goal{Target: eur(100000), Saved: eur(50000), Deadline: date(2019, time.March, 1)},
+			nets: months(10000),
+			exp: goal.Status{
+				Remaining:       eur(50000),
+				PercentReached:  50,
+				RequiredMonthly: eur(50000),
+				ProjectedDate:   ptr(date(2019, time.July, 31)),
+			},
+		},
+		{
+			name: "lastMonth",
+			gl:   goal.Goal{Target: eur(100000), Saved: eur(90000), Deadline: date(2019, time.March, 20)},
+			nets: months(10000),
+			exp: goal.Status{
+				Remaining:       eur(10000),
+				PercentReached:  90,
+				MonthsLeft:      1,
+				RequiredMonthly: eur(10000),
+				ProjectedDate:   ptr(date(2019, time.March, 31)),
+			},
+		},
+		{
+			name: "never",
+			gl:   goal.Goal{Target: eur(100000), Saved: eur(0), Deadline: date(2019, time.December, 31)},
+			nets: months(-5000),
+			exp: goal.Status{
+				Remaining:       eur(100000),
+				MonthsLeft:      10,
+				RequiredMonthly: eur(10000),
+			},
+		},
+	}
+
+	t.Log("Given the need to know how far a goal is from its target.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s goal.", testID, tst.name)
+				{
+					got, err := goal.StatusOf(tst.gl, tst.nets, now)
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to get the status : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to get the status.", success, testID)
+
+					tst.exp.Goal = tst.gl
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected status. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected status.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package goal
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/goal/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Goal represents an amount of money a user wants to have saved by a
+// deadline.
+type Goal struct {
+	ID          string      `json:"id"`                   // Unique identifier.
+	UserID      string      `json:"user_id"`              // ID of the user who owns the goal.
+	AccountID   string      `json:"account_id,omitempty"` // ID of the account the savings are kept in.
+	Name        string      `json:"name"`                 // Display name of the goal.
+	Target      money.Money `json:"target"`               // Amount to save.
+	Deadline    time.Time   `json:"deadline"`             // When the target should be reached.
+	Saved       money.Money `json:"saved"`                // Sum of the contributions so far.
+	DateCreated time.Time   `json:"date_created"`         // When the goal was added.
+	DateUpdated time.Time   `json:"date_updated"`         // When the goal record was last modified.
+}
+
+// NewGoal is what we require from clients when adding a Goal.
+type NewGoal struct {
+	UserID    string      `json:"user_id" validate:"required"`
+	AccountID string      `json:"account_id"`
+	Name      string      `json:"name" validate:"required"`
+	Target    money.Money `json:"target"`
+	Deadline  time.Time   `json:"deadline" validate:"required"`
+}
+
+// UpdateGoal defines what information may be provided to modify an existing
+// Goal. All fields are optional so clients can send just the fields they want
+// changed. It uses pointer fields so we can differentiate between a field that
+// was not provided and a field that was provided as explicitly blank. An empty
+// account ID unlinks the goal from its account.
+type UpdateGoal struct {
+	AccountID *string      `json:"account_id"`
+	Name      *string      `json:"name" validate:"omitempty,min=1"`
+	Target    *money.Money `json:"target"`
+	Deadline  *time.Time   `json:"deadline"`
+}
+
+// Contribution represents money put towards a goal, or taken out of it when
+// the amount is negative.
+type Contribution struct {
+	ID              string      `json:"id"`               // Unique identifier.
+	GoalID          string      `json:"goal_id"`          // ID of the goal contributed to.
+	Amount          money.Money `json:"amount"`           // Amount put towards the goal.
+	DateContributed time.Time   `json:"date_contributed"` // When the money was set aside.
+	DateCreated     time.Time   `json:"date_created"`     // When the contribution was recorded.
+}
+
+// NewContribution is what we require from clients when recording a
+// Contribution. The date defaults to the time of the request.
+type NewContribution struct {
+	Amount money.Money `json:"amount"`
+	Date   time.Time   `json:"date"`
+}
+
+// Status represents how far a goal is from its target and whether the
+// recurring incomes and expenses of the user get it there by the deadline.
+type Status struct {
+	Goal            Goal        `json:"goal"`                     // Goal the status is for.
+	Remaining       money.Money `json:"remaining"`                // Target minus saved, zero once reached.
+	PercentReached  float64     `json:"percent_reached"`          // Saved as a percentage of the target.
+	Reached         bool        `json:"reached"`                  // Saved got to the target.
+	MonthsLeft      int         `json:"months_left"`              // Months started before the deadline, zero once it passed.
+	RequiredMonthly money.Money `json:"required_monthly"`         // Contribution needed every month left to reach the target.
+	ProjectedDate   *time.Time  `json:"projected_date,omitempty"` // End of the month the projected net cash flow covers the remaining amount.
+	OnTrack         bool        `json:"on_track"`                 // Goal is reached or projected to be by the deadline.
+}
+
+// =============================================================================
+
+func toGoal(dbGl db.Goal) Goal {
+	return Goal{
+		ID:          dbGl.ID,
+		UserID:      dbGl.UserID,
+		AccountID:   dbGl.AccountID.String,
+		Name:        dbGl.Name,
+		Target:      money.Money{Amount: dbGl.Target, Currency: dbGl.Currency},
+		Deadline:    dbGl.Deadline,
+		Saved:       money.Money{Amount: dbGl.Saved, Currency: dbGl.Currency},
+		DateCreated: dbGl.DateCreated,
+		DateUpdated: dbGl.DateUpdated,
+	}
+}
+
+func toGoalSlice(dbGls []db.Goal) []Goal {
+	gls := make([]Goal, len(dbGls))
+	for i, dbGl := range dbGls {
+		gls[i] = toGoal(dbGl)
+	}
+	return gls
+}
+
+func toContribution(dbCtb db.Contribution, currency string) Contribution {
+	return Contribution{
+		ID:              dbCtb.ID,
+		GoalID:          dbCtb.GoalID,
+		Amount:          money.Money{Amount: dbCtb.Amount, Currency: currency},
+		DateContributed: dbCtb.DateContributed,
+		DateCreated:     dbCtb.DateCreated,
+	}
+}
+
+func toContributionSlice(dbCtbs []db.Contribution, currency string) []Contribution {
+	ctbs := make([]Contribution, len(dbCtbs))
+	for i, dbCtb := range dbCtbs {
+		ctbs[i] = toContribution(dbCtb, currency)
+	}
+	return ctbs
+}
@@ -0,0 +1,487 @@
+// Package db contains household related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for household access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// Create adds a Household to the database.
+func (s Store) Create(ctx context.Context, hh Household) error {
+	const q = `
+	INSERT INTO households
+		(household_id, name, date_created, date_updated)
+	VALUES
+		(:household_id, :name, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, hh); err != nil {
+		return fmt.Errorf("inserting household: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about a Household.
+func (s Store) Update(ctx context.Context, hh Household) error {
+	const q = `
+	UPDATE
+		households
+	SET
+		"name" = :name,
+		"date_updated" = :date_updated
+	WHERE
+		household_id = :household_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, hh); err != nil {
+		return fmt.Errorf("updating household householdID[%s]: %w", hh.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the household identified by a given ID along with its
+// members, splits and settlements.
+func (s Store) Delete(ctx context.Context, householdID string) error {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+	}{
+		HouseholdID: householdID,
+	}
+
+	const q = `
+	DELETE FROM
+		households
+	WHERE
+		household_id = :household_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting household householdID[%s]: %w", householdID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the household identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, householdID string) (Household, error) {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+	}{
+		HouseholdID: householdID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		households
+	WHERE
+		household_id = :household_id`
+
+	var hh Household
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &hh); err != nil {
+		return Household{}, fmt.Errorf("selecting household householdID[%q]: %w", householdID, err)
+	}
+
+	return hh, nil
+}
+
+// QueryByUserID finds the households a given User ID is a member of.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Household, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		h.*
+	FROM
+		households AS h
+	JOIN
+		household_members AS m USING (household_id)
+	WHERE
+		m.user_id = :user_id
+	ORDER BY
+		h.name`
+
+	var hhs []Household
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &hhs); err != nil {
+		return nil, fmt.Errorf("selecting households userID[%s]: %w", userID, err)
+	}
+
+	return hhs, nil
+}
+
+// CreateMember adds a Member to a household.
+func (s Store) CreateMember(ctx context.Context, mbr Member) error {
+	const q = `
+	INSERT INTO household_members
+		(household_id, user_id, role, date_created)
+	VALUES
+		(:household_id, :user_id, :role, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, mbr); err != nil {
+		return fmt.Errorf("inserting member userID[%s]: %w", mbr.UserID, err)
+	}
+
+	return nil
+}
+
+// UpdateMember modifies the role of a Member.
+func (s Store) UpdateMember(ctx context.Context, mbr Member) error {
+	const q = `
+	UPDATE
+		household_members
+	SET
+		"role" = :role
+	WHERE
+		household_id = :household_id AND
+		user_id = :user_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, mbr); err != nil {
+		return fmt.Errorf("updating member userID[%s]: %w", mbr.UserID, err)
+	}
+
+	return nil
+}
+
+// DeleteMember removes a user from a household.
+func (s Store) DeleteMember(ctx context.Context, householdID string, userID string) error {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+		UserID      string `db:"user_id"`
+	}{
+		HouseholdID: householdID,
+		UserID:      userID,
+	}
+
+	const q = `
+	DELETE FROM
+		household_members
+	WHERE
+		household_id = :household_id AND
+		user_id = :user_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting member userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// QueryMember finds the membership of a given User ID in a household.
+func (s Store) QueryMember(ctx context.Context, householdID string, userID string) (Member, error) {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+		UserID      string `db:"user_id"`
+	}{
+		HouseholdID: householdID,
+		UserID:      userID,
+	}
+
+	const q = `
+	SELECT
+		m.household_id, m.user_id, u.name, m.role, m.date_created
+	FROM
+		household_members AS m
+	JOIN
+		users AS u USING (user_id)
+	WHERE
+		m.household_id = :household_id AND
+		m.user_id = :user_id`
+
+	var mbr Member
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &mbr); err != nil {
+		return Member{}, fmt.Errorf("selecting member householdID[%q] userID[%q]: %w", householdID, userID, err)
+	}
+
+	return mbr, nil
+}
+
+// QueryMembers finds the members of a given Household ID.
+func (s Store) QueryMembers(ctx context.Context, householdID string) ([]Member, error) {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+	}{
+		HouseholdID: householdID,
+	}
+
+	const q = `
+	SELECT
+		m.household_id, m.user_id, u.name, m.role, m.date_created
+	FROM
+		household_members AS m
+	JOIN
+		users AS u USING (user_id)
+	WHERE
+		m.household_id = :household_id
+	ORDER BY
+		m.date_created, u.name`
+
+	var mbrs []Member
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &mbrs); err != nil {
+		return nil, fmt.Errorf("selecting members householdID[%s]: %w", householdID, err)
+	}
+
+	return mbrs, nil
+}
+
+// CreateSplit shares an expense with a household in the given shares,
+// replacing the way it was shared before.
+func (s Store) CreateSplit(ctx context.Context, spl Split, shrs []Share) error {
+	if err := s.DeleteSplit(ctx, spl.ExpenseID); err != nil {
+		return err
+	}
+
+	const q = `
+	INSERT INTO household_expenses
+		(expense_id, household_id, method, date_created)
+	VALUES
+		(:expense_id, :household_id, :method, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, spl); err != nil {
+		return fmt.Errorf("inserting split expenseID[%s]: %w", spl.ExpenseID, err)
+	}
+
+	const qs = `
+	INSERT INTO household_shares
+		(expense_id, user_id, weight)
+	VALUES
+		(:expense_id, :user_id, :weight)`
+
+	for _, shr := range shrs {
+		if err := database.NamedExecContext(ctx, s.log, s.db, qs, shr); err != nil {
+			return fmt.Errorf("inserting share userID[%s]: %w", shr.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteSplit stops sharing an expense along with its shares.
+func (s Store) DeleteSplit(ctx context.Context, expenseID string) error {
+	data := struct {
+		ExpenseID string `db:"expense_id"`
+	}{
+		ExpenseID: expenseID,
+	}
+
+	const q = `
+	DELETE FROM
+		household_expenses
+	WHERE
+		expense_id = :expense_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting split expenseID[%s]: %w", expenseID, err)
+	}
+
+	return nil
+}
+
+// MoveSplit moves the way an expense is shared, along with its shares, onto
+// another expense that is not shared yet.
+func (s Store) MoveSplit(ctx context.Context, fromExpenseID string, toExpenseID string) error {
+	data := struct {
+		FromExpenseID string `db:"from_expense_id"`
+		ToExpenseID   string `db:"to_expense_id"`
+	}{
+		FromExpenseID: fromExpenseID,
+		ToExpenseID:   toExpenseID,
+	}
+
+	const q = `
+	INSERT INTO household_expenses
+		(expense_id, household_id, method, date_created)
+	SELECT
+		CAST(:to_expense_id AS UUID), household_id, method, date_created
+	FROM
+		household_expenses
+	WHERE
+		expense_id = :from_expense_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("inserting split expenseID[%s]: %w", toExpenseID, err)
+	}
+
+	const qs = `
+	UPDATE
+		household_shares
+	SET
+		"expense_id" = :to_expense_id
+	WHERE
+		expense_id = :from_expense_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, qs, data); err != nil {
+		return fmt.Errorf("moving shares expenseID[%s]: %w", fromExpenseID, err)
+	}
+
+	return s.DeleteSplit(ctx, fromExpenseID)
+}
+
+// selectSplits selects shared expenses along with the expense fields. Expenses
+// in the trash are left out.
+const selectSplits = `
+	SELECT
+		he.expense_id, he.household_id, he.method, he.date_created,
+		e.user_id, e.name, e.currency, e.amount, e.reoccurrence, e.duration, e.reoccurrence_type, e.duration_type,
+		e.date_created AS expense_created
+	FROM
+		household_expenses AS he
+	JOIN
+		expenses AS e ON e.expense_id = he.expense_id AND e.deleted_at IS NULL`
+
+// QuerySplitByExpenseID finds how the given Expense ID is shared.
+func (s Store) QuerySplitByExpenseID(ctx context.Context, expenseID string) (Split, error) {
+	data := struct {
+		ExpenseID string `db:"expense_id"`
+	}{
+		ExpenseID: expenseID,
+	}
+
+	const q = selectSplits + `
+	WHERE
+		he.expense_id = :expense_id`
+
+	var spl Split
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &spl); err != nil {
+		return Split{}, fmt.Errorf("selecting split expenseID[%q]: %w", expenseID, err)
+	}
+
+	return spl, nil
+}
+
+// QuerySplits finds the expenses shared with a given Household ID.
+func (s Store) QuerySplits(ctx context.Context, householdID string) ([]Split, error) {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+	}{
+		HouseholdID: householdID,
+	}
+
+	const q = selectSplits + `
+	WHERE
+		he.household_id = :household_id
+	ORDER BY
+		e.date_created DESC, e.name`
+
+	var spls []Split
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &spls); err != nil {
+		return nil, fmt.Errorf("selecting splits householdID[%s]: %w", householdID, err)
+	}
+
+	return spls, nil
+}
+
+// QueryShares finds the shares of every expense shared with a given
+// Household ID. Expenses in the trash are left out.
+func (s Store) QueryShares(ctx context.Context, householdID string) ([]Share, error) {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+	}{
+		HouseholdID: householdID,
+	}
+
+	const q = `
+	SELECT
+		s.*
+	FROM
+		household_shares AS s
+	JOIN
+		household_expenses AS he ON he.expense_id = s.expense_id
+	JOIN
+		expenses AS e ON e.expense_id = s.expense_id AND e.deleted_at IS NULL
+	WHERE
+		he.household_id = :household_id
+	ORDER BY
+		s.expense_id, s.user_id`
+
+	var shrs []Share
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &shrs); err != nil {
+		return nil, fmt.Errorf("selecting shares householdID[%s]: %w", householdID, err)
+	}
+
+	return shrs, nil
+}
+
+// CreateSettlement adds a Settlement to the database.
+func (s Store) CreateSettlement(ctx context.Context, stl Settlement) error {
+	const q = `
+	INSERT INTO settlements
+		(settlement_id, household_id, from_user_id, to_user_id, currency, amount, date_settled, date_created)
+	VALUES
+		(:settlement_id, :household_id, :from_user_id, :to_user_id, :currency, :amount, :date_settled, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, stl); err != nil {
+		return fmt.Errorf("inserting settlement: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySettlements finds the settlements of a given Household ID, the most
+// recent first.
+func (s Store) QuerySettlements(ctx context.Context, householdID string) ([]Settlement, error) {
+	data := struct {
+		HouseholdID string `db:"household_id"`
+	}{
+		HouseholdID: householdID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		settlements
+	WHERE
+		household_id = :household_id
+	ORDER BY
+		date_settled DESC, date_created DESC`
+
+	var stls []Settlement
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &stls); err != nil {
+		return nil, fmt.Errorf("selecting settlements householdID[%s]: %w", householdID, err)
+	}
+
+	return stls, nil
+}
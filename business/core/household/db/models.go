@@ -0,0 +1,57 @@
+package db
+
+import "time"
+
+// Household represents a group of users sharing their expenses.
+type Household struct {
+	ID          string    `db:"household_id"` // Unique identifier.
+	Name        string    `db:"name"`         // Display name of the household.
+	DateCreated time.Time `db:"date_created"` // When the household was added.
+	DateUpdated time.Time `db:"date_updated"` // When the household record was last modified.
+}
+
+// Member represents a user taking part in a household.
+type Member struct {
+	HouseholdID string    `db:"household_id"` // ID of the household.
+	UserID      string    `db:"user_id"`      // ID of the member.
+	Name        string    `db:"name"`         // Name of the member, selected from users.
+	Role        string    `db:"role"`         // Role of the member (owner, member, viewer).
+	DateCreated time.Time `db:"date_created"` // When the user joined.
+}
+
+// Split represents an expense shared with a household, along with the
+// fields of the expense needed to know how much it adds up to.
+type Split struct {
+	ExpenseID        string    `db:"expense_id"`        // ID of the expense shared.
+	HouseholdID      string    `db:"household_id"`      // ID of the household sharing it.
+	Method           string    `db:"method"`            // How it is split (equal, percentage, exact).
+	DateCreated      time.Time `db:"date_created"`      // When the expense was shared.
+	UserID           string    `db:"user_id"`           // ID of the user who paid the expense.
+	Name             string    `db:"name"`              // Display name of the expense.
+	Currency         string    `db:"currency"`          // Currency of the expense.
+	Amount           int64     `db:"amount"`            // Amount of every occurrence of the expense.
+	Reoccurrence     int       `db:"reoccurrence"`      // Interval between occurrences.
+	Duration         int       `db:"duration"`          // How long the expense recurs for.
+	ReoccurrenceType string    `db:"reoccurrence_type"` // Unit of the interval.
+	DurationType     string    `db:"duration_type"`     // Unit of the duration.
+	ExpenseCreated   time.Time `db:"expense_created"`   // When the expense started.
+}
+
+// Share represents the weight of a member in a split expense.
+type Share struct {
+	ExpenseID string `db:"expense_id"` // ID of the expense shared.
+	UserID    string `db:"user_id"`    // ID of the member.
+	Weight    int64  `db:"weight"`     // Part of the expense relative to the other shares.
+}
+
+// Settlement represents money paid back between two members.
+type Settlement struct {
+	ID          string    `db:"settlement_id"` // Unique identifier.
+	HouseholdID string    `db:"household_id"`  // ID of the household.
+	FromUserID  string    `db:"from_user_id"`  // ID of the member who paid.
+	ToUserID    string    `db:"to_user_id"`    // ID of the member who got paid.
+	Currency    string    `db:"currency"`      // Currency of the settlement.
+	Amount      int64     `db:"amount"`        // Amount in the minor unit of the currency.
+	DateSettled time.Time `db:"date_settled"`  // When the money was paid.
+	DateCreated time.Time `db:"date_created"`  // When the settlement was recorded.
+}
@@ -0,0 +1,660 @@
+// Package household provides a core business API for groups of users sharing
+// their expenses, the way those expenses are split between the members and
+// the settlements that even them out.
+package household
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/household/db"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound         = errors.New("household not found")
+	ErrInvalidID        = errors.New("ID is not in its proper form")
+	ErrNotMember        = errors.New("user is not a member of the household")
+	ErrInsufficientRole = errors.New("member role does not allow this")
+	ErrDuplicate        = errors.New("user is already a member of the household")
+	ErrLastOwner        = errors.New("household must keep at least one owner")
+	ErrInvalidSplit     = errors.New("shares do not add up to the expense")
+	ErrSplitNotFound    = errors.New("expense is not shared with the household")
+	ErrInvalidAmount    = errors.New("amount must be greater than zero")
+)
+
+// Core manages the set of APIs for household access.
+type Core struct {
+	store   db.Store
+	user    user.Core
+	expense expense.Core
+}
+
+// NewCore constructs a core for household api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store:   db.NewStore(log, sqlxDB),
+		user:    user.NewCore(log, sqlxDB),
+		expense: expense.NewCore(log, sqlxDB),
+	}
+}
+
+// Tran returns a new Core running every write within the transaction.
+func (c Core) Tran(tx sqlx.ExtContext) Core {
+	return Core{
+		store:   c.store.Tran(tx),
+		user:    c.user,
+		expense: c.expense,
+	}
+}
+
+// Create adds a Household to the database with the given user as its owner.
+// It returns the created Household with fields like ID and DateCreated
+// populated.
+func (c Core) Create(ctx context.Context, nh NewHousehold, ownerID string, now time.Time) (Household, error) {
+	if err := validate.Check(nh); err != nil {
+		return Household{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	owner, err := c.user.QueryByID(ctx, ownerID)
+	if err != nil {
+		return Household{}, fmt.Errorf("owner: %w", err)
+	}
+
+	dbHh := db.Household{
+		ID:          validate.GenerateID(),
+		Name:        nh.Name,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	dbMbr := db.Member{
+		HouseholdID: dbHh.ID,
+		UserID:      owner.ID,
+		Name:        owner.Name,
+		Role:        RoleOwner,
+		DateCreated: now,
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Create(ctx, dbHh); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		if err := c.store.Tran(tx).CreateMember(ctx, dbMbr); err != nil {
+			return fmt.Errorf("owner: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return Household{}, fmt.Errorf("tran: %w", err)
+	}
+
+	return toHousehold(dbHh, []db.Member{dbMbr}), nil
+}
+
+// Update modifies data about a Household. It will error if the specified ID
+// is invalid or does not reference an existing Household.
+func (c Core) Update(ctx context.Context, householdID string, uh UpdateHousehold, now time.Time) error {
+	if err := validate.CheckID(householdID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(uh); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbHh, err := c.store.QueryByID(ctx, householdID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("updating household householdID[%s]: %w", householdID, err)
+	}
+
+	if uh.Name != nil {
+		dbHh.Name = *uh.Name
+	}
+	dbHh.DateUpdated = now
+
+	if err := c.store.Update(ctx, dbHh); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the household identified by a given ID along with its
+// members, splits and settlements. The expenses themselves are kept.
+func (c Core) Delete(ctx context.Context, householdID string) error {
+	if err := validate.CheckID(householdID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := c.store.Delete(ctx, householdID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the household identified by a given ID along with its
+// members.
+func (c Core) QueryByID(ctx context.Context, householdID string) (Household, error) {
+	if err := validate.CheckID(householdID); err != nil {
+		return Household{}, ErrInvalidID
+	}
+
+	dbHh, err := c.store.QueryByID(ctx, householdID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Household{}, ErrNotFound
+		}
+		return Household{}, fmt.Errorf("query: %w", err)
+	}
+
+	dbMbrs, err := c.store.QueryMembers(ctx, householdID)
+	if err != nil {
+		return Household{}, fmt.Errorf("query members: %w", err)
+	}
+
+	return toHousehold(dbHh, dbMbrs), nil
+}
+
+// QueryByUserID finds the households a given User ID is a member of.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Household, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbHhs, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	hhs := make([]Household, len(dbHhs))
+	for i, dbHh := range dbHhs {
+		dbMbrs, err := c.store.QueryMembers(ctx, dbHh.ID)
+		if err != nil {
+			return nil, fmt.Errorf("query members: %w", err)
+		}
+		hhs[i] = toHousehold(dbHh, dbMbrs)
+	}
+
+	return hhs, nil
+}
+
+// Check makes sure the user is a member of the household with at least the
+// given role.
+func (c Core) Check(ctx context.Context, householdID string, userID string, role string) error {
+	mbr, err := c.queryMember(ctx, householdID, userID)
+	if err != nil {
+		return err
+	}
+
+	if ranks[mbr.Role] < ranks[role] {
+		return ErrInsufficientRole
+	}
+
+	return nil
+}
+
+// AddMember adds a user to the household identified by a given ID.
+func (c Core) AddMember(ctx context.Context, householdID string, nm NewMember, now time.Time) (Member, error) {
+	hh, err := c.QueryByID(ctx, householdID)
+	if err != nil {
+		return Member{}, err
+	}
+
+	if err := validate.Check(nm); err != nil {
+		return Member{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	usr, err := c.user.QueryByID(ctx, nm.UserID)
+	if err != nil {
+		return Member{}, fmt.Errorf("user: %w", err)
+	}
+
+	dbMbr := db.Member{
+		HouseholdID: hh.ID,
+		UserID:      usr.ID,
+		Name:        usr.Name,
+		Role:        nm.Role,
+		DateCreated: now,
+	}
+
+	if err := c.store.CreateMember(ctx, dbMbr); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return Member{}, fmt.Errorf("create: %w", ErrDuplicate)
+		}
+		return Member{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toMember(dbMbr), nil
+}
+
+// UpdateMember changes the role of a member of the household identified by a
+// given ID. The last owner can't be demoted.
+func (c Core) UpdateMember(ctx context.Context, householdID string, userID string, um UpdateMember) error {
+	if err := validate.Check(um); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbMbr, err := c.queryMember(ctx, householdID, userID)
+	if err != nil {
+		return err
+	}
+
+	if dbMbr.Role == RoleOwner && um.Role != RoleOwner {
+		if err := c.keepOwner(ctx, householdID); err != nil {
+			return err
+		}
+	}
+
+	dbMbr.Role = um.Role
+	if err := c.store.UpdateMember(ctx, dbMbr); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember takes a user out of the household identified by a given ID.
+// The last owner can't leave. The expenses the user shared stay shared.
+func (c Core) RemoveMember(ctx context.Context, householdID string, userID string) error {
+	dbMbr, err := c.queryMember(ctx, householdID, userID)
+	if err != nil {
+		return err
+	}
+
+	if dbMbr.Role == RoleOwner {
+		if err := c.keepOwner(ctx, householdID); err != nil {
+			return err
+		}
+	}
+
+	if err := c.store.DeleteMember(ctx, householdID, userID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Split shares an expense with the household identified by a given ID,
+// replacing any earlier split of it. The user who paid the expense must be a
+// member who can share expenses, and every share must go to a member.
+func (c Core) Split(ctx context.Context, householdID string, expenseID string, ns NewSplit, now time.Time) (Split, error) {
+	hh, err := c.QueryByID(ctx, householdID)
+	if err != nil {
+		return Split{}, err
+	}
+
+	if err := validate.Check(ns); err != nil {
+		return Split{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	exp, err := c.expense.QueryByID(ctx, expenseID)
+	if err != nil {
+		return Split{}, fmt.Errorf("expense: %w", err)
+	}
+
+	if err := c.Check(ctx, hh.ID, exp.UserID, RoleMember); err != nil {
+		return Split{}, fmt.Errorf("payer: %w", err)
+	}
+
+	members := make([]string, len(hh.Members))
+	for i, mbr := range hh.Members {
+		members[i] = mbr.UserID
+	}
+
+	weights, err := ns.Weights(exp.Amount, members)
+	if err != nil {
+		return Split{}, err
+	}
+
+	dbSpl := db.Split{
+		ExpenseID:   exp.ID,
+		HouseholdID: hh.ID,
+		Method:      ns.Method,
+		DateCreated: now,
+		UserID:      exp.UserID,
+		Name:        exp.Name,
+		Currency:    exp.Amount.Currency,
+		Amount:      exp.Amount.Amount,
+	}
+
+	// Shares are kept in the order of the user IDs, the same order they are
+	// selected in, so the leftover minor units always go to the same members.
+	dbShrs := make([]db.Share, 0, len(weights))
+	for userID, w := range weights {
+		dbShrs = append(dbShrs, db.Share{ExpenseID: exp.ID, UserID: userID, Weight: w})
+	}
+	sort.Slice(dbShrs, func(i, j int) bool { return dbShrs[i].UserID < dbShrs[j].UserID })
+
+	tran := func(tx sqlx.ExtContext) error {
+		return c.store.Tran(tx).CreateSplit(ctx, dbSpl, dbShrs)
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return Split{}, fmt.Errorf("tran: %w", err)
+	}
+
+	return toSplit(dbSpl, dbShrs)
+}
+
+// Unsplit stops sharing an expense with the household identified by a given
+// ID.
+func (c Core) Unsplit(ctx context.Context, householdID string, expenseID string) error {
+	dbSpl, err := c.querySplit(ctx, expenseID)
+	if err != nil {
+		return err
+	}
+
+	if dbSpl.HouseholdID != householdID {
+		return ErrSplitNotFound
+	}
+
+	if err := c.store.DeleteSplit(ctx, expenseID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// MoveSplit moves how an expense is shared onto another expense, like when
+// merging two expenses that were entered twice. An expense that is shared
+// already keeps its own split, the other one is left as it is.
+func (c Core) MoveSplit(ctx context.Context, fromExpenseID string, toExpenseID string) error {
+	if _, err := c.querySplit(ctx, fromExpenseID); err != nil {
+		if errors.Is(err, ErrSplitNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	_, err := c.querySplit(ctx, toExpenseID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrSplitNotFound) {
+		return err
+	}
+
+	if err := c.store.MoveSplit(ctx, fromExpenseID, toExpenseID); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySplits finds the expenses shared with the household identified by a
+// given ID.
+func (c Core) QuerySplits(ctx context.Context, householdID string) ([]Split, error) {
+	if err := validate.CheckID(householdID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbSpls, byExpense, err := c.querySplits(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	spls := make([]Split, len(dbSpls))
+	for i, dbSpl := range dbSpls {
+		if spls[i], err = toSplit(dbSpl, byExpense[dbSpl.ExpenseID]); err != nil {
+			return nil, err
+		}
+	}
+
+	return spls, nil
+}
+
+// SharesExpense reports whether the expense identified by a given ID is
+// shared with a household the user is a member of.
+func (c Core) SharesExpense(ctx context.Context, expenseID string, userID string) (bool, error) {
+	dbSpl, err := c.querySplit(ctx, expenseID)
+	if err != nil {
+		if errors.Is(err, ErrSplitNotFound) || errors.Is(err, ErrInvalidID) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := c.Check(ctx, dbSpl.HouseholdID, userID, RoleViewer); err != nil {
+		if errors.Is(err, ErrNotMember) || errors.Is(err, ErrInvalidID) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Settle records money paid back between two members of the household
+// identified by a given ID.
+func (c Core) Settle(ctx context.Context, householdID string, ns NewSettlement, now time.Time) (Settlement, error) {
+	if err := validate.Check(ns); err != nil {
+		return Settlement{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if !ns.Amount.IsPositive() {
+		return Settlement{}, ErrInvalidAmount
+	}
+
+	for _, userID := range []string{ns.FromUserID, ns.ToUserID} {
+		if err := c.Check(ctx, householdID, userID, RoleViewer); err != nil {
+			return Settlement{}, fmt.Errorf("userID[%s]: %w", userID, err)
+		}
+	}
+
+	date := ns.Date
+	if date.IsZero() {
+		date = now
+	}
+
+	dbStl := db.Settlement{
+		ID:          validate.GenerateID(),
+		HouseholdID: householdID,
+		FromUserID:  ns.FromUserID,
+		ToUserID:    ns.ToUserID,
+		Currency:    ns.Amount.Currency,
+		Amount:      ns.Amount.Amount,
+		DateSettled: date,
+		DateCreated: now,
+	}
+
+	if err := c.store.CreateSettlement(ctx, dbStl); err != nil {
+		return Settlement{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toSettlement(dbStl), nil
+}
+
+// QuerySettlements finds the settlements of the household identified by a
+// given ID.
+func (c Core) QuerySettlements(ctx context.Context, householdID string) ([]Settlement, error) {
+	if err := validate.CheckID(householdID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbStls, err := c.store.QuerySettlements(ctx, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toSettlementSlice(dbStls), nil
+}
+
+// QueryBalances works out who owes whom in the household identified by a
+// given ID as of now. Every occurrence of a shared expense up to now counts:
+// the member who paid it is owed the amount and every member owes their
+// share. Settlements move the balances of both members back towards zero.
+func (c Core) QueryBalances(ctx context.Context, householdID string, now time.Time) (Balances, error) {
+	if err := validate.CheckID(householdID); err != nil {
+		return Balances{}, ErrInvalidID
+	}
+
+	dbSpls, byExpense, err := c.querySplits(ctx, householdID)
+	if err != nil {
+		return Balances{}, err
+	}
+
+	stls, err := c.QuerySettlements(ctx, householdID)
+	if err != nil {
+		return Balances{}, err
+	}
+
+	type key struct {
+		userID   string
+		currency string
+	}
+	nets := make(map[key]int64)
+
+	for _, dbSpl := range dbSpls {
+		spl, err := toSplit(dbSpl, byExpense[dbSpl.ExpenseID])
+		if err != nil {
+			return Balances{}, err
+		}
+
+		rule := recurrence.Rule{
+			Start:        dbSpl.ExpenseCreated,
+			Type:         dbSpl.ReoccurrenceType,
+			Interval:     dbSpl.Reoccurrence,
+			Duration:     dbSpl.Duration,
+			DurationType: dbSpl.DurationType,
+		}
+
+		n := int64(len(rule.Between(time.Time{}, now)))
+		if n == 0 {
+			continue
+		}
+
+		nets[key{spl.PaidBy, spl.Amount.Currency}] += spl.Amount.Amount * n
+		for _, shr := range spl.Shares {
+			nets[key{shr.UserID, spl.Amount.Currency}] -= shr.Amount.Amount * n
+		}
+	}
+
+	for _, stl := range stls {
+		if stl.DateSettled.After(now) {
+			continue
+		}
+		nets[key{stl.FromUserID, stl.Amount.Currency}] += stl.Amount.Amount
+		nets[key{stl.ToUserID, stl.Amount.Currency}] -= stl.Amount.Amount
+	}
+
+	bals := make([]Balance, 0, len(nets))
+	for k, net := range nets {
+		bals = append(bals, Balance{UserID: k.userID, Net: money.Money{Amount: net, Currency: k.currency}})
+	}
+	sort.Slice(bals, func(i, j int) bool {
+		if bals[i].Net.Currency != bals[j].Net.Currency {
+			return bals[i].Net.Currency < bals[j].Net.Currency
+		}
+		return bals[i].UserID < bals[j].UserID
+	})
+
+	b := Balances{
+		HouseholdID: householdID,
+		Members:     bals,
+		Transfers:   SettleUp(bals),
+	}
+
+	return b, nil
+}
+
+// =============================================================================
+
+// queryMember finds the membership of a user in a household.
+func (c Core) queryMember(ctx context.Context, householdID string, userID string) (db.Member, error) {
+	if err := validate.CheckID(householdID); err != nil {
+		return db.Member{}, ErrInvalidID
+	}
+
+	if err := validate.CheckID(userID); err != nil {
+		return db.Member{}, ErrInvalidID
+	}
+
+	dbMbr, err := c.store.QueryMember(ctx, householdID, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return db.Member{}, ErrNotMember
+		}
+		return db.Member{}, fmt.Errorf("query member: %w", err)
+	}
+
+	return dbMbr, nil
+}
+
+// querySplits finds the expenses shared with a household along with their
+// shares grouped by expense.
+func (c Core) querySplits(ctx context.Context, householdID string) ([]db.Split, map[string][]db.Share, error) {
+	dbSpls, err := c.store.QuerySplits(ctx, householdID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query: %w", err)
+	}
+
+	dbShrs, err := c.store.QueryShares(ctx, householdID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query shares: %w", err)
+	}
+
+	byExpense := make(map[string][]db.Share)
+	for _, dbShr := range dbShrs {
+		byExpense[dbShr.ExpenseID] = append(byExpense[dbShr.ExpenseID], dbShr)
+	}
+
+	return dbSpls, byExpense, nil
+}
+
+// querySplit finds how an expense is shared.
+func (c Core) querySplit(ctx context.Context, expenseID string) (db.Split, error) {
+	if err := validate.CheckID(expenseID); err != nil {
+		return db.Split{}, ErrInvalidID
+	}
+
+	dbSpl, err := c.store.QuerySplitByExpenseID(ctx, expenseID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return db.Split{}, ErrSplitNotFound
+		}
+		return db.Split{}, fmt.Errorf("query split: %w", err)
+	}
+
+	return dbSpl, nil
+}
+
+// keepOwner makes sure the household has another owner before one of them
+// steps down or leaves.
+func (c Core) keepOwner(ctx context.Context, householdID string) error {
+	dbMbrs, err := c.store.QueryMembers(ctx, householdID)
+	if err != nil {
+		return fmt.Errorf("query members: %w", err)
+	}
+
+	var owners int
+	for _, dbMbr := range dbMbrs {
+		if dbMbr.Role == RoleOwner {
+			owners++
+		}
+	}
+
+	if owners < 2 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package household_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/core/household"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Weights(t *testing.T) {
+	usd := func(amount int64) *money.Money {
+		return &money.Money{Amount: amount, Currency: "USD"}
+	}
+
+	members := []string{"ann", "bob", "cat"}
+	amount := *usd(10000)
+
+	tt := []struct {
+		name   string
+		split  household.NewSplit
+		exp    map[string]int64
+		expErr error
+	}{
+		{
+			name:  "equalAll",
+			split: household.NewSplit{Method: household.MethodEqual},
+			exp:   map[string]int64{"ann": 1, "bob": 1, "cat": 1},
+		},
+		{
+			name: "equalSome",
+			split: household.NewSplit{Method: household.MethodEqual, Shares: []household.NewShare{
+				{UserID: "ann"}, {UserID: "cat"},
+			}},
+			exp: map[string]int64{"ann": 1, "cat": 1},
+		},
+		{
+			name: "percentage",
+			split: household.NewSplit{Method: household.MethodPercentage, Shares: []household.NewShare{
+				{UserID: "ann", Percent: 33.33}, {UserID: "bob", Percent: 33.33}, {UserID: "cat", Percent: 33.34},
+			}},
+			exp: map[string]int64{"ann": 3333, "bob": 3333, "cat": 3334},
+		},
+		{
+			name: "percentageShort",
+			split: household.NewSplit{Method: household.MethodPercentage, Shares: []household.NewShare{
+				{UserID: "ann", Percent: 50}, {UserID: "bob", Percent: 40},
+			}},
+			expErr: household.ErrInvalidSplit,
+		},
+		{
+			name: "exact",
+			split: household.NewSplit{Method: household.MethodExact, Shares: []household.NewShare{
+				{UserID: "ann", Amount: usd(7000)}, {UserID: "bob", Amount: usd(3000)},
+			}},
+			exp: map[string]int64{"ann": 7000, "bob": 3000},
+		},
+		{
+			name: "exactOver",
+			split: household.NewSplit{Method: household.MethodExact, Shares: []household.NewShare{
+				{UserID: "ann", Amount: usd(7000)}, {UserID: "bob", Amount: usd(4000)},
+			}},
+			expErr: household.ErrInvalidSplit,
+		},
+		{
+			name: "exactCurrency",
+			split: household.NewSplit{Method: household.MethodExact, Shares: []household.NewShare{
+				{UserID: "ann", Amount: &money.Money{Amount: 10000, Currency: "EUR"}},
+			}},
+			expErr: household.ErrInvalidSplit,
+		},
+		{
+			name: "stranger",
+			split: household.NewSplit{Method: household.MethodEqual, Shares: []household.NewShare{
+				{UserID: "dan"},
+			}},
+			expErr: household.ErrNotMember,
+		},
+		{
+			name: "twice",
+			split: household.NewSplit{Method: household.MethodEqual, Shares: []household.NewShare{
+				{UserID: "ann"}, {UserID: "ann"},
+			}},
+			expErr: household.ErrInvalidSplit,
+		},
+	}
+
+	t.Log("Given the need to weigh the shares of a split expense.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s split.", testID, tst.name)
+				{
+					got, err := tst.split.Weights(amount, members)
+					if tst.expErr != nil {
+						if !errors.Is(err, tst.expErr) {
+							t.Fatalf("\t%s\tTest %d:\tShould get error %q, got %v.", failed, testID, tst.expErr, err)
+						}
+						t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+						return
+					}
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to weigh the split : %s.", failed, testID, err)
+					}
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected weights. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected weights.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_SettleUp(t *testing.T) {
+	bal := func(userID string, amount int64, currency string) household.Balance {
+		return household.Balance{UserID: userID, Net: money.Money{Amount: amount, Currency: currency}}
+	}
+	trf := func(from string, to string, amount int64, currency string) household.Transfer {
+		return household.Transfer{FromUserID: from, ToUserID: to, Amount: money.Money{Amount: amount, Currency: currency}}
+	}
+
+	tt := []struct {
+		name string
+		bals []household.Balance
+		exp  []household.Transfer
+	}{
+		{
+			name: "even",
+			bals: []household.Balance{bal("ann", 0, "USD"), bal("bob", 0, "USD")},
+			exp:  nil,
+		},
+		{
+			name: "oneDebtor",
+			bals: []household.Balance{bal("ann", 2000, "USD"), bal("bob", -1000, "USD"), bal("cat", -1000, "USD")},
+			exp:  []household.Transfer{trf("bob", "ann", 1000, "USD"), trf("cat", "ann", 1000, "USD")},
+		},
+		{
+			name: "chain",
+			bals: []household.Balance{bal("ann", 3000, "USD"), bal("bob", 1000, "USD"), bal("cat", -4000, "USD")},
+			exp:  []household.Transfer{trf("cat", "ann", 3000, "USD"), trf("cat", "bob", 1000, "USD")},
+		},
+		{
+			name: "currencies",
+			bals: []household.Balance{bal("ann", 500, "USD"), bal("bob", -500, "USD"), bal("bob", 700, "EUR"), bal("ann", -700, "EUR")},
+			exp:  []household.Transfer{trf("ann", "bob", 700, "EUR"), trf("bob", "ann", 500, "USD")},
+		},
+	}
+
+	t.Log("Given the need to settle up the balances of a household.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling %s balances.", testID, tst.name)
+				{
+					got := household.SettleUp(tst.bals)
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected transfers. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected transfers.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
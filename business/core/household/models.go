@@ -0,0 +1,356 @@
+package household
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/household/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Set of roles a member can have in a household. Owners manage the household
+// and its members, members share expenses and settle up, viewers can only
+// look.
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+// ranks orders the roles, every role is allowed what the lower ones are.
+var ranks = map[string]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleOwner:  3,
+}
+
+// Set of methods an expense can be split by.
+const (
+	MethodEqual      = "equal"
+	MethodPercentage = "percentage"
+	MethodExact      = "exact"
+)
+
+// Household represents a group of users sharing their expenses.
+type Household struct {
+	ID          string    `json:"id"`           // Unique identifier.
+	Name        string    `json:"name"`         // Display name of the household.
+	Members     []Member  `json:"members"`      // Users taking part in the household.
+	DateCreated time.Time `json:"date_created"` // When the household was added.
+	DateUpdated time.Time `json:"date_updated"` // When the household record was last modified.
+}
+
+// Member represents a user taking part in a household.
+type Member struct {
+	UserID      string    `json:"user_id"`      // ID of the member.
+	Name        string    `json:"name"`         // Name of the member.
+	Role        string    `json:"role"`         // Role of the member (owner, member, viewer).
+	DateCreated time.Time `json:"date_created"` // When the user joined.
+}
+
+// NewHousehold is what we require from clients when adding a Household. The
+// user creating it becomes its owner.
+type NewHousehold struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// UpdateHousehold defines what information may be provided to modify an
+// existing Household. All fields are optional so clients can send just the
+// fields they want changed. It uses pointer fields so we can differentiate
+// between a field that was not provided and a field that was provided as
+// explicitly blank.
+type UpdateHousehold struct {
+	Name *string `json:"name" validate:"omitempty,min=1"`
+}
+
+// NewMember is what we require from clients when adding a Member.
+type NewMember struct {
+	UserID string `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"required,oneof=owner member viewer"`
+}
+
+// UpdateMember defines what information may be provided to modify an
+// existing Member.
+type UpdateMember struct {
+	Role string `json:"role" validate:"required,oneof=owner member viewer"`
+}
+
+// Split represents an expense shared with a household and the part of every
+// occurrence each member owes.
+type Split struct {
+	ExpenseID   string      `json:"expense_id"`   // ID of the expense shared.
+	HouseholdID string      `json:"household_id"` // ID of the household sharing it.
+	PaidBy      string      `json:"paid_by"`      // ID of the user who paid the expense.
+	Name        string      `json:"name"`         // Display name of the expense.
+	Amount      money.Money `json:"amount"`       // Amount of every occurrence of the expense.
+	Method      string      `json:"method"`       // How it is split (equal, percentage, exact).
+	Shares      []Share     `json:"shares"`       // Part of the amount owed by each member.
+	DateCreated time.Time   `json:"date_created"` // When the expense was shared.
+}
+
+// Share represents the part of a split expense a member owes.
+type Share struct {
+	UserID string      `json:"user_id"` // ID of the member.
+	Amount money.Money `json:"amount"`  // Part of every occurrence of the expense.
+}
+
+// NewSplit is what we require from clients when sharing an expense. Equal
+// splits without shares are split among every member of the household.
+// Percentage splits need percentages adding up to 100 and exact splits need
+// amounts adding up to the expense.
+type NewSplit struct {
+	Method string     `json:"method" validate:"required,oneof=equal percentage exact"`
+	Shares []NewShare `json:"shares" validate:"dive"`
+}
+
+// NewShare is the part of a NewSplit for a single member.
+type NewShare struct {
+	UserID  string       `json:"user_id" validate:"required"`
+	Percent float64      `json:"percent" validate:"gte=0,lte=100"`
+	Amount  *money.Money `json:"amount"`
+}
+
+// Settlement represents money paid back between two members.
+type Settlement struct {
+	ID          string      `json:"id"`           // Unique identifier.
+	HouseholdID string      `json:"household_id"` // ID of the household.
+	FromUserID  string      `json:"from_user_id"` // ID of the member who paid.
+	ToUserID    string      `json:"to_user_id"`   // ID of the member who got paid.
+	Amount      money.Money `json:"amount"`       // Amount paid.
+	DateSettled time.Time   `json:"date_settled"` // When the money was paid.
+	DateCreated time.Time   `json:"date_created"` // When the settlement was recorded.
+}
+
+// NewSettlement is what we require from clients when recording a Settlement.
+// The date defaults to the time of the request.
+type NewSettlement struct {
+	FromUserID string      `json:"from_user_id" validate:"required"`
+	ToUserID   string      `json:"to_user_id" validate:"required,nefield=FromUserID"`
+	Amount     money.Money `json:"amount"`
+	Date       time.Time   `json:"date"`
+}
+
+// Balances represents who owes whom within a household.
+type Balances struct {
+	HouseholdID string     `json:"household_id"` // ID of the household.
+	Members     []Balance  `json:"members"`      // Net position of every member per currency.
+	Transfers   []Transfer `json:"transfers"`    // Payments that would settle every balance.
+}
+
+// Balance represents the net position of a member in a currency. It is
+// positive when the member is owed money and negative when they owe it.
+type Balance struct {
+	UserID string      `json:"user_id"`
+	Net    money.Money `json:"net"`
+}
+
+// Transfer represents a payment a member should make to another to settle up.
+type Transfer struct {
+	FromUserID string      `json:"from_user_id"`
+	ToUserID   string      `json:"to_user_id"`
+	Amount     money.Money `json:"amount"`
+}
+
+// Weights turns the shares of a split into the relative weight of every
+// member, the amount of the expense is divided by. Members lists the users
+// the shares may go to.
+func (ns NewSplit) Weights(amount money.Money, members []string) (map[string]int64, error) {
+	known := make(map[string]bool, len(members))
+	for _, m := range members {
+		known[m] = true
+	}
+
+	weights := make(map[string]int64)
+	if ns.Method == MethodEqual && len(ns.Shares) == 0 {
+		for _, m := range members {
+			weights[m] = 1
+		}
+	}
+
+	var total int64
+	for _, shr := range ns.Shares {
+		if !known[shr.UserID] {
+			return nil, fmt.Errorf("userID[%s]: %w", shr.UserID, ErrNotMember)
+		}
+		if _, exists := weights[shr.UserID]; exists {
+			return nil, fmt.Errorf("userID[%s] listed twice: %w", shr.UserID, ErrInvalidSplit)
+		}
+
+		var w int64
+		switch ns.Method {
+		case MethodEqual:
+			w = 1
+		case MethodPercentage:
+			// Percentages are kept in basis points so 33.33 stays exact.
+			w = int64(math.Round(shr.Percent * 100))
+		case MethodExact:
+			if shr.Amount == nil || shr.Amount.Currency != amount.Currency || shr.Amount.IsNegative() {
+				return nil, fmt.Errorf("userID[%s] amount: %w", shr.UserID, ErrInvalidSplit)
+			}
+			w = shr.Amount.Amount
+		default:
+			return nil, fmt.Errorf("method[%s]: %w", ns.Method, ErrInvalidSplit)
+		}
+
+		weights[shr.UserID] = w
+		total += w
+	}
+
+	switch {
+	case ns.Method == MethodPercentage && total != 100*100:
+		return nil, fmt.Errorf("percentages add up to %.2f: %w", float64(total)/100, ErrInvalidSplit)
+	case ns.Method == MethodExact && total != amount.Amount:
+		return nil, fmt.Errorf("amounts add up to %s: %w", money.Money{Amount: total, Currency: amount.Currency}, ErrInvalidSplit)
+	case len(weights) == 0:
+		return nil, fmt.Errorf("no shares: %w", ErrInvalidSplit)
+	}
+
+	return weights, nil
+}
+
+// SettleUp works out the payments that bring every balance back to zero,
+// currency by currency. The members owing the most pay the members owed the
+// most first, which keeps the number of payments low.
+func SettleUp(bals []Balance) []Transfer {
+	type position struct {
+		userID string
+		amount int64
+	}
+
+	creditors := make(map[string][]position)
+	debtors := make(map[string][]position)
+	for _, b := range bals {
+		switch {
+		case b.Net.IsPositive():
+			creditors[b.Net.Currency] = append(creditors[b.Net.Currency], position{b.UserID, b.Net.Amount})
+		case b.Net.IsNegative():
+			debtors[b.Net.Currency] = append(debtors[b.Net.Currency], position{b.UserID, -b.Net.Amount})
+		}
+	}
+
+	currencies := make([]string, 0, len(debtors))
+	for cur := range debtors {
+		currencies = append(currencies, cur)
+	}
+	sort.Strings(currencies)
+
+	byAmount := func(ps []position) {
+		sort.Slice(ps, func(i, j int) bool {
+			if ps[i].amount != ps[j].amount {
+				return ps[i].amount > ps[j].amount
+			}
+			return ps[i].userID < ps[j].userID
+		})
+	}
+
+	var trfs []Transfer
+	for _, cur := range currencies {
+		cs, ds := creditors[cur], debtors[cur]
+		byAmount(cs)
+		byAmount(ds)
+
+		for i, j := 0, 0; i < len(ds) && j < len(cs); {
+			amount := ds[i].amount
+			if cs[j].amount < amount {
+				amount = cs[j].amount
+			}
+
+			trfs = append(trfs, Transfer{
+				FromUserID: ds[i].userID,
+				ToUserID:   cs[j].userID,
+				Amount:     money.Money{Amount: amount, Currency: cur},
+			})
+
+			ds[i].amount -= amount
+			cs[j].amount -= amount
+			if ds[i].amount == 0 {
+				i++
+			}
+			if cs[j].amount == 0 {
+				j++
+			}
+		}
+	}
+
+	return trfs
+}
+
+// =============================================================================
+
+func toHousehold(dbHh db.Household, dbMbrs []db.Member) Household {
+	mbrs := make([]Member, len(dbMbrs))
+	for i, dbMbr := range dbMbrs {
+		mbrs[i] = toMember(dbMbr)
+	}
+
+	return Household{
+		ID:          dbHh.ID,
+		Name:        dbHh.Name,
+		Members:     mbrs,
+		DateCreated: dbHh.DateCreated,
+		DateUpdated: dbHh.DateUpdated,
+	}
+}
+
+func toMember(dbMbr db.Member) Member {
+	return Member{
+		UserID:      dbMbr.UserID,
+		Name:        dbMbr.Name,
+		Role:        dbMbr.Role,
+		DateCreated: dbMbr.DateCreated,
+	}
+}
+
+func toSplit(dbSpl db.Split, dbShrs []db.Share) (Split, error) {
+	amount := money.Money{Amount: dbSpl.Amount, Currency: dbSpl.Currency}
+
+	ratios := make([]int64, len(dbShrs))
+	for i, dbShr := range dbShrs {
+		ratios[i] = dbShr.Weight
+	}
+
+	parts, err := amount.Allocate(ratios...)
+	if err != nil {
+		return Split{}, fmt.Errorf("expenseID[%s]: %w", dbSpl.ExpenseID, err)
+	}
+
+	shrs := make([]Share, len(dbShrs))
+	for i, dbShr := range dbShrs {
+		shrs[i] = Share{UserID: dbShr.UserID, Amount: parts[i]}
+	}
+
+	spl := Split{
+		ExpenseID:   dbSpl.ExpenseID,
+		HouseholdID: dbSpl.HouseholdID,
+		PaidBy:      dbSpl.UserID,
+		Name:        dbSpl.Name,
+		Amount:      amount,
+		Method:      dbSpl.Method,
+		Shares:      shrs,
+		DateCreated: dbSpl.DateCreated,
+	}
+
+	return spl, nil
+}
+
+func toSettlement(dbStl db.Settlement) Settlement {
+	return Settlement{
+		ID:          dbStl.ID,
+		HouseholdID: dbStl.HouseholdID,
+		FromUserID:  dbStl.FromUserID,
+		ToUserID:    dbStl.ToUserID,
+		Amount:      money.Money{Amount: dbStl.Amount, Currency: dbStl.Currency},
+		DateSettled: dbStl.DateSettled,
+		DateCreated: dbStl.DateCreated,
+	}
+}
+
+func toSettlementSlice(dbStls []db.Settlement) []Settlement {
+	stls := make([]Settlement, len(dbStls))
+	for i, dbStl := range dbStls {
+		stls[i] = toSettlement(dbStl)
+	}
+	return stls
+}
@@ -0,0 +1,113 @@
+package income
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/batch"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+)
+
+// BatchOperation is a single change of a batch of incomes. New is set to create
+// an income and Update to update one, the one identified by ID at Version when
+// it is not zero.
+type BatchOperation struct {
+	Op      string
+	ID      string
+	Version int64
+	New     *NewIncome
+	Update  *UpdateIncome
+}
+
+// Batch applies the operations in order within a single transaction and
+// reports on each of them. Every operation is validated before any is run.
+// In atomic mode the first failure rolls back the whole batch and
+// batch.ErrFailed is returned along with the results.
+func (c Core) Batch(ctx context.Context, mode string, ops []BatchOperation, now time.Time) ([]batch.Result, error) {
+	mode, err := batch.Check(mode, len(ops))
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		errs[i] = op.check()
+	}
+
+	var results []batch.Result
+	tran := func(tx sqlx.ExtContext) error {
+		tc := c.Tran(tx)
+
+		savepoint := func(fn func() error) error {
+			return tc.store.WithinSavepoint(ctx, fn)
+		}
+
+		apply := func(i int) (string, error) {
+			return tc.apply(ctx, ops[i], now)
+		}
+
+		var err error
+		results, err = batch.Run(mode, errs, savepoint, apply)
+		return err
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		if errors.Is(err, batch.ErrFailed) {
+			return results, batch.ErrFailed
+		}
+		return nil, fmt.Errorf("tran: %w", err)
+	}
+
+	return results, nil
+}
+
+// =============================================================================
+
+// check validates an operation before any of the batch is run.
+func (op BatchOperation) check() error {
+	switch op.Op {
+	case batch.OpCreate:
+		if op.New == nil {
+			return batch.ErrMissingData
+		}
+		return validate.Check(*op.New)
+
+	case batch.OpUpdate:
+		if err := validate.CheckID(op.ID); err != nil {
+			return ErrInvalidID
+		}
+		if op.Update == nil {
+			return batch.ErrMissingData
+		}
+		return validate.Check(*op.Update)
+
+	case batch.OpDelete:
+		if err := validate.CheckID(op.ID); err != nil {
+			return ErrInvalidID
+		}
+		return nil
+	}
+
+	return batch.ErrInvalidOp
+}
+
+// apply runs an operation, returning the ID of the income it was about.
+func (c Core) apply(ctx context.Context, op BatchOperation, now time.Time) (string, error) {
+	switch op.Op {
+	case batch.OpCreate:
+		inc, err := c.Create(ctx, *op.New, now)
+		if err != nil {
+			return "", err
+		}
+		return inc.ID, nil
+
+	case batch.OpUpdate:
+		return op.ID, c.Update(ctx, op.ID, *op.Update, op.Version, now)
+
+	default:
+		return op.ID, c.Delete(ctx, op.ID, now)
+	}
+}
@@ -4,8 +4,12 @@ package db
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
 	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/order"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
@@ -30,7 +34,7 @@ func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
 // WithinTran runs passed function and do commit/rollback at the end.
 func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
 	if s.isWithinTran {
-		fn(s.db)
+		return fn(s.db)
 	}
 	return database.WithinTran(ctx, s.log, s.tr, fn)
 }
@@ -45,14 +49,57 @@ func (s Store) Tran(tx sqlx.ExtContext) Store {
 	}
 }
 
+// WithinSavepoint runs passed function within a savepoint of the transaction
+// the store runs in, so a failure only rolls back what the function did.
+func (s Store) WithinSavepoint(ctx context.Context, fn func() error) error {
+	return database.WithinSavepoint(ctx, s.log, s.db, fn)
+}
+
+// selectIncomes selects incomes along with the names of their tags.
+const selectIncomes = `
+	SELECT
+		i.*,
+		ARRAY(
+			SELECT
+				t.name
+			FROM
+				transaction_tags AS tt
+			JOIN
+				tags AS t USING (tag_id)
+			WHERE
+				tt.income_id = i.income_id
+			ORDER BY
+				t.name
+		) AS tags
+	FROM
+		incomes AS i`
+
+// whereTagged matches the incomes that have any of the :tags, or all of them
+// with :match_all. Every income matches when there are no tags.
+const whereTagged = `
+	(
+		COALESCE(CARDINALITY(CAST(:tags AS TEXT[])), 0) = 0 OR
+		(
+			SELECT
+				COUNT(DISTINCT LOWER(t.name))
+			FROM
+				transaction_tags AS tt
+			JOIN
+				tags AS t USING (tag_id)
+			WHERE
+				tt.income_id = i.income_id AND
+				LOWER(t.name) = ANY(CAST(:tags AS TEXT[]))
+		) >= CASE WHEN :match_all THEN CARDINALITY(CAST(:tags AS TEXT[])) ELSE 1 END
+	)`
+
 // Create adds an Income to the database. It returns the created Income with
 // fields like ID and DateCreated populated.
 func (s Store) Create(ctx context.Context, inc Income) error {
 	const q = `
 	INSERT INTO incomes
-		(income_id, user_id, name, category, currency, amount, reocurrence, duration, reocurrence_type, duration_type, date_created, date_updated)
+		(income_id, user_id, name, category_id, currency, amount, reoccurrence, duration, reoccurrence_type, duration_type, account_id, external_id, date_created, date_updated, version)
 	VALUES
-		(:income_id, :user_id, :name, :category, :currency, amount, reocurrence, duration, reocurrence_type, duration_type, :date_created, :date_updated)`
+		(:income_id, :user_id, :name, :category_id, :currency, :amount, :reoccurrence, :duration, :reoccurrence_type, :duration_type, :account_id, :external_id, :date_created, :date_updated, :version)`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, inc); err != nil {
 		return fmt.Errorf("inserting income: %w", err)
@@ -61,34 +108,66 @@ func (s Store) Create(ctx context.Context, inc Income) error {
 	return nil
 }
 
-// Update modifies data about a Income. It will error if the specified ID is
-// invalid or does not reference an existing Income.
+// Update modifies data about a Income that is still at the version it was
+// read at, and moves it to the next version. It returns ErrDBNotFound when
+// the Income is gone or was changed in the meantime.
 func (s Store) Update(ctx context.Context, inc Income) error {
 	const q = `
 	UPDATE
 		incomes
 	SET
 		"name" = :name,
-		"category" = :category,
+		"category_id" = :category_id,
 		"currency" = :currency,
 		"amount" = :amount,
-		"reocurrence" = :reocurrence,
+		"reoccurrence" = :reoccurrence,
 		"duration" = :duration,
-		"reocurrence_type" = :reocurrence_type,
+		"reoccurrence_type" = :reoccurrence_type,
 		"duration_type" = :duration_type,
-		"date_updated" = :date_updated
+		"account_id" = :account_id,
+		"date_updated" = :date_updated,
+		"version" = version + 1
 	WHERE
-		income_id = :income_id`
+		income_id = :income_id AND
+		version = :version AND
+		deleted_at IS NULL`
 
-	if err := database.NamedExecContext(ctx, s.log, s.db, q, inc); err != nil {
+	if err := database.NamedExecRowContext(ctx, s.log, s.db, q, inc); err != nil {
 		return fmt.Errorf("updating income incomeID[%s]: %w", inc.ID, err)
 	}
 
 	return nil
 }
 
-// Delete removes the income identified by a given ID.
-func (s Store) Delete(ctx context.Context, incomeID string) error {
+// Delete moves the income identified by a given ID to the trash. It stays
+// there until it is restored or purged.
+func (s Store) Delete(ctx context.Context, incomeID string, now time.Time) error {
+	data := struct {
+		IncomeID  string    `db:"income_id"`
+		DeletedAt time.Time `db:"deleted_at"`
+	}{
+		IncomeID:  incomeID,
+		DeletedAt: now,
+	}
+
+	const q = `
+	UPDATE
+		incomes
+	SET
+		"deleted_at" = :deleted_at
+	WHERE
+		income_id = :income_id AND
+		deleted_at IS NULL`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting income incomeID[%s]: %w", incomeID, err)
+	}
+
+	return nil
+}
+
+// Restore takes the income identified by a given ID out of the trash.
+func (s Store) Restore(ctx context.Context, incomeID string) error {
 	data := struct {
 		IncomeID string `db:"income_id"`
 	}{
@@ -96,35 +175,87 @@ func (s Store) Delete(ctx context.Context, incomeID string) error {
 	}
 
 	const q = `
-	DELETE FROM
+	UPDATE
 		incomes
+	SET
+		"deleted_at" = NULL
 	WHERE
-		income_id = :income_id`
+		income_id = :income_id AND
+		deleted_at IS NOT NULL`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
-		return fmt.Errorf("deleting income incomeID[%s]: %w", incomeID, err)
+		return fmt.Errorf("restoring income incomeID[%s]: %w", incomeID, err)
 	}
 
 	return nil
 }
 
-// Query gets all Incomes from the database.
-func (s Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Income, error) {
+// PurgePostings removes the ledger postings of the incomes that were moved to
+// the trash before the given time. The postings have no foreign key on their
+// source, so they have to go before the incomes do.
+func (s Store) PurgePostings(ctx context.Context, before time.Time) error {
 	data := struct {
-		Offset      int `db:"offset"`
-		RowsPerPage int `db:"rows_per_page"`
+		Before time.Time `db:"before"`
 	}{
-		Offset:      (pageNumber - 1) * rowsPerPage,
-		RowsPerPage: rowsPerPage,
+		Before: before,
 	}
 
 	const q = `
-	SELECT
-		*
-	FROM
+	DELETE FROM
+		transactions
+	WHERE
+		source_type = 'income' AND
+		source_id IN (SELECT income_id FROM incomes WHERE deleted_at < :before)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("purging income postings: %w", err)
+	}
+
+	return nil
+}
+
+// Purge removes for good the incomes that were moved to the trash before the
+// given time.
+func (s Store) Purge(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
 		incomes
+	WHERE
+		deleted_at < :before`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("purging incomes: %w", err)
+	}
+
+	return nil
+}
+
+// Query gets the Incomes from the database that pass the filter, in the
+// given order.
+func (s Store) Query(ctx context.Context, filter Filter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Income, error) {
+	data := map[string]any{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	wc := applyFilter(filter, data)
+
+	orderByClause, err := order.Clause(orderBy, orderByFields, "i.income_id")
+	if err != nil {
+		return nil, fmt.Errorf("ordering: %w", err)
+	}
+
+	q := selectIncomes + `
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t") + `
 	ORDER BY
-		date_created
+		` + orderByClause + `
 	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
 
 	var incs []Income
@@ -135,6 +266,76 @@ func (s Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]In
 	return incs, nil
 }
 
+// QueryAfter gets up to limit Incomes from the database that pass the filter,
+// in the given order, starting right after the cursor. A nil cursor starts
+// from the first Income. The returned cursor points at the last Income and is
+// nil when there are no more of them.
+func (s Store) QueryAfter(ctx context.Context, filter Filter, orderBy order.By, after *cursor.Cursor, limit int) ([]Income, *cursor.Cursor, error) {
+	data := map[string]any{
+		"limit": limit + 1,
+	}
+
+	wc := applyFilter(filter, data)
+
+	orderByClause, err := order.Clause(orderBy, orderByFields, "i.income_id")
+	if err != nil {
+		return nil, nil, fmt.Errorf("ordering: %w", err)
+	}
+
+	if after != nil {
+		data["cursor_value"] = after.Value
+		data["cursor_id"] = after.ID
+		wc = append(wc, after.Clause(orderByFields[orderBy.Field], "i.income_id"))
+	}
+
+	q := selectIncomes + `
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t") + `
+	ORDER BY
+		` + orderByClause + `
+	FETCH FIRST :limit ROWS ONLY`
+
+	var incs []Income
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &incs); err != nil {
+		return nil, nil, fmt.Errorf("selecting incomes: %w", err)
+	}
+
+	// One extra income was asked for to know if there is another page.
+	if len(incs) <= limit {
+		return incs, nil, nil
+	}
+
+	incs = incs[:limit]
+	last := incs[limit-1]
+	next := cursor.New(orderBy, sortValue(last, orderBy.Field), last.ID)
+
+	return incs, &next, nil
+}
+
+// Count returns the number of Incomes in the database that pass the filter.
+func (s Store) Count(ctx context.Context, filter Filter) (int, error) {
+	data := map[string]any{}
+
+	wc := applyFilter(filter, data)
+
+	q := `
+	SELECT
+		COUNT(*) AS count
+	FROM
+		incomes AS i
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t")
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("counting incomes: %w", err)
+	}
+
+	return count.Count, nil
+}
+
 // QueryByID finds the income identified by a given ID.
 func (s Store) QueryByID(ctx context.Context, incomeID string) (Income, error) {
 	data := struct {
@@ -143,13 +344,10 @@ func (s Store) QueryByID(ctx context.Context, incomeID string) (Income, error) {
 		IncomeID: incomeID,
 	}
 
-	const q = `
-	SELECT
-		*
-	FROM
-		incomes
-	WHERE 
-		income_id = :income_id`
+	const q = selectIncomes + `
+	WHERE
+		i.income_id = :income_id AND
+		i.deleted_at IS NULL`
 
 	var inc Income
 	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &inc); err != nil {
@@ -159,30 +357,99 @@ func (s Store) QueryByID(ctx context.Context, incomeID string) (Income, error) {
 	return inc, nil
 }
 
-// QueryByUserID finds the income identified by a given User ID.
-func (s Store) QueryByUserID(ctx context.Context, userID string) ([]IncomeByUser, error) {
+// QueryByExternalID finds the income of a user identified by the ID it is
+// known by at the bank it was imported from.
+func (s Store) QueryByExternalID(ctx context.Context, userID string, externalID string) (Income, error) {
+	data := struct {
+		UserID     string `db:"user_id"`
+		ExternalID string `db:"external_id"`
+	}{
+		UserID:     userID,
+		ExternalID: externalID,
+	}
+
+	const q = selectIncomes + `
+	WHERE
+		i.user_id = :user_id AND
+		i.external_id = :external_id AND
+		i.deleted_at IS NULL`
+
+	var inc Income
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &inc); err != nil {
+		return Income{}, fmt.Errorf("selecting income externalID[%q]: %w", externalID, err)
+	}
+
+	return inc, nil
+}
+
+// QueryByUserID finds the incomes of a given User ID that pass the filter,
+// in the given order.
+func (s Store) QueryByUserID(ctx context.Context, userID string, filter Filter, orderBy order.By) ([]Income, error) {
+	data := map[string]any{
+		"user_id": userID,
+	}
+
+	wc := append([]string{"i.user_id = :user_id"}, applyFilter(filter, data)...)
+
+	orderByClause, err := order.Clause(orderBy, orderByFields, "i.income_id")
+	if err != nil {
+		return nil, fmt.Errorf("ordering: %w", err)
+	}
+
+	q := selectIncomes + `
+	WHERE
+		` + strings.Join(wc, " AND\n\t\t") + `
+	ORDER BY
+		` + orderByClause
+
+	var incs []Income
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &incs); err != nil {
+		return nil, fmt.Errorf("selecting incomes userID[%s]: %w", userID, err)
+	}
+
+	return incs, nil
+}
+
+// QueryDeletedByID finds the income in the trash identified by a given ID.
+func (s Store) QueryDeletedByID(ctx context.Context, incomeID string) (Income, error) {
+	data := struct {
+		IncomeID string `db:"income_id"`
+	}{
+		IncomeID: incomeID,
+	}
+
+	const q = selectIncomes + `
+	WHERE
+		i.income_id = :income_id AND
+		i.deleted_at IS NOT NULL`
+
+	var inc Income
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &inc); err != nil {
+		return Income{}, fmt.Errorf("selecting deleted income incomeID[%q]: %w", incomeID, err)
+	}
+
+	return inc, nil
+}
+
+// QueryDeleted finds the incomes of a given User ID that are in the trash, the
+// most recently deleted first.
+func (s Store) QueryDeleted(ctx context.Context, userID string) ([]Income, error) {
 	data := struct {
 		UserID string `db:"user_id"`
 	}{
 		UserID: userID,
 	}
 
-	const q = `
-	SELECT
-		COALESCE(SUM(i2.amount), 0) as total,
-		i1.*
-	FROM
-		incomes as i1
-	LEFT JOIN
-		incomes AS i2 ON i1.user_id = i2.user_id 
+	const q = selectIncomes + `
 	WHERE
-		i1.user_id = :user_id
-	group by
-		i1.income_id`
+		i.user_id = :user_id AND
+		i.deleted_at IS NOT NULL
+	ORDER BY
+		i.deleted_at DESC`
 
-	var incs []IncomeByUser
+	var incs []Income
 	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &incs); err != nil {
-		return nil, fmt.Errorf("selecting incomes userID[%s]: %w", userID, err)
+		return nil, fmt.Errorf("selecting deleted incomes userID[%s]: %w", userID, err)
 	}
 
 	return incs, nil
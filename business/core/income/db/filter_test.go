@@ -0,0 +1,118 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_ApplyFilter(t *testing.T) {
+	str := func(v string) *string {
+		return &v
+	}
+
+	amount := func(v int64) *int64 {
+		return &v
+	}
+
+	from := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name    string
+		filter  Filter
+		expWC   []string
+		expData map[string]any
+	}{
+		{
+			name:   "empty",
+			filter: Filter{},
+			expWC:  []string{"i.deleted_at IS NULL", whereTagged},
+			expData: map[string]any{
+				"tags":      pq.StringArray(nil),
+				"match_all": false,
+			},
+		},
+		{
+			name: "every",
+			filter: Filter{
+				Tags:             []string{"work", "weekly"},
+				MatchAll:         true,
+				CategoryID:       str("49698892-62e7-4770-aeb6-f3677be0855b"),
+				Currency:         str("EUR"),
+				MinAmount:        amount(1000),
+				MaxAmount:        amount(5000),
+				From:             &from,
+				To:               &to,
+				ReoccurrenceType: str("Monthly"),
+				Name:             str("salary"),
+			},
+			expWC: []string{
+				"i.deleted_at IS NULL",
+				whereTagged,
+				"i.category_id = :category_id",
+				"i.currency = :currency",
+				"i.amount >= :min_amount",
+				"i.amount <= :max_amount",
+				"i.date_created >= :from",
+				"i.date_created < :to",
+				"i.reoccurrence_type = :reoccurrence_type",
+				"i.name ILIKE :name",
+			},
+			expData: map[string]any{
+				"tags":              pq.StringArray{"work", "weekly"},
+				"match_all":         true,
+				"category_id":       "49698892-62e7-4770-aeb6-f3677be0855b",
+				"currency":          "EUR",
+				"min_amount":        int64(1000),
+				"max_amount":        int64(5000),
+				"from":              from,
+				"to":                to,
+				"reoccurrence_type": "Monthly",
+				"name":              "%salary%",
+			},
+		},
+		{
+			name:   "likeEscaped",
+			filter: Filter{Name: str(`50%_off\now`)},
+			expWC:  []string{"i.deleted_at IS NULL", whereTagged, "i.name ILIKE :name"},
+			expData: map[string]any{
+				"tags":      pq.StringArray(nil),
+				"match_all": false,
+				"name":      `%50\%\_off\\now%`,
+			},
+		},
+	}
+
+	t.Log("Given the need to turn a filter into the conditions of a query.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s filter.", testID, tst.name)
+				{
+					data := make(map[string]any)
+					wc := applyFilter(tst.filter, data)
+
+					if diff := cmp.Diff(tst.expWC, wc); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected conditions. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected conditions.", success, testID)
+
+					if diff := cmp.Diff(tst.expData, data); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected values. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected values.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package income
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/income/db"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/sys/order"
+)
+
+// Set of fields the incomes can be ordered by.
+const (
+	OrderByDateCreated      = "date_created"
+	OrderByName             = "name"
+	OrderByAmount           = "amount"
+	OrderByCurrency         = "currency"
+	OrderByCategoryID       = "category_id"
+	OrderByReoccurrenceType = "reoccurrence_type"
+)
+
+// DefaultOrderBy lists the incomes from the oldest to the newest.
+var DefaultOrderBy = order.NewBy(OrderByDateCreated, order.ASC)
+
+// QueryFilter holds the available fields a query can be filtered on. The
+// fields left nil don't filter anything. MinAmount and MaxAmount are in the
+// minor units of Currency, which they need to make sense.
+type QueryFilter struct {
+	Tag              tag.Filter
+	CategoryID       *string `validate:"omitempty,uuid"`
+	Currency         *string `validate:"omitempty,len=3"`
+	MinAmount        *int64
+	MaxAmount        *int64
+	From             *time.Time
+	To               *time.Time
+	ReoccurrenceType *string `validate:"omitempty,oneof=Monthly Daily Once"`
+	Name             *string `validate:"omitempty,min=1"`
+}
+
+// =============================================================================
+
+func toDBFilter(filter QueryFilter) db.Filter {
+	return db.Filter{
+		Tags:             filter.Tag.Names,
+		MatchAll:         filter.Tag.All,
+		CategoryID:       filter.CategoryID,
+		Currency:         filter.Currency,
+		MinAmount:        filter.MinAmount,
+		MaxAmount:        filter.MaxAmount,
+		From:             filter.From,
+		To:               filter.To,
+		ReoccurrenceType: filter.ReoccurrenceType,
+		Name:             filter.Name,
+	}
+}
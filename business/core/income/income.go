@@ -3,12 +3,24 @@ package income
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/audit"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/fx"
 	"github.com/gloompi/ultimate-service/business/core/income/db"
+	"github.com/gloompi/ultimate-service/business/core/rule"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
 	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/order"
 	"github.com/gloompi/ultimate-service/business/sys/validate"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
@@ -16,54 +28,148 @@ import (
 
 // Set of error variables for CRUD operations.
 var (
-	ErrNotFound  = errors.New("income not found")
-	ErrInvalidID = errors.New("ID is not in its proper form")
+	ErrNotFound        = errors.New("income not found")
+	ErrInvalidID       = errors.New("ID is not in its proper form")
+	ErrInvalidAmount   = errors.New("amount can't be negative")
+	ErrDuplicate       = errors.New("income with the same external ID already exists")
+	ErrVersionConflict = errors.New("income was changed by someone else")
 )
 
 // Core manages the set of APIs for income access.
 type Core struct {
-	store db.Store
+	store    db.Store
+	user     user.Core
+	fx       fx.Core
+	account  account.Core
+	category category.Core
+	tag      tag.Core
+	audit    audit.Core
+	rule     rule.Core
 }
 
 // NewCore constructs a core for income api access.
 func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
 	return Core{
-		store: db.NewStore(log, sqlxDB),
+		store:    db.NewStore(log, sqlxDB),
+		user:     user.NewCore(log, sqlxDB),
+		fx:       fx.NewCore(log, sqlxDB),
+		account:  account.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+		tag:      tag.NewCore(log, sqlxDB),
+		audit:    audit.NewCore(log, sqlxDB),
+		rule:     rule.NewCore(log, sqlxDB),
+	}
+}
+
+// Tran returns a new Core running every write within the transaction, so
+// several incomes can be changed all at once.
+func (c Core) Tran(tx sqlx.ExtContext) Core {
+	return Core{
+		store:    c.store.Tran(tx),
+		user:     c.user,
+		fx:       c.fx,
+		account:  c.account,
+		category: c.category,
+		tag:      c.tag.Tran(tx),
+		audit:    c.audit.Tran(tx),
+		rule:     c.rule,
 	}
 }
 
 // Create adds an Income to the database. It returns the created Income with
 // fields like ID and DateCreated populated.
+// The rules of the user run over it before, see rule.Evaluate.
 func (c Core) Create(ctx context.Context, ni NewIncome, now time.Time) (Income, error) {
 	if err := validate.Check(ni); err != nil {
 		return Income{}, fmt.Errorf("validating data: %w", err)
 	}
 
+	if ni.Amount.IsNegative() {
+		return Income{}, ErrInvalidAmount
+	}
+
+	// The rules of the user get to pick the category and add tags first.
+	out, err := c.rule.Apply(ctx, ni.UserID, rule.Subject{
+		Kind:       rule.KindIncome,
+		Name:       ni.Name,
+		Amount:     ni.Amount,
+		AccountID:  ni.AccountID,
+		CategoryID: ni.CategoryID,
+		Tags:       ni.Tags,
+	})
+	if err != nil {
+		return Income{}, fmt.Errorf("rules: %w", err)
+	}
+	ni.CategoryID, ni.Tags = out.CategoryID, out.Tags
+
+	if err := c.category.Check(ctx, ni.CategoryID, ni.UserID, category.KindIncome); err != nil {
+		return Income{}, fmt.Errorf("category: %w", err)
+	}
+
+	if ni.AccountID != "" {
+		if err := c.account.Check(ctx, ni.AccountID, ni.UserID, ni.Amount.Currency); err != nil {
+			return Income{}, fmt.Errorf("account: %w", err)
+		}
+	}
+
+	// Records added after the fact, like imported ones, start on the day they
+	// happened so the ledger posts them then.
+	start := now
+	if !ni.Date.IsZero() {
+		start = ni.Date
+	}
+
 	dbInc := db.Income{
 		ID:               validate.GenerateID(),
 		Name:             ni.Name,
-		Category:         ni.Category,
-		Currency:         ni.Currency,
-		Amount:           ni.Amount,
+		CategoryID:       ni.CategoryID,
+		Currency:         ni.Amount.Currency,
+		Amount:           ni.Amount.Amount,
 		Reoccurrence:     ni.Reoccurrence,
 		Duration:         ni.Duration,
 		ReoccurrenceType: ni.ReoccurrenceType,
 		DurationType:     ni.DurationType,
 		UserID:           ni.UserID,
-		DateCreated:      now,
+		AccountID:        sql.NullString{String: ni.AccountID, Valid: ni.AccountID != ""},
+		ExternalID:       sql.NullString{String: ni.ExternalID, Valid: ni.ExternalID != ""},
+		DateCreated:      start,
 		DateUpdated:      now,
+		Version:          1,
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Create(ctx, dbInc); err != nil {
+			if errors.Is(err, database.ErrDBDuplicatedEntry) {
+				return fmt.Errorf("create: %w", ErrDuplicate)
+			}
+			return fmt.Errorf("create: %w", err)
+		}
+
+		tags, err := c.tag.Tran(tx).Set(ctx, dbInc.UserID, transaction.SourceIncome, dbInc.ID, ni.Tags, now)
+		if err != nil {
+			return fmt.Errorf("tags: %w", err)
+		}
+		dbInc.Tags = tags
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityIncome, dbInc.ID, audit.ActionCreate, nil, toIncome(dbInc), now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
 	}
 
-	if err := c.store.Create(ctx, dbInc); err != nil {
-		return Income{}, fmt.Errorf("create: %w", err)
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return Income{}, fmt.Errorf("tran: %w", err)
 	}
 
 	return toIncome(dbInc), nil
 }
 
 // Update modifies data about a Income. It will error if the specified ID is
-// invalid or does not reference an existing Income.
-func (c Core) Update(ctx context.Context, incomeID string, ui UpdateIncome, now time.Time) error {
+// invalid or does not reference an existing Income. When version is not zero the
+// Income must still be at that version, and it must not change while it is
+// being updated in any case, or ErrVersionConflict is returned.
+func (c Core) Update(ctx context.Context, incomeID string, ui UpdateIncome, version int64, now time.Time) error {
 	if err := validate.CheckID(incomeID); err != nil {
 		return ErrInvalidID
 	}
@@ -79,18 +185,26 @@ func (c Core) Update(ctx context.Context, incomeID string, ui UpdateIncome, now
 		}
 		return fmt.Errorf("updating income incomeID[%s]: %w", incomeID, err)
 	}
+	if version != 0 && dbInc.Version != version {
+		return ErrVersionConflict
+	}
+	before := toIncome(dbInc)
 
 	if ui.Name != nil {
 		dbInc.Name = *ui.Name
 	}
-	if ui.Category != nil {
-		dbInc.Category = *ui.Category
-	}
-	if ui.Currency != nil {
-		dbInc.Currency = *ui.Currency
+	if ui.CategoryID != nil {
+		if err := c.category.Check(ctx, *ui.CategoryID, dbInc.UserID, category.KindIncome); err != nil {
+			return fmt.Errorf("category: %w", err)
+		}
+		dbInc.CategoryID = *ui.CategoryID
 	}
 	if ui.Amount != nil {
-		dbInc.Amount = *ui.Amount
+		if ui.Amount.IsNegative() {
+			return ErrInvalidAmount
+		}
+		dbInc.Currency = ui.Amount.Currency
+		dbInc.Amount = ui.Amount.Amount
 	}
 	if ui.Reoccurrence != nil {
 		dbInc.Reoccurrence = *ui.Reoccurrence
@@ -104,31 +218,140 @@ func (c Core) Update(ctx context.Context, incomeID string, ui UpdateIncome, now
 	if ui.DurationType != nil {
 		dbInc.DurationType = *ui.DurationType
 	}
+	if ui.AccountID != nil {
+		dbInc.AccountID = sql.NullString{String: *ui.AccountID, Valid: *ui.AccountID != ""}
+	}
 	dbInc.DateUpdated = now
 
-	if err := c.store.Update(ctx, dbInc); err != nil {
-		return fmt.Errorf("update: %w", err)
+	// The account must still be able to hold the income when either side changed.
+	if dbInc.AccountID.Valid && (ui.AccountID != nil || ui.Amount != nil) {
+		if err := c.account.Check(ctx, dbInc.AccountID.String, dbInc.UserID, dbInc.Currency); err != nil {
+			return fmt.Errorf("account: %w", err)
+		}
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Update(ctx, dbInc); err != nil {
+			if errors.Is(err, database.ErrDBNotFound) {
+				return ErrVersionConflict
+			}
+			return fmt.Errorf("update: %w", err)
+		}
+		dbInc.Version++
+
+		if ui.Tags != nil {
+			tags, err := c.tag.Tran(tx).Set(ctx, dbInc.UserID, transaction.SourceIncome, dbInc.ID, *ui.Tags, now)
+			if err != nil {
+				return fmt.Errorf("tags: %w", err)
+			}
+			dbInc.Tags = tags
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityIncome, dbInc.ID, audit.ActionUpdate, before, toIncome(dbInc), now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes the income identified by a given ID.
-func (c Core) Delete(ctx context.Context, incomeID string) error {
-	if err := validate.CheckID(incomeID); err != nil {
-		return ErrInvalidID
+// Delete moves the income identified by a given ID to the trash. It stays
+// there until it is restored or purged.
+func (c Core) Delete(ctx context.Context, incomeID string, now time.Time) error {
+	before, err := c.QueryByID(ctx, incomeID)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.DeletedAt = &now
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Delete(ctx, incomeID, now); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityIncome, incomeID, audit.ActionDelete, before, after, now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// Restore takes the income identified by a given ID out of the trash.
+func (c Core) Restore(ctx context.Context, incomeID string, now time.Time) error {
+	before, err := c.QueryDeletedByID(ctx, incomeID)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.DeletedAt = nil
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Restore(ctx, incomeID); err != nil {
+			return fmt.Errorf("restore: %w", err)
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityIncome, incomeID, audit.ActionRestore, before, after, now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// Purge removes for good the incomes that were moved to the trash before the
+// given time, along with their postings in the ledger.
+func (c Core) Purge(ctx context.Context, before time.Time) error {
+	tran := func(tx sqlx.ExtContext) error {
+		store := c.store.Tran(tx)
+
+		if err := store.PurgePostings(ctx, before); err != nil {
+			return fmt.Errorf("postings: %w", err)
+		}
+
+		if err := store.Purge(ctx, before); err != nil {
+			return fmt.Errorf("purge: %w", err)
+		}
+
+		return nil
 	}
 
-	if err := c.store.Delete(ctx, incomeID); err != nil {
-		return fmt.Errorf("delete: %w", err)
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
 	}
 
 	return nil
 }
 
-// Query gets all Incomes from the database.
-func (c Core) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Income, error) {
-	dbIncs, err := c.store.Query(ctx, pageNumber, rowsPerPage)
+// Query gets the Incomes from the database that pass the filter, in the
+// given order.
+func (c Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Income, error) {
+	if err := validate.Check(filter); err != nil {
+		return nil, fmt.Errorf("validating filter: %w", err)
+	}
+
+	dbIncs, err := c.store.Query(ctx, toDBFilter(filter), orderBy, pageNumber, rowsPerPage)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
@@ -136,6 +359,40 @@ func (c Core) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Inc
 	return toIncomeSlice(dbIncs), nil
 }
 
+// QueryPage gets a page of the Incomes that pass the filter, in the given
+// order, starting right after the cursor token. An empty token starts from
+// the first Income.
+func (c Core) QueryPage(ctx context.Context, filter QueryFilter, orderBy order.By, token string, limit int) (cursor.Page[Income], error) {
+	if err := validate.Check(filter); err != nil {
+		return cursor.Page[Income]{}, fmt.Errorf("validating filter: %w", err)
+	}
+
+	after, err := cursor.Decode(token, orderBy)
+	if err != nil {
+		return cursor.Page[Income]{}, err
+	}
+
+	dbIncs, next, err := c.store.QueryAfter(ctx, toDBFilter(filter), orderBy, after, limit)
+	if err != nil {
+		return cursor.Page[Income]{}, fmt.Errorf("query: %w", err)
+	}
+
+	total, err := c.store.Count(ctx, toDBFilter(filter))
+	if err != nil {
+		return cursor.Page[Income]{}, fmt.Errorf("count: %w", err)
+	}
+
+	page := cursor.Page[Income]{
+		Items: toIncomeSlice(dbIncs),
+		Total: total,
+	}
+	if next != nil {
+		page.NextCursor = next.Encode()
+	}
+
+	return page, nil
+}
+
 // QueryByID finds the income identified by a given ID.
 func (c Core) QueryByID(ctx context.Context, incomeID string) (Income, error) {
 	if err := validate.CheckID(incomeID); err != nil {
@@ -153,16 +410,119 @@ func (c Core) QueryByID(ctx context.Context, incomeID string) (Income, error) {
 	return toIncome(dbInc), nil
 }
 
-// QueryByUserID finds the incomes identified by a given User ID.
-func (c Core) QueryByUserID(ctx context.Context, userID string) (IncomesByUser, error) {
+// QueryByExternalID finds the income of a user identified by the ID it is
+// known by at the bank it was imported from.
+func (c Core) QueryByExternalID(ctx context.Context, userID string, externalID string) (Income, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Income{}, ErrInvalidID
+	}
+
+	dbInc, err := c.store.QueryByExternalID(ctx, userID, externalID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Income{}, ErrNotFound
+		}
+		return Income{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toIncome(dbInc), nil
+}
+
+// QueryByUserID finds the incomes identified by a given User ID that pass the
+// filter, in the given order, along with their totals per currency. The total
+// is converted into the base currency of the user, using the exchange rate of
+// the date each income was added.
+func (c Core) QueryByUserID(ctx context.Context, userID string, filter QueryFilter, orderBy order.By) (IncomesByUser, error) {
 	if err := validate.CheckID(userID); err != nil {
 		return IncomesByUser{}, ErrInvalidID
 	}
 
-	dbInc, err := c.store.QueryByUserID(ctx, userID)
+	if err := validate.Check(filter); err != nil {
+		return IncomesByUser{}, fmt.Errorf("validating filter: %w", err)
+	}
+
+	usr, err := c.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return IncomesByUser{}, ErrNotFound
+		}
+		return IncomesByUser{}, fmt.Errorf("query user: %w", err)
+	}
+
+	dbIncs, err := c.store.QueryByUserID(ctx, userID, toDBFilter(filter), orderBy)
 	if err != nil {
 		return IncomesByUser{}, fmt.Errorf("query: %w", err)
 	}
 
-	return toIncomesByUser(dbInc), nil
+	incs := IncomesByUser{
+		Incomes: toIncomeSlice(dbIncs),
+		Total:   money.Zero(usr.BaseCurrency),
+	}
+
+	amounts := make([]money.Money, len(incs.Incomes))
+	cv := c.fx.NewConverter()
+	for i, inc := range incs.Incomes {
+		amounts[i] = inc.Amount
+
+		amount, err := cv.Convert(ctx, inc.Amount, usr.BaseCurrency, inc.DateCreated)
+		if err != nil {
+			return IncomesByUser{}, fmt.Errorf("converting incomeID[%s]: %w", inc.ID, err)
+		}
+
+		if incs.Total, err = incs.Total.Add(amount); err != nil {
+			return IncomesByUser{}, fmt.Errorf("adding incomeID[%s]: %w", inc.ID, err)
+		}
+	}
+
+	if incs.Totals, err = money.Totals(amounts); err != nil {
+		return IncomesByUser{}, fmt.Errorf("totals: %w", err)
+	}
+
+	return incs, nil
+}
+
+// QueryAllByUserID finds every income of a user. Unlike QueryByUserID there
+// is no total, so it never depends on the exchange rates being known.
+func (c Core) QueryAllByUserID(ctx context.Context, userID string) ([]Income, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbIncs, err := c.store.QueryByUserID(ctx, userID, db.Filter{}, DefaultOrderBy)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toIncomeSlice(dbIncs), nil
+}
+
+// QueryDeletedByID finds the income in the trash identified by a given ID.
+func (c Core) QueryDeletedByID(ctx context.Context, incomeID string) (Income, error) {
+	if err := validate.CheckID(incomeID); err != nil {
+		return Income{}, ErrInvalidID
+	}
+
+	dbInc, err := c.store.QueryDeletedByID(ctx, incomeID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Income{}, ErrNotFound
+		}
+		return Income{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toIncome(dbInc), nil
+}
+
+// QueryDeleted finds the incomes of a user that are in the trash.
+func (c Core) QueryDeleted(ctx context.Context, userID string) ([]Income, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbIncs, err := c.store.QueryDeleted(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toIncomeSlice(dbIncs), nil
 }
@@ -1,39 +1,48 @@
 package income
 
 import (
+	"database/sql"
 	"time"
-	"unsafe"
 
 	"github.com/gloompi/ultimate-service/business/core/income/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
 )
 
 // Income represents an individual income.
 type Income struct {
-	ID               string    `json:"id"`                // Unique identifier.
-	Name             string    `json:"name"`              // Display name of the income.
-	Category         string    `json:"category"`          // Category of the income.
-	Currency         string    `json:"currency"`          // Currency of the income.
-	Amount           int       `json:"amount"`            // Amount of money of the income.
-	Reoccurrence     int       `json:"reoccurrence"`      // Execute transaction each day, week, month.
-	Duration         int       `json:"duration"`          // Range of time transaction needs to be happening.
-	ReoccurrenceType string    `json:"reoccurrence_type"` // Type of reoccurrence (Monthly, Daily, Once).
-	DurationType     string    `json:"duration_type"`     // Type of duration (Months, Days).
-	UserID           string    `json:"user_id"`           // ID of the user who created the income.
-	DateCreated      time.Time `json:"date_created"`      // When the income was added.
-	DateUpdated      time.Time `json:"date_updated"`      // When the income record was last modified.
+	ID               string      `json:"id"`                    // Unique identifier.
+	Name             string      `json:"name"`                  // Display name of the income.
+	CategoryID       string      `json:"category_id"`           // ID of the category of the income.
+	Amount           money.Money `json:"amount"`                // Amount of money of the income.
+	Reoccurrence     int         `json:"reoccurrence"`          // Execute transaction each day, week, month.
+	Duration         int         `json:"duration"`              // Range of time transaction needs to be happening.
+	ReoccurrenceType string      `json:"reoccurrence_type"`     // Type of reoccurrence (Monthly, Daily, Once).
+	DurationType     string      `json:"duration_type"`         // Type of duration (Months, Days).
+	UserID           string      `json:"user_id"`               // ID of the user who created the income.
+	AccountID        string      `json:"account_id,omitempty"`  // ID of the account the money moves in or out of.
+	ExternalID       string      `json:"external_id,omitempty"` // ID the income is known by at the bank it was imported from.
+	Tags             []string    `json:"tags"`                  // Names of the tags of the income.
+	DateCreated      time.Time   `json:"date_created"`          // When the income was added.
+	DateUpdated      time.Time   `json:"date_updated"`          // When the income record was last modified.
+	DeletedAt        *time.Time  `json:"deleted_at,omitempty"`  // When the income was moved to the trash.
+	Version          int64       `json:"version"`               // Incremented by every update, the ETag of the income.
 }
 
-// NewIncome is what we require from clients when adding a Income.
+// NewIncome is what we require from clients when adding a Income. The date is
+// when it first happens, when no date is provided it happens right away.
 type NewIncome struct {
-	Name             string `json:"name" validate:"required"`
-	Category         string `json:"category" validate:"required"`
-	Currency         string `json:"currency" validate:"required"`
-	Amount           int    `json:"amount" validate:"omitempty,gte=1"`
-	Reoccurrence     int    `json:"reoccurrence" validate:"omitempty,gte=1"`
-	Duration         int    `json:"duration" validate:"omitempty,gte=1"`
-	ReoccurrenceType string `json:"reoccurrence_type"`
-	DurationType     string `json:"duration_type"`
-	UserID           string `json:"user_id" validate:"required"`
+	Name             string      `json:"name" validate:"required"`
+	CategoryID       string      `json:"category_id" validate:"required"`
+	Amount           money.Money `json:"amount"`
+	Reoccurrence     int         `json:"reoccurrence" validate:"omitempty,gte=1"`
+	Duration         int         `json:"duration" validate:"omitempty,gte=1"`
+	ReoccurrenceType string      `json:"reoccurrence_type"`
+	DurationType     string      `json:"duration_type"`
+	UserID           string      `json:"user_id" validate:"required"`
+	AccountID        string      `json:"account_id"`
+	ExternalID       string      `json:"external_id" validate:"omitempty,max=255"`
+	Tags             []string    `json:"tags" validate:"dive,max=50"`
+	Date             time.Time   `json:"date"`
 }
 
 // UpdateIncome defines what information may be provided to modify an
@@ -43,31 +52,46 @@ type NewIncome struct {
 // explicitly blank. Normally we do not want to use pointers to basic types but
 // we make exceptions around marshalling/unmarshalling.
 type UpdateIncome struct {
-	Name             *string `json:"name"`
-	Category         *string `json:"category" validate:"required"`
-	Currency         *string `json:"currency" validate:"required"`
-	Amount           *int    `json:"amount" validate:"required"`
-	Reoccurrence     *int    `json:"reoccurrence"`
-	Duration         *int    `json:"duration"`
-	ReoccurrenceType *string `json:"reoccurrence_type"`
-	DurationType     *string `json:"duration_type"`
+	Name             *string      `json:"name"`
+	CategoryID       *string      `json:"category_id" validate:"required"`
+	Amount           *money.Money `json:"amount" validate:"required"`
+	Reoccurrence     *int         `json:"reoccurrence"`
+	Duration         *int         `json:"duration"`
+	ReoccurrenceType *string      `json:"reoccurrence_type"`
+	DurationType     *string      `json:"duration_type"`
+	AccountID        *string      `json:"account_id"`
+	Tags             *[]string    `json:"tags" validate:"omitempty,dive,max=50"`
 }
 
+// IncomesByUser represents the incomes of a user along with their totals per
+// currency and their total converted into the base currency of the user.
 type IncomesByUser struct {
-	Incomes []Income `json:"incomes"` // List of incomes.
-	Total   int      `json:"total"`   // Total income.
+	Incomes []Income      `json:"incomes"` // List of incomes.
+	Totals  []money.Money `json:"totals"`  // Total income per currency.
+	Total   money.Money   `json:"total"`   // Total income in the base currency of the user.
 }
 
 // =============================================================================
 
 func toIncome(dbInc db.Income) Income {
-	iu := (*Income)(unsafe.Pointer(&dbInc))
-	return *iu
-}
-
-func toIncomeByUser(dbInc db.IncomeByUser) Income {
-	iu := (*Income)(unsafe.Pointer(&dbInc))
-	return *iu
+	return Income{
+		ID:               dbInc.ID,
+		Name:             dbInc.Name,
+		CategoryID:       dbInc.CategoryID,
+		Amount:           money.Money{Amount: dbInc.Amount, Currency: dbInc.Currency},
+		Reoccurrence:     dbInc.Reoccurrence,
+		Duration:         dbInc.Duration,
+		ReoccurrenceType: dbInc.ReoccurrenceType,
+		DurationType:     dbInc.DurationType,
+		UserID:           dbInc.UserID,
+		AccountID:        dbInc.AccountID.String,
+		ExternalID:       dbInc.ExternalID.String,
+		Tags:             dbInc.Tags,
+		DateCreated:      dbInc.DateCreated,
+		DateUpdated:      dbInc.DateUpdated,
+		DeletedAt:        deletedAt(dbInc.DeletedAt),
+		Version:          dbInc.Version,
+	}
 }
 
 func toIncomeSlice(dbIncs []db.Income) []Income {
@@ -78,10 +102,11 @@ func toIncomeSlice(dbIncs []db.Income) []Income {
 	return incs
 }
 
-func toIncomesByUser(dbIncs []db.IncomeByUser) IncomesByUser {
-	income := IncomesByUser{make([]Income, len(dbIncs)), dbIncs[0].Total}
-	for i, dbInc := range dbIncs {
-		income.Incomes[i] = toIncomeByUser(dbInc)
+// deletedAt returns when a income was moved to the trash, or nil when it is
+// not in the trash.
+func deletedAt(nt sql.NullTime) *time.Time {
+	if !nt.Valid {
+		return nil
 	}
-	return income
+	return &nt.Time
 }
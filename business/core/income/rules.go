@@ -0,0 +1,65 @@
+package income
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/rule"
+)
+
+// ApplyRules runs the rules of the user over every income it has, the same
+// way they run over the ones added, and updates those that end up with a
+// different category or more tags. An income changed meanwhile is left for
+// the next run. It returns how many incomes were updated.
+func (c Core) ApplyRules(ctx context.Context, userID string, now time.Time) (int, error) {
+	rls, err := c.rule.QueryByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("query rules: %w", err)
+	}
+	if len(rls) == 0 {
+		return 0, nil
+	}
+
+	incs, err := c.QueryAllByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("query incomes: %w", err)
+	}
+
+	var n int
+	for _, inc := range incs {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		out := rule.Evaluate(rls, rule.Subject{
+			Kind:       rule.KindIncome,
+			Name:       inc.Name,
+			Amount:     inc.Amount,
+			AccountID:  inc.AccountID,
+			CategoryID: inc.CategoryID,
+			Tags:       inc.Tags,
+		})
+		if !out.Changed {
+			continue
+		}
+
+		// The amount goes along since an update always carries it.
+		upd := UpdateIncome{
+			CategoryID: &out.CategoryID,
+			Amount:     &inc.Amount,
+			Tags:       &out.Tags,
+		}
+
+		if err := c.Update(ctx, inc.ID, upd, inc.Version, now); err != nil {
+			if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return n, fmt.Errorf("update incomeID[%s]: %w", inc.ID, err)
+		}
+		n++
+	}
+
+	return n, nil
+}
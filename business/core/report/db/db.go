@@ -0,0 +1,85 @@
+// Package db contains report related aggregation functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// buckets maps every way a report can be grouped by to the expression that
+// computes the bucket of a transaction.
+var buckets = map[string]string{
+	"month":    "TO_CHAR(date_occurred, 'YYYY-MM')",
+	"year":     "TO_CHAR(date_occurred, 'YYYY')",
+	"category": "CAST(category_id AS TEXT)",
+	"currency": "currency",
+}
+
+// Store manages the set of APIs for report access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// QueryTotals adds up the incomes and expenses in the ledger of a given User
+// ID that occurred within the [from, to) range. The sums are grouped by the
+// bucket, the currency and the day, so they can be converted exactly. Debt
+// payments count as expenses. Transfers only move money between accounts and
+// are left out, and so are the postings of incomes and expenses in the trash.
+func (s Store) QueryTotals(ctx context.Context, userID string, groupBy string, from time.Time, to time.Time) ([]Total, error) {
+	bucket, exists := buckets[groupBy]
+	if !exists {
+		return nil, fmt.Errorf("unknown group by [%s]", groupBy)
+	}
+
+	data := struct {
+		UserID string    `db:"user_id"`
+		From   time.Time `db:"from"`
+		To     time.Time `db:"to"`
+	}{
+		UserID: userID,
+		From:   from,
+		To:     to,
+	}
+
+	q := `
+	SELECT
+		` + bucket + ` AS bucket,
+		currency,
+		DATE_TRUNC('day', date_occurred) AS day,
+		COALESCE(SUM(amount) FILTER (WHERE source_type = 'income'), 0) AS income,
+		COALESCE(SUM(amount) FILTER (WHERE source_type IN ('expense', 'debt_payment')), 0) AS expense
+	FROM
+		transactions AS t
+	WHERE
+		user_id = :user_id AND
+		source_type IN ('income', 'expense', 'debt_payment') AND
+		date_occurred >= :from AND
+		date_occurred < :to AND
+		NOT EXISTS (SELECT 1 FROM incomes AS i WHERE t.source_type = 'income' AND i.income_id = t.source_id AND i.deleted_at IS NOT NULL) AND
+		NOT EXISTS (SELECT 1 FROM expenses AS e WHERE t.source_type = 'expense' AND e.expense_id = t.source_id AND e.deleted_at IS NOT NULL)
+	GROUP BY
+		bucket, currency, day
+	ORDER BY
+		bucket, day, currency`
+
+	var tots []Total
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &tots); err != nil {
+		return nil, fmt.Errorf("selecting totals userID[%s]: %w", userID, err)
+	}
+
+	return tots, nil
+}
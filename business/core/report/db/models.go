@@ -0,0 +1,13 @@
+package db
+
+import "time"
+
+// Total represents the money that came in and went out for one bucket of a
+// report, in one currency on one day.
+type Total struct {
+	Bucket   string    `db:"bucket"`   // Value the transactions are grouped by.
+	Currency string    `db:"currency"` // Currency of the transactions.
+	Day      time.Time `db:"day"`      // Day the transactions occurred.
+	Income   int64     `db:"income"`   // Sum of the incomes, in the minor unit of the currency.
+	Expense  int64     `db:"expense"`  // Sum of the expenses, in the minor unit of the currency.
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Set of ways the transactions of a summary can be grouped by.
+const (
+	GroupByMonth    = "month"
+	GroupByYear     = "year"
+	GroupByCategory = "category"
+	GroupByCurrency = "currency"
+)
+
+// Summary represents the incomes and expenses of a user within a period,
+// grouped into buckets.
+type Summary struct {
+	UserID   string    `json:"user_id"`  // ID of the user the summary belongs to.
+	From     time.Time `json:"from"`     // Start of the period, inclusive.
+	To       time.Time `json:"to"`       // End of the period, exclusive.
+	GroupBy  string    `json:"group_by"` // What the buckets are grouped by (month, year, category, currency).
+	Currency string    `json:"currency"` // Base currency of the user the totals are converted into.
+	Buckets  []Bucket  `json:"buckets"`  // Totals of every bucket, in order of their key.
+	Total    Bucket    `json:"total"`    // Totals of the whole period.
+}
+
+// Bucket represents the totals of the transactions sharing the same month,
+// year, category or currency. Buckets grouped by currency keep their own
+// currency, every other bucket is in the base currency of the user.
+type Bucket struct {
+	Key         string      `json:"key"`          // Month (YYYY-MM), year (YYYY), category ID or currency of the bucket.
+	Income      money.Money `json:"income"`       // Total income of the bucket.
+	Expense     money.Money `json:"expense"`      // Total expense of the bucket.
+	Net         money.Money `json:"net"`          // Income minus expense of the bucket.
+	SavingsRate float64     `json:"savings_rate"` // Net as a percentage of the income, zero without income.
+}
+
+// Entry represents what was made and spent within a bucket on a single day,
+// in the currency it happened in and converted into the base currency of the
+// user.
+type Entry struct {
+	Bucket      string      // Month, year, category ID or currency of the bucket.
+	Income      money.Money // Income in the currency it happened in.
+	Expense     money.Money // Expense in the currency it happened in.
+	BaseIncome  money.Money // Income converted into the base currency.
+	BaseExpense money.Money // Expense converted into the base currency.
+}
+
+// =============================================================================
+
+// newBucket constructs an empty bucket in the currency.
+func newBucket(key string, currency string) Bucket {
+	return Bucket{
+		Key:     key,
+		Income:  money.Zero(currency),
+		Expense: money.Zero(currency),
+		Net:     money.Zero(currency),
+	}
+}
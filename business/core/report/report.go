@@ -0,0 +1,201 @@
+// Package report provides a core business API for summaries of the money a
+// user made and spent over a period, aggregated from the transaction ledger.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/fx"
+	"github.com/gloompi/ultimate-service/business/core/report/db"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for report operations.
+var (
+	ErrNotFound       = errors.New("user not found")
+	ErrInvalidID      = errors.New("ID is not in its proper form")
+	ErrInvalidGroupBy = errors.New("group by must be month, year, category or currency")
+	ErrInvalidRange   = errors.New("from must be before to")
+)
+
+// Core manages the set of APIs for report access.
+type Core struct {
+	store    db.Store
+	user     user.Core
+	fx       fx.Core
+	category category.Core
+}
+
+// NewCore constructs a core for report api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store:    db.NewStore(log, sqlxDB),
+		user:     user.NewCore(log, sqlxDB),
+		fx:       fx.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+	}
+}
+
+// QuerySummary reports the income, expense, net and savings rate of a user
+// within the [from, to) range, grouped by month, year, category or currency.
+// Amounts are converted into the base currency of the user with the exchange
+// rate of the day they occurred. With rollup, categories are reported under
+// their top-level parent.
+func (c Core) QuerySummary(ctx context.Context, userID string, groupBy string, from time.Time, to time.Time, rollup bool) (Summary, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Summary{}, ErrInvalidID
+	}
+
+	switch groupBy {
+	case GroupByMonth, GroupByYear, GroupByCategory, GroupByCurrency:
+	default:
+		return Summary{}, ErrInvalidGroupBy
+	}
+
+	if !from.Before(to) {
+		return Summary{}, ErrInvalidRange
+	}
+
+	usr, err := c.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return Summary{}, ErrNotFound
+		}
+		return Summary{}, fmt.Errorf("query user: %w", err)
+	}
+
+	dbTots, err := c.store.QueryTotals(ctx, userID, groupBy, from, to)
+	if err != nil {
+		return Summary{}, fmt.Errorf("query: %w", err)
+	}
+
+	var h category.Hierarchy
+	if rollup && groupBy == GroupByCategory {
+		if h, err = c.category.QueryHierarchyByUserID(ctx, userID); err != nil {
+			return Summary{}, fmt.Errorf("query categories: %w", err)
+		}
+	}
+
+	cv := c.fx.NewConverter()
+	entries := make([]Entry, len(dbTots))
+	for i, dbTot := range dbTots {
+		ent := Entry{
+			Bucket:  dbTot.Bucket,
+			Income:  money.Money{Amount: dbTot.Income, Currency: dbTot.Currency},
+			Expense: money.Money{Amount: dbTot.Expense, Currency: dbTot.Currency},
+		}
+
+		if ent.BaseIncome, err = cv.Convert(ctx, ent.Income, usr.BaseCurrency, dbTot.Day); err != nil {
+			return Summary{}, fmt.Errorf("converting bucket[%s]: %w", dbTot.Bucket, err)
+		}
+		if ent.BaseExpense, err = cv.Convert(ctx, ent.Expense, usr.BaseCurrency, dbTot.Day); err != nil {
+			return Summary{}, fmt.Errorf("converting bucket[%s]: %w", dbTot.Bucket, err)
+		}
+
+		entries[i] = ent
+	}
+
+	sum := Summary{
+		UserID:   userID,
+		From:     from,
+		To:       to,
+		GroupBy:  groupBy,
+		Currency: usr.BaseCurrency,
+	}
+
+	return Summarize(sum, entries, rollup, h)
+}
+
+// Summarize adds up the entries into the buckets and the total of the
+// summary, which must have its group by and currency set. Buckets grouped by
+// currency keep their own currency, every other bucket and the total are in
+// the base currency. With rollup, categories are reported under their
+// top-level parent in the hierarchy.
+func Summarize(sum Summary, entries []Entry, rollup bool, h category.Hierarchy) (Summary, error) {
+	keyOf := func(bucket string) string { return bucket }
+	if rollup && sum.GroupBy == GroupByCategory {
+		keyOf = h.Root
+	}
+
+	sum.Total = newBucket("", sum.Currency)
+
+	var err error
+	bkts := make(map[string]Bucket)
+	for _, ent := range entries {
+		if sum.Total, err = add(sum.Total, ent.BaseIncome, ent.BaseExpense); err != nil {
+			return Summary{}, fmt.Errorf("total: %w", err)
+		}
+
+		// Buckets of a single currency have no need to be converted.
+		income, expense := ent.BaseIncome, ent.BaseExpense
+		if sum.GroupBy == GroupByCurrency {
+			income, expense = ent.Income, ent.Expense
+		}
+
+		key := keyOf(ent.Bucket)
+		bkt, exists := bkts[key]
+		if !exists {
+			bkt = newBucket(key, income.Currency)
+		}
+		if bkts[key], err = add(bkt, income, expense); err != nil {
+			return Summary{}, fmt.Errorf("bucket[%s]: %w", key, err)
+		}
+	}
+
+	sum.Buckets = make([]Bucket, 0, len(bkts))
+	for key, bkt := range bkts {
+		bkt, err := finish(bkt)
+		if err != nil {
+			return Summary{}, fmt.Errorf("bucket[%s]: %w", key, err)
+		}
+		sum.Buckets = append(sum.Buckets, bkt)
+	}
+	sort.Slice(sum.Buckets, func(i, j int) bool { return sum.Buckets[i].Key < sum.Buckets[j].Key })
+
+	if sum.Total, err = finish(sum.Total); err != nil {
+		return Summary{}, fmt.Errorf("total: %w", err)
+	}
+
+	return sum, nil
+}
+
+// =============================================================================
+
+// add accumulates an income and an expense into the bucket.
+func add(bkt Bucket, income money.Money, expense money.Money) (Bucket, error) {
+	var err error
+	if bkt.Income, err = bkt.Income.Add(income); err != nil {
+		return Bucket{}, err
+	}
+	if bkt.Expense, err = bkt.Expense.Add(expense); err != nil {
+		return Bucket{}, err
+	}
+	return bkt, nil
+}
+
+// finish computes the net and savings rate of the bucket once every amount
+// has been accumulated. The savings rate is rounded to two decimals.
+func finish(bkt Bucket) (Bucket, error) {
+	net, err := bkt.Income.Sub(bkt.Expense)
+	if err != nil {
+		return Bucket{}, err
+	}
+	bkt.Net = net
+
+	if bkt.Income.IsPositive() {
+		rate := float64(net.Amount) / float64(bkt.Income.Amount) * 100
+		bkt.SavingsRate = math.Round(rate*100) / 100
+	}
+
+	return bkt, nil
+}
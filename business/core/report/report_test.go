@@ -0,0 +1,156 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/report"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Summarize(t *testing.T) {
+	eur := func(amount int64) money.Money {
+		return money.Money{Amount: amount, Currency: "EUR"}
+	}
+	usd := func(amount int64) money.Money {
+		return money.Money{Amount: amount, Currency: "USD"}
+	}
+
+	// bucket builds the expected totals of a bucket.
+	bucket := func(key string, income money.Money, expense money.Money, net money.Money, rate float64) report.Bucket {
+		return report.Bucket{Key: key, Income: income, Expense: expense, Net: net, SavingsRate: rate}
+	}
+
+	// EUR entries need no conversion, USD entries are converted at 0.9.
+	eurEntry := func(key string, income int64, expense int64) report.Entry {
+		return report.Entry{Bucket: key, Income: eur(income), Expense: eur(expense), BaseIncome: eur(income), BaseExpense: eur(expense)}
+	}
+	usdEntry := func(key string, income int64, expense int64) report.Entry {
+		return report.Entry{Bucket: key, Income: usd(income), Expense: usd(expense), BaseIncome: eur(income * 9 / 10), BaseExpense: eur(expense * 9 / 10)}
+	}
+
+	h := category.NewHierarchy([]category.Category{
+		{ID: "housing", Kind: category.KindExpense},
+		{ID: "rent", ParentID: "housing", Kind: category.KindExpense},
+		{ID: "deposit", ParentID: "rent", Kind: category.KindExpense},
+		{ID: "food", Kind: category.KindExpense},
+	})
+
+	tt := []struct {
+		name    string
+		groupBy string
+		rollup  bool
+		entries []report.Entry
+		buckets []report.Bucket
+		total   report.Bucket
+	}{
+		{
+			name:    "empty",
+			groupBy: report.GroupByMonth,
+			buckets: []report.Bucket{},
+			total:   bucket("", eur(0), eur(0), eur(0), 0),
+		},
+		{
+			name:    "month",
+			groupBy: report.GroupByMonth,
+			entries: []report.Entry{
+				eurEntry("2019-02", 10000, 2500),
+				eurEntry("2019-01", 30000, 10000),
+				usdEntry("2019-01", 0, 10000),
+			},
+			buckets: []report.Bucket{
+				bucket("2019-01", eur(30000), eur(19000), eur(11000), 36.67),
+				bucket("2019-02", eur(10000), eur(2500), eur(7500), 75),
+			},
+			total: bucket("", eur(40000), eur(21500), eur(18500), 46.25),
+		},
+		{
+			name:    "currency",
+			groupBy: report.GroupByCurrency,
+			entries: []report.Entry{
+				eurEntry("EUR", 30000, 10000),
+				usdEntry("USD", 20000, 10000),
+				usdEntry("USD", 0, 5000),
+			},
+			buckets: []report.Bucket{
+				bucket("EUR", eur(30000), eur(10000), eur(20000), 66.67),
+				bucket("USD", usd(20000), usd(15000), usd(5000), 25),
+			},
+			total: bucket("", eur(48000), eur(23500), eur(24500), 51.04),
+		},
+		{
+			name:    "category",
+			groupBy: report.GroupByCategory,
+			entries: []report.Entry{
+				eurEntry("rent", 0, 80000),
+				eurEntry("deposit", 0, 20000),
+				eurEntry("food", 0, 5000),
+			},
+			buckets: []report.Bucket{
+				bucket("deposit", eur(0), eur(20000), eur(-20000), 0),
+				bucket("food", eur(0), eur(5000), eur(-5000), 0),
+				bucket("rent", eur(0), eur(80000), eur(-80000), 0),
+			},
+			total: bucket("", eur(0), eur(105000), eur(-105000), 0),
+		},
+		{
+			name:    "rollup",
+			groupBy: report.GroupByCategory,
+			rollup:  true,
+			entries: []report.Entry{
+				eurEntry("rent", 0, 80000),
+				eurEntry("deposit", 0, 20000),
+				eurEntry("food", 0, 5000),
+			},
+			buckets: []report.Bucket{
+				bucket("food", eur(0), eur(5000), eur(-5000), 0),
+				bucket("housing", eur(0), eur(100000), eur(-100000), 0),
+			},
+			total: bucket("", eur(0), eur(105000), eur(-105000), 0),
+		},
+		{
+			name:    "rollupMonth",
+			groupBy: report.GroupByMonth,
+			rollup:  true,
+			entries: []report.Entry{
+				eurEntry("rent", 0, 80000),
+			},
+			buckets: []report.Bucket{
+				bucket("rent", eur(0), eur(80000), eur(-80000), 0),
+			},
+			total: bucket("", eur(0), eur(80000), eur(-80000), 0),
+		},
+	}
+
+	t.Log("Given the need to add up the ledger into a summary.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s summary.", testID, tst.name)
+				{
+					sum := report.Summary{GroupBy: tst.groupBy, Currency: "EUR"}
+
+					got, err := report.Summarize(sum, tst.entries, tst.rollup, h)
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to summarize : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to summarize.", success, testID)
+
+					exp := report.Summary{GroupBy: tst.groupBy, Currency: "EUR", Buckets: tst.buckets, Total: tst.total}
+					if diff := cmp.Diff(exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected summary. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected summary.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
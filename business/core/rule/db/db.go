@@ -0,0 +1,133 @@
+// Package db contains rule related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for rule access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create adds a Rule to the database.
+func (s Store) Create(ctx context.Context, rl Rule) error {
+	const q = `
+	INSERT INTO rules
+		(rule_id, user_id, name, kind, priority, conditions, category_id, tags, date_created, date_updated)
+	VALUES
+		(:rule_id, :user_id, :name, :kind, :priority, CAST(:conditions AS JSONB), :category_id, :tags, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rl); err != nil {
+		return fmt.Errorf("inserting rule: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about a Rule.
+func (s Store) Update(ctx context.Context, rl Rule) error {
+	const q = `
+	UPDATE
+		rules
+	SET
+		"name" = :name,
+		"priority" = :priority,
+		"conditions" = CAST(:conditions AS JSONB),
+		"category_id" = :category_id,
+		"tags" = :tags,
+		"date_updated" = :date_updated
+	WHERE
+		rule_id = :rule_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rl); err != nil {
+		return fmt.Errorf("updating rule ruleID[%s]: %w", rl.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the rule identified by a given ID.
+func (s Store) Delete(ctx context.Context, ruleID string) error {
+	data := struct {
+		RuleID string `db:"rule_id"`
+	}{
+		RuleID: ruleID,
+	}
+
+	const q = `
+	DELETE FROM
+		rules
+	WHERE
+		rule_id = :rule_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting rule ruleID[%s]: %w", ruleID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the rule identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, ruleID string) (Rule, error) {
+	data := struct {
+		RuleID string `db:"rule_id"`
+	}{
+		RuleID: ruleID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		rules
+	WHERE
+		rule_id = :rule_id`
+
+	var rl Rule
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &rl); err != nil {
+		return Rule{}, fmt.Errorf("selecting rule ruleID[%q]: %w", ruleID, err)
+	}
+
+	return rl, nil
+}
+
+// QueryByUserID gets the rules of a user in the order they run.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Rule, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		rules
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		priority, date_created, rule_id`
+
+	var rls []Rule
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &rls); err != nil {
+		return nil, fmt.Errorf("selecting rules userID[%s]: %w", userID, err)
+	}
+
+	return rls, nil
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Rule represents a rule a user set to categorize incomes or expenses.
+type Rule struct {
+	ID          string         `db:"rule_id"`      // Unique identifier.
+	UserID      string         `db:"user_id"`      // ID of the user who owns the rule.
+	Name        string         `db:"name"`         // Display name of the rule.
+	Kind        string         `db:"kind"`         // Kind of record the rule runs over (income, expense).
+	Priority    int            `db:"priority"`     // Rules run from the lowest priority up.
+	Conditions  string         `db:"conditions"`   // JSON array of the conditions a record has to meet.
+	CategoryID  sql.NullString `db:"category_id"`  // ID of the category put on the records matched.
+	Tags        pq.StringArray `db:"tags"`         // Names of the tags put on the records matched.
+	DateCreated time.Time      `db:"date_created"` // When the rule was added.
+	DateUpdated time.Time      `db:"date_updated"` // When the rule record was last modified.
+}
@@ -0,0 +1,181 @@
+package rule
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+)
+
+// fieldOps are the operators each field can be compared with.
+var fieldOps = map[string][]string{
+	FieldName:      {OpContains, OpEquals, OpStartsWith, OpEndsWith},
+	FieldAmount:    {OpEquals, OpGT, OpGTE, OpLT, OpLTE},
+	FieldCurrency:  {OpEquals},
+	FieldAccountID: {OpEquals},
+}
+
+// decimal matches the amounts conditions compare with, like "1000" or "9.99".
+var decimal = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// Evaluate runs the rules of the kind of the subject over it, from the lowest
+// priority up, and returns what it ends up with. The first rule matched that
+// has a category sets it, but only when the subject is in the fallback
+// category of its kind, so a category picked by hand is never overwritten.
+// The tags of every rule matched are added.
+func Evaluate(rules []Rule, s Subject) Outcome {
+	ordered := make([]Rule, len(rules))
+	copy(ordered, rules)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority < ordered[j].Priority
+	})
+
+	out := Outcome{
+		CategoryID: s.CategoryID,
+		Tags:       append([]string(nil), s.Tags...),
+	}
+
+	seen := make(map[string]bool, len(s.Tags))
+	for _, name := range s.Tags {
+		seen[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	categorize := isFallback(s.Kind, s.CategoryID)
+
+	for _, rl := range ordered {
+		if rl.Kind != s.Kind || !matches(rl.Conditions, s) {
+			continue
+		}
+		out.Matched = append(out.Matched, rl.ID)
+
+		if categorize && rl.CategoryID != "" {
+			if rl.CategoryID != out.CategoryID {
+				out.CategoryID = rl.CategoryID
+				out.Changed = true
+			}
+			categorize = false
+		}
+
+		for _, name := range rl.Tags {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			out.Tags = append(out.Tags, name)
+			out.Changed = true
+		}
+	}
+
+	return out
+}
+
+// =============================================================================
+
+// isFallback reports whether rules can pick the category of a record: when
+// it has none or it is in the catch-all category of its kind.
+func isFallback(kind string, categoryID string) bool {
+	switch {
+	case categoryID == "":
+		return true
+	case kind == KindIncome:
+		return categoryID == category.OtherIncomeID
+	case kind == KindExpense:
+		return categoryID == category.OtherExpensesID
+	}
+	return false
+}
+
+// matches reports whether the subject meets every condition. No conditions
+// match nothing, so a broken rule can't sweep over every record.
+func matches(conds []Condition, s Subject) bool {
+	if len(conds) == 0 {
+		return false
+	}
+
+	for _, cond := range conds {
+		if !meets(cond, s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// meets reports whether the subject meets the condition.
+func meets(cond Condition, s Subject) bool {
+	switch cond.Field {
+	case FieldName:
+		name := strings.ToLower(s.Name)
+		value := strings.ToLower(cond.Value)
+
+		switch cond.Op {
+		case OpContains:
+			return strings.Contains(name, value)
+		case OpEquals:
+			return name == value
+		case OpStartsWith:
+			return strings.HasPrefix(name, value)
+		case OpEndsWith:
+			return strings.HasSuffix(name, value)
+		}
+
+	case FieldAmount:
+		amount, ok := new(big.Rat).SetString(s.Amount.Decimal())
+		if !ok {
+			return false
+		}
+		value, ok := new(big.Rat).SetString(cond.Value)
+		if !ok {
+			return false
+		}
+
+		cmp := amount.Cmp(value)
+		switch cond.Op {
+		case OpEquals:
+			return cmp == 0
+		case OpGT:
+			return cmp > 0
+		case OpGTE:
+			return cmp >= 0
+		case OpLT:
+			return cmp < 0
+		case OpLTE:
+			return cmp <= 0
+		}
+
+	case FieldCurrency:
+		return cond.Op == OpEquals && strings.EqualFold(s.Amount.Currency, cond.Value)
+
+	case FieldAccountID:
+		return cond.Op == OpEquals && s.AccountID != "" && strings.EqualFold(s.AccountID, cond.Value)
+	}
+
+	return false
+}
+
+// checkConditions verifies that every condition compares its field with an
+// operator and a value that make sense for it.
+func checkConditions(conds []Condition) error {
+	for i, cond := range conds {
+		ok := false
+		for _, op := range fieldOps[cond.Field] {
+			if op == cond.Op {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("condition %d: %w", i, ErrInvalidOp)
+		}
+
+		if cond.Field == FieldAmount && !decimal.MatchString(cond.Value) {
+			return fmt.Errorf("condition %d: %w", i, ErrInvalidAmount)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package rule
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/rule/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Set of kinds of records rules run over.
+const (
+	KindIncome  = "income"
+	KindExpense = "expense"
+)
+
+// Set of fields of a record conditions look at.
+const (
+	FieldName      = "name"
+	FieldAmount    = "amount"
+	FieldCurrency  = "currency"
+	FieldAccountID = "account_id"
+)
+
+// Set of operators conditions compare a field with. Names are compared
+// regardless of case, amounts as decimals in major units.
+const (
+	OpContains   = "contains"
+	OpEquals     = "equals"
+	OpStartsWith = "starts_with"
+	OpEndsWith   = "ends_with"
+	OpGT         = "gt"
+	OpGTE        = "gte"
+	OpLT         = "lt"
+	OpLTE        = "lte"
+)
+
+// Rule represents a rule a user set to categorize incomes or expenses. A
+// record matches a rule when it meets every condition of it.
+type Rule struct {
+	ID          string      `json:"id"`                    // Unique identifier.
+	UserID      string      `json:"user_id"`               // ID of the user who owns the rule.
+	Name        string      `json:"name"`                  // Display name of the rule.
+	Kind        string      `json:"kind"`                  // Kind of record the rule runs over (income, expense).
+	Priority    int         `json:"priority"`              // Rules run from the lowest priority up.
+	Conditions  []Condition `json:"conditions"`            // Conditions a record has to meet.
+	CategoryID  string      `json:"category_id,omitempty"` // ID of the category put on the records matched.
+	Tags        []string    `json:"tags"`                  // Names of the tags put on the records matched.
+	DateCreated time.Time   `json:"date_created"`          // When the rule was added.
+	DateUpdated time.Time   `json:"date_updated"`          // When the rule record was last modified.
+}
+
+// Condition represents a comparison of a field of a record with a value, like
+// the name containing "SPOTIFY" or the amount being greater than 1000.
+type Condition struct {
+	Field string `json:"field" validate:"required,oneof=name amount currency account_id"`
+	Op    string `json:"op" validate:"required,oneof=contains equals starts_with ends_with gt gte lt lte"`
+	Value string `json:"value" validate:"required,max=255"`
+}
+
+// NewRule is what we require from clients when adding a Rule. A rule has to
+// put a category or at least a tag on the records it matches.
+type NewRule struct {
+	UserID     string      `json:"user_id" validate:"required"`
+	Name       string      `json:"name" validate:"required,max=100"`
+	Kind       string      `json:"kind" validate:"required,oneof=income expense"`
+	Priority   int         `json:"priority" validate:"gte=0"`
+	Conditions []Condition `json:"conditions" validate:"required,min=1,max=20,dive"`
+	CategoryID string      `json:"category_id"`
+	Tags       []string    `json:"tags" validate:"max=20,dive,max=50"`
+}
+
+// UpdateRule defines what information may be provided to modify an existing
+// Rule. All fields are optional so clients can send just the fields they want
+// changed. The kind of a rule can't be changed.
+type UpdateRule struct {
+	Name       *string      `json:"name" validate:"omitempty,min=1,max=100"`
+	Priority   *int         `json:"priority" validate:"omitempty,gte=0"`
+	Conditions *[]Condition `json:"conditions" validate:"omitempty,min=1,max=20,dive"`
+	CategoryID *string      `json:"category_id"`
+	Tags       *[]string    `json:"tags" validate:"omitempty,max=20,dive,max=50"`
+}
+
+// Subject represents the income or expense rules run over.
+type Subject struct {
+	Kind       string      // Kind of record (income, expense).
+	Name       string      // Display name of the record.
+	Amount     money.Money // Amount of money of the record.
+	AccountID  string      // ID of the account of the record, if any.
+	CategoryID string      // ID of the category of the record.
+	Tags       []string    // Names of the tags of the record.
+}
+
+// Outcome represents what a record ends up with once rules ran over it.
+type Outcome struct {
+	CategoryID string   // ID of the category of the record.
+	Tags       []string // Names of the tags of the record.
+	Matched    []string // IDs of the rules the record matched, in the order they ran.
+	Changed    bool     // Whether the category or the tags are different from before.
+}
+
+// =============================================================================
+
+func toRule(dbRl db.Rule) Rule {
+	var conds []Condition
+	if err := json.Unmarshal([]byte(dbRl.Conditions), &conds); err != nil {
+
+		// Conditions are only ever written by Create and Update, so this
+		// can't happen short of someone editing the table by hand. A rule
+		// without conditions matches nothing.
+		conds = nil
+	}
+
+	tags := make([]string, len(dbRl.Tags))
+	copy(tags, dbRl.Tags)
+
+	return Rule{
+		ID:          dbRl.ID,
+		UserID:      dbRl.UserID,
+		Name:        dbRl.Name,
+		Kind:        dbRl.Kind,
+		Priority:    dbRl.Priority,
+		Conditions:  conds,
+		CategoryID:  dbRl.CategoryID.String,
+		Tags:        tags,
+		DateCreated: dbRl.DateCreated,
+		DateUpdated: dbRl.DateUpdated,
+	}
+}
+
+func toRuleSlice(dbRls []db.Rule) []Rule {
+	rls := make([]Rule, len(dbRls))
+	for i, dbRl := range dbRls {
+		rls[i] = toRule(dbRl)
+	}
+	return rls
+}
+
+func toDBRule(rl Rule) (db.Rule, error) {
+	conds, err := json.Marshal(rl.Conditions)
+	if err != nil {
+		return db.Rule{}, fmt.Errorf("encoding conditions: %w", err)
+	}
+
+	return db.Rule{
+		ID:          rl.ID,
+		UserID:      rl.UserID,
+		Name:        rl.Name,
+		Kind:        rl.Kind,
+		Priority:    rl.Priority,
+		Conditions:  string(conds),
+		CategoryID:  sql.NullString{String: rl.CategoryID, Valid: rl.CategoryID != ""},
+		Tags:        rl.Tags,
+		DateCreated: rl.DateCreated,
+		DateUpdated: rl.DateUpdated,
+	}, nil
+}
@@ -0,0 +1,222 @@
+// Package rule provides a core business API for the rules users set to
+// categorize and tag their incomes and expenses as they come in.
+package rule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/rule/db"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound      = errors.New("rule not found")
+	ErrInvalidID     = errors.New("ID is not in its proper form")
+	ErrInvalidOp     = errors.New("operator can't be used with the field")
+	ErrInvalidAmount = errors.New("amount must be a decimal number")
+	ErrNoAction      = errors.New("rule must set a category or add a tag")
+)
+
+// Core manages the set of APIs for rule access.
+type Core struct {
+	store    db.Store
+	category category.Core
+}
+
+// NewCore constructs a core for rule api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store:    db.NewStore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+	}
+}
+
+// Create adds a Rule to the database.
+func (c Core) Create(ctx context.Context, nr NewRule, now time.Time) (Rule, error) {
+	if err := validate.Check(nr); err != nil {
+		return Rule{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	rl := Rule{
+		ID:          validate.GenerateID(),
+		UserID:      nr.UserID,
+		Name:        strings.TrimSpace(nr.Name),
+		Kind:        nr.Kind,
+		Priority:    nr.Priority,
+		Conditions:  nr.Conditions,
+		CategoryID:  nr.CategoryID,
+		Tags:        cleanTags(nr.Tags),
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.check(ctx, rl); err != nil {
+		return Rule{}, err
+	}
+
+	dbRl, err := toDBRule(rl)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	if err := c.store.Create(ctx, dbRl); err != nil {
+		return Rule{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rl, nil
+}
+
+// Update modifies data about a Rule. It will error if the specified ID is
+// invalid or does not reference an existing Rule.
+func (c Core) Update(ctx context.Context, ruleID string, ur UpdateRule, now time.Time) error {
+	if err := validate.CheckID(ruleID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(ur); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	rl, err := c.QueryByID(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if ur.Name != nil {
+		rl.Name = strings.TrimSpace(*ur.Name)
+	}
+	if ur.Priority != nil {
+		rl.Priority = *ur.Priority
+	}
+	if ur.Conditions != nil {
+		rl.Conditions = *ur.Conditions
+	}
+	if ur.CategoryID != nil {
+		rl.CategoryID = *ur.CategoryID
+	}
+	if ur.Tags != nil {
+		rl.Tags = cleanTags(*ur.Tags)
+	}
+	rl.DateUpdated = now
+
+	if err := c.check(ctx, rl); err != nil {
+		return err
+	}
+
+	dbRl, err := toDBRule(rl)
+	if err != nil {
+		return err
+	}
+
+	if err := c.store.Update(ctx, dbRl); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the rule identified by a given ID. Records it categorized
+// keep their category and tags.
+func (c Core) Delete(ctx context.Context, ruleID string) error {
+	if err := validate.CheckID(ruleID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := c.store.Delete(ctx, ruleID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the rule identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, ruleID string) (Rule, error) {
+	if err := validate.CheckID(ruleID); err != nil {
+		return Rule{}, ErrInvalidID
+	}
+
+	dbRl, err := c.store.QueryByID(ctx, ruleID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Rule{}, ErrNotFound
+		}
+		return Rule{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toRule(dbRl), nil
+}
+
+// QueryByUserID finds the rules of a user in the order they run.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Rule, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbRls, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toRuleSlice(dbRls), nil
+}
+
+// Apply runs the rules of the user over an income or expense about to be
+// added. See Evaluate for how rules change it.
+func (c Core) Apply(ctx context.Context, userID string, s Subject) (Outcome, error) {
+	rls, err := c.QueryByUserID(ctx, userID)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	return Evaluate(rls, s), nil
+}
+
+// =============================================================================
+
+// check verifies the conditions of a rule make sense, that it does something
+// and that its category can be put on the records it runs over.
+func (c Core) check(ctx context.Context, rl Rule) error {
+	if err := checkConditions(rl.Conditions); err != nil {
+		return validate.NewFieldsError("conditions", err)
+	}
+
+	if rl.CategoryID == "" && len(rl.Tags) == 0 {
+		return validate.NewFieldsError("category_id", ErrNoAction)
+	}
+
+	if rl.CategoryID != "" {
+		if err := c.category.Check(ctx, rl.CategoryID, rl.UserID, rl.Kind); err != nil {
+			return fmt.Errorf("category: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cleanTags trims the names of tags and drops the empty ones and the ones
+// repeated regardless of case.
+func cleanTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, name)
+	}
+
+	return out
+}
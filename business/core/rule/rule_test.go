@@ -0,0 +1,215 @@
+package rule_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/rule"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+const (
+	subscriptionsID = "0e3b8f4e-7b0c-4d43-9a55-0c5d1c1f6a01"
+	travelID        = "0e3b8f4e-7b0c-4d43-9a55-0c5d1c1f6a02"
+	groceriesID     = "0e3b8f4e-7b0c-4d43-9a55-0c5d1c1f6a03"
+	salaryID        = "0e3b8f4e-7b0c-4d43-9a55-0c5d1c1f6a04"
+)
+
+func Test_Evaluate(t *testing.T) {
+	rules := []rule.Rule{
+		{
+			ID:         "travel",
+			Kind:       rule.KindExpense,
+			Priority:   20,
+			Conditions: []rule.Condition{{Field: rule.FieldAmount, Op: rule.OpGT, Value: "1000"}, {Field: rule.FieldCurrency, Op: rule.OpEquals, Value: "usd"}},
+			CategoryID: travelID,
+		},
+		{
+			ID:         "spotify",
+			Kind:       rule.KindExpense,
+			Priority:   10,
+			Conditions: []rule.Condition{{Field: rule.FieldName, Op: rule.OpContains, Value: "SPOTIFY"}},
+			CategoryID: subscriptionsID,
+			Tags:       []string{"entertainment"},
+		},
+		{
+			ID:         "big",
+			Kind:       rule.KindExpense,
+			Priority:   30,
+			Conditions: []rule.Condition{{Field: rule.FieldAmount, Op: rule.OpGTE, Value: "500"}},
+			CategoryID: groceriesID,
+			Tags:       []string{"big"},
+		},
+		{
+			ID:         "salary",
+			Kind:       rule.KindIncome,
+			Conditions: []rule.Condition{{Field: rule.FieldName, Op: rule.OpContains, Value: "spotify"}},
+			CategoryID: salaryID,
+		},
+		{
+			ID:         "broken",
+			Kind:       rule.KindExpense,
+			CategoryID: groceriesID,
+		},
+	}
+
+	tt := []struct {
+		name string
+		sub  rule.Subject
+		exp  rule.Outcome
+	}{
+		{
+			name: "name",
+			sub:  expense("Spotify Premium", 999, "EUR", category.OtherExpensesID),
+			exp:  rule.Outcome{CategoryID: subscriptionsID, Tags: []string{"entertainment"}, Matched: []string{"spotify"}, Changed: true},
+		},
+		{
+			name: "picked by hand",
+			sub:  expense("SPOTIFY", 999, "EUR", groceriesID, "Music"),
+			exp:  rule.Outcome{CategoryID: groceriesID, Tags: []string{"Music", "entertainment"}, Matched: []string{"spotify"}, Changed: true},
+		},
+		{
+			name: "amount and currency",
+			sub:  expense("Flight", 120000, "USD", category.OtherExpensesID),
+			exp:  rule.Outcome{CategoryID: travelID, Tags: []string{"big"}, Matched: []string{"travel", "big"}, Changed: true},
+		},
+		{
+			name: "amount on the bound",
+			sub:  expense("Flight", 100000, "USD", category.OtherExpensesID),
+			exp:  rule.Outcome{CategoryID: groceriesID, Tags: []string{"big"}, Matched: []string{"big"}, Changed: true},
+		},
+		{
+			name: "other currency",
+			sub:  expense("Flight", 120000, "EUR", category.OtherExpensesID),
+			exp:  rule.Outcome{CategoryID: groceriesID, Tags: []string{"big"}, Matched: []string{"big"}, Changed: true},
+		},
+		{
+			name: "priority",
+			sub:  expense("spotify family", 120000, "USD", ""),
+			exp:  rule.Outcome{CategoryID: subscriptionsID, Tags: []string{"entertainment", "big"}, Matched: []string{"spotify", "travel", "big"}, Changed: true},
+		},
+		{
+			name: "unchanged",
+			sub:  expense("Spotify", 999, "EUR", subscriptionsID, "Entertainment"),
+			exp:  rule.Outcome{CategoryID: subscriptionsID, Tags: []string{"Entertainment"}, Matched: []string{"spotify"}},
+		},
+		{
+			name: "no match",
+			sub:  expense("Bakery", 350, "EUR", category.OtherExpensesID),
+			exp:  rule.Outcome{CategoryID: category.OtherExpensesID},
+		},
+		{
+			name: "income",
+			sub: rule.Subject{
+				Kind:       rule.KindIncome,
+				Name:       "Spotify payout",
+				Amount:     money.Money{Amount: 5000, Currency: "EUR"},
+				CategoryID: category.OtherIncomeID,
+			},
+			exp: rule.Outcome{CategoryID: salaryID, Matched: []string{"salary"}, Changed: true},
+		},
+	}
+
+	t.Log("Given the need to categorize records with the rules of their user.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen evaluating the %s case.", testID, tst.name)
+				{
+					got := rule.Evaluate(rules, tst.sub)
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected outcome. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected outcome.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_CreateInvalid(t *testing.T) {
+
+	// Rules are refused before the database is needed.
+	core := rule.NewCore(nil, nil)
+
+	tt := []struct {
+		name string
+		nr   rule.NewRule
+	}{
+		{
+			name: "operator",
+			nr:   newRule(rule.Condition{Field: rule.FieldCurrency, Op: rule.OpContains, Value: "US"}),
+		},
+		{
+			name: "amount",
+			nr:   newRule(rule.Condition{Field: rule.FieldAmount, Op: rule.OpGT, Value: "1e3"}),
+		},
+		{
+			name: "field",
+			nr:   newRule(rule.Condition{Field: "memo", Op: rule.OpEquals, Value: "x"}),
+		},
+		{
+			name: "no conditions",
+			nr:   newRule(),
+		},
+		{
+			name: "no action",
+			nr: rule.NewRule{
+				UserID:     "45b5fbd3-755f-4379-8f07-a58d4a30fa2f",
+				Name:       "Nothing",
+				Kind:       rule.KindExpense,
+				Conditions: []rule.Condition{{Field: rule.FieldName, Op: rule.OpContains, Value: "x"}},
+			},
+		},
+	}
+
+	t.Log("Given the need to only store rules that make sense.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen adding a rule with a bad %s.", testID, tst.name)
+				{
+					_, err := core.Create(context.Background(), tst.nr, time.Now())
+					if !validate.IsFieldErrors(err) {
+						t.Fatalf("\t%s\tTest %d:\tShould get a field error, got %v.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get a field error.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+// =============================================================================
+
+func expense(name string, amount int64, currency string, categoryID string, tags ...string) rule.Subject {
+	return rule.Subject{
+		Kind:       rule.KindExpense,
+		Name:       name,
+		Amount:     money.Money{Amount: amount, Currency: currency},
+		CategoryID: categoryID,
+		Tags:       tags,
+	}
+}
+
+func newRule(conds ...rule.Condition) rule.NewRule {
+	return rule.NewRule{
+		UserID:     "45b5fbd3-755f-4379-8f07-a58d4a30fa2f",
+		Name:       "Test",
+		Kind:       rule.KindExpense,
+		Conditions: conds,
+		Tags:       []string{"test"},
+	}
+}
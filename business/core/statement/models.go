@@ -0,0 +1,53 @@
+package statement
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Set of kinds of record a statement row is imported as.
+const (
+	KindIncome  = "income"
+	KindExpense = "expense"
+)
+
+// Options describes where the records of a statement are put.
+type Options struct {
+	IncomeCategoryID  string `json:"income_category_id"`  // Category of the incomes, Other Income by default.
+	ExpenseCategoryID string `json:"expense_category_id"` // Category of the expenses, Other Expenses by default.
+	AccountID         string `json:"account_id"`          // Account the records are put on.
+}
+
+// Result represents the outcome of importing a statement.
+type Result struct {
+	DryRun   bool  `json:"dry_run"`  // Nothing was stored, the rows only show what would be imported.
+	Total    int   `json:"total"`    // Number of rows in the statement.
+	Imported int   `json:"imported"` // Number of rows imported, or that would be in a dry run.
+	Skipped  int   `json:"skipped"`  // Number of rows skipped since they were imported before.
+	Failed   int   `json:"failed"`   // Number of rows that failed.
+	Rows     []Row `json:"rows"`     // Outcome of every row.
+}
+
+// Set of outcomes of importing a row of a statement.
+const (
+	StatusImported = "imported"
+	StatusSkipped  = "skipped"
+	StatusFailed   = "failed"
+)
+
+// Row represents the outcome of importing a single row of a statement.
+type Row struct {
+	Line   int          `json:"line"`             // Line of the row in the statement.
+	Status string       `json:"status"`           // Outcome of the row (imported, skipped, failed).
+	Kind   string       `json:"kind,omitempty"`   // Kind of record the row is imported as (income, expense).
+	ID     string       `json:"id,omitempty"`     // ID of the income or expense created, or imported before.
+	FITID  string       `json:"fitid,omitempty"`  // ID the bank knows the row by.
+	Name   string       `json:"name,omitempty"`   // Name of the record.
+	Amount *money.Money `json:"amount,omitempty"` // Amount of the record, always positive.
+	Date   time.Time    `json:"date"`             // When the money moved.
+	Error  string       `json:"error,omitempty"`  // Reason the row failed.
+
+	// IDs of the expenses the expense created may duplicate.
+	Duplicates []string `json:"duplicates,omitempty"`
+}
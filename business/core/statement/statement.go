@@ -0,0 +1,236 @@
+// Package statement provides a core business API for importing bank
+// statements as incomes and expenses of a user.
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/category"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/importers"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for import operations.
+var (
+	ErrNotFound  = errors.New("user not found")
+	ErrInvalidID = errors.New("ID is not in its proper form")
+)
+
+// Core manages the set of APIs for statement imports.
+type Core struct {
+	income   income.Core
+	expense  expense.Core
+	user     user.Core
+	account  account.Core
+	category category.Core
+}
+
+// NewCore constructs a core for statement import api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		income:   income.NewCore(log, sqlxDB),
+		expense:  expense.NewCore(log, sqlxDB),
+		user:     user.NewCore(log, sqlxDB),
+		account:  account.NewCore(log, sqlxDB),
+		category: category.NewCore(log, sqlxDB),
+	}
+}
+
+// Import adds every record of a statement as a one-off income or expense of
+// the user, depending on the sign of its amount. Records the bank gave an ID
+// are only ever imported once, so the same statement can be imported again
+// safely. A row that fails is reported in the result without stopping the
+// others. In a dry run nothing is stored and the result shows what would be
+// imported.
+func (c Core) Import(ctx context.Context, userID string, opts Options, recs []importers.Record, dryRun bool, now time.Time) (Result, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Result{}, ErrInvalidID
+	}
+
+	if _, err := c.user.QueryByID(ctx, userID); err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return Result{}, ErrNotFound
+		}
+		return Result{}, fmt.Errorf("query user: %w", err)
+	}
+
+	if opts.IncomeCategoryID == "" {
+		opts.IncomeCategoryID = category.OtherIncomeID
+	}
+	if err := c.category.Check(ctx, opts.IncomeCategoryID, userID, category.KindIncome); err != nil {
+		return Result{}, fmt.Errorf("income category: %w", err)
+	}
+
+	if opts.ExpenseCategoryID == "" {
+		opts.ExpenseCategoryID = category.OtherExpensesID
+	}
+	if err := c.category.Check(ctx, opts.ExpenseCategoryID, userID, category.KindExpense); err != nil {
+		return Result{}, fmt.Errorf("expense category: %w", err)
+	}
+
+	res := Result{
+		DryRun: dryRun,
+		Total:  len(recs),
+		Rows:   make([]Row, len(recs)),
+	}
+
+	// A statement can hold the same record twice, like when two exports of
+	// overlapping periods are joined together.
+	seen := make(map[string]bool)
+
+	for i, rec := range recs {
+		row, err := c.importRecord(ctx, userID, opts, rec, seen, dryRun, now)
+		switch {
+		case err != nil:
+			row.Status, row.Error = StatusFailed, err.Error()
+			res.Failed++
+		case row.Status == StatusSkipped:
+			res.Skipped++
+		default:
+			row.Status = StatusImported
+			res.Imported++
+		}
+		res.Rows[i] = row
+	}
+
+	return res, nil
+}
+
+// =============================================================================
+
+// importRecord adds a single record of a statement, unless it was imported
+// before. The record happens on the date the money moved, so the ledger posts
+// it on that day, while now is when it was added.
+func (c Core) importRecord(ctx context.Context, userID string, opts Options, rec importers.Record, seen map[string]bool, dryRun bool, now time.Time) (Row, error) {
+	row := Row{
+		Line:  rec.Line,
+		FITID: rec.FITID,
+		Name:  rec.Description,
+		Date:  rec.Date,
+	}
+
+	if rec.Err != nil {
+		return row, rec.Err
+	}
+
+	amount := rec.Amount
+	row.Kind = KindIncome
+	if amount.IsNegative() {
+		amount = amount.Neg()
+		row.Kind = KindExpense
+	}
+	row.Amount = &amount
+
+	if rec.FITID != "" {
+		if seen[row.Kind+rec.FITID] {
+			row.Status = StatusSkipped
+			return row, nil
+		}
+		seen[row.Kind+rec.FITID] = true
+
+		id, err := c.queryImported(ctx, userID, row.Kind, rec.FITID)
+		if err != nil {
+			return row, err
+		}
+		if id != "" {
+			row.ID, row.Status = id, StatusSkipped
+			return row, nil
+		}
+	}
+
+	if opts.AccountID != "" {
+		if err := c.account.Check(ctx, opts.AccountID, userID, amount.Currency); err != nil {
+			return row, fmt.Errorf("account: %w", err)
+		}
+	}
+
+	if dryRun {
+		return row, nil
+	}
+
+	switch row.Kind {
+	case KindIncome:
+		ni := income.NewIncome{
+			Name:             rec.Description,
+			CategoryID:       opts.IncomeCategoryID,
+			Amount:           amount,
+			ReoccurrenceType: recurrence.TypeOnce,
+			DurationType:     recurrence.DurationNone,
+			UserID:           userID,
+			AccountID:        opts.AccountID,
+			ExternalID:       rec.FITID,
+			Date:             rec.Date,
+		}
+
+		inc, err := c.income.Create(ctx, ni, now)
+		if err != nil {
+			if errors.Is(err, income.ErrDuplicate) {
+				row.Status = StatusSkipped
+				return row, nil
+			}
+			return row, err
+		}
+		row.ID = inc.ID
+
+	default:
+		ne := expense.NewExpense{
+			Name:             rec.Description,
+			CategoryID:       opts.ExpenseCategoryID,
+			Amount:           amount,
+			ReoccurrenceType: recurrence.TypeOnce,
+			DurationType:     recurrence.DurationNone,
+			UserID:           userID,
+			AccountID:        opts.AccountID,
+			ExternalID:       rec.FITID,
+			Date:             rec.Date,
+		}
+
+		exp, err := c.expense.Create(ctx, ne, now)
+		if err != nil {
+			if errors.Is(err, expense.ErrDuplicate) {
+				row.Status = StatusSkipped
+				return row, nil
+			}
+			return row, err
+		}
+		row.ID, row.Duplicates = exp.ID, exp.Duplicates
+	}
+
+	return row, nil
+}
+
+// queryImported returns the ID of the income or expense imported before with
+// the bank ID, empty when there is none.
+func (c Core) queryImported(ctx context.Context, userID string, kind string, fitID string) (string, error) {
+	switch kind {
+	case KindIncome:
+		inc, err := c.income.QueryByExternalID(ctx, userID, fitID)
+		if err != nil {
+			if errors.Is(err, income.ErrNotFound) {
+				return "", nil
+			}
+			return "", err
+		}
+		return inc.ID, nil
+
+	default:
+		exp, err := c.expense.QueryByExternalID(ctx, userID, fitID)
+		if err != nil {
+			if errors.Is(err, expense.ErrNotFound) {
+				return "", nil
+			}
+			return "", err
+		}
+		return exp.ID, nil
+	}
+}
@@ -0,0 +1,259 @@
+// Package db contains tag related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for tag access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// Create adds a Tag to the database.
+func (s Store) Create(ctx context.Context, tag Tag) error {
+	const q = `
+	INSERT INTO tags
+		(tag_id, user_id, name, date_created, date_updated)
+	VALUES
+		(:tag_id, :user_id, :name, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, tag); err != nil {
+		return fmt.Errorf("inserting tag: %w", err)
+	}
+
+	return nil
+}
+
+// CreateIfNotExists adds a Tag to the database unless the user already has a
+// tag with the same name, regardless of case.
+func (s Store) CreateIfNotExists(ctx context.Context, tag Tag) error {
+	const q = `
+	INSERT INTO tags
+		(tag_id, user_id, name, date_created, date_updated)
+	VALUES
+		(:tag_id, :user_id, :name, :date_created, :date_updated)
+	ON CONFLICT DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, tag); err != nil {
+		return fmt.Errorf("inserting tag: %w", err)
+	}
+
+	return nil
+}
+
+// Update modifies data about a Tag.
+func (s Store) Update(ctx context.Context, tag Tag) error {
+	const q = `
+	UPDATE
+		tags
+	SET
+		"name" = :name,
+		"date_updated" = :date_updated
+	WHERE
+		tag_id = :tag_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, tag); err != nil {
+		return fmt.Errorf("updating tag tagID[%s]: %w", tag.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the tag identified by a given ID along with every link to it.
+func (s Store) Delete(ctx context.Context, tagID string) error {
+	data := struct {
+		TagID string `db:"tag_id"`
+	}{
+		TagID: tagID,
+	}
+
+	const q = `
+	DELETE FROM
+		tags
+	WHERE
+		tag_id = :tag_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting tag tagID[%s]: %w", tagID, err)
+	}
+
+	return nil
+}
+
+// Move puts every record tagged with one tag on another tag instead. Records
+// that already have both tags keep a single link.
+func (s Store) Move(ctx context.Context, fromID string, toID string) error {
+	data := struct {
+		FromID string `db:"from_id"`
+		ToID   string `db:"to_id"`
+	}{
+		FromID: fromID,
+		ToID:   toID,
+	}
+
+	const q = `
+	INSERT INTO transaction_tags
+		(tag_id, income_id, expense_id)
+	SELECT
+		:to_id, income_id, expense_id
+	FROM
+		transaction_tags
+	WHERE
+		tag_id = :from_id
+	ON CONFLICT DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("moving links fromID[%s] toID[%s]: %w", fromID, toID, err)
+	}
+
+	return nil
+}
+
+// Link puts a tag on an income or an expense.
+func (s Store) Link(ctx context.Context, lnk Link) error {
+	const q = `
+	INSERT INTO transaction_tags
+		(tag_id, income_id, expense_id)
+	VALUES
+		(:tag_id, :income_id, :expense_id)
+	ON CONFLICT DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, lnk); err != nil {
+		return fmt.Errorf("inserting link tagID[%s]: %w", lnk.TagID, err)
+	}
+
+	return nil
+}
+
+// Unlink removes every tag from the income or expense of the link. The tag of
+// the link is ignored.
+func (s Store) Unlink(ctx context.Context, lnk Link) error {
+	const q = `
+	DELETE FROM
+		transaction_tags
+	WHERE
+		income_id = :income_id OR expense_id = :expense_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, lnk); err != nil {
+		return fmt.Errorf("deleting links: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the tag identified by a given ID.
+func (s Store) QueryByID(ctx context.Context, tagID string) (Tag, error) {
+	data := struct {
+		TagID string `db:"tag_id"`
+	}{
+		TagID: tagID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		tags
+	WHERE
+		tag_id = :tag_id`
+
+	var tag Tag
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &tag); err != nil {
+		return Tag{}, fmt.Errorf("selecting tag tagID[%q]: %w", tagID, err)
+	}
+
+	return tag, nil
+}
+
+// QueryByUserID finds the tags of a given User ID.
+func (s Store) QueryByUserID(ctx context.Context, userID string) ([]Tag, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		tags
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		name`
+
+	var tags []Tag
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &tags); err != nil {
+		return nil, fmt.Errorf("selecting tags userID[%s]: %w", userID, err)
+	}
+
+	return tags, nil
+}
+
+// QueryByNames finds the tags of a given User ID with the given names,
+// regardless of case.
+func (s Store) QueryByNames(ctx context.Context, userID string, names []string) ([]Tag, error) {
+	data := struct {
+		UserID string         `db:"user_id"`
+		Names  pq.StringArray `db:"names"`
+	}{
+		UserID: userID,
+		Names:  names,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		tags
+	WHERE
+		user_id = :user_id AND
+		LOWER(name) = ANY(SELECT LOWER(n) FROM UNNEST(CAST(:names AS TEXT[])) AS n)
+	ORDER BY
+		name`
+
+	var tags []Tag
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &tags); err != nil {
+		return nil, fmt.Errorf("selecting tags userID[%s]: %w", userID, err)
+	}
+
+	return tags, nil
+}
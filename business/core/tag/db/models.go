@@ -0,0 +1,23 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Tag represents a free-form label a user puts on incomes and expenses.
+type Tag struct {
+	ID          string    `db:"tag_id"`       // Unique identifier.
+	UserID      string    `db:"user_id"`      // ID of the user who owns the tag.
+	Name        string    `db:"name"`         // Display name of the tag.
+	DateCreated time.Time `db:"date_created"` // When the tag was added.
+	DateUpdated time.Time `db:"date_updated"` // When the tag record was last modified.
+}
+
+// Link represents a tag put on an income or an expense. Exactly one of the
+// record IDs is set.
+type Link struct {
+	TagID     string         `db:"tag_id"`     // ID of the tag.
+	IncomeID  sql.NullString `db:"income_id"`  // ID of the tagged income.
+	ExpenseID sql.NullString `db:"expense_id"` // ID of the tagged expense.
+}
@@ -0,0 +1,71 @@
+package tag
+
+import (
+	"errors"
+	"strings"
+)
+
+// Set of ways the tags of a filter can match.
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
+// ErrInvalidMatch is returned when a filter is asked to match in an unknown way.
+var ErrInvalidMatch = errors.New("match must be either any or all")
+
+// Filter selects incomes and expenses by their tags. An empty filter selects
+// every record.
+type Filter struct {
+	Names []string // Lower-cased names of the tags to look for.
+	All   bool     // Records need all of the tags instead of any of them.
+}
+
+// NewFilter constructs a filter for the given tag names, which may also be
+// separated by commas. Records match on any of the tags unless match is all.
+func NewFilter(names []string, match string) (Filter, error) {
+	var all bool
+	switch match {
+	case "", MatchAny:
+	case MatchAll:
+		all = true
+	default:
+		return Filter{}, ErrInvalidMatch
+	}
+
+	var split []string
+	for _, name := range names {
+		split = append(split, strings.Split(name, ",")...)
+	}
+
+	names = normalize(split)
+	for i := range names {
+		names[i] = strings.ToLower(names[i])
+	}
+
+	f := Filter{
+		Names: names,
+		All:   all,
+	}
+
+	return f, nil
+}
+
+// normalize trims the names and drops the empty ones and the ones repeated
+// regardless of case, keeping the first spelling.
+func normalize(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, name)
+	}
+
+	return out
+}
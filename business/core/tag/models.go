@@ -0,0 +1,53 @@
+package tag
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/tag/db"
+)
+
+// Tag represents a free-form label a user puts on incomes and expenses.
+type Tag struct {
+	ID          string    `json:"id"`           // Unique identifier.
+	UserID      string    `json:"user_id"`      // ID of the user who owns the tag.
+	Name        string    `json:"name"`         // Display name of the tag.
+	DateCreated time.Time `json:"date_created"` // When the tag was added.
+	DateUpdated time.Time `json:"date_updated"` // When the tag record was last modified.
+}
+
+// NewTag is what we require from clients when adding a Tag.
+type NewTag struct {
+	UserID string `json:"user_id" validate:"required"`
+	Name   string `json:"name" validate:"required,max=50"`
+}
+
+// UpdateTag defines what information may be provided to modify an existing
+// Tag. Renaming a tag renames it on every record it is put on.
+type UpdateTag struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=50"`
+}
+
+// MergeTag is what we require from clients when merging a Tag into another.
+type MergeTag struct {
+	IntoID string `json:"into_id" validate:"required"`
+}
+
+// =============================================================================
+
+func toTag(dbTag db.Tag) Tag {
+	return Tag{
+		ID:          dbTag.ID,
+		UserID:      dbTag.UserID,
+		Name:        dbTag.Name,
+		DateCreated: dbTag.DateCreated,
+		DateUpdated: dbTag.DateUpdated,
+	}
+}
+
+func toTagSlice(dbTags []db.Tag) []Tag {
+	tags := make([]Tag, len(dbTags))
+	for i, dbTag := range dbTags {
+		tags[i] = toTag(dbTag)
+	}
+	return tags
+}
@@ -0,0 +1,271 @@
+// Package tag provides a core business API for the free-form labels users put
+// on their incomes and expenses.
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/tag/db"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound     = errors.New("tag not found")
+	ErrInvalidID    = errors.New("ID is not in its proper form")
+	ErrDuplicate    = errors.New("tag already exists")
+	ErrNotOwner     = errors.New("tag belongs to another user")
+	ErrInvalidMerge = errors.New("tag can't be merged into itself")
+)
+
+// Core manages the set of APIs for tag access.
+type Core struct {
+	store db.Store
+}
+
+// NewCore constructs a core for tag api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store: db.NewStore(log, sqlxDB),
+	}
+}
+
+// Tran returns a new Core running every query within the transaction, so
+// tags can be set along with the record they are put on.
+func (c Core) Tran(tx sqlx.ExtContext) Core {
+	return Core{
+		store: c.store.Tran(tx),
+	}
+}
+
+// Create adds a Tag to the database. Names are unique per user, regardless of
+// case.
+func (c Core) Create(ctx context.Context, nt NewTag, now time.Time) (Tag, error) {
+	if err := validate.Check(nt); err != nil {
+		return Tag{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	dbTag := db.Tag{
+		ID:          validate.GenerateID(),
+		UserID:      nt.UserID,
+		Name:        strings.TrimSpace(nt.Name),
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.store.Create(ctx, dbTag); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return Tag{}, fmt.Errorf("create: %w", ErrDuplicate)
+		}
+		return Tag{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toTag(dbTag), nil
+}
+
+// Update modifies data about a Tag. It will error if the specified ID is
+// invalid or does not reference an existing Tag. Renaming a tag to the name
+// of another one is refused, those can be merged instead.
+func (c Core) Update(ctx context.Context, tagID string, ut UpdateTag, now time.Time) error {
+	if err := validate.CheckID(tagID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(ut); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	dbTag, err := c.store.QueryByID(ctx, tagID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("updating tag tagID[%s]: %w", tagID, err)
+	}
+
+	if ut.Name != nil {
+		dbTag.Name = strings.TrimSpace(*ut.Name)
+	}
+	dbTag.DateUpdated = now
+
+	if err := c.store.Update(ctx, dbTag); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("update: %w", ErrDuplicate)
+		}
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the tag identified by a given ID from the database and from
+// every record it is put on.
+func (c Core) Delete(ctx context.Context, tagID string) error {
+	if err := validate.CheckID(tagID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := c.store.Delete(ctx, tagID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Merge puts the tag identified by a given ID on every record the tag it is
+// merged into is not on yet, then removes it. Both tags must belong to the
+// same user.
+func (c Core) Merge(ctx context.Context, tagID string, mt MergeTag) error {
+	if err := validate.CheckID(tagID); err != nil {
+		return ErrInvalidID
+	}
+
+	if err := validate.Check(mt); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	if tagID == mt.IntoID {
+		return ErrInvalidMerge
+	}
+
+	from, err := c.QueryByID(ctx, tagID)
+	if err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+
+	into, err := c.QueryByID(ctx, mt.IntoID)
+	if err != nil {
+		return fmt.Errorf("into: %w", err)
+	}
+
+	if from.UserID != into.UserID {
+		return ErrNotOwner
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		store := c.store.Tran(tx)
+
+		if err := store.Move(ctx, from.ID, into.ID); err != nil {
+			return fmt.Errorf("move: %w", err)
+		}
+
+		if err := store.Delete(ctx, from.ID); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the tag identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, tagID string) (Tag, error) {
+	if err := validate.CheckID(tagID); err != nil {
+		return Tag{}, ErrInvalidID
+	}
+
+	dbTag, err := c.store.QueryByID(ctx, tagID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Tag{}, ErrNotFound
+		}
+		return Tag{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toTag(dbTag), nil
+}
+
+// QueryByUserID finds the tags identified by a given User ID.
+func (c Core) QueryByUserID(ctx context.Context, userID string) ([]Tag, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbTags, err := c.store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toTagSlice(dbTags), nil
+}
+
+// Set replaces the tags of the income or expense identified by a given ID
+// with the tags of the given names, adding the ones the user doesn't have
+// yet. It returns the names of the tags as they are stored.
+func (c Core) Set(ctx context.Context, userID string, source string, recordID string, names []string, now time.Time) ([]string, error) {
+	var rec db.Link
+	switch source {
+	case transaction.SourceIncome:
+		rec.IncomeID = sql.NullString{String: recordID, Valid: true}
+	case transaction.SourceExpense:
+		rec.ExpenseID = sql.NullString{String: recordID, Valid: true}
+	default:
+		return nil, fmt.Errorf("unknown source[%s]", source)
+	}
+
+	names = normalize(names)
+	out := make([]string, 0, len(names))
+
+	tran := func(tx sqlx.ExtContext) error {
+		store := c.store.Tran(tx)
+
+		if err := store.Unlink(ctx, rec); err != nil {
+			return fmt.Errorf("unlink: %w", err)
+		}
+
+		if len(names) == 0 {
+			return nil
+		}
+
+		for _, name := range names {
+			dbTag := db.Tag{
+				ID:          validate.GenerateID(),
+				UserID:      userID,
+				Name:        name,
+				DateCreated: now,
+				DateUpdated: now,
+			}
+
+			if err := store.CreateIfNotExists(ctx, dbTag); err != nil {
+				return fmt.Errorf("create: %w", err)
+			}
+		}
+
+		dbTags, err := store.QueryByNames(ctx, userID, names)
+		if err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+
+		for _, dbTag := range dbTags {
+			lnk := rec
+			lnk.TagID = dbTag.ID
+
+			if err := store.Link(ctx, lnk); err != nil {
+				return fmt.Errorf("link: %w", err)
+			}
+			out = append(out, dbTag.Name)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return nil, fmt.Errorf("tran: %w", err)
+	}
+
+	return out, nil
+}
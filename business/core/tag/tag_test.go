@@ -0,0 +1,191 @@
+package tag_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/tag"
+	"github.com/gloompi/ultimate-service/business/data/tests"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/foundation/docker"
+	"github.com/google/go-cmp/cmp"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+// Seeded user and category the expenses are tagged for.
+const (
+	userID  = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+	taxesID = "4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9"
+)
+
+func Test_Tag(t *testing.T) {
+	log, db, teardown := tests.NewUnit(t, c, "testtag")
+	t.Cleanup(teardown)
+
+	core := tag.NewCore(log, db)
+	expCore := expense.NewCore(log, db)
+
+	ctx := context.Background()
+	now := time.Date(2019, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+	create := func(name string, tags ...string) (string, error) {
+		ne := expense.NewExpense{
+			Name:             name,
+			CategoryID:       taxesID,
+			Amount:           money.Money{Amount: 1000, Currency: "EUR"},
+			Reoccurrence:     1,
+			ReoccurrenceType: recurrence.TypeOnce,
+			DurationType:     recurrence.DurationNone,
+			UserID:           userID,
+			Tags:             tags,
+		}
+
+		exp, err := expCore.Create(ctx, ne, now)
+		if err != nil {
+			return "", err
+		}
+		return exp.ID, nil
+	}
+
+	// tagged gets the sorted names of the expenses that pass the tag filter.
+	tagged := func(match string, names ...string) ([]string, error) {
+		f, err := tag.NewFilter(names, match)
+		if err != nil {
+			return nil, err
+		}
+
+		exps, err := expCore.Query(ctx, expense.QueryFilter{Tag: f}, expense.DefaultOrderBy, 1, 100)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]string, len(exps))
+		for i, exp := range exps {
+			out[i] = exp.Name
+		}
+		sort.Strings(out)
+		return out, nil
+	}
+
+	exps := []struct {
+		name string
+		tags []string
+	}{
+		{"Market", []string{"groceries", "weekly"}},
+		{"Bakery", []string{"grocery"}},
+		{"Butcher", []string{"grocery", "groceries"}},
+		{"Cleaning", []string{"weekly"}},
+	}
+
+	ids := make(map[string]string)
+	for _, exp := range exps {
+		id, err := create(exp.name, exp.tags...)
+		if err != nil {
+			t.Fatalf("Creating expense %s: %s", exp.name, err)
+		}
+		ids[exp.name] = id
+	}
+
+	t.Log("Given the need to merge tags.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen merging a tag into one some records already have.", testID)
+		{
+			tags, err := core.QueryByUserID(ctx, userID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the tags : %s.", tests.Failed, testID, err)
+			}
+
+			byName := make(map[string]string)
+			for _, tg := range tags {
+				byName[tg.Name] = tg.ID
+			}
+			if byName["grocery"] == "" || byName["groceries"] == "" {
+				t.Fatalf("\t%s\tTest %d:\tShould have both tags : got %v.", tests.Failed, testID, byName)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have both tags.", tests.Success, testID)
+
+			if err := core.Merge(ctx, byName["grocery"], tag.MergeTag{IntoID: byName["groceries"]}); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to merge : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to merge.", tests.Success, testID)
+
+			if _, err := core.QueryByID(ctx, byName["grocery"]); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould remove the merged tag.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould remove the merged tag.", tests.Success, testID)
+
+			for _, name := range []string{"Bakery", "Butcher"} {
+				exp, err := expCore.QueryByID(ctx, ids[name])
+				if err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the expense : %s.", tests.Failed, testID, err)
+				}
+				if diff := cmp.Diff([]string{"groceries"}, exp.Tags); diff != "" {
+					t.Fatalf("\t%s\tTest %d:\tShould tag %s once with the kept tag. Diff:\n%s", tests.Failed, testID, name, diff)
+				}
+			}
+			t.Logf("\t%s\tTest %d:\tShould tag the records once with the kept tag.", tests.Success, testID)
+
+			got, err := tagged(tag.MatchAny, "groceries")
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to filter by tag : %s.", tests.Failed, testID, err)
+			}
+			if diff := cmp.Diff([]string{"Bakery", "Butcher", "Market"}, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould list every record once. Diff:\n%s", tests.Failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould list every record once.", tests.Success, testID)
+		}
+	}
+
+	t.Log("Given the need to filter records by their tags.")
+	{
+		tt := []struct {
+			name  string
+			match string
+			tags  []string
+			exp   []string
+		}{
+			{"any", tag.MatchAny, []string{"groceries", "weekly"}, []string{"Bakery", "Butcher", "Cleaning", "Market"}},
+			{"all", tag.MatchAll, []string{"groceries", "weekly"}, []string{"Market"}},
+			{"allCase", tag.MatchAll, []string{"Groceries,WEEKLY"}, []string{"Market"}},
+			{"allUnknown", tag.MatchAll, []string{"groceries", "yearly"}, []string{}},
+		}
+
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen matching %s of the tags %v.", testID, tst.match, tst.tags)
+				{
+					got, err := tagged(tst.match, tst.tags...)
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to filter by tag : %s.", tests.Failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to filter by tag.", tests.Success, testID)
+
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected records. Diff:\n%s", tests.Failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected records.", tests.Success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
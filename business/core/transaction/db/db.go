@@ -0,0 +1,164 @@
+// Package db contains transaction related CRUD functionality.
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for transaction access.
+type Store struct {
+	log          *zap.SugaredLogger
+	tr           database.Transactor
+	db           sqlx.ExtContext
+	isWithinTran bool
+}
+
+// NewStore constructs a data for api access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) Store {
+	return Store{
+		log: log,
+		tr:  db,
+		db:  db,
+	}
+}
+
+// WithinTran runs passed function and do commit/rollback at the end.
+func (s Store) WithinTran(ctx context.Context, fn func(sqlx.ExtContext) error) error {
+	if s.isWithinTran {
+		return fn(s.db)
+	}
+	return database.WithinTran(ctx, s.log, s.tr, fn)
+}
+
+// Tran return new Store with transaction in it.
+func (s Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log:          s.log,
+		tr:           s.tr,
+		db:           tx,
+		isWithinTran: true,
+	}
+}
+
+// Create adds a Transaction to the database. A transaction that was already
+// posted for the same source and date is silently skipped.
+func (s Store) Create(ctx context.Context, trn Transaction) error {
+	const q = `
+	INSERT INTO transactions
+		(transaction_id, user_id, source_type, source_id, account_id, name, category_id, currency, amount, date_occurred, date_created)
+	VALUES
+		(:transaction_id, :user_id, :source_type, :source_id, :account_id, :name, :category_id, :currency, :amount, :date_occurred, :date_created)
+	ON CONFLICT (source_type, source_id, date_occurred) DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, trn); err != nil {
+		return fmt.Errorf("inserting transaction: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the transaction identified by a given ID. Postings of
+// incomes and expenses in the trash are not found.
+func (s Store) QueryByID(ctx context.Context, transactionID string) (Transaction, error) {
+	data := struct {
+		TransactionID string `db:"transaction_id"`
+	}{
+		TransactionID: transactionID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		transactions AS t
+	WHERE
+		transaction_id = :transaction_id AND
+		NOT EXISTS (SELECT 1 FROM incomes AS i WHERE t.source_type = 'income' AND i.income_id = t.source_id AND i.deleted_at IS NOT NULL) AND
+		NOT EXISTS (SELECT 1 FROM expenses AS e WHERE t.source_type = 'expense' AND e.expense_id = t.source_id AND e.deleted_at IS NOT NULL)`
+
+	var trn Transaction
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &trn); err != nil {
+		return Transaction{}, fmt.Errorf("selecting transaction transactionID[%q]: %w", transactionID, err)
+	}
+
+	return trn, nil
+}
+
+// QueryByUserID finds the transactions of a given User ID that occurred
+// within the [from, to) range. Postings of incomes and expenses in the trash
+// are left out.
+func (s Store) QueryByUserID(ctx context.Context, userID string, from time.Time, to time.Time) ([]Transaction, error) {
+	data := struct {
+		UserID string    `db:"user_id"`
+		From   time.Time `db:"from"`
+		To     time.Time `db:"to"`
+	}{
+		UserID: userID,
+		From:   from,
+		To:     to,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		transactions AS t
+	WHERE
+		user_id = :user_id AND
+		date_occurred >= :from AND
+		date_occurred < :to AND
+		NOT EXISTS (SELECT 1 FROM incomes AS i WHERE t.source_type = 'income' AND i.income_id = t.source_id AND i.deleted_at IS NOT NULL) AND
+		NOT EXISTS (SELECT 1 FROM expenses AS e WHERE t.source_type = 'expense' AND e.expense_id = t.source_id AND e.deleted_at IS NOT NULL)
+	ORDER BY
+		date_occurred`
+
+	var trns []Transaction
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &trns); err != nil {
+		return nil, fmt.Errorf("selecting transactions userID[%s]: %w", userID, err)
+	}
+
+	return trns, nil
+}
+
+// QuerySources gets every income and expense that is not in the trash along
+// with the date of the last transaction materialized from it.
+func (s Store) QuerySources(ctx context.Context) ([]Source, error) {
+	const q = `
+	SELECT
+		src.*,
+		(SELECT MAX(t.date_occurred) FROM transactions AS t WHERE t.source_type = src.source_type AND t.source_id = src.source_id) AS last_occurred
+	FROM (
+		SELECT
+			'income' AS source_type, income_id AS source_id, user_id, account_id, name, category_id, currency,
+			COALESCE(amount, 0) AS amount, COALESCE(reoccurrence, 0) AS reoccurrence, COALESCE(duration, 0) AS duration,
+			COALESCE(CAST(reoccurrence_type AS TEXT), 'Monthly') AS reoccurrence_type, COALESCE(CAST(duration_type AS TEXT), 'None') AS duration_type,
+			date_created
+		FROM
+			incomes
+		WHERE
+			deleted_at IS NULL
+		UNION ALL
+		SELECT
+			'expense' AS source_type, expense_id AS source_id, user_id, account_id, name, category_id, currency,
+			COALESCE(amount, 0) AS amount, COALESCE(reoccurrence, 0) AS reoccurrence, COALESCE(duration, 0) AS duration,
+			COALESCE(CAST(reoccurrence_type AS TEXT), 'Monthly') AS reoccurrence_type, COALESCE(CAST(duration_type AS TEXT), 'None') AS duration_type,
+			date_created
+		FROM
+			expenses
+		WHERE
+			deleted_at IS NULL
+	) AS src`
+
+	var srcs []Source
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, struct{}{}, &srcs); err != nil {
+		return nil, fmt.Errorf("selecting sources: %w", err)
+	}
+
+	return srcs, nil
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Transaction represents a single dated money movement in the ledger.
+type Transaction struct {
+	ID           string         `db:"transaction_id"` // Unique identifier.
+	UserID       string         `db:"user_id"`        // ID of the user who owns the transaction.
+	SourceType   string         `db:"source_type"`    // Type of the record it originates from (income, expense, transfer_in, transfer_out, debt_payment).
+	SourceID     string         `db:"source_id"`      // ID of the record it originates from.
+	AccountID    sql.NullString `db:"account_id"`     // ID of the account the money moved in or out of.
+	Name         string         `db:"name"`           // Display name of the transaction.
+	CategoryID   sql.NullString `db:"category_id"`    // ID of the category of the transaction, null for transfers.
+	Currency     string         `db:"currency"`       // Currency of the transaction.
+	Amount       int64          `db:"amount"`         // Amount of money of the transaction.
+	DateOccurred time.Time      `db:"date_occurred"`  // When the money moved.
+	DateCreated  time.Time      `db:"date_created"`   // When the transaction was added.
+}
+
+// Source represents an income or expense together with the date of the last
+// transaction that was materialized from it.
+type Source struct {
+	SourceType       string         `db:"source_type"`       // Type of the record (income, expense).
+	ID               string         `db:"source_id"`         // Unique identifier of the record.
+	UserID           string         `db:"user_id"`           // ID of the user who owns the record.
+	AccountID        sql.NullString `db:"account_id"`        // ID of the account of the record.
+	Name             string         `db:"name"`              // Display name of the record.
+	CategoryID       string         `db:"category_id"`       // ID of the category of the record.
+	Currency         string         `db:"currency"`          // Currency of the record.
+	Amount           int64          `db:"amount"`            // Amount of money of the record.
+	Reoccurrence     int            `db:"reoccurrence"`      // Execute transaction each day, week, month.
+	Duration         int            `db:"duration"`          // Range of time transaction needs to be happening.
+	ReoccurrenceType string         `db:"reoccurrence_type"` // Type of reoccurrence (Monthly, Daily, Once).
+	DurationType     string         `db:"duration_type"`     // Type of duration (Months, Days).
+	DateCreated      time.Time      `db:"date_created"`      // When the record was added.
+	LastOccurred     sql.NullTime   `db:"last_occurred"`     // Date of the last materialized transaction.
+}
@@ -0,0 +1,58 @@
+package transaction
+
+import (
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/transaction/db"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Set of record types a transaction can originate from. A transfer posts two
+// transactions, one for each account involved. Debt payments are both the
+// installments and the extra payments of a debt, and count as expenses.
+const (
+	SourceIncome      = "income"
+	SourceExpense     = "expense"
+	SourceTransferIn  = "transfer_in"
+	SourceTransferOut = "transfer_out"
+	SourceDebtPayment = "debt_payment"
+)
+
+// Transaction represents a single dated money movement in the ledger.
+type Transaction struct {
+	ID           string      `json:"id"`                    // Unique identifier.
+	UserID       string      `json:"user_id"`               // ID of the user who owns the transaction.
+	SourceType   string      `json:"source_type"`           // Type of the record it originates from (income, expense, transfer_in, transfer_out, debt_payment).
+	SourceID     string      `json:"source_id"`             // ID of the record it originates from.
+	AccountID    string      `json:"account_id,omitempty"`  // ID of the account the money moved in or out of.
+	Name         string      `json:"name"`                  // Display name of the transaction.
+	CategoryID   string      `json:"category_id,omitempty"` // ID of the category of the transaction, empty for transfers.
+	Amount       money.Money `json:"amount"`                // Amount of money of the transaction.
+	DateOccurred time.Time   `json:"date_occurred"`         // When the money moved.
+	DateCreated  time.Time   `json:"date_created"`          // When the transaction was added.
+}
+
+// =============================================================================
+
+func toTransaction(dbTrn db.Transaction) Transaction {
+	return Transaction{
+		ID:           dbTrn.ID,
+		UserID:       dbTrn.UserID,
+		SourceType:   dbTrn.SourceType,
+		SourceID:     dbTrn.SourceID,
+		AccountID:    dbTrn.AccountID.String,
+		Name:         dbTrn.Name,
+		CategoryID:   dbTrn.CategoryID.String,
+		Amount:       money.Money{Amount: dbTrn.Amount, Currency: dbTrn.Currency},
+		DateOccurred: dbTrn.DateOccurred,
+		DateCreated:  dbTrn.DateCreated,
+	}
+}
+
+func toTransactionSlice(dbTrns []db.Transaction) []Transaction {
+	trns := make([]Transaction, len(dbTrns))
+	for i, dbTrn := range dbTrns {
+		trns[i] = toTransaction(dbTrn)
+	}
+	return trns
+}
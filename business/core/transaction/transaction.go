@@ -0,0 +1,136 @@
+// Package transaction provides a core business API for the ledger of dated
+// money movements that are materialized from incomes and expenses, or posted
+// by transfers between accounts.
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/transaction/db"
+	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound  = errors.New("transaction not found")
+	ErrInvalidID = errors.New("ID is not in its proper form")
+)
+
+// Core manages the set of APIs for transaction access.
+type Core struct {
+	store db.Store
+}
+
+// NewCore constructs a core for transaction api access.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
+	return Core{
+		store: db.NewStore(log, sqlxDB),
+	}
+}
+
+// Materialize walks every income and expense and posts one transaction per
+// occurrence that happened up to now. Occurrences that were already posted
+// are skipped, so it is safe to call this as often as needed. It returns the
+// number of occurrences that were processed.
+func (c Core) Materialize(ctx context.Context, now time.Time) (int, error) {
+	srcs, err := c.store.QuerySources(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("query sources: %w", err)
+	}
+
+	var total int
+	for _, src := range srcs {
+		rule := recurrence.Rule{
+			Start:        src.DateCreated,
+			Type:         src.ReoccurrenceType,
+			Interval:     src.Reoccurrence,
+			Duration:     src.Duration,
+			DurationType: src.DurationType,
+		}
+
+		// Only look at the dates after the last posted transaction. The first
+		// occurrence falls at midnight of the day the record was added, which
+		// is before the moment it was added.
+		created := src.DateCreated.UTC()
+		from := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)
+		if src.LastOccurred.Valid {
+			from = src.LastOccurred.Time.AddDate(0, 0, 1)
+		}
+
+		dates := rule.Between(from, now)
+		if len(dates) == 0 {
+			continue
+		}
+
+		tran := func(tx sqlx.ExtContext) error {
+			for _, date := range dates {
+				dbTrn := db.Transaction{
+					ID:           validate.GenerateID(),
+					UserID:       src.UserID,
+					SourceType:   src.SourceType,
+					SourceID:     src.ID,
+					AccountID:    src.AccountID,
+					Name:         src.Name,
+					CategoryID:   sql.NullString{String: src.CategoryID, Valid: true},
+					Currency:     src.Currency,
+					Amount:       src.Amount,
+					DateOccurred: date,
+					DateCreated:  now,
+				}
+
+				if err := c.store.Tran(tx).Create(ctx, dbTrn); err != nil {
+					return fmt.Errorf("create: %w", err)
+				}
+			}
+			return nil
+		}
+
+		if err := c.store.WithinTran(ctx, tran); err != nil {
+			return total, fmt.Errorf("tran %s[%s]: %w", src.SourceType, src.ID, err)
+		}
+
+		total += len(dates)
+	}
+
+	return total, nil
+}
+
+// QueryByID finds the transaction identified by a given ID.
+func (c Core) QueryByID(ctx context.Context, transactionID string) (Transaction, error) {
+	if err := validate.CheckID(transactionID); err != nil {
+		return Transaction{}, ErrInvalidID
+	}
+
+	dbTrn, err := c.store.QueryByID(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Transaction{}, ErrNotFound
+		}
+		return Transaction{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toTransaction(dbTrn), nil
+}
+
+// QueryByUserID finds the transactions of a given User ID that occurred
+// within the [from, to) range.
+func (c Core) QueryByUserID(ctx context.Context, userID string, from time.Time, to time.Time) ([]Transaction, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dbTrns, err := c.store.QueryByUserID(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toTransactionSlice(dbTrns), nil
+}
@@ -0,0 +1,14 @@
+package trash
+
+import (
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/user"
+)
+
+// Trash represents the records that were deleted and can still be restored.
+type Trash struct {
+	Incomes  []income.Income   `json:"incomes"`         // Incomes in the trash.
+	Expenses []expense.Expense `json:"expenses"`        // Expenses in the trash.
+	Users    []user.User       `json:"users,omitempty"` // Users in the trash, only listed for admins.
+}
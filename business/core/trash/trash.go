@@ -0,0 +1,97 @@
+// Package trash provides a core business API to look into and empty the trash
+// that deleted incomes, expenses and users are moved to.
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/attachment"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/user"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+	"github.com/gloompi/ultimate-service/foundation/blob"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for trash operations.
+var (
+	ErrInvalidID = errors.New("ID is not in its proper form")
+)
+
+// Core manages the set of APIs for trash access.
+type Core struct {
+	user       user.Core
+	income     income.Core
+	expense    expense.Core
+	attachment attachment.Core
+}
+
+// NewCore constructs a core for trash api access. The blob store holds the
+// content of the attachments of the expenses purged.
+func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB, bs blob.Store) Core {
+	return Core{
+		user:       user.NewCore(log, sqlxDB),
+		income:     income.NewCore(log, sqlxDB),
+		expense:    expense.NewCore(log, sqlxDB),
+		attachment: attachment.NewCore(log, sqlxDB, bs, 0),
+	}
+}
+
+// QueryByUserID gets the incomes and expenses of a user that are in the trash.
+func (c Core) QueryByUserID(ctx context.Context, userID string) (Trash, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return Trash{}, ErrInvalidID
+	}
+
+	incs, err := c.income.QueryDeleted(ctx, userID)
+	if err != nil {
+		return Trash{}, fmt.Errorf("query incomes: %w", err)
+	}
+
+	exps, err := c.expense.QueryDeleted(ctx, userID)
+	if err != nil {
+		return Trash{}, fmt.Errorf("query expenses: %w", err)
+	}
+
+	return Trash{
+		Incomes:  incs,
+		Expenses: exps,
+	}, nil
+}
+
+// QueryUsers gets the users that are in the trash.
+func (c Core) QueryUsers(ctx context.Context) ([]user.User, error) {
+	usrs, err := c.user.QueryDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+
+	return usrs, nil
+}
+
+// Purge removes for good everything that was moved to the trash before the
+// given time. Purging a user also removes every record it still owns.
+func (c Core) Purge(ctx context.Context, before time.Time) error {
+	if err := c.attachment.Purge(ctx, before); err != nil {
+		return fmt.Errorf("purge attachments: %w", err)
+	}
+
+	if err := c.income.Purge(ctx, before); err != nil {
+		return fmt.Errorf("purge incomes: %w", err)
+	}
+
+	if err := c.expense.Purge(ctx, before); err != nil {
+		return fmt.Errorf("purge expenses: %w", err)
+	}
+
+	if err := c.user.Purge(ctx, before); err != nil {
+		return fmt.Errorf("purge users: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package trash_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/core/account"
+	"github.com/gloompi/ultimate-service/business/core/expense"
+	"github.com/gloompi/ultimate-service/business/core/income"
+	"github.com/gloompi/ultimate-service/business/core/report"
+	"github.com/gloompi/ultimate-service/business/core/transaction"
+	"github.com/gloompi/ultimate-service/business/core/trash"
+	"github.com/gloompi/ultimate-service/business/data/tests"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/foundation/blob"
+	"github.com/gloompi/ultimate-service/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+// Seeded user, account and categories the records are booked with.
+const (
+	userID     = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+	checkingID = "c1e2a3b4-5d6e-4f70-8a91-b2c3d4e5f607"
+	taxesID    = "4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9"
+	salaryID   = "49698892-62e7-4770-aeb6-f3677be0855b"
+)
+
+// totals is what the ledger says about the user at some point.
+type totals struct {
+	income  int64 // EUR income of the January report.
+	expense int64 // EUR expense of the January report.
+	balance int64 // Balance of the checking account.
+	ledger  int   // Number of transactions in January.
+}
+
+func Test_Trash(t *testing.T) {
+	log, db, teardown := tests.NewUnit(t, c, "testtrash")
+	t.Cleanup(teardown)
+
+	bs, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Creating blob store: %s", err)
+	}
+
+	core := trash.NewCore(log, db, bs)
+	incCore := income.NewCore(log, db)
+	expCore := expense.NewCore(log, db)
+	accCore := account.NewCore(log, db)
+	repCore := report.NewCore(log, db)
+	trnCore := transaction.NewCore(log, db)
+
+	from := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	query := func(ctx context.Context) (totals, error) {
+		sum, err := repCore.QuerySummary(ctx, userID, report.GroupByCurrency, from, to, false)
+		if err != nil {
+			return totals{}, fmt.Errorf("summary: %w", err)
+		}
+
+		acc, err := accCore.QueryByID(ctx, checkingID)
+		if err != nil {
+			return totals{}, fmt.Errorf("account: %w", err)
+		}
+
+		trns, err := trnCore.QueryByUserID(ctx, userID, from, to)
+		if err != nil {
+			return totals{}, fmt.Errorf("ledger: %w", err)
+		}
+
+		tot := totals{balance: acc.Balance.Amount, ledger: len(trns)}
+		for _, bkt := range sum.Buckets {
+			if bkt.Key == "EUR" {
+				tot.income = bkt.Income.Amount
+				tot.expense = bkt.Expense.Amount
+			}
+		}
+		return tot, nil
+	}
+
+	t.Log("Given the need to leave trashed and purged records out of the ledger.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen moving a posted expense to the trash and back.", testID)
+		{
+			ctx := context.Background()
+			now := time.Date(2019, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+			ne := expense.NewExpense{
+				Name:             "Gym membership",
+				CategoryID:       taxesID,
+				Amount:           money.Money{Amount: 4500, Currency: "EUR"},
+				Reoccurrence:     1,
+				ReoccurrenceType: recurrence.TypeOnce,
+				DurationType:     recurrence.DurationNone,
+				UserID:           userID,
+				AccountID:        checkingID,
+			}
+
+			exp, err := expCore.Create(ctx, ne, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an expense : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create an expense.", tests.Success, testID)
+
+			if _, err := trnCore.Materialize(ctx, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to post the ledger : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to post the ledger.", tests.Success, testID)
+
+			posted, err := query(ctx)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the totals : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to query the totals.", tests.Success, testID)
+
+			if err := expCore.Delete(ctx, exp.ID, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to delete the expense : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to delete the expense.", tests.Success, testID)
+
+			trashed, err := query(ctx)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the totals : %s.", tests.Failed, testID, err)
+			}
+
+			exp2 := totals{
+				expense: posted.expense - ne.Amount.Amount,
+				balance: posted.balance + ne.Amount.Amount,
+				ledger:  posted.ledger - 1,
+			}
+			if trashed != exp2 {
+				t.Fatalf("\t%s\tTest %d:\tShould leave the expense out of the totals : got %+v, exp %+v.", tests.Failed, testID, trashed, exp2)
+			}
+			t.Logf("\t%s\tTest %d:\tShould leave the expense out of the totals.", tests.Success, testID)
+
+			if err := expCore.Restore(ctx, exp.ID, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to restore the expense : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to restore the expense.", tests.Success, testID)
+
+			restored, err := query(ctx)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the totals : %s.", tests.Failed, testID, err)
+			}
+
+			if restored != posted {
+				t.Fatalf("\t%s\tTest %d:\tShould count the expense again : got %+v, exp %+v.", tests.Failed, testID, restored, posted)
+			}
+			t.Logf("\t%s\tTest %d:\tShould count the expense again.", tests.Success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen purging posted records from the trash.", testID)
+		{
+			ctx := context.Background()
+			now := time.Date(2019, time.January, 20, 12, 0, 0, 0, time.UTC)
+
+			ni := income.NewIncome{
+				Name:             "Bonus",
+				CategoryID:       salaryID,
+				Amount:           money.Money{Amount: 20000, Currency: "EUR"},
+				Reoccurrence:     1,
+				ReoccurrenceType: recurrence.TypeOnce,
+				DurationType:     recurrence.DurationNone,
+				UserID:           userID,
+				AccountID:        checkingID,
+			}
+
+			inc, err := incCore.Create(ctx, ni, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an income : %s.", tests.Failed, testID, err)
+			}
+
+			ne := expense.NewExpense{
+				Name:             "Concert tickets",
+				CategoryID:       taxesID,
+				Amount:           money.Money{Amount: 7000, Currency: "EUR"},
+				Reoccurrence:     1,
+				ReoccurrenceType: recurrence.TypeOnce,
+				DurationType:     recurrence.DurationNone,
+				UserID:           userID,
+				AccountID:        checkingID,
+			}
+
+			exp, err := expCore.Create(ctx, ne, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create an expense : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create the records.", tests.Success, testID)
+
+			if _, err := trnCore.Materialize(ctx, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to post the ledger : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to post the ledger.", tests.Success, testID)
+
+			posted, err := query(ctx)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the totals : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to query the totals.", tests.Success, testID)
+
+			if err := incCore.Delete(ctx, inc.ID, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to delete the income : %s.", tests.Failed, testID, err)
+			}
+			if err := expCore.Delete(ctx, exp.ID, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to delete the expense : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to delete the records.", tests.Success, testID)
+
+			if err := core.Purge(ctx, now.Add(time.Hour)); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to purge the trash : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to purge the trash.", tests.Success, testID)
+
+			if _, err := expCore.QueryDeletedByID(ctx, exp.ID); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould remove the expense for good.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould remove the expense for good.", tests.Success, testID)
+
+			purged, err := query(ctx)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to query the totals : %s.", tests.Failed, testID, err)
+			}
+
+			exp2 := totals{
+				income:  posted.income - ni.Amount.Amount,
+				expense: posted.expense - ne.Amount.Amount,
+				balance: posted.balance - ni.Amount.Amount + ne.Amount.Amount,
+				ledger:  posted.ledger - 2,
+			}
+			if purged != exp2 {
+				t.Fatalf("\t%s\tTest %d:\tShould leave the purged records out of the totals : got %+v, exp %+v.", tests.Failed, testID, purged, exp2)
+			}
+			t.Logf("\t%s\tTest %d:\tShould leave the purged records out of the totals.", tests.Success, testID)
+		}
+	}
+}
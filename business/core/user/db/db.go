@@ -4,8 +4,11 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
 	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/order"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
@@ -49,9 +52,9 @@ func (s Store) Tran(tx sqlx.ExtContext) Store {
 func (s Store) Create(ctx context.Context, usr User) error {
 	const q = `
 	INSERT INTO users
-		(user_id, name, email, password_hash, roles, date_created, date_updated)
+		(user_id, name, email, password_hash, roles, base_currency, date_created, date_updated, version)
 	VALUES
-		(:user_id, :name, :email, :password_hash, :roles, :date_created, :date_updated)`
+		(:user_id, :name, :email, :password_hash, :roles, :base_currency, :date_created, :date_updated, :version)`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, usr); err != nil {
 		return fmt.Errorf("inserting user: %w", err)
@@ -60,7 +63,9 @@ func (s Store) Create(ctx context.Context, usr User) error {
 	return nil
 }
 
-// Update replaces a user document in the database.
+// Update replaces a user document in the database if it is still at the
+// version it was read at, and moves it to the next version. It returns
+// ErrDBNotFound when the user is gone or was changed in the meantime.
 func (s Store) Update(ctx context.Context, usr User) error {
 	const q = `
 	UPDATE
@@ -69,34 +74,90 @@ func (s Store) Update(ctx context.Context, usr User) error {
 		"name" = :name,
 		"email" = :email,
 		"roles" = :roles,
+		"base_currency" = :base_currency,
 		"password_hash" = :password_hash,
-		"date_updated" = :date_updated
+		"date_updated" = :date_updated,
+		"version" = version + 1
 	WHERE
-		user_id = :user_id`
+		user_id = :user_id AND
+		version = :version AND
+		deleted_at IS NULL`
 
-	if err := database.NamedExecContext(ctx, s.log, s.db, q, usr); err != nil {
+	if err := database.NamedExecRowContext(ctx, s.log, s.db, q, usr); err != nil {
 		return fmt.Errorf("updating userID[%s]: %w", usr.ID, err)
 	}
 
 	return nil
 }
 
-// Delete removes a user from the database.
-func (s Store) Delete(ctx context.Context, userID string) error {
+// Delete moves a user to the trash. The incomes and expenses of the user are
+// kept until the user is purged.
+func (s Store) Delete(ctx context.Context, userID string, now time.Time) error {
+	data := struct {
+		UserID    string    `db:"user_id"`
+		DeletedAt time.Time `db:"deleted_at"`
+	}{
+		UserID:    userID,
+		DeletedAt: now,
+	}
+
+	const q = `
+	UPDATE
+		users
+	SET
+		"deleted_at" = :deleted_at
+	WHERE
+		user_id = :user_id AND
+		deleted_at IS NULL`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// Restore takes a user out of the trash.
+func (s Store) Restore(ctx context.Context, userID string) error {
 	data := struct {
 		UserID string `db:"user_id"`
 	}{
 		UserID: userID,
 	}
 
+	const q = `
+	UPDATE
+		users
+	SET
+		"deleted_at" = NULL
+	WHERE
+		user_id = :user_id AND
+		deleted_at IS NOT NULL`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("restoring userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// Purge removes for good the users that were moved to the trash before the
+// given time, along with everything they own.
+func (s Store) Purge(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
 	const q = `
 	DELETE FROM
 		users
 	WHERE
-		user_id = :user_id`
+		deleted_at < :before`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
-		return fmt.Errorf("deleting userID[%s]: %w", userID, err)
+		return fmt.Errorf("purging users: %w", err)
 	}
 
 	return nil
@@ -117,6 +178,8 @@ func (s Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Us
 		*
 	FROM
 		users
+	WHERE
+		deleted_at IS NULL
 	ORDER BY
 		user_id
 	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
@@ -129,6 +192,80 @@ func (s Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Us
 	return usrs, nil
 }
 
+// orderByFields is the whitelist of the fields users can be ordered by along
+// with the column behind each of them.
+var orderByFields = map[string]string{
+	"user_id": "user_id",
+}
+
+// QueryAfter gets up to limit Users from the database in the given order,
+// starting right after the cursor. A nil cursor starts from the first User.
+// The returned cursor points at the last User and is nil when there are no
+// more of them.
+func (s Store) QueryAfter(ctx context.Context, orderBy order.By, after *cursor.Cursor, limit int) ([]User, *cursor.Cursor, error) {
+	data := map[string]any{
+		"limit": limit + 1,
+	}
+
+	orderByClause, err := order.Clause(orderBy, orderByFields)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ordering: %w", err)
+	}
+
+	where := "deleted_at IS NULL"
+	if after != nil {
+		data["cursor_id"] = after.ID
+		where += " AND " + after.Clause(orderByFields[orderBy.Field], "user_id")
+	}
+
+	q := `
+	SELECT
+		*
+	FROM
+		users
+	WHERE
+		` + where + `
+	ORDER BY
+		` + orderByClause + `
+	FETCH FIRST :limit ROWS ONLY`
+
+	var usrs []User
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &usrs); err != nil {
+		return nil, nil, fmt.Errorf("selecting users: %w", err)
+	}
+
+	// One extra user was asked for to know if there is another page.
+	if len(usrs) <= limit {
+		return usrs, nil, nil
+	}
+
+	usrs = usrs[:limit]
+	last := usrs[limit-1]
+	next := cursor.New(orderBy, last.ID, last.ID)
+
+	return usrs, &next, nil
+}
+
+// Count returns the number of Users in the database.
+func (s Store) Count(ctx context.Context) (int, error) {
+	const q = `
+	SELECT
+		COUNT(*) AS count
+	FROM
+		users
+	WHERE
+		deleted_at IS NULL`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, struct{}{}, &count); err != nil {
+		return 0, fmt.Errorf("counting users: %w", err)
+	}
+
+	return count.Count, nil
+}
+
 // QueryByID gets the specified user from the database.
 func (s Store) QueryByID(ctx context.Context, userID string) (User, error) {
 	data := struct {
@@ -142,8 +279,9 @@ func (s Store) QueryByID(ctx context.Context, userID string) (User, error) {
 		*
 	FROM
 		users
-	WHERE 
-		user_id = :user_id`
+	WHERE
+		user_id = :user_id AND
+		deleted_at IS NULL`
 
 	var usr User
 	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &usr); err != nil {
@@ -167,7 +305,8 @@ func (s Store) QueryByEmail(ctx context.Context, email string) (User, error) {
 	FROM
 		users
 	WHERE
-		email = :email`
+		email = :email AND
+		deleted_at IS NULL`
 
 	var usr User
 	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &usr); err != nil {
@@ -176,3 +315,49 @@ func (s Store) QueryByEmail(ctx context.Context, email string) (User, error) {
 
 	return usr, nil
 }
+
+// QueryDeletedByID gets the specified user in the trash from the database.
+func (s Store) QueryDeletedByID(ctx context.Context, userID string) (User, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		users
+	WHERE
+		user_id = :user_id AND
+		deleted_at IS NOT NULL`
+
+	var usr User
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &usr); err != nil {
+		return User{}, fmt.Errorf("selecting deleted userID[%q]: %w", userID, err)
+	}
+
+	return usr, nil
+}
+
+// QueryDeleted retrieves the users in the trash, the most recently deleted
+// first.
+func (s Store) QueryDeleted(ctx context.Context) ([]User, error) {
+	const q = `
+	SELECT
+		*
+	FROM
+		users
+	WHERE
+		deleted_at IS NOT NULL
+	ORDER BY
+		deleted_at DESC`
+
+	var usrs []User
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, struct{}{}, &usrs); err != nil {
+		return nil, fmt.Errorf("selecting deleted users: %w", err)
+	}
+
+	return usrs, nil
+}
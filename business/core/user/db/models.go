@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/lib/pq"
@@ -13,7 +14,10 @@ type User struct {
 	Name         string         `db:"name"`
 	Email        string         `db:"email"`
 	Roles        pq.StringArray `db:"roles"`
+	BaseCurrency string         `db:"base_currency"`
 	PasswordHash []byte         `db:"password_hash"`
 	DateCreated  time.Time      `db:"date_created"`
 	DateUpdated  time.Time      `db:"date_updated"`
+	DeletedAt    sql.NullTime   `db:"deleted_at"`
+	Version      int64          `db:"version"`
 }
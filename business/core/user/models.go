@@ -2,20 +2,22 @@ package user
 
 import (
 	"time"
-	"unsafe"
 
 	"github.com/gloompi/ultimate-service/business/core/user/db"
 )
 
 // User represents an individual user.
 type User struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Email        string    `json:"email"`
-	Roles        []string  `json:"roles"`
-	PasswordHash []byte    `json:"-"`
-	DateCreated  time.Time `json:"date_created"`
-	DateUpdated  time.Time `json:"date_updated"`
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	Roles        []string   `json:"roles"`
+	BaseCurrency string     `json:"base_currency"`
+	PasswordHash []byte     `json:"-"`
+	DateCreated  time.Time  `json:"date_created"`
+	DateUpdated  time.Time  `json:"date_updated"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	Version      int64      `json:"version"`
 }
 
 // NewUser contains information needed to create a new User.
@@ -23,6 +25,7 @@ type NewUser struct {
 	Name            string   `json:"name" validate:"required"`
 	Email           string   `json:"email" validate:"required,email"`
 	Roles           []string `json:"roles" validate:"required"`
+	BaseCurrency    string   `json:"base_currency" validate:"omitempty,iso4217"`
 	Password        string   `json:"password" validate:"required"`
 	PasswordConfirm string   `json:"password_confirm" validate:"eqfield=Password"`
 }
@@ -37,6 +40,7 @@ type UpdateUser struct {
 	Name            *string  `json:"name"`
 	Email           *string  `json:"email" validate:"omitempty,email"`
 	Roles           []string `json:"roles"`
+	BaseCurrency    *string  `json:"base_currency" validate:"omitempty,iso4217"`
 	Password        *string  `json:"password"`
 	PasswordConfirm *string  `json:"password_confirm" validate:"omitempty,eqfield=Password"`
 }
@@ -44,8 +48,21 @@ type UpdateUser struct {
 // =============================================================================
 
 func toUser(dbUsr db.User) User {
-	pu := (*User)(unsafe.Pointer(&dbUsr))
-	return *pu
+	usr := User{
+		ID:           dbUsr.ID,
+		Name:         dbUsr.Name,
+		Email:        dbUsr.Email,
+		Roles:        dbUsr.Roles,
+		BaseCurrency: dbUsr.BaseCurrency,
+		PasswordHash: dbUsr.PasswordHash,
+		DateCreated:  dbUsr.DateCreated,
+		DateUpdated:  dbUsr.DateUpdated,
+		Version:      dbUsr.Version,
+	}
+	if dbUsr.DeletedAt.Valid {
+		usr.DeletedAt = &dbUsr.DeletedAt.Time
+	}
+	return usr
 }
 
 func toUserSlice(dbUsrs []db.User) []User {
@@ -1,6 +1,5 @@
-// Package user provides an example of a core business API. Right now these
-// calls are just wrapping the data/data layer. But at some point you will
-// want auditing or something that isn't specific to the data/store layer.
+// Package user provides an example of a core business API. Every change made
+// to a user is recorded in the audit log along with it.
 package user
 
 import (
@@ -9,8 +8,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gloompi/ultimate-service/business/core/audit"
+	"github.com/gloompi/ultimate-service/business/core/fx"
 	"github.com/gloompi/ultimate-service/business/core/user/db"
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
 	"github.com/gloompi/ultimate-service/business/sys/database"
+	"github.com/gloompi/ultimate-service/business/sys/order"
 	"github.com/gloompi/ultimate-service/business/sys/validate"
 	"github.com/gloompi/ultimate-service/business/web/auth"
 	"github.com/golang-jwt/jwt/v4"
@@ -26,17 +29,23 @@ var (
 	ErrInvalidEmail          = errors.New("email is not valid")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrVersionConflict       = errors.New("user was changed by someone else")
 )
 
+// DefaultOrderBy lists the users by their ID, the only order they come in.
+var DefaultOrderBy = order.NewBy("user_id", order.ASC)
+
 // Core manages the set of APIs for user access.
 type Core struct {
 	store db.Store
+	audit audit.Core
 }
 
 // NewCore constructs a core for user api access.
 func NewCore(log *zap.SugaredLogger, sqlxDB *sqlx.DB) Core {
 	return Core{
 		store: db.NewStore(log, sqlxDB),
+		audit: audit.NewCore(log, sqlxDB),
 	}
 }
 
@@ -51,14 +60,22 @@ func (c Core) Create(ctx context.Context, nu NewUser, now time.Time) (User, erro
 		return User{}, fmt.Errorf("generating password hash: %w", err)
 	}
 
+	// Amounts are reported in euros unless the user asks for another currency.
+	baseCurrency := nu.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = fx.BaseCurrency
+	}
+
 	dbUsr := db.User{
 		ID:           validate.GenerateID(),
 		Name:         nu.Name,
 		Email:        nu.Email,
 		PasswordHash: hash,
 		Roles:        nu.Roles,
+		BaseCurrency: baseCurrency,
 		DateCreated:  now,
 		DateUpdated:  now,
+		Version:      1,
 	}
 
 	// This provides an example of how to execute a transaction if required.
@@ -69,6 +86,11 @@ func (c Core) Create(ctx context.Context, nu NewUser, now time.Time) (User, erro
 			}
 			return fmt.Errorf("create: %w", err)
 		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityUser, dbUsr.ID, audit.ActionCreate, nil, toUser(dbUsr), now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
 		return nil
 	}
 
@@ -79,8 +101,10 @@ func (c Core) Create(ctx context.Context, nu NewUser, now time.Time) (User, erro
 	return toUser(dbUsr), nil
 }
 
-// Update replaces a user document in the database.
-func (c Core) Update(ctx context.Context, userID string, uu UpdateUser, now time.Time) error {
+// Update replaces a user document in the database. When version is not zero
+// the user must still be at that version, and it must not change while it is
+// being updated in any case, or ErrVersionConflict is returned.
+func (c Core) Update(ctx context.Context, userID string, uu UpdateUser, version int64, now time.Time) error {
 	if err := validate.CheckID(userID); err != nil {
 		return ErrInvalidID
 	}
@@ -96,6 +120,10 @@ func (c Core) Update(ctx context.Context, userID string, uu UpdateUser, now time
 		}
 		return fmt.Errorf("updating user userID[%s]: %w", userID, err)
 	}
+	if version != 0 && dbUsr.Version != version {
+		return ErrVersionConflict
+	}
+	before := toUser(dbUsr)
 
 	if uu.Name != nil {
 		dbUsr.Name = *uu.Name
@@ -106,6 +134,9 @@ func (c Core) Update(ctx context.Context, userID string, uu UpdateUser, now time
 	if uu.Roles != nil {
 		dbUsr.Roles = uu.Roles
 	}
+	if uu.BaseCurrency != nil {
+		dbUsr.BaseCurrency = *uu.BaseCurrency
+	}
 	if uu.Password != nil {
 		pw, err := bcrypt.GenerateFromPassword([]byte(*uu.Password), bcrypt.DefaultCost)
 		if err != nil {
@@ -115,24 +146,100 @@ func (c Core) Update(ctx context.Context, userID string, uu UpdateUser, now time
 	}
 	dbUsr.DateUpdated = now
 
-	if err := c.store.Update(ctx, dbUsr); err != nil {
-		if errors.Is(err, database.ErrDBDuplicatedEntry) {
-			return fmt.Errorf("updating user userID[%s]: %w", userID, ErrUniqueEmail)
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Update(ctx, dbUsr); err != nil {
+			switch {
+			case errors.Is(err, database.ErrDBDuplicatedEntry):
+				return fmt.Errorf("updating user userID[%s]: %w", userID, ErrUniqueEmail)
+			case errors.Is(err, database.ErrDBNotFound):
+				return ErrVersionConflict
+			}
+			return fmt.Errorf("update: %w", err)
+		}
+		dbUsr.Version++
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityUser, userID, audit.ActionUpdate, before, toUser(dbUsr), now); err != nil {
+			return fmt.Errorf("audit: %w", err)
 		}
-		return fmt.Errorf("update: %w", err)
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes a user from the database.
-func (c Core) Delete(ctx context.Context, userID string) error {
-	if err := validate.CheckID(userID); err != nil {
-		return ErrInvalidID
+// Delete moves a user to the trash. It stays there until it is restored or
+// purged.
+func (c Core) Delete(ctx context.Context, userID string, now time.Time) error {
+	before, err := c.QueryByID(ctx, userID)
+	if err != nil {
+		return err
 	}
 
-	if err := c.store.Delete(ctx, userID); err != nil {
-		return fmt.Errorf("delete: %w", err)
+	after := before
+	after.DeletedAt = &now
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Delete(ctx, userID, now); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityUser, userID, audit.ActionDelete, before, after, now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// Restore takes a user out of the trash. It fails when another user took the
+// email in the meantime.
+func (c Core) Restore(ctx context.Context, userID string, now time.Time) error {
+	before, err := c.QueryDeletedByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.DeletedAt = nil
+
+	tran := func(tx sqlx.ExtContext) error {
+		if err := c.store.Tran(tx).Restore(ctx, userID); err != nil {
+			if errors.Is(err, database.ErrDBDuplicatedEntry) {
+				return fmt.Errorf("restore: %w", ErrUniqueEmail)
+			}
+			return fmt.Errorf("restore: %w", err)
+		}
+
+		if err := c.audit.Tran(tx).Record(ctx, audit.EntityUser, userID, audit.ActionRestore, before, after, now); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.store.WithinTran(ctx, tran); err != nil {
+		return fmt.Errorf("tran: %w", err)
+	}
+
+	return nil
+}
+
+// Purge removes for good the users that were moved to the trash before the
+// given time.
+func (c Core) Purge(ctx context.Context, before time.Time) error {
+	if err := c.store.Purge(ctx, before); err != nil {
+		return fmt.Errorf("purge: %w", err)
 	}
 
 	return nil
@@ -148,6 +255,35 @@ func (c Core) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Use
 	return toUserSlice(dbUsers), nil
 }
 
+// QueryPage gets a page of the users ordered by their ID, starting right
+// after the cursor token. An empty token starts from the first user.
+func (c Core) QueryPage(ctx context.Context, token string, limit int) (cursor.Page[User], error) {
+	after, err := cursor.Decode(token, DefaultOrderBy)
+	if err != nil {
+		return cursor.Page[User]{}, err
+	}
+
+	dbUsrs, next, err := c.store.QueryAfter(ctx, DefaultOrderBy, after, limit)
+	if err != nil {
+		return cursor.Page[User]{}, fmt.Errorf("query: %w", err)
+	}
+
+	total, err := c.store.Count(ctx)
+	if err != nil {
+		return cursor.Page[User]{}, fmt.Errorf("count: %w", err)
+	}
+
+	page := cursor.Page[User]{
+		Items: toUserSlice(dbUsrs),
+		Total: total,
+	}
+	if next != nil {
+		page.NextCursor = next.Encode()
+	}
+
+	return page, nil
+}
+
 // QueryByID gets the specified user from the database.
 func (c Core) QueryByID(ctx context.Context, userID string) (User, error) {
 	if err := validate.CheckID(userID); err != nil {
@@ -165,6 +301,33 @@ func (c Core) QueryByID(ctx context.Context, userID string) (User, error) {
 	return toUser(dbUsr), nil
 }
 
+// QueryDeletedByID gets the specified user in the trash from the database.
+func (c Core) QueryDeletedByID(ctx context.Context, userID string) (User, error) {
+	if err := validate.CheckID(userID); err != nil {
+		return User{}, ErrInvalidID
+	}
+
+	dbUsr, err := c.store.QueryDeletedByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return User{}, ErrNotFound
+		}
+		return User{}, fmt.Errorf("query: %w", err)
+	}
+
+	return toUser(dbUsr), nil
+}
+
+// QueryDeleted retrieves the users in the trash.
+func (c Core) QueryDeleted(ctx context.Context) ([]User, error) {
+	dbUsrs, err := c.store.QueryDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return toUserSlice(dbUsrs), nil
+}
+
 // QueryByEmail gets the specified user from the database by email.
 func (c Core) QueryByEmail(ctx context.Context, email string) (User, error) {
 	// Email Validate function in validate.
@@ -73,7 +73,7 @@ func Test_User(t *testing.T) {
 				Email: tests.StringPointer("jacob@test.com"),
 			}
 
-			if err := core.Update(ctx, usr.ID, upd, now); err != nil {
+			if err := core.Update(ctx, usr.ID, upd, 0, now); err != nil {
 				t.Fatalf("\t%s\tTest %d:\tShould be able to update user : %s.", tests.Failed, testID, err)
 			}
 			t.Logf("\t%s\tTest %d:\tShould be able to update user.", tests.Success, testID)
@@ -100,7 +100,7 @@ func Test_User(t *testing.T) {
 				t.Logf("\t%s\tTest %d:\tShould be able to see updates to Email.", tests.Success, testID)
 			}
 
-			if err := core.Delete(ctx, usr.ID); err != nil {
+			if err := core.Delete(ctx, usr.ID, now); err != nil {
 				t.Fatalf("\t%s\tTest %d:\tShould be able to delete user : %s.", tests.Failed, testID, err)
 			}
 			t.Logf("\t%s\tTest %d:\tShould be able to delete user.", tests.Success, testID)
@@ -110,6 +110,16 @@ func Test_User(t *testing.T) {
 				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to retrieve user : %s.", tests.Failed, testID, err)
 			}
 			t.Logf("\t%s\tTest %d:\tShould NOT be able to retrieve user.", tests.Success, testID)
+
+			if err := core.Restore(ctx, usr.ID, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to restore user : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to restore user.", tests.Success, testID)
+
+			if _, err := core.QueryByID(ctx, usr.ID); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve restored user : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to retrieve restored user.", tests.Success, testID)
 		}
 	}
 }
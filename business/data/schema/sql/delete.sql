@@ -1,3 +1,23 @@
+DELETE FROM rules;
+DELETE FROM attachments;
+DELETE FROM audit_events;
+DELETE FROM settlements;
+DELETE FROM household_shares;
+DELETE FROM household_expenses;
+DELETE FROM household_members;
+DELETE FROM households;
+DELETE FROM debt_payments;
+DELETE FROM debts;
+DELETE FROM goal_contributions;
+DELETE FROM goals;
+DELETE FROM transaction_tags;
+DELETE FROM tags;
+DELETE FROM transfers;
+DELETE FROM budgets;
+DELETE FROM exchange_rates;
+DELETE FROM transactions;
 DELETE FROM expenses;
 DELETE FROM incomes;
+DELETE FROM accounts;
+DELETE FROM categories WHERE user_id IS NOT NULL;
 DELETE FROM users;
\ No newline at end of file
@@ -56,3 +56,465 @@ CREATE TABLE expenses (
 	PRIMARY KEY (expense_id),
 	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
 );
+
+-- Version: 1.5
+-- Description: Create table transactions
+CREATE TABLE transactions (
+	transaction_id UUID,
+	user_id        UUID,
+	source_type    TEXT,
+	source_id      UUID,
+	name           TEXT,
+	category       TEXT,
+	currency       TEXT,
+	amount         INT,
+	date_occurred  TIMESTAMP,
+	date_created   TIMESTAMP,
+
+	PRIMARY KEY (transaction_id),
+	UNIQUE (source_id, date_occurred),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.6
+-- Description: Create table exchange_rates
+CREATE TABLE exchange_rates (
+	currency  TEXT,
+	rate_date TIMESTAMP,
+	rate      NUMERIC(18, 8),
+
+	PRIMARY KEY (currency, rate_date)
+);
+
+-- Version: 1.7
+-- Description: Add base currency to users
+ALTER TABLE users ADD COLUMN base_currency TEXT DEFAULT 'EUR';
+
+-- Version: 1.8
+-- Description: Store amounts in the minor unit of their currency
+ALTER TABLE incomes ALTER COLUMN amount TYPE BIGINT;
+ALTER TABLE expenses ALTER COLUMN amount TYPE BIGINT;
+ALTER TABLE transactions ALTER COLUMN amount TYPE BIGINT;
+UPDATE incomes SET currency = UPPER(currency), amount = amount * CASE
+	WHEN UPPER(currency) IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 1
+	WHEN UPPER(currency) IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 1000
+	WHEN UPPER(currency) IN ('CLF', 'UYW') THEN 10000
+	ELSE 100
+END;
+UPDATE expenses SET currency = UPPER(currency), amount = amount * CASE
+	WHEN UPPER(currency) IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 1
+	WHEN UPPER(currency) IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 1000
+	WHEN UPPER(currency) IN ('CLF', 'UYW') THEN 10000
+	ELSE 100
+END;
+UPDATE transactions SET currency = UPPER(currency), amount = amount * CASE
+	WHEN UPPER(currency) IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 1
+	WHEN UPPER(currency) IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 1000
+	WHEN UPPER(currency) IN ('CLF', 'UYW') THEN 10000
+	ELSE 100
+END;
+
+-- Version: 1.9
+-- Description: Create table budgets
+CREATE TABLE budgets (
+	budget_id    UUID,
+	user_id      UUID,
+	category     TEXT,
+	currency     TEXT,
+	amount       BIGINT,
+	date_created TIMESTAMP,
+	date_updated TIMESTAMP,
+
+	PRIMARY KEY (budget_id),
+	UNIQUE (user_id, category, currency),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.10
+-- Description: Create table accounts
+CREATE TABLE accounts (
+	account_id      UUID,
+	user_id         UUID,
+	name            TEXT,
+	type            TEXT,
+	currency        TEXT,
+	opening_balance BIGINT,
+	date_created    TIMESTAMP,
+	date_updated    TIMESTAMP,
+
+	PRIMARY KEY (account_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.11
+-- Description: Link incomes, expenses and transactions to accounts
+ALTER TABLE incomes ADD COLUMN account_id UUID REFERENCES accounts(account_id) ON DELETE SET NULL;
+ALTER TABLE expenses ADD COLUMN account_id UUID REFERENCES accounts(account_id) ON DELETE SET NULL;
+ALTER TABLE transactions ADD COLUMN account_id UUID REFERENCES accounts(account_id) ON DELETE SET NULL;
+
+-- Version: 1.12
+-- Description: Create table transfers
+CREATE TABLE transfers (
+	transfer_id     UUID,
+	user_id         UUID,
+	from_account_id UUID,
+	to_account_id   UUID,
+	currency        TEXT,
+	amount          BIGINT,
+	to_currency     TEXT,
+	to_amount       BIGINT,
+	date_occurred   TIMESTAMP,
+	date_created    TIMESTAMP,
+
+	PRIMARY KEY (transfer_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (from_account_id) REFERENCES accounts(account_id) ON DELETE CASCADE,
+	FOREIGN KEY (to_account_id) REFERENCES accounts(account_id) ON DELETE CASCADE
+);
+
+-- Version: 1.13
+-- Description: Allow both legs of a transfer in the transactions ledger
+ALTER TABLE transactions DROP CONSTRAINT transactions_source_id_date_occurred_key;
+ALTER TABLE transactions ADD UNIQUE (source_type, source_id, date_occurred);
+
+-- Version: 1.14
+-- Description: Create table categories
+CREATE TABLE categories (
+	category_id  UUID,
+	user_id      UUID,
+	parent_id    UUID,
+	name         TEXT,
+	kind         TEXT,
+	icon         TEXT,
+	color        TEXT,
+	date_created TIMESTAMP,
+	date_updated TIMESTAMP,
+
+	PRIMARY KEY (category_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (parent_id) REFERENCES categories(category_id)
+);
+CREATE UNIQUE INDEX categories_name_key ON categories (
+	COALESCE(user_id, '00000000-0000-0000-0000-000000000000'),
+	COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'),
+	kind,
+	LOWER(name)
+);
+
+-- Version: 1.15
+-- Description: Add the default categories shared by every user
+INSERT INTO categories (category_id, user_id, parent_id, name, kind, icon, color, date_created, date_updated) VALUES
+	('49698892-62e7-4770-aeb6-f3677be0855b', NULL, NULL, 'Salary', 'income', 'briefcase', '#2E7D32', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('4e5eb8f6-96aa-4b46-bbea-064fca1cda63', NULL, NULL, 'Freelance', 'income', 'laptop', '#388E3C', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('c54911b4-14da-4373-8cd9-96f4482364e8', NULL, NULL, 'Investments', 'income', 'trending-up', '#43A047', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('2b21b4f1-73a2-4fbd-8d52-cfb28bd974b8', NULL, NULL, 'Rental Income', 'income', 'key', '#4CAF50', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('ad6bd099-d76f-4c76-9f75-17c896dab563', NULL, NULL, 'Gifts', 'income', 'gift', '#66BB6A', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('d557d02e-b4d5-4519-bf93-9ed3ab72d68f', NULL, NULL, 'Other Income', 'income', 'plus-circle', '#81C784', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('2c0c29cc-9e65-4c23-8f03-0c0cf0a7b93b', NULL, NULL, 'Housing', 'expense', 'home', '#C62828', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('6a958c5b-32e9-4bfc-ba23-70a25ce9310e', NULL, NULL, 'Food', 'expense', 'shopping-cart', '#EF6C00', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('cda07ac5-8f2f-406a-9b4f-c2fa32f91d00', NULL, NULL, 'Transportation', 'expense', 'truck', '#1565C0', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('a95aa504-60fc-4df6-8170-990756b59927', NULL, NULL, 'Health', 'expense', 'heart', '#AD1457', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('35eeddfb-9282-4416-be90-6ea8595247a0', NULL, NULL, 'Entertainment', 'expense', 'film', '#6A1B9A', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('d92dfe48-fd3d-4088-a734-a66b77e22ecf', NULL, NULL, 'Shopping', 'expense', 'shopping-bag', '#00838F', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('eda753a3-e860-4153-aeac-3a1bbee5c3f2', NULL, NULL, 'Education', 'expense', 'book', '#283593', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9', NULL, NULL, 'Taxes', 'expense', 'file-text', '#4E342E', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('a498b021-f4ed-4f7f-9695-9e789e3ddf78', NULL, NULL, 'Other Expenses', 'expense', 'more-horizontal', '#616161', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('c636a049-1c09-47a3-998c-ea38010faf6c', NULL, '2c0c29cc-9e65-4c23-8f03-0c0cf0a7b93b', 'Rent', 'expense', 'home', '#D32F2F', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('a0d2f578-9b43-45c9-85a0-eddffa9f3b62', NULL, '2c0c29cc-9e65-4c23-8f03-0c0cf0a7b93b', 'Utilities', 'expense', 'zap', '#E53935', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('672fab88-6848-45e1-adeb-7c38d7881b45', NULL, '6a958c5b-32e9-4bfc-ba23-70a25ce9310e', 'Groceries', 'expense', 'shopping-cart', '#F57C00', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('6ae35d93-a63d-47e0-a6be-a5cdd5ded47d', NULL, '6a958c5b-32e9-4bfc-ba23-70a25ce9310e', 'Restaurants', 'expense', 'coffee', '#FB8C00', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('a4dfca7d-e8f8-41bc-b281-6e7fb5a110a6', NULL, 'cda07ac5-8f2f-406a-9b4f-c2fa32f91d00', 'Fuel', 'expense', 'droplet', '#1976D2', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('1dd8db68-020c-4e95-bcbe-ea11d4b4348e', NULL, 'cda07ac5-8f2f-406a-9b4f-c2fa32f91d00', 'Public Transport', 'expense', 'navigation', '#1E88E5', '2019-01-01 00:00:00', '2019-01-01 00:00:00');
+
+-- Version: 1.16
+-- Description: Replace free-text categories with references to categories
+ALTER TABLE incomes ADD COLUMN category_id UUID REFERENCES categories(category_id);
+ALTER TABLE expenses ADD COLUMN category_id UUID REFERENCES categories(category_id);
+ALTER TABLE budgets ADD COLUMN category_id UUID REFERENCES categories(category_id) ON DELETE CASCADE;
+ALTER TABLE transactions ADD COLUMN category_id UUID REFERENCES categories(category_id) ON DELETE SET NULL;
+INSERT INTO categories (category_id, user_id, parent_id, name, kind, icon, color, date_created, date_updated)
+	SELECT gen_random_uuid(), src.user_id, NULL, MIN(src.name), src.kind, '', '', NOW(), NOW()
+	FROM (
+		SELECT user_id, TRIM(category) AS name, 'income' AS kind FROM incomes
+		UNION ALL
+		SELECT user_id, TRIM(category), 'expense' FROM expenses
+		UNION ALL
+		SELECT user_id, TRIM(category), 'expense' FROM budgets
+		UNION ALL
+		SELECT user_id, TRIM(category), source_type FROM transactions WHERE source_type IN ('income', 'expense')
+	) AS src
+	WHERE
+		src.name <> '' AND
+		NOT EXISTS (SELECT 1 FROM categories AS c WHERE c.user_id IS NULL AND c.kind = src.kind AND LOWER(c.name) = LOWER(src.name))
+	GROUP BY src.user_id, src.kind, LOWER(src.name);
+UPDATE incomes AS t SET category_id = COALESCE(
+	(SELECT c.category_id FROM categories AS c WHERE c.kind = 'income' AND (c.user_id IS NULL OR c.user_id = t.user_id) AND LOWER(c.name) = LOWER(TRIM(t.category)) ORDER BY c.user_id NULLS FIRST LIMIT 1),
+	'd557d02e-b4d5-4519-bf93-9ed3ab72d68f'
+);
+UPDATE expenses AS t SET category_id = COALESCE(
+	(SELECT c.category_id FROM categories AS c WHERE c.kind = 'expense' AND (c.user_id IS NULL OR c.user_id = t.user_id) AND LOWER(c.name) = LOWER(TRIM(t.category)) ORDER BY c.user_id NULLS FIRST LIMIT 1),
+	'a498b021-f4ed-4f7f-9695-9e789e3ddf78'
+);
+UPDATE budgets AS t SET category_id = COALESCE(
+	(SELECT c.category_id FROM categories AS c WHERE c.kind = 'expense' AND (c.user_id IS NULL OR c.user_id = t.user_id) AND LOWER(c.name) = LOWER(TRIM(t.category)) ORDER BY c.user_id NULLS FIRST LIMIT 1),
+	'a498b021-f4ed-4f7f-9695-9e789e3ddf78'
+);
+UPDATE transactions AS t SET category_id = COALESCE(
+	(SELECT c.category_id FROM categories AS c WHERE c.kind = t.source_type AND (c.user_id IS NULL OR c.user_id = t.user_id) AND LOWER(c.name) = LOWER(TRIM(t.category)) ORDER BY c.user_id NULLS FIRST LIMIT 1),
+	CASE t.source_type WHEN 'income' THEN CAST('d557d02e-b4d5-4519-bf93-9ed3ab72d68f' AS UUID) ELSE CAST('a498b021-f4ed-4f7f-9695-9e789e3ddf78' AS UUID) END
+) WHERE t.source_type IN ('income', 'expense');
+DELETE FROM budgets AS b USING budgets AS o
+	WHERE b.user_id = o.user_id AND b.category_id = o.category_id AND b.currency = o.currency AND (b.date_created, b.budget_id) > (o.date_created, o.budget_id);
+ALTER TABLE incomes DROP COLUMN category;
+ALTER TABLE incomes ALTER COLUMN category_id SET NOT NULL;
+ALTER TABLE expenses DROP COLUMN category;
+ALTER TABLE expenses ALTER COLUMN category_id SET NOT NULL;
+ALTER TABLE budgets DROP COLUMN category;
+ALTER TABLE budgets ALTER COLUMN category_id SET NOT NULL;
+ALTER TABLE budgets ADD UNIQUE (user_id, category_id, currency);
+ALTER TABLE transactions DROP COLUMN category;
+
+-- Version: 1.17
+-- Description: Create tables tags and transaction_tags
+CREATE TABLE tags (
+	tag_id       UUID,
+	user_id      UUID,
+	name         TEXT,
+	date_created TIMESTAMP,
+	date_updated TIMESTAMP,
+
+	PRIMARY KEY (tag_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX tags_name_key ON tags (user_id, LOWER(name));
+CREATE TABLE transaction_tags (
+	tag_id     UUID,
+	income_id  UUID,
+	expense_id UUID,
+
+	UNIQUE (tag_id, income_id),
+	UNIQUE (tag_id, expense_id),
+	CHECK ((income_id IS NULL) <> (expense_id IS NULL)),
+	FOREIGN KEY (tag_id) REFERENCES tags(tag_id) ON DELETE CASCADE,
+	FOREIGN KEY (income_id) REFERENCES incomes(income_id) ON DELETE CASCADE,
+	FOREIGN KEY (expense_id) REFERENCES expenses(expense_id) ON DELETE CASCADE
+);
+
+-- Version: 1.18
+-- Description: Add external_id to incomes and expenses
+ALTER TABLE incomes ADD COLUMN external_id TEXT;
+CREATE UNIQUE INDEX incomes_external_id_key ON incomes (user_id, external_id);
+ALTER TABLE expenses ADD COLUMN external_id TEXT;
+CREATE UNIQUE INDEX expenses_external_id_key ON expenses (user_id, external_id);
+
+-- Version: 1.19
+-- Description: Create tables goals and goal_contributions
+CREATE TABLE goals (
+	goal_id      UUID,
+	user_id      UUID,
+	account_id   UUID,
+	name         TEXT,
+	currency     TEXT,
+	target       BIGINT,
+	deadline     TIMESTAMP,
+	date_created TIMESTAMP,
+	date_updated TIMESTAMP,
+
+	PRIMARY KEY (goal_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (account_id) REFERENCES accounts(account_id) ON DELETE SET NULL
+);
+CREATE TABLE goal_contributions (
+	contribution_id  UUID,
+	goal_id          UUID,
+	amount           BIGINT,
+	date_contributed TIMESTAMP,
+	date_created     TIMESTAMP,
+
+	PRIMARY KEY (contribution_id),
+	FOREIGN KEY (goal_id) REFERENCES goals(goal_id) ON DELETE CASCADE
+);
+
+-- Version: 1.20
+-- Description: Create tables debts and debt_payments
+INSERT INTO categories (category_id, user_id, parent_id, name, kind, icon, color, date_created, date_updated) VALUES
+	('f0b3c1d2-7e4a-4c5b-9d6e-1a2b3c4d5e6f', NULL, NULL, 'Loans', 'expense', 'credit-card', '#5D4037', '2019-01-01 00:00:00', '2019-01-01 00:00:00');
+CREATE TABLE debts (
+	debt_id       UUID,
+	user_id       UUID,
+	account_id    UUID,
+	category_id   UUID,
+	name          TEXT,
+	currency      TEXT,
+	principal     BIGINT,
+	interest_rate NUMERIC(9, 6),
+	term          INT,
+	frequency     TEXT,
+	start_date    TIMESTAMP,
+	date_created  TIMESTAMP,
+	date_updated  TIMESTAMP,
+
+	PRIMARY KEY (debt_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (account_id) REFERENCES accounts(account_id) ON DELETE SET NULL,
+	FOREIGN KEY (category_id) REFERENCES categories(category_id)
+);
+CREATE TABLE debt_payments (
+	payment_id   UUID,
+	debt_id      UUID,
+	amount       BIGINT,
+	date_paid    TIMESTAMP,
+	date_created TIMESTAMP,
+
+	PRIMARY KEY (payment_id),
+	FOREIGN KEY (debt_id) REFERENCES debts(debt_id) ON DELETE CASCADE
+);
+
+-- Version: 1.21
+-- Description: Create tables for households, their members, split expenses and settlements
+CREATE TABLE households (
+	household_id UUID,
+	name         TEXT,
+	date_created TIMESTAMP,
+	date_updated TIMESTAMP,
+
+	PRIMARY KEY (household_id)
+);
+CREATE TABLE household_members (
+	household_id UUID,
+	user_id      UUID,
+	role         TEXT,
+	date_created TIMESTAMP,
+
+	PRIMARY KEY (household_id, user_id),
+	FOREIGN KEY (household_id) REFERENCES households(household_id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+CREATE TABLE household_expenses (
+	expense_id   UUID,
+	household_id UUID,
+	method       TEXT,
+	date_created TIMESTAMP,
+
+	PRIMARY KEY (expense_id),
+	FOREIGN KEY (expense_id) REFERENCES expenses(expense_id) ON DELETE CASCADE,
+	FOREIGN KEY (household_id) REFERENCES households(household_id) ON DELETE CASCADE
+);
+CREATE TABLE household_shares (
+	expense_id UUID,
+	user_id    UUID,
+	weight     BIGINT,
+
+	PRIMARY KEY (expense_id, user_id),
+	FOREIGN KEY (expense_id) REFERENCES household_expenses(expense_id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+CREATE TABLE settlements (
+	settlement_id UUID,
+	household_id  UUID,
+	from_user_id  UUID,
+	to_user_id    UUID,
+	currency      TEXT,
+	amount        BIGINT,
+	date_settled  TIMESTAMP,
+	date_created  TIMESTAMP,
+
+	PRIMARY KEY (settlement_id),
+	FOREIGN KEY (household_id) REFERENCES households(household_id) ON DELETE CASCADE,
+	FOREIGN KEY (from_user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (to_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.22
+-- Description: Add indexes for keyset pagination of incomes and expenses
+CREATE INDEX incomes_date_created_idx ON incomes (date_created, income_id);
+CREATE INDEX expenses_date_created_idx ON expenses (date_created, expense_id);
+
+-- Version: 1.23
+-- Description: Soft delete users, incomes and expenses
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE incomes ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE expenses ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_idx ON users (email) WHERE deleted_at IS NULL;
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX incomes_deleted_at_idx ON incomes (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX expenses_deleted_at_idx ON expenses (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Version: 1.24
+-- Description: Create table audit_events
+CREATE TABLE audit_events (
+	event_id     UUID,
+	entity       TEXT,
+	entity_id    UUID,
+	action       TEXT,
+	actor_id     UUID,
+	trace_id     TEXT,
+	changes      JSONB,
+	date_created TIMESTAMP,
+
+	PRIMARY KEY (event_id)
+);
+CREATE INDEX audit_events_entity_idx ON audit_events (entity, entity_id, date_created);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor_id, date_created);
+
+-- Version: 1.25
+-- Description: Add version to users, incomes and expenses for optimistic locking
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE incomes ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE expenses ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- Version: 1.26
+-- Description: Create table attachments
+CREATE TABLE attachments (
+	attachment_id UUID,
+	expense_id    UUID,
+	user_id       UUID,
+	file_name     TEXT,
+	content_type  TEXT,
+	size          BIGINT,
+	blob_key      TEXT,
+	date_created  TIMESTAMP,
+
+	PRIMARY KEY (attachment_id),
+	FOREIGN KEY (expense_id) REFERENCES expenses(expense_id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+CREATE INDEX attachments_expense_idx ON attachments (expense_id, date_created);
+
+-- Version: 1.27
+-- Description: Create table rules
+CREATE TABLE rules (
+	rule_id      UUID,
+	user_id      UUID,
+	name         TEXT,
+	kind         TEXT,
+	priority     INT,
+	conditions   JSONB,
+	category_id  UUID,
+	tags         TEXT[],
+	date_created TIMESTAMP,
+	date_updated TIMESTAMP,
+
+	PRIMARY KEY (rule_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (category_id) REFERENCES categories(category_id) ON DELETE SET NULL
+);
+CREATE INDEX rules_user_idx ON rules (user_id, priority, date_created);
+
+-- Version: 1.28
+-- Description: Remove the postings left behind by purged incomes and expenses
+DELETE FROM
+	transactions AS t
+WHERE
+	(t.source_type = 'income' AND NOT EXISTS (SELECT 1 FROM incomes AS i WHERE i.income_id = t.source_id)) OR
+	(t.source_type = 'expense' AND NOT EXISTS (SELECT 1 FROM expenses AS e WHERE e.expense_id = t.source_id));
+
+-- Version: 1.29
+-- Description: Remove the postings left behind by deleted debts
+DELETE FROM
+	transactions AS t
+WHERE
+	t.source_type = 'debt_payment' AND
+	NOT EXISTS (SELECT 1 FROM debts AS d WHERE d.debt_id = t.source_id) AND
+	NOT EXISTS (SELECT 1 FROM debt_payments AS p WHERE p.payment_id = t.source_id);
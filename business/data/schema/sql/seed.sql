@@ -3,19 +3,55 @@ INSERT INTO users (user_id, name, email, roles, password_hash, date_created, dat
 	('45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'User Gopher', 'user@example.com', '{USER}', '$2a$10$9/XASPKBbJKVfCAZKDH.UuhsuALDr5vVm6VrYA9VFR8rccK86C1hW', '2019-03-24 00:00:00', '2019-03-24 00:00:00')
 	ON CONFLICT DO NOTHING;
 
-INSERT INTO incomes (income_id, user_id, name, category, currency, amount, reoccurrence, duration, reoccurrence_type, duration_type, date_created, date_updated) VALUES
-	('a2b0639f-2cc6-44b8-b97b-15d69dbb511e', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Web Development', 'Job', 'EUR', 6250, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
-	('72f8b983-3eb4-48db-9ed0-e45cc6bd716b', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Freelance', 'Part-time Job', 'EUR', 3200, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
-	('7424b1d4-37f2-4d10-ae36-16365ce30dbf', '5cf37266-3473-4006-984f-9325122678b7', 'Software engineer', 'Job', 'EUR', 8200, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
-	('775e20aa-3e57-4b10-b5f3-bad95ee50337', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Grocery store', 'Assets', 'EUR', 500, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
-	('484e5fdb-da74-478b-a66c-f0e28388f257', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Billboard', 'Assets', 'EUR', 250, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
-	('c74111d6-4a5a-41d1-801f-0b8dbc6d3ef9', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Mobile app', 'Part-time Job', 'EUR', 300, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
-	('7d32570f-5206-4ed5-93b0-40f71ecc3300', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Go courses', 'Assets', 'EUR', 1500, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
-	('42483f2e-58d6-4b28-922c-d09c9d1e1193', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Appertment rental', 'Assets', 'EUR', 700, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00')
-	ON CONFLICT DO NOTHING;
-
-INSERT INTO expenses (expense_id, user_id, name, category, currency, amount, reoccurrence, duration, reoccurrence_type, duration_type, date_created, date_updated) VALUES
-	('98b6d4b8-f04b-4c79-8c2e-a0aef46854b7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Taxes', 'Fees', 'EUR', 2550, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
-	('85f6fb09-eb05-4874-ae39-82d1a30fe0d7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Consumer Basket', 'Other', 'EUR', 800, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
-	('a235be9e-ab5d-44e6-a987-fa1c749264c7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Appartment Rent', 'Routine', 'EUR', 800, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00')
-	ON CONFLICT DO NOTHING;
\ No newline at end of file
+INSERT INTO incomes (income_id, user_id, name, category_id, currency, amount, reoccurrence, duration, reoccurrence_type, duration_type, date_created, date_updated) VALUES
+	('a2b0639f-2cc6-44b8-b97b-15d69dbb511e', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Web Development', '49698892-62e7-4770-aeb6-f3677be0855b', 'EUR', 625000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('72f8b983-3eb4-48db-9ed0-e45cc6bd716b', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Freelance', '4e5eb8f6-96aa-4b46-bbea-064fca1cda63', 'EUR', 320000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('7424b1d4-37f2-4d10-ae36-16365ce30dbf', '5cf37266-3473-4006-984f-9325122678b7', 'Software engineer', '49698892-62e7-4770-aeb6-f3677be0855b', 'EUR', 820000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('775e20aa-3e57-4b10-b5f3-bad95ee50337', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Grocery store', 'c54911b4-14da-4373-8cd9-96f4482364e8', 'EUR', 50000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('484e5fdb-da74-478b-a66c-f0e28388f257', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Billboard', 'c54911b4-14da-4373-8cd9-96f4482364e8', 'EUR', 25000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('c74111d6-4a5a-41d1-801f-0b8dbc6d3ef9', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Mobile app', '4e5eb8f6-96aa-4b46-bbea-064fca1cda63', 'EUR', 30000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('7d32570f-5206-4ed5-93b0-40f71ecc3300', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Go courses', 'c54911b4-14da-4373-8cd9-96f4482364e8', 'EUR', 150000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('42483f2e-58d6-4b28-922c-d09c9d1e1193', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Appertment rental', '2b21b4f1-73a2-4fbd-8d52-cfb28bd974b8', 'EUR', 70000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO expenses (expense_id, user_id, name, category_id, currency, amount, reoccurrence, duration, reoccurrence_type, duration_type, date_created, date_updated) VALUES
+	('98b6d4b8-f04b-4c79-8c2e-a0aef46854b7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Taxes', '4a02c57f-ef2a-48bd-9f90-d3369d8fd5c9', 'EUR', 255000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('85f6fb09-eb05-4874-ae39-82d1a30fe0d7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Consumer Basket', '672fab88-6848-45e1-adeb-7c38d7881b45', 'EUR', 80000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('a235be9e-ab5d-44e6-a987-fa1c749264c7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Appartment Rent', 'c636a049-1c09-47a3-998c-ea38010faf6c', 'EUR', 80000, 1, 0, 'Monthly', 'None', '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00')
+	ON CONFLICT DO NOTHING;
+INSERT INTO exchange_rates (currency, rate_date, rate) VALUES
+	('USD', '2019-01-01 00:00:00', 1.1450),
+	('GBP', '2019-01-01 00:00:00', 0.8945),
+	('JPY', '2019-01-01 00:00:00', 125.85),
+	('CHF', '2019-01-01 00:00:00', 1.1269)
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO budgets (budget_id, user_id, category_id, currency, amount, date_created, date_updated) VALUES
+	('0b9e4a6c-3f5e-4d0a-9a57-2f1a8c6f4e11', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', '2c0c29cc-9e65-4c23-8f03-0c0cf0a7b93b', 'EUR', 100000, '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00'),
+	('5d7c2e8b-1a4f-4b6e-8c3d-9e0f1a2b3c4d', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', '6a958c5b-32e9-4bfc-ba23-70a25ce9310e', 'EUR', 50000, '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO accounts (account_id, user_id, name, type, currency, opening_balance, date_created, date_updated) VALUES
+	('c1e2a3b4-5d6e-4f70-8a91-b2c3d4e5f607', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Checking', 'checking', 'EUR', 150000, '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('d2f3b4c5-6e7f-4081-9ba2-c3d4e5f60718', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Savings', 'savings', 'EUR', 500000, '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('e3a4c5d6-7f80-4192-acb3-d4e5f6071829', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Credit Card', 'credit_card', 'EUR', -25000, '2019-01-01 00:00:00', '2019-01-01 00:00:00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO tags (tag_id, user_id, name, date_created, date_updated) VALUES
+	('8f1d2c3b-4a5e-4f60-9b7a-0c1d2e3f4a5b', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'tax-deductible', '2019-01-01 00:00:00', '2019-01-01 00:00:00'),
+	('9a2e3d4c-5b6f-4071-8c8b-1d2e3f4a5b6c', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'side-project', '2019-01-01 00:00:00', '2019-01-01 00:00:00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO transaction_tags (tag_id, income_id, expense_id) VALUES
+	('8f1d2c3b-4a5e-4f60-9b7a-0c1d2e3f4a5b', NULL, '98b6d4b8-f04b-4c79-8c2e-a0aef46854b7'),
+	('9a2e3d4c-5b6f-4071-8c8b-1d2e3f4a5b6c', '72f8b983-3eb4-48db-9ed0-e45cc6bd716b', NULL),
+	('9a2e3d4c-5b6f-4071-8c8b-1d2e3f4a5b6c', 'c74111d6-4a5a-41d1-801f-0b8dbc6d3ef9', NULL)
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO goals (goal_id, user_id, account_id, name, currency, target, deadline, date_created, date_updated) VALUES
+	('b4c5d6e7-8091-4a2b-bc3d-e4f5a6b7c8d9', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'd2f3b4c5-6e7f-4081-9ba2-c3d4e5f60718', 'Emergency fund', 'EUR', 1500000, '2030-12-31 00:00:00', '2019-01-01 00:00:00', '2019-01-01 00:00:00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO goal_contributions (contribution_id, goal_id, amount, date_contributed, date_created) VALUES
+	('c5d6e7f8-91a2-4b3c-8d4e-f5a6b7c8d9e0', 'b4c5d6e7-8091-4a2b-bc3d-e4f5a6b7c8d9', 500000, '2019-01-01 00:00:00', '2019-01-01 00:00:00')
+	ON CONFLICT DO NOTHING;
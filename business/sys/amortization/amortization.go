@@ -0,0 +1,208 @@
+// Package amortization provides support for splitting the repayment of a
+// fixed rate loan into equal installments and reporting how much of each one
+// goes to interest and how much to the principal.
+package amortization
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+)
+
+// Set of supported payment frequencies.
+const (
+	FrequencyWeekly    = "weekly"
+	FrequencyBiweekly  = "biweekly"
+	FrequencyMonthly   = "monthly"
+	FrequencyQuarterly = "quarterly"
+	FrequencyYearly    = "yearly"
+)
+
+// Set of error variables for amortization.
+var (
+	ErrUnknownFrequency = errors.New("unknown payment frequency")
+	ErrInvalidLoan      = errors.New("invalid loan")
+)
+
+// Loan describes a fixed rate loan repaid in equal installments.
+type Loan struct {
+	Principal money.Money // Amount borrowed.
+	Rate      float64     // Annual interest rate as a percentage, like 4.5.
+	Term      int         // Number of installments.
+	Frequency string      // How often an installment is due.
+	Start     time.Time   // Date of the first installment.
+}
+
+// Extra represents a payment made on top of the installments. It goes
+// entirely to the principal.
+type Extra struct {
+	Date   time.Time
+	Amount money.Money
+}
+
+// Payment represents a single installment of a schedule.
+type Payment struct {
+	Number    int         `json:"number"`    // Position of the installment, starting with 1.
+	Date      time.Time   `json:"date"`      // When the installment is due.
+	Payment   money.Money `json:"payment"`   // Interest plus principal of the installment.
+	Interest  money.Money `json:"interest"`  // Part of the installment paying the interest.
+	Principal money.Money `json:"principal"` // Part of the installment paying the principal.
+	Extra     money.Money `json:"extra"`     // Extra payments applied along with the installment.
+	Balance   money.Money `json:"balance"`   // Principal still owed afterwards.
+}
+
+// Schedule represents the installments it takes to repay a loan.
+type Schedule struct {
+	Installment   money.Money `json:"installment"`    // Regular installment, the last one can be lower.
+	Payments      []Payment   `json:"payments"`       // Every installment until the loan is repaid.
+	TotalInterest money.Money `json:"total_interest"` // Sum of the interest paid.
+	TotalPaid     money.Money `json:"total_paid"`     // Sum of the installments and extra payments.
+	PayoffDate    time.Time   `json:"payoff_date"`    // Date of the last installment.
+}
+
+// Schedule computes the installments of the loan. Extra payments are applied
+// along with the first installment due on or after their date and lower the
+// balance the following interest is charged on, so the installment stays the
+// same and the loan is repaid sooner. Extra payments made after the loan is
+// repaid are ignored.
+func (l Loan) Schedule(extras ...Extra) (Schedule, error) {
+	if !l.Principal.IsPositive() || l.Term < 1 || l.Rate < 0 {
+		return Schedule{}, ErrInvalidLoan
+	}
+
+	perYear, err := PerYear(l.Frequency)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	extras = append([]Extra(nil), extras...)
+	sort.SliceStable(extras, func(i, j int) bool { return extras[i].Date.Before(extras[j].Date) })
+	for _, e := range extras {
+		if e.Amount.Currency != l.Principal.Currency || e.Amount.IsNegative() {
+			return Schedule{}, fmt.Errorf("extra payment[%s]: %w", e.Amount, ErrInvalidLoan)
+		}
+	}
+
+	rate := l.Rate / 100 / float64(perYear)
+	installment := l.installment(rate)
+	cur := l.Principal.Currency
+
+	sch := Schedule{
+		Installment:   money.Money{Amount: installment, Currency: cur},
+		TotalInterest: money.Zero(cur),
+		TotalPaid:     money.Zero(cur),
+	}
+
+	dates := l.dates()
+	balance := l.Principal.Amount
+
+	var next int
+	for i, date := range dates {
+		interest := int64(math.RoundToEven(float64(balance) * rate))
+
+		// The last installment settles whatever is left over from rounding.
+		principal := installment - interest
+		if principal > balance || i == len(dates)-1 {
+			principal = balance
+		}
+		balance -= principal
+
+		var extra int64
+		for ; next < len(extras) && !extras[next].Date.After(date); next++ {
+			extra += extras[next].Amount.Amount
+		}
+		if extra > balance {
+			extra = balance
+		}
+		balance -= extra
+
+		sch.Payments = append(sch.Payments, Payment{
+			Number:    i + 1,
+			Date:      date,
+			Payment:   money.Money{Amount: interest + principal, Currency: cur},
+			Interest:  money.Money{Amount: interest, Currency: cur},
+			Principal: money.Money{Amount: principal, Currency: cur},
+			Extra:     money.Money{Amount: extra, Currency: cur},
+			Balance:   money.Money{Amount: balance, Currency: cur},
+		})
+		sch.TotalInterest.Amount += interest
+		sch.TotalPaid.Amount += interest + principal + extra
+		sch.PayoffDate = date
+
+		if balance == 0 {
+			break
+		}
+	}
+
+	return sch, nil
+}
+
+// PerYear returns the number of installments due in a year for a payment
+// frequency.
+func PerYear(frequency string) (int, error) {
+	switch frequency {
+	case FrequencyWeekly:
+		return 52, nil
+	case FrequencyBiweekly:
+		return 26, nil
+	case FrequencyMonthly:
+		return 12, nil
+	case FrequencyQuarterly:
+		return 4, nil
+	case FrequencyYearly:
+		return 1, nil
+	}
+
+	return 0, fmt.Errorf("frequency[%s]: %w", frequency, ErrUnknownFrequency)
+}
+
+// =============================================================================
+
+// installment returns the annuity installment in minor units that repays the
+// principal in the term at the periodic rate, rounded to the nearest minor
+// unit. The last installment makes up for the rounding.
+func (l Loan) installment(rate float64) int64 {
+	p := float64(l.Principal.Amount)
+	n := float64(l.Term)
+
+	var v float64
+	switch rate {
+	case 0:
+		v = p / n
+	default:
+		v = p * rate / (1 - math.Pow(1+rate, -n))
+	}
+
+	return int64(math.Round(v))
+}
+
+// dates returns the due date of every installment of the term.
+func (l Loan) dates() []time.Time {
+	rule := recurrence.Rule{
+		Start:        l.Start,
+		Type:         recurrence.TypeMonthly,
+		DurationType: recurrence.DurationMonths,
+	}
+
+	switch l.Frequency {
+	case FrequencyWeekly:
+		rule.Type, rule.Interval, rule.DurationType = recurrence.TypeDaily, 7, recurrence.DurationDays
+	case FrequencyBiweekly:
+		rule.Type, rule.Interval, rule.DurationType = recurrence.TypeDaily, 14, recurrence.DurationDays
+	case FrequencyMonthly:
+		rule.Interval = 1
+	case FrequencyQuarterly:
+		rule.Interval = 3
+	case FrequencyYearly:
+		rule.Interval = 12
+	}
+	rule.Duration = l.Term * rule.Interval
+
+	end, _ := rule.End()
+	return rule.Between(time.Time{}, end)
+}
@@ -0,0 +1,163 @@
+package amortization_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/amortization"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func eur(amount int64) money.Money {
+	return money.Money{Amount: amount, Currency: "EUR"}
+}
+
+func payment(n int, d time.Time, interest int64, principal int64, extra int64, balance int64) amortization.Payment {
+	return amortization.Payment{
+		Number:    n,
+		Date:      d,
+		Payment:   eur(interest + principal),
+		Interest:  eur(interest),
+		Principal: eur(principal),
+		Extra:     eur(extra),
+		Balance:   eur(balance),
+	}
+}
+
+func Test_Schedule(t *testing.T) {
+	loan := amortization.Loan{
+		Principal: eur(100000),
+		Rate:      12,
+		Term:      3,
+		Frequency: amortization.FrequencyMonthly,
+		Start:     date(2022, time.January, 31),
+	}
+
+	tt := []struct {
+		name   string
+		loan   amortization.Loan
+		extras []amortization.Extra
+		exp    amortization.Schedule
+	}{
+		{
+			name: "noInterest",
+			loan: amortization.Loan{Principal: eur(30000), Term: 3, Frequency: amortization.FrequencyBiweekly, Start: date(2022, time.January, 1)},
+			exp: amortization.Schedule{
+				Installment: eur(10000),
+				Payments: []amortization.Payment{
+					payment(1, date(2022, time.January, 1), 0, 10000, 0, 20000),
+					payment(2, date(2022, time.January, 15), 0, 10000, 0, 10000),
+					payment(3, date(2022, time.January, 29), 0, 10000, 0, 0),
+				},
+				TotalInterest: eur(0),
+				TotalPaid:     eur(30000),
+				PayoffDate:    date(2022, time.January, 29),
+			},
+		},
+		{
+			name: "monthly",
+			loan: loan,
+			exp: amortization.Schedule{
+				Installment: eur(34002),
+				Payments: []amortization.Payment{
+					payment(1, date(2022, time.January, 31), 1000, 33002, 0, 66998),
+					payment(2, date(2022, time.February, 28), 670, 33332, 0, 33666),
+					payment(3, date(2022, time.March, 31), 337, 33666, 0, 0),
+				},
+				TotalInterest: eur(2007),
+				TotalPaid:     eur(102007),
+				PayoffDate:    date(2022, time.March, 31),
+			},
+		},
+		{
+			name:   "extraPayment",
+			loan:   loan,
+			extras: []amortization.Extra{{Date: date(2022, time.January, 20), Amount: eur(60000)}},
+			exp: amortization.Schedule{
+				Installment: eur(34002),
+				Payments: []amortization.Payment{
+					payment(1, date(2022, time.January, 31), 1000, 33002, 60000, 6998),
+					payment(2, date(2022, time.February, 28), 70, 6998, 0, 0),
+				},
+				TotalInterest: eur(1070),
+				TotalPaid:     eur(101070),
+				PayoffDate:    date(2022, time.February, 28),
+			},
+		},
+	}
+
+	t.Log("Given the need to compute the amortization schedule of a loan.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s loan.", testID, tst.name)
+				{
+					got, err := tst.loan.Schedule(tst.extras...)
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to compute the schedule : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to compute the schedule.", success, testID)
+
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get back the expected schedule. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get back the expected schedule.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_ScheduleErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		loan amortization.Loan
+		exp  error
+	}{
+		{
+			name: "unknownFrequency",
+			loan: amortization.Loan{Principal: eur(100), Term: 1, Frequency: "hourly"},
+			exp:  amortization.ErrUnknownFrequency,
+		},
+		{
+			name: "noTerm",
+			loan: amortization.Loan{Principal: eur(100), Frequency: amortization.FrequencyMonthly},
+			exp:  amortization.ErrInvalidLoan,
+		},
+		{
+			name: "negativeRate",
+			loan: amortization.Loan{Principal: eur(100), Rate: -1, Term: 1, Frequency: amortization.FrequencyMonthly},
+			exp:  amortization.ErrInvalidLoan,
+		},
+	}
+
+	t.Log("Given the need to reject loans that can't be repaid.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a loan with %s.", testID, tst.name)
+				{
+					_, err := tst.loan.Schedule()
+					if !errors.Is(err, tst.exp) {
+						t.Fatalf("\t%s\tTest %d:\tShould get back %q : %v.", failed, testID, tst.exp, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get back %q.", success, testID, tst.exp)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package batch provides support for running a list of create, update and
+// delete operations within a single transaction and reporting on each one.
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+)
+
+// MaxOperations is the largest number of operations a batch can hold.
+const MaxOperations = 500
+
+// Set of modes a batch can run in.
+const (
+	ModeAtomic     = "atomic"      // Every operation is applied or none is.
+	ModeBestEffort = "best_effort" // The operations that fail are left out.
+)
+
+// Set of operations a batch can hold.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Set of statuses an operation ends up in.
+const (
+	StatusOK         = "ok"          // The operation was applied.
+	StatusFailed     = "failed"      // The operation failed.
+	StatusRolledBack = "rolled_back" // The operation succeeded but another one failed an atomic batch.
+	StatusSkipped    = "skipped"     // The operation didn't run since another one failed an atomic batch.
+)
+
+// Set of error variables for batches.
+var (
+	ErrInvalidMode = errors.New("mode must be atomic or best_effort")
+	ErrEmpty       = errors.New("batch holds no operations")
+	ErrTooMany     = errors.New("batch holds too many operations")
+	ErrInvalidOp   = errors.New("op must be create, update or delete")
+	ErrMissingData = errors.New("operation is missing its data")
+	ErrFailed      = errors.New("batch failed and was rolled back")
+)
+
+// Request is the body of a batch request. The Data of an Operation holds the
+// record to create or the fields to update, as the single record endpoints
+// take them.
+type Request struct {
+	Mode       string      `json:"mode"`
+	Operations []Operation `json:"operations"`
+}
+
+// Operation is a single change requested in a batch.
+type Operation struct {
+	Op      string          `json:"op"`      // Kind of change.
+	ID      string          `json:"id"`      // ID of the record to update or delete.
+	Version int64           `json:"version"` // Version the record must be at to be updated, zero for any.
+	Data    json.RawMessage `json:"data"`    // Record to create or fields to update.
+}
+
+// Result reports what happened to an operation of a batch.
+type Result struct {
+	Index  int               `json:"index"`            // Position of the operation in the batch.
+	Status string            `json:"status"`           // What happened to the operation.
+	ID     string            `json:"id,omitempty"`     // ID of the record the operation was about.
+	Error  string            `json:"error,omitempty"`  // Why the operation failed.
+	Fields map[string]string `json:"fields,omitempty"` // Fields of the operation that failed validation.
+	Err    error             `json:"-"`                // Error the operation failed with.
+}
+
+// Response is the body of a batch response.
+type Response struct {
+	Mode    string   `json:"mode"`    // Mode the batch ran in.
+	Applied bool     `json:"applied"` // Whether any operation was applied.
+	Results []Result `json:"results"` // Result of every operation, in order.
+}
+
+// Check validates the mode and the number of operations of a batch. An empty
+// mode is atomic.
+func Check(mode string, n int) (string, error) {
+	switch mode {
+	case "":
+		mode = ModeAtomic
+	case ModeAtomic, ModeBestEffort:
+	default:
+		return "", validate.NewFieldsError("mode", ErrInvalidMode)
+	}
+
+	switch {
+	case n == 0:
+		return "", validate.NewFieldsError("operations", ErrEmpty)
+	case n > MaxOperations:
+		return "", validate.NewFieldsError("operations", ErrTooMany)
+	}
+
+	return mode, nil
+}
+
+// Run applies the n operations of a batch in order, through apply. The errs
+// hold what was found wrong with each operation before running any, nil for
+// the valid ones.
+//
+// An atomic batch stops at the first failure and Run returns ErrFailed, so
+// the transaction it runs in gets rolled back. A best effort batch runs every
+// valid operation within a savepoint, so the failing ones are undone alone.
+func Run(mode string, errs []error, savepoint func(fn func() error) error, apply func(i int) (string, error)) ([]Result, error) {
+	results := make([]Result, len(errs))
+	for i := range results {
+		results[i] = Result{Index: i, Status: StatusSkipped}
+	}
+
+	if mode == ModeAtomic {
+		failed := false
+		for i, err := range errs {
+			if err != nil {
+				results[i].Status = StatusFailed
+				results[i].Err = err
+				failed = true
+			}
+		}
+		if failed {
+			return results, ErrFailed
+		}
+
+		for i := range results {
+			id, err := apply(i)
+			results[i].ID = id
+			if err != nil {
+				results[i].Status = StatusFailed
+				results[i].Err = err
+				for j := 0; j < i; j++ {
+					results[j].Status = StatusRolledBack
+				}
+				return results, ErrFailed
+			}
+			results[i].Status = StatusOK
+		}
+
+		return results, nil
+	}
+
+	for i := range results {
+		if errs[i] != nil {
+			results[i].Status = StatusFailed
+			results[i].Err = errs[i]
+			continue
+		}
+
+		var id string
+		var applyErr error
+		err := savepoint(func() error {
+			id, applyErr = apply(i)
+			return applyErr
+		})
+		results[i].ID = id
+
+		if applyErr != nil {
+			// Anything else than the error of the operation means the
+			// savepoint couldn't be rolled back.
+			if err != applyErr {
+				return nil, err
+			}
+			results[i].Status = StatusFailed
+			results[i].Err = applyErr
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[i].Status = StatusOK
+	}
+
+	return results, nil
+}
+
+// NewResponse builds the response of a batch, describing why the operations
+// failed. Only the messages of validation errors and of the known errors are
+// given out; any other error is reported as an internal one.
+func NewResponse(mode string, results []Result, known ...error) Response {
+	applied := false
+	for i, res := range results {
+		if res.Status == StatusOK {
+			applied = true
+		}
+		if res.Err == nil {
+			continue
+		}
+
+		results[i].Error = describe(res.Err, known)
+		if validate.IsFieldErrors(res.Err) {
+			results[i].Fields = validate.GetFieldErrors(res.Err).Fields()
+		}
+	}
+
+	return Response{
+		Mode:    mode,
+		Applied: applied,
+		Results: results,
+	}
+}
+
+// =============================================================================
+
+// describe returns the message of the first known error err wraps.
+func describe(err error, known []error) string {
+	if validate.IsFieldErrors(err) {
+		return "data validation error"
+	}
+
+	for _, kerr := range known {
+		if errors.Is(err, kerr) {
+			return kerr.Error()
+		}
+	}
+
+	return http.StatusText(http.StatusInternalServerError)
+}
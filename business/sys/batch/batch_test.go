@@ -0,0 +1,132 @@
+package batch_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/sys/batch"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Run(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// apply fails the operations at the indexes listed in fail.
+	apply := func(fail ...int) func(i int) (string, error) {
+		return func(i int) (string, error) {
+			for _, f := range fail {
+				if i == f {
+					return "", errBoom
+				}
+			}
+			return fmt.Sprintf("id-%d", i), nil
+		}
+	}
+
+	savepoint := func(fn func() error) error {
+		return fn()
+	}
+
+	tt := []struct {
+		name   string
+		mode   string
+		errs   []error
+		apply  func(i int) (string, error)
+		exp    []string
+		expErr error
+	}{
+		{
+			name:  "atomicOK",
+			mode:  batch.ModeAtomic,
+			errs:  make([]error, 3),
+			apply: apply(),
+			exp:   []string{batch.StatusOK, batch.StatusOK, batch.StatusOK},
+		},
+		{
+			name:   "atomicFailed",
+			mode:   batch.ModeAtomic,
+			errs:   make([]error, 3),
+			apply:  apply(1),
+			exp:    []string{batch.StatusRolledBack, batch.StatusFailed, batch.StatusSkipped},
+			expErr: batch.ErrFailed,
+		},
+		{
+			name:   "atomicInvalid",
+			mode:   batch.ModeAtomic,
+			errs:   []error{nil, batch.ErrMissingData, nil},
+			apply:  apply(),
+			exp:    []string{batch.StatusSkipped, batch.StatusFailed, batch.StatusSkipped},
+			expErr: batch.ErrFailed,
+		},
+		{
+			name:  "bestEffort",
+			mode:  batch.ModeBestEffort,
+			errs:  []error{nil, batch.ErrMissingData, nil, nil},
+			apply: apply(2),
+			exp:   []string{batch.StatusOK, batch.StatusFailed, batch.StatusFailed, batch.StatusOK},
+		},
+	}
+
+	t.Log("Given the need to run a batch of operations.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen running a %s batch.", testID, tst.name)
+				{
+					results, err := batch.Run(tst.mode, tst.errs, savepoint, tst.apply)
+					if !errors.Is(err, tst.expErr) {
+						t.Fatalf("\t%s\tTest %d:\tShould get error %v, got %v.", failed, testID, tst.expErr, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+
+					got := make([]string, len(results))
+					for i, res := range results {
+						got[i] = res.Status
+					}
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected statuses. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected statuses.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_NewResponse(t *testing.T) {
+	errKnown := errors.New("income not found")
+
+	results := []batch.Result{
+		{Index: 0, Status: batch.StatusOK, ID: "a"},
+		{Index: 1, Status: batch.StatusFailed, Err: fmt.Errorf("tran: %w", errKnown)},
+		{Index: 2, Status: batch.StatusFailed, Err: errors.New("pq: connection refused")},
+	}
+
+	t.Log("Given the need to report on a batch without leaking internal errors.")
+	{
+		resp := batch.NewResponse(batch.ModeBestEffort, results, errKnown)
+
+		exp := []string{"", errKnown.Error(), "Internal Server Error"}
+		got := make([]string, len(resp.Results))
+		for i, res := range resp.Results {
+			got[i] = res.Error
+		}
+		if diff := cmp.Diff(exp, got); diff != "" {
+			t.Fatalf("\t%s\tShould describe only the known errors. Diff:\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould describe only the known errors.", success)
+
+		if !resp.Applied {
+			t.Fatalf("\t%s\tShould report the batch as applied.", failed)
+		}
+		t.Logf("\t%s\tShould report the batch as applied.", success)
+	}
+}
@@ -0,0 +1,159 @@
+// Package cursor provides support for keyset pagination. A cursor remembers
+// the sort key of the last row of a page, so the next page starts right
+// after it no matter how many rows were added or removed in between.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/order"
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+)
+
+// Set of limits on the size of a page.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 1000
+)
+
+// Set of error variables for cursors.
+var (
+	ErrInvalidCursor = errors.New("cursor is not valid")
+	ErrOrderMismatch = errors.New("cursor was made for another order")
+	ErrInvalidLimit  = errors.New("limit must be between 1 and 1000")
+)
+
+// checks validates the value of a cursor against the field it was made for,
+// so a tampered token can't reach the database. The values of the fields not
+// listed are plain text.
+var checks = map[string]func(value string) error{
+	"user_id":     validate.CheckID,
+	"category_id": validate.CheckID,
+	"amount": func(value string) error {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err
+	},
+	"date_created": func(value string) error {
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err
+	},
+	"reoccurrence_type": func(value string) error {
+		switch value {
+		case recurrence.TypeMonthly, recurrence.TypeDaily, recurrence.TypeOnce:
+			return nil
+		}
+		return errors.New("unknown reoccurrence type")
+	},
+}
+
+// Cursor marks the last row of a page. Value is the value of the field the
+// rows are ordered by and ID breaks the ties between rows sharing it.
+type Cursor struct {
+	OrderBy order.By `json:"o"`
+	Value   string   `json:"v"`
+	ID      string   `json:"i"`
+}
+
+// New constructs a cursor pointing at a row.
+func New(orderBy order.By, value string, id string) Cursor {
+	return Cursor{
+		OrderBy: orderBy,
+		Value:   value,
+		ID:      id,
+	}
+}
+
+// Encode turns the cursor into the opaque token handed out to clients.
+func (c Cursor) Encode() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode reads a token made by Encode. The cursor must have been made for
+// the order the rows are asked for, and hold an ID and a value that fit the
+// field the rows are ordered by. An empty token gives back a nil cursor,
+// which starts from the first row.
+func Decode(token string, orderBy order.By) (*Cursor, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if err := validate.CheckID(c.ID); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.OrderBy != orderBy {
+		return nil, fmt.Errorf("order[%s %s]: %w", c.OrderBy.Field, c.OrderBy.Direction, ErrOrderMismatch)
+	}
+
+	if check, exists := checks[c.OrderBy.Field]; exists {
+		if err := check(c.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &c, nil
+}
+
+// Page represents a page of a list along with the token to fetch the next
+// one, which is empty on the last page, and the number of rows in the list.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+	Total      int    `json:"total"`
+}
+
+// Clause returns the condition of a WHERE clause selecting the rows that
+// come after the cursor, comparing the column and the id column as a pair
+// so the rows sharing a value stay in order. The values are read from the
+// cursor_value and cursor_id named parameters.
+func (c Cursor) Clause(column string, idColumn string) string {
+	op := ">"
+	if c.OrderBy.Direction == order.DESC {
+		op = "<"
+	}
+
+	if column == idColumn {
+		return idColumn + " " + op + " :cursor_id"
+	}
+
+	return "(" + column + ", " + idColumn + ") " + op + " (:cursor_value, :cursor_id)"
+}
+
+// ParseLimit reads the number of rows a page holds. An empty value gives
+// back the default limit.
+func ParseLimit(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DefaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > MaxLimit {
+		return 0, ErrInvalidLimit
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,139 @@
+package cursor_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/sys/cursor"
+	"github.com/gloompi/ultimate-service/business/sys/order"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Decode(t *testing.T) {
+	byAmount := order.NewBy("amount", order.DESC)
+	c := cursor.New(byAmount, "1250", "5cf37266-3473-4006-984f-9325122678b7")
+
+	byDate := order.NewBy("date_created", order.ASC)
+	dc := cursor.New(byDate, "2019-01-01T00:00:01.000001Z", c.ID)
+	byType := order.NewBy("reoccurrence_type", order.ASC)
+	byCategory := order.NewBy("category_id", order.ASC)
+	byName := order.NewBy("name", order.ASC)
+	nc := cursor.New(byName, "Rent; DROP TABLE users", c.ID)
+
+	tt := []struct {
+		name    string
+		token   string
+		orderBy order.By
+		exp     *cursor.Cursor
+		expErr  error
+	}{
+		{name: "empty", token: "", orderBy: byAmount, exp: nil},
+		{name: "roundTrip", token: c.Encode(), orderBy: byAmount, exp: &c},
+		{name: "otherOrder", token: c.Encode(), orderBy: order.NewBy("amount", order.ASC), expErr: cursor.ErrOrderMismatch},
+		{name: "garbage", token: "not a cursor!", orderBy: byAmount, expErr: cursor.ErrInvalidCursor},
+		{name: "noID", token: cursor.New(byAmount, "1250", "").Encode(), orderBy: byAmount, expErr: cursor.ErrInvalidCursor},
+		{name: "badID", token: cursor.New(byAmount, "1250", "42").Encode(), orderBy: byAmount, expErr: cursor.ErrInvalidCursor},
+		{name: "textAmount", token: cursor.New(byAmount, "lots", c.ID).Encode(), orderBy: byAmount, expErr: cursor.ErrInvalidCursor},
+		{name: "badDate", token: cursor.New(byDate, "yesterday", c.ID).Encode(), orderBy: byDate, expErr: cursor.ErrInvalidCursor},
+		{name: "date", token: dc.Encode(), orderBy: byDate, exp: &dc},
+		{name: "badType", token: cursor.New(byType, "Weekly", c.ID).Encode(), orderBy: byType, expErr: cursor.ErrInvalidCursor},
+		{name: "badCategory", token: cursor.New(byCategory, "food", c.ID).Encode(), orderBy: byCategory, expErr: cursor.ErrInvalidCursor},
+		{name: "name", token: nc.Encode(), orderBy: byName, exp: &nc},
+	}
+
+	t.Log("Given the need to read back the cursors handed out to clients.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s token.", testID, tst.name)
+				{
+					got, err := cursor.Decode(tst.token, tst.orderBy)
+					if tst.expErr != nil {
+						if !errors.Is(err, tst.expErr) {
+							t.Fatalf("\t%s\tTest %d:\tShould get error %q, got %v.", failed, testID, tst.expErr, err)
+						}
+						t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+						return
+					}
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to decode the token : %s.", failed, testID, err)
+					}
+					if (got == nil) != (tst.exp == nil) || got != nil && *got != *tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get %+v, got %+v.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected cursor.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_Clause(t *testing.T) {
+	tt := []struct {
+		name   string
+		cursor cursor.Cursor
+		column string
+		exp    string
+	}{
+		{name: "ascending", cursor: cursor.New(order.NewBy("amount", order.ASC), "1", "a"), column: "e.amount", exp: "(e.amount, e.expense_id) > (:cursor_value, :cursor_id)"},
+		{name: "descending", cursor: cursor.New(order.NewBy("amount", order.DESC), "1", "a"), column: "e.amount", exp: "(e.amount, e.expense_id) < (:cursor_value, :cursor_id)"},
+		{name: "byID", cursor: cursor.New(order.NewBy("expense_id", order.ASC), "a", "a"), column: "e.expense_id", exp: "e.expense_id > :cursor_id"},
+	}
+
+	t.Log("Given the need to select the rows after a cursor.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling an %s order.", testID, tst.name)
+				{
+					if got := tst.cursor.Clause(tst.column, "e.expense_id"); got != tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get %q, got %q.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected condition.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_ParseLimit(t *testing.T) {
+	tt := []struct {
+		name   string
+		value  string
+		exp    int
+		expErr error
+	}{
+		{name: "default", value: "", exp: cursor.DefaultLimit},
+		{name: "given", value: "20", exp: 20},
+		{name: "zero", value: "0", expErr: cursor.ErrInvalidLimit},
+		{name: "tooMany", value: "1001", expErr: cursor.ErrInvalidLimit},
+		{name: "notNumber", value: "ten", expErr: cursor.ErrInvalidLimit},
+	}
+
+	t.Log("Given the need to read the size of a page.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s limit.", testID, tst.name)
+				{
+					got, err := cursor.ParseLimit(tst.value)
+					if !errors.Is(err, tst.expErr) {
+						t.Fatalf("\t%s\tTest %d:\tShould get error %v, got %v.", failed, testID, tst.expErr, err)
+					}
+					if got != tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get %d, got %d.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected limit.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/url"
@@ -19,12 +20,16 @@ import (
 
 // lib/pq errorCodeNames
 // https://github.com/lib/pq/blob/master/error.go#L178
-const uniqueViolation = "23505"
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
 
 // Set of error variables for CRUD operations.
 var (
 	ErrDBNotFound        = errors.New("not found")
 	ErrDBDuplicatedEntry = errors.New("duplicated entry")
+	ErrDBForeignKey      = errors.New("referenced by another record")
 )
 
 // Config is the required properties to use the database.
@@ -149,24 +154,60 @@ func WithinTran(ctx context.Context, log *zap.SugaredLogger, db Transactor, fn f
 	return nil
 }
 
+// WithinSavepoint runs passed function within a savepoint of the transaction.
+// When the function fails only what it did is rolled back and the rest of the
+// transaction can go on.
+func WithinSavepoint(ctx context.Context, log *zap.SugaredLogger, tx sqlx.ExtContext, fn func() error) error {
+	traceID := web.GetTraceID(ctx)
+
+	log.Infow("begin savepoint", "traceid", traceID)
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT within_savepoint"); err != nil {
+		return fmt.Errorf("begin savepoint: %w", err)
+	}
+
+	if err := fn(); err != nil {
+		log.Infow("rollback savepoint", "traceid", traceID)
+		if _, rerr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT within_savepoint"); rerr != nil {
+			return fmt.Errorf("rollback savepoint: %w", rerr)
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT within_savepoint"); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return nil
+}
+
 // NamedExecContext is a helper function to execute a CRUD operation with
 // logging and tracing.
 func NamedExecContext(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any) error {
-	q := queryString(query, data)
-	log.Infow("database.NamedExecContext", "traceid", web.GetTraceID(ctx), "query", q)
+	if _, err := namedExec(ctx, log, db, query, data); err != nil {
+		return err
+	}
 
-	ctx, span := otel.GetTracerProvider().Tracer("").Start(ctx, "business.sys.database")
-	span.SetAttributes(attribute.String("query", q))
-	defer span.End()
+	return nil
+}
 
-	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
-		// Checks if the error is of code 23505 (unique_violation).
-		if pqerr, ok := err.(*pq.Error); ok && pqerr.Code == uniqueViolation {
-			return ErrDBDuplicatedEntry
-		}
+// NamedExecRowContext is a helper function to execute a CRUD operation that
+// has to change at least one row, with logging and tracing. It returns
+// ErrDBNotFound when no row matched the query.
+func NamedExecRowContext(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any) error {
+	res, err := namedExec(ctx, log, db, query, data)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
 		return err
 	}
 
+	if n == 0 {
+		return ErrDBNotFound
+	}
+
 	return nil
 }
 
@@ -243,3 +284,31 @@ func queryString(query string, args ...any) string {
 
 	return strings.Trim(query, " ")
 }
+
+// namedExec executes a CRUD operation with logging and tracing, turning the
+// constraint violations into the errors of this package.
+func namedExec(ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data any) (sql.Result, error) {
+	q := queryString(query, data)
+	log.Infow("database.NamedExecContext", "traceid", web.GetTraceID(ctx), "query", q)
+
+	ctx, span := otel.GetTracerProvider().Tracer("").Start(ctx, "business.sys.database")
+	span.SetAttributes(attribute.String("query", q))
+	defer span.End()
+
+	res, err := sqlx.NamedExecContext(ctx, db, query, data)
+	if err != nil {
+		// Checks if the error is of code 23505 (unique_violation) or
+		// 23503 (foreign_key_violation).
+		if pqerr, ok := err.(*pq.Error); ok {
+			switch pqerr.Code {
+			case uniqueViolation:
+				return nil, ErrDBDuplicatedEntry
+			case foreignKeyViolation:
+				return nil, ErrDBForeignKey
+			}
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
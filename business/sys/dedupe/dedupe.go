@@ -0,0 +1,192 @@
+// Package dedupe provides support for spotting records entered twice, like an
+// expense typed in by hand and then imported from the bank statement. Two
+// records are suspected duplicates when they are of the same amount of the
+// same currency, close in time and named alike.
+package dedupe
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Window is how far apart in time two records can be and still be
+// duplicates. Banks often post a card payment a couple of days after it was
+// made.
+const Window = 3 * 24 * time.Hour
+
+// Threshold is how similar, from 0 to 1, the names of two records have to be
+// for them to be duplicates.
+const Threshold = 0.8
+
+// Record represents what is compared of an income or expense.
+type Record struct {
+	ID     string
+	Name   string
+	Amount money.Money
+	Date   time.Time
+}
+
+// Pair represents two records suspected to be duplicates. The first one is
+// the earlier of the two.
+type Pair struct {
+	First  Record
+	Second Record
+	Score  float64 // Similarity of the names, from Threshold to 1.
+}
+
+// Match reports whether two records are suspected duplicates, along with how
+// similar their names are.
+func Match(a Record, b Record) (float64, bool) {
+	if a.Amount != b.Amount {
+		return 0, false
+	}
+
+	if d := a.Date.Sub(b.Date); d > Window || d < -Window {
+		return 0, false
+	}
+
+	score := Similarity(a.Name, b.Name)
+	return score, score >= Threshold
+}
+
+// Find returns every pair of the records suspected to be duplicates, the
+// most recent first.
+func Find(recs []Record) []Pair {
+	sorted := make([]Record, len(recs))
+	copy(sorted, recs)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch {
+		case a.Amount.Currency != b.Amount.Currency:
+			return a.Amount.Currency < b.Amount.Currency
+		case a.Amount.Amount != b.Amount.Amount:
+			return a.Amount.Amount < b.Amount.Amount
+		case !a.Date.Equal(b.Date):
+			return a.Date.Before(b.Date)
+		}
+		return a.ID < b.ID
+	})
+
+	var pairs []Pair
+
+	// Records that can match sit next to each other once sorted, so only
+	// the ones up to a window later are compared.
+	for i, a := range sorted {
+		for _, b := range sorted[i+1:] {
+			if b.Amount != a.Amount || b.Date.Sub(a.Date) > Window {
+				break
+			}
+
+			if score, ok := Match(a, b); ok {
+				pairs = append(pairs, Pair{First: a, Second: b, Score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Second.Date.After(pairs[j].Second.Date)
+	})
+
+	return pairs
+}
+
+// Similarity returns how alike two names are, from 0 to 1. Case, punctuation
+// and spacing are ignored. The names are compared both as strings, to forgive
+// typos, and as sets of words, to forgive the extra words banks add, like a
+// reference number or the city.
+func Similarity(a string, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+
+	sa, sb := strings.Join(wa, " "), strings.Join(wb, " ")
+	if sa == sb {
+		return 1
+	}
+
+	score := math.Max(editSimilarity(sa, sb), wordSimilarity(wa, wb))
+	return math.Round(score*100) / 100
+}
+
+// =============================================================================
+
+// words returns the words of a name in lower case, split on anything that is
+// not a letter or a digit.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// editSimilarity returns one minus the edit distance between the strings
+// relative to the length of the longest one.
+func editSimilarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// wordSimilarity returns the Dice coefficient of the sets of words: twice the
+// number of words in common over the number of words of both.
+func wordSimilarity(a []string, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, w := range a {
+		set[w] = true
+	}
+
+	seen := make(map[string]bool, len(b))
+	common := 0
+	for _, w := range b {
+		if set[w] && !seen[w] {
+			common++
+		}
+		seen[w] = true
+	}
+
+	return 2 * float64(common) / float64(len(set)+len(seen))
+}
+
+// levenshtein returns the least number of single character insertions,
+// deletions and substitutions turning a into b.
+func levenshtein(a []rune, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
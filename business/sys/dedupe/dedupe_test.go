@@ -0,0 +1,96 @@
+package dedupe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/dedupe"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Similarity(t *testing.T) {
+	tt := []struct {
+		name string
+		a    string
+		b    string
+		dup  bool
+	}{
+		{name: "same", a: "Coffee Shop", b: "Coffee Shop", dup: true},
+		{name: "case and punctuation", a: "coffee-shop!", b: "COFFEE SHOP", dup: true},
+		{name: "typo", a: "Netflix", b: "Netflx", dup: true},
+		{name: "extra words", a: "Coffee Shop", b: "COFFEE SHOP #123", dup: true},
+		{name: "bank reference", a: "Spotify Premium", b: "SPOTIFY PREMIUM P1A2B3 STOCKHOLM", dup: false},
+		{name: "same brand", a: "Uber Trip", b: "Uber Eats", dup: false},
+		{name: "different", a: "Rent", b: "Groceries", dup: false},
+		{name: "empty", a: "", b: "...", dup: false},
+	}
+
+	t.Log("Given the need to tell whether two names are alike.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen comparing %q and %q.", testID, tst.a, tst.b)
+				{
+					got := dedupe.Similarity(tst.a, tst.b)
+					if back := dedupe.Similarity(tst.b, tst.a); back != got {
+						t.Fatalf("\t%s\tTest %d:\tShould get the same score both ways, got %v and %v.", failed, testID, got, back)
+					}
+					if (got >= dedupe.Threshold) != tst.dup {
+						t.Fatalf("\t%s\tTest %d:\tShould get a score on the right side of the threshold, got %v.", failed, testID, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get a score on the right side of the threshold.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_Find(t *testing.T) {
+	day := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	rec := func(id string, name string, amount int64, currency string, days int) dedupe.Record {
+		return dedupe.Record{
+			ID:     id,
+			Name:   name,
+			Amount: money.Money{Amount: amount, Currency: currency},
+			Date:   day.AddDate(0, 0, days),
+		}
+	}
+
+	manual := rec("manual", "Coffee Shop", 450, "EUR", 0)
+	imported := rec("imported", "COFFEE SHOP #123", 450, "EUR", 2)
+	late := rec("late", "Coffee Shop", 450, "EUR", 4)
+	dollars := rec("dollars", "Coffee Shop", 450, "USD", 0)
+	pricier := rec("pricier", "Coffee Shop", 451, "EUR", 0)
+	rentA := rec("rent-a", "Rent", 90000, "EUR", 20)
+	rentB := rec("rent-b", "rent", 90000, "EUR", 20)
+
+	recs := []dedupe.Record{late, rentB, dollars, imported, pricier, manual, rentA}
+
+	exp := []dedupe.Pair{
+		{First: rentA, Second: rentB, Score: 1},
+		{First: imported, Second: late, Score: 0.8},
+		{First: manual, Second: imported, Score: 0.8},
+	}
+
+	t.Log("Given the need to find the records entered twice.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen looking through records of a user.", testID)
+		{
+			got := dedupe.Find(recs)
+			if diff := cmp.Diff(exp, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould get the expected pairs. Diff:\n%s", failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get the expected pairs.", success, testID)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+)
+
+// Set of sign conventions of the amount column of a CSV statement.
+const (
+	SignExpenseNegative = "expense_negative"
+	SignIncomeNegative  = "income_negative"
+)
+
+// defaultDateFormat is the layout of the dates when the mapping has none.
+const defaultDateFormat = "2006-01-02"
+
+// CSV reads CSV bank statements, with the columns laid out as its mapping
+// describes. Columns are referenced by their header name, or by their
+// position starting at 1 when the statement has no header row.
+//
+//	Date,Description,Amount
+//	2022-06-01,Salary ACME,2500.00
+//	2022-06-02,Grocery store,-54.20
+type CSV struct {
+	Header            bool   `json:"header"`                                                           // First row holds the column names.
+	Delimiter         string `json:"delimiter" validate:"omitempty,len=1"`                             // Character separating the columns, a comma by default.
+	IDColumn          string `json:"id_column"`                                                        // Column of the ID the bank knows the row by.
+	DateColumn        string `json:"date_column" validate:"required"`                                  // Column of the date the money moved.
+	DateFormat        string `json:"date_format"`                                                      // Layout of the date as Go reference time, 2006-01-02 by default.
+	DescriptionColumn string `json:"description_column" validate:"required"`                           // Column of the description the record is named after.
+	AmountColumn      string `json:"amount_column" validate:"required"`                                // Column of the signed amount.
+	Sign              string `json:"sign" validate:"omitempty,oneof=expense_negative income_negative"` // Which of incomes or expenses are negative, expenses by default.
+	DecimalSeparator  string `json:"decimal_separator" validate:"omitempty,len=1"`                     // Separator of the decimals of the amount, a dot by default.
+	CurrencyColumn    string `json:"currency_column"`                                                  // Column of the currency of the amount.
+	Currency          string `json:"currency" validate:"omitempty,iso4217"`                            // Currency of every amount when there is no currency column.
+}
+
+// Import implements the Importer interface.
+func (m CSV) Import(r io.Reader) ([]Record, error) {
+	if err := validate.Check(m); err != nil {
+		return nil, fmt.Errorf("validating data: %w", err)
+	}
+
+	decimal := m.DecimalSeparator
+	if decimal == "" {
+		decimal = "."
+	}
+	if decimal != "." && decimal != "," {
+		return nil, fmt.Errorf("invalid decimal separator [%s]", decimal)
+	}
+
+	if m.CurrencyColumn == "" && m.Currency == "" {
+		return nil, errors.New("either a currency column or a currency is required")
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	if m.Delimiter != "" {
+		cr.Comma = []rune(m.Delimiter)[0]
+	}
+
+	var header []string
+	if m.Header {
+		rec, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading header: %w", err)
+		}
+		header = rec
+	}
+
+	cols := make(map[string]int)
+	for field, name := range map[string]string{
+		"id":          m.IDColumn,
+		"date":        m.DateColumn,
+		"description": m.DescriptionColumn,
+		"amount":      m.AmountColumn,
+		"currency":    m.CurrencyColumn,
+	} {
+		if name == "" {
+			continue
+		}
+		col, err := column(header, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s column: %w", field, err)
+		}
+		cols[field] = col
+	}
+
+	layout := m.DateFormat
+	if layout == "" {
+		layout = defaultDateFormat
+	}
+
+	var recs []Record
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return nil, fmt.Errorf("reading csv: %w", err)
+			}
+			recs = append(recs, Record{Line: perr.StartLine, Err: perr.Err})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+
+		recs = append(recs, m.parseRow(row, line, cols, layout, decimal))
+	}
+
+	if len(recs) == 0 {
+		return nil, errors.New("no rows found in statement")
+	}
+
+	return recs, nil
+}
+
+// =============================================================================
+
+// parseRow converts a single row of the statement into a record.
+func (m CSV) parseRow(row []string, line int, cols map[string]int, layout string, decimal string) Record {
+	rec := Record{Line: line}
+
+	value := func(field string) (string, error) {
+		col := cols[field]
+		if col >= len(row) {
+			return "", fmt.Errorf("row has no %s column", field)
+		}
+		return strings.TrimSpace(row[col]), nil
+	}
+
+	var err error
+	if m.IDColumn != "" {
+		if rec.FITID, err = value("id"); err != nil {
+			rec.Err = err
+			return rec
+		}
+	}
+
+	date, err := value("date")
+	if err != nil {
+		rec.Err = err
+		return rec
+	}
+	if rec.Date, err = time.Parse(layout, date); err != nil {
+		rec.Err = fmt.Errorf("invalid date format [%s]", date)
+		return rec
+	}
+
+	if rec.Description, err = value("description"); err != nil {
+		rec.Err = err
+		return rec
+	}
+	if rec.Description == "" {
+		rec.Err = errors.New("description is empty")
+		return rec
+	}
+
+	currency := m.Currency
+	if m.CurrencyColumn != "" {
+		if currency, err = value("currency"); err != nil {
+			rec.Err = err
+			return rec
+		}
+	}
+
+	amount, err := value("amount")
+	if err != nil {
+		rec.Err = err
+		return rec
+	}
+	if rec.Amount, err = parseAmount(amount, strings.ToUpper(currency), decimal); err != nil {
+		rec.Err = err
+		return rec
+	}
+	if rec.Amount.IsZero() {
+		rec.Err = errors.New("amount is zero")
+		return rec
+	}
+
+	if m.Sign == SignIncomeNegative {
+		rec.Amount = rec.Amount.Neg()
+	}
+
+	return rec
+}
+
+// parseAmount reads an amount written with the decimal separator. Grouping
+// separators and spaces are dropped, and amounts in parentheses are negative.
+func parseAmount(s string, currency string, decimal string) (money.Money, error) {
+	group := ","
+	if decimal == "," {
+		group = "."
+	}
+
+	neg := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.Trim(s, "()")
+
+	s = strings.NewReplacer(group, "", " ", "", "'", "").Replace(s)
+	s = strings.Replace(s, decimal, ".", 1)
+
+	m, err := money.Parse(s, currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if neg {
+		m = m.Neg()
+	}
+
+	return m, nil
+}
+
+// column finds the position of a column, either by its name in the header or
+// by its number starting at 1.
+func column(header []string, name string) (int, error) {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i, nil
+		}
+	}
+
+	n, err := strconv.Atoi(name)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("column [%s] not found", name)
+	}
+
+	return n - 1, nil
+}
@@ -0,0 +1,130 @@
+package importers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/importers"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_ParseCSV(t *testing.T) {
+	type want struct {
+		fitid       string
+		date        time.Time
+		description string
+		amount      money.Money
+		err         bool
+	}
+
+	tt := []struct {
+		name    string
+		mapping importers.CSV
+		doc     string
+		want    []want
+	}{
+		{
+			name: "header",
+			mapping: importers.CSV{
+				Header:            true,
+				IDColumn:          "Reference",
+				DateColumn:        "Date",
+				DescriptionColumn: "Description",
+				AmountColumn:      "Amount",
+				Currency:          "USD",
+			},
+			doc: "Date,Reference,Description,Amount\n2022-06-01,T1,Salary ACME,\"2,500.00\"\n2022-06-02,T2,Grocery store,-54.2\n2022-06-03,T3,Coffee,abc\n",
+			want: []want{
+				{fitid: "T1", date: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), description: "Salary ACME", amount: money.Money{Amount: 250000, Currency: "USD"}},
+				{fitid: "T2", date: time.Date(2022, time.June, 2, 0, 0, 0, 0, time.UTC), description: "Grocery store", amount: money.Money{Amount: -5420, Currency: "USD"}},
+				{err: true},
+			},
+		},
+		{
+			name: "positions",
+			mapping: importers.CSV{
+				Delimiter:         ";",
+				DateColumn:        "1",
+				DescriptionColumn: "3",
+				AmountColumn:      "2",
+				CurrencyColumn:    "4",
+				DateFormat:        "02.01.2006",
+				DecimalSeparator:  ",",
+				Sign:              importers.SignIncomeNegative,
+			},
+			doc: "01.06.2022;1.234,50;Card payment;eur\n02.06.2022;(10,00);Refund;EUR\n",
+			want: []want{
+				{date: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), description: "Card payment", amount: money.Money{Amount: -123450, Currency: "EUR"}},
+				{date: time.Date(2022, time.June, 2, 0, 0, 0, 0, time.UTC), description: "Refund", amount: money.Money{Amount: 1000, Currency: "EUR"}},
+			},
+		},
+	}
+
+	t.Log("Given the need to read bank statements laid out in CSV.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling the %s mapping.", testID, tst.name)
+				{
+					recs, err := tst.mapping.Import(strings.NewReader(tst.doc))
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to parse the statement : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to parse the statement.", success, testID)
+
+					if len(recs) != len(tst.want) {
+						t.Fatalf("\t%s\tTest %d:\tShould get back %d records : got %d.", failed, testID, len(tst.want), len(recs))
+					}
+					t.Logf("\t%s\tTest %d:\tShould get back %d records.", success, testID, len(tst.want))
+
+					for i, w := range tst.want {
+						rec := recs[i]
+						if w.err {
+							if rec.Err == nil {
+								t.Fatalf("\t%s\tTest %d:\tShould get an error for record %d.", failed, testID, i)
+							}
+							t.Logf("\t%s\tTest %d:\tShould get an error for record %d.", success, testID, i)
+							continue
+						}
+
+						if rec.Err != nil {
+							t.Fatalf("\t%s\tTest %d:\tShould be able to read record %d : %s.", failed, testID, i, rec.Err)
+						}
+						if rec.FITID != w.fitid || !rec.Date.Equal(w.date) || rec.Description != w.description || rec.Amount != w.amount {
+							t.Fatalf("\t%s\tTest %d:\tShould get back the same record %d : got %q %v %q %v.", failed, testID, i, rec.FITID, rec.Date, rec.Description, rec.Amount)
+						}
+						t.Logf("\t%s\tTest %d:\tShould get back the same record %d.", success, testID, i)
+					}
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+
+	t.Log("Given the need to reject statements that can't be read.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen a mapped column is missing from the header.", testID)
+		{
+			m := importers.CSV{
+				Header:            true,
+				DateColumn:        "Date",
+				DescriptionColumn: "Memo",
+				AmountColumn:      "Amount",
+				Currency:          "USD",
+			}
+
+			if _, err := m.Import(strings.NewReader("Date,Description,Amount\n")); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould not be able to parse the statement.", failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould not be able to parse the statement.", success, testID)
+		}
+	}
+}
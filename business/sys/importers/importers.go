@@ -0,0 +1,28 @@
+// Package importers provides support for reading the money movements out of
+// the statements banks export, like CSV, OFX and QIF files.
+package importers
+
+import (
+	"io"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// Importer reads the records out of a statement. It only fails when the
+// statement as a whole can't be read, rows with bad values are returned with
+// the reason in their Err.
+type Importer interface {
+	Import(r io.Reader) ([]Record, error)
+}
+
+// Record represents a single money movement read out of a statement. Incomes
+// have a positive amount and expenses a negative one.
+type Record struct {
+	Line        int         // Line of the row in the statement.
+	FITID       string      // ID the bank knows the money movement by, empty when unknown.
+	Date        time.Time   // When the money moved.
+	Description string      // Description of the money movement.
+	Amount      money.Money // Signed amount of the money movement.
+	Err         error       // Reason the row could not be read.
+}
@@ -0,0 +1,147 @@
+package importers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+// OFX reads Open Financial Exchange statements, both the SGML flavour of
+// version 1, where leaf elements are not closed, and the XML one of version
+// 2. Every transaction keeps the FITID the bank gave it.
+//
+//	<STMTTRN>
+//		<TRNTYPE>DEBIT
+//		<DTPOSTED>20220602120000
+//		<TRNAMT>-54.20
+//		<FITID>2022060201
+//		<NAME>Grocery store
+//	</STMTTRN>
+type OFX struct{}
+
+// Import implements the Importer interface.
+func (OFX) Import(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading ofx: %w", err)
+	}
+	doc := string(data)
+
+	// The headers before the root element are of no use.
+	start := strings.Index(strings.ToUpper(doc), "<OFX>")
+	if start == -1 {
+		return nil, errors.New("no OFX element found in statement")
+	}
+
+	var (
+		recs     []Record
+		currency string
+		trn      map[string]string
+		trnLine  int
+	)
+
+	line := 1 + strings.Count(doc[:start], "\n")
+	for pos := start; pos < len(doc); {
+		open := strings.IndexByte(doc[pos:], '<')
+		if open == -1 {
+			break
+		}
+		line += strings.Count(doc[pos:pos+open], "\n")
+		pos += open
+
+		end := strings.IndexByte(doc[pos:], '>')
+		if end == -1 {
+			return nil, fmt.Errorf("line %d: unterminated element", line)
+		}
+		tag := strings.ToUpper(strings.TrimSpace(doc[pos+1 : pos+end]))
+		pos += end + 1
+
+		// The value of a leaf element runs up to the next element.
+		next := strings.IndexByte(doc[pos:], '<')
+		if next == -1 {
+			next = len(doc) - pos
+		}
+		value := html.UnescapeString(strings.TrimSpace(doc[pos : pos+next]))
+
+		switch {
+		case tag == "STMTTRN":
+			trn, trnLine = make(map[string]string), line
+
+		case tag == "/STMTTRN":
+			if trn != nil {
+				recs = append(recs, ofxRecord(trn, trnLine, currency))
+				trn = nil
+			}
+
+		case strings.HasPrefix(tag, "/"), strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+
+		case tag == "CURDEF":
+			currency = strings.ToUpper(value)
+
+		case trn != nil && value != "":
+			if _, exists := trn[tag]; !exists {
+				trn[tag] = value
+			}
+		}
+	}
+
+	if len(recs) == 0 {
+		return nil, errors.New("no transactions found in statement")
+	}
+
+	return recs, nil
+}
+
+// =============================================================================
+
+// ofxRecord converts the elements of a STMTTRN aggregate into a record.
+func ofxRecord(trn map[string]string, line int, currency string) Record {
+	rec := Record{
+		Line:        line,
+		FITID:       trn["FITID"],
+		Description: trn["NAME"],
+	}
+	if rec.Description == "" {
+		rec.Description = trn["MEMO"]
+	}
+
+	if rec.Description == "" {
+		rec.Err = errors.New("transaction has no name or memo")
+		return rec
+	}
+
+	// Dates are YYYYMMDDHHMMSS.XXX[gmt offset:tz name], only the day matters.
+	posted := trn["DTPOSTED"]
+	if len(posted) < 8 {
+		rec.Err = fmt.Errorf("invalid date format [%s]", posted)
+		return rec
+	}
+	date, err := time.Parse("20060102", posted[:8])
+	if err != nil {
+		rec.Err = fmt.Errorf("invalid date format [%s]", posted)
+		return rec
+	}
+	rec.Date = date
+
+	if currency == "" {
+		rec.Err = errors.New("statement has no default currency")
+		return rec
+	}
+
+	amount := strings.Replace(trn["TRNAMT"], ",", ".", 1)
+	if rec.Amount, err = money.Parse(amount, currency); err != nil {
+		rec.Err = err
+		return rec
+	}
+	if rec.Amount.IsZero() {
+		rec.Err = errors.New("amount is zero")
+		return rec
+	}
+
+	return rec
+}
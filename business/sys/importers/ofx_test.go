@@ -0,0 +1,124 @@
+package importers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/importers"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>EUR
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20220601
+<TRNAMT>2500.00
+<FITID>2022060101
+<NAME>Salary ACME
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20220602120000.000[-5:EST]
+<TRNAMT>-54,20
+<FITID>2022060201
+<MEMO>Fish &amp; chips
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+	<CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+		<CURDEF>USD</CURDEF>
+		<BANKTRANLIST>
+			<STMTTRN>
+				<TRNTYPE>DEBIT</TRNTYPE>
+				<DTPOSTED>20220603</DTPOSTED>
+				<TRNAMT>-12.5</TRNAMT>
+				<FITID>X-1</FITID>
+				<NAME>Coffee</NAME>
+			</STMTTRN>
+			<STMTTRN>
+				<TRNTYPE>DEBIT</TRNTYPE>
+				<DTPOSTED>2022</DTPOSTED>
+				<TRNAMT>-1.00</TRNAMT>
+				<FITID>X-2</FITID>
+				<NAME>Bad date</NAME>
+			</STMTTRN>
+		</BANKTRANLIST>
+	</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func Test_OFX(t *testing.T) {
+	type rec struct {
+		Line        int
+		FITID       string
+		Date        time.Time
+		Description string
+		Amount      money.Money
+		Err         bool
+	}
+
+	tt := []struct {
+		name string
+		doc  string
+		exp  []rec
+	}{
+		{
+			name: "sgml",
+			doc:  ofxSGML,
+			exp: []rec{
+				{Line: 9, FITID: "2022060101", Date: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), Description: "Salary ACME", Amount: money.Money{Amount: 250000, Currency: "EUR"}},
+				{Line: 16, FITID: "2022060201", Date: time.Date(2022, time.June, 2, 0, 0, 0, 0, time.UTC), Description: "Fish & chips", Amount: money.Money{Amount: -5420, Currency: "EUR"}},
+			},
+		},
+		{
+			name: "xml",
+			doc:  ofxXML,
+			exp: []rec{
+				{Line: 7, FITID: "X-1", Date: time.Date(2022, time.June, 3, 0, 0, 0, 0, time.UTC), Description: "Coffee", Amount: money.Money{Amount: -1250, Currency: "USD"}},
+				{Line: 14, FITID: "X-2", Description: "Bad date", Err: true},
+			},
+		},
+	}
+
+	t.Log("Given the need to read bank statements in OFX.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling the %s flavour.", testID, tst.name)
+				{
+					recs, err := importers.OFX{}.Import(strings.NewReader(tst.doc))
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to parse the statement : %s.", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to parse the statement.", success, testID)
+
+					got := make([]rec, len(recs))
+					for i, r := range recs {
+						got[i] = rec{Line: r.Line, FITID: r.FITID, Date: r.Date, Description: r.Description, Amount: r.Amount, Err: r.Err != nil}
+					}
+
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get back the same records. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get back the same records.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package importers
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/validate"
+)
+
+// defaultQIFDateFormat is the layout of the dates when the QIF importer has
+// none, the month first as Quicken writes them.
+const defaultQIFDateFormat = "1/2/2006"
+
+// QIF reads Quicken Interchange Format statements. The format carries
+// neither the currency nor the order of day and month, so both are up to the
+// importer. QIF has no transaction IDs either, so every record gets one
+// derived from its fields, which stays the same when the same statement is
+// read again.
+//
+//	!Type:Bank
+//	D6/2/2022
+//	T-54.20
+//	PGrocery store
+//	^
+type QIF struct {
+	Currency   string `json:"currency" validate:"required,iso4217"` // Currency of every amount.
+	DateFormat string `json:"date_format"`                          // Layout of the date as Go reference time, 1/2/2006 by default.
+}
+
+// Import implements the Importer interface.
+func (q QIF) Import(r io.Reader) ([]Record, error) {
+	if err := validate.Check(q); err != nil {
+		return nil, fmt.Errorf("validating data: %w", err)
+	}
+
+	layout := q.DateFormat
+	if layout == "" {
+		layout = defaultQIFDateFormat
+	}
+
+	var (
+		recs   []Record
+		fields map[byte]string
+		start  int
+		inTrns bool
+	)
+
+	// Identical transactions within the statement are told apart by the
+	// number of times they were seen before.
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if text == "" {
+			continue
+		}
+
+		// Headers switch between sections, only the ones with bank, cash or
+		// credit card transactions are of interest.
+		if text[0] == '!' {
+			header := strings.ToLower(text)
+			switch {
+			case strings.HasPrefix(header, "!type:"):
+				switch strings.TrimSpace(header[len("!type:"):]) {
+				case "bank", "cash", "ccard", "oth a", "oth l":
+					inTrns = true
+				default:
+					inTrns = false
+				}
+			case strings.HasPrefix(header, "!account"):
+				inTrns = false
+			}
+			continue
+		}
+
+		if !inTrns {
+			continue
+		}
+
+		if text[0] == '^' {
+			if fields != nil {
+				rec := q.record(fields, start, layout)
+				if rec.Err == nil {
+					key := fields['D'] + "|" + fields['T'] + "|" + fields['P'] + "|" + fields['M'] + "|" + fields['N']
+					rec.FITID = qifID(key, seen[key])
+					seen[key]++
+				}
+				recs = append(recs, rec)
+				fields = nil
+			}
+			continue
+		}
+
+		if fields == nil {
+			fields, start = make(map[byte]string), line
+		}
+		if _, exists := fields[text[0]]; !exists {
+			fields[text[0]] = strings.TrimSpace(text[1:])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading qif: %w", err)
+	}
+
+	if len(recs) == 0 {
+		return nil, errors.New("no transactions found in statement")
+	}
+
+	return recs, nil
+}
+
+// =============================================================================
+
+// record converts the fields of a QIF transaction into a record.
+func (q QIF) record(fields map[byte]string, line int, layout string) Record {
+	rec := Record{
+		Line:        line,
+		Description: fields['P'],
+	}
+	if rec.Description == "" {
+		rec.Description = fields['M']
+	}
+
+	if rec.Description == "" {
+		rec.Err = errors.New("transaction has no payee or memo")
+		return rec
+	}
+
+	// Quicken writes the year after an apostrophe and pads with spaces, as in
+	// 6/ 2'22.
+	date := strings.NewReplacer("'", "/", " ", "").Replace(fields['D'])
+	d, err := time.Parse(layout, date)
+	if err != nil {
+		if d, err = time.Parse(strings.Replace(layout, "2006", "06", 1), date); err != nil {
+			rec.Err = fmt.Errorf("invalid date format [%s]", fields['D'])
+			return rec
+		}
+	}
+	rec.Date = d
+
+	amount, exists := fields['T']
+	if !exists {
+		amount = fields['U']
+	}
+	if rec.Amount, err = parseAmount(amount, q.Currency, "."); err != nil {
+		rec.Err = err
+		return rec
+	}
+	if rec.Amount.IsZero() {
+		rec.Err = errors.New("amount is zero")
+		return rec
+	}
+
+	return rec
+}
+
+// qifID derives the ID of a transaction from its fields and the number of
+// identical transactions before it.
+func qifID(key string, n int) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d", key, n)))
+	return "qif-" + hex.EncodeToString(sum[:10])
+}
@@ -0,0 +1,82 @@
+package importers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/importers"
+	"github.com/gloompi/ultimate-service/business/sys/money"
+)
+
+const qifDoc = `!Account
+NChecking
+TBank
+^
+!Type:Bank
+D6/ 1'22
+T2,500.00
+PSalary ACME
+^
+D6/2/2022
+T-4.50
+PCoffee
+^
+D6/2/2022
+T-4.50
+PCoffee
+^
+D13/2/2022
+T-1.00
+PBad date
+^
+`
+
+func Test_QIF(t *testing.T) {
+	t.Log("Given the need to read bank statements in QIF.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen handling a bank statement.", testID)
+		{
+			qif := importers.QIF{Currency: "USD"}
+
+			recs, err := qif.Import(strings.NewReader(qifDoc))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to parse the statement : %s.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to parse the statement.", success, testID)
+
+			if len(recs) != 4 {
+				t.Fatalf("\t%s\tTest %d:\tShould get back 4 records : got %d.", failed, testID, len(recs))
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back 4 records.", success, testID)
+
+			rec := recs[0]
+			if rec.Line != 6 || !rec.Date.Equal(time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)) || rec.Description != "Salary ACME" || rec.Amount != (money.Money{Amount: 250000, Currency: "USD"}) {
+				t.Fatalf("\t%s\tTest %d:\tShould get back the first record : got %d %v %q %v.", failed, testID, rec.Line, rec.Date, rec.Description, rec.Amount)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back the first record.", success, testID)
+
+			if recs[1].FITID == "" || recs[1].FITID == recs[2].FITID {
+				t.Fatalf("\t%s\tTest %d:\tShould tell identical transactions apart : got %q and %q.", failed, testID, recs[1].FITID, recs[2].FITID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould tell identical transactions apart.", success, testID)
+
+			if recs[3].Err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould get an error for the bad date.", failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get an error for the bad date.", success, testID)
+
+			again, err := qif.Import(strings.NewReader(qifDoc))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to parse the statement again : %s.", failed, testID, err)
+			}
+			for i := range recs {
+				if recs[i].FITID != again[i].FITID {
+					t.Fatalf("\t%s\tTest %d:\tShould get back the same IDs when reading the statement again.", failed, testID)
+				}
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back the same IDs when reading the statement again.", success, testID)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+// Package money provides an exact representation of an amount of money in
+// the minor units of its ISO 4217 currency, along with the arithmetic and
+// rounding rules to work with it.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Set of error variables for money operations.
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrOverflow         = errors.New("amount out of range")
+)
+
+// Money represents an amount of a currency. The amount is kept in the minor
+// unit of the currency (cents for EUR, yen for JPY, fils for KWD) so it is
+// always exact.
+type Money struct {
+	Amount   int64  // Amount in the minor unit of the currency.
+	Currency string // ISO 4217 code of the currency.
+}
+
+// New constructs a Money value from an amount in minor units.
+func New(amount int64, currency string) (Money, error) {
+	if !IsCurrency(currency) {
+		return Money{}, fmt.Errorf("currency[%s]: %w", currency, ErrUnknownCurrency)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Zero returns an empty amount of the currency.
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// Parse constructs a Money value from a decimal string like "12.34". The
+// string can't carry more decimals than the currency has, unless the extra
+// digits are zeros.
+func Parse(amount string, currency string) (Money, error) {
+	exp, exists := Exponent(currency)
+	if !exists {
+		return Money{}, fmt.Errorf("currency[%s]: %w", currency, ErrUnknownCurrency)
+	}
+
+	s := strings.TrimSpace(amount)
+	sign := ""
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = "-", s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || !digits(whole) || !digits(frac) {
+		return Money{}, fmt.Errorf("amount[%s]: %w", amount, ErrInvalidAmount)
+	}
+
+	if len(frac) > exp {
+		if strings.Trim(frac[exp:], "0") != "" {
+			return Money{}, fmt.Errorf("amount[%s] has more than %d decimals: %w", amount, exp, ErrInvalidAmount)
+		}
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	n, err := strconv.ParseInt(sign+whole+frac, 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return Money{}, fmt.Errorf("amount[%s]: %w", amount, ErrOverflow)
+		}
+		return Money{}, fmt.Errorf("amount[%s]: %w", amount, ErrInvalidAmount)
+	}
+
+	return Money{Amount: n, Currency: currency}, nil
+}
+
+// Add returns the sum of both amounts. Both must be of the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%s + %s: %w", m.Currency, o.Currency, ErrCurrencyMismatch)
+	}
+
+	sum := m.Amount + o.Amount
+	if (sum > m.Amount) != (o.Amount > 0) {
+		return Money{}, fmt.Errorf("%s + %s: %w", m, o, ErrOverflow)
+	}
+
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of both amounts. Both must be of the same
+// currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%s - %s: %w", m, o, ErrOverflow)
+	}
+	return m.Add(o.Neg())
+}
+
+// Mul returns the amount multiplied by n.
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+
+	p := m.Amount * n
+	if p/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, fmt.Errorf("%s * %d: %w", m, n, ErrOverflow)
+	}
+
+	return Money{Amount: p, Currency: m.Currency}, nil
+}
+
+// Neg returns the amount with its sign flipped.
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Convert returns the amount expressed in another currency, where rate is
+// the units of the target currency one unit of this currency buys. The
+// result is rounded to the minor unit of the target currency using half to
+// even rounding.
+func (m Money) Convert(to string, rate *big.Rat) (Money, error) {
+	fromExp, exists := Exponent(m.Currency)
+	if !exists {
+		return Money{}, fmt.Errorf("currency[%s]: %w", m.Currency, ErrUnknownCurrency)
+	}
+
+	toExp, exists := Exponent(to)
+	if !exists {
+		return Money{}, fmt.Errorf("currency[%s]: %w", to, ErrUnknownCurrency)
+	}
+
+	// minor(to) = minor(from) / 10^fromExp * rate * 10^toExp
+	r := new(big.Rat).SetInt64(m.Amount)
+	r.Mul(r, rate)
+	r.Mul(r, new(big.Rat).SetFrac(pow10(toExp), pow10(fromExp)))
+
+	n, err := roundHalfEven(r)
+	if err != nil {
+		return Money{}, fmt.Errorf("%s to %s: %w", m, to, err)
+	}
+
+	return Money{Amount: n, Currency: to}, nil
+}
+
+// Allocate splits the amount into as many shares as there are ratios,
+// proportional to each ratio. The minor units left over by the division are
+// handed out one by one starting with the first share, so the shares always
+// add up to the original amount.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("negative ratio[%d]: %w", r, ErrInvalidAmount)
+		}
+		total += r
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("ratios add up to zero: %w", ErrInvalidAmount)
+	}
+
+	amount := big.NewInt(m.Amount)
+	shares := make([]Money, len(ratios))
+
+	var allocated int64
+	for i, r := range ratios {
+		share := new(big.Int).Mul(amount, big.NewInt(r))
+		share.Quo(share, big.NewInt(total))
+
+		shares[i] = Money{Amount: share.Int64(), Currency: m.Currency}
+		allocated += shares[i].Amount
+	}
+
+	unit := int64(1)
+	if m.Amount < 0 {
+		unit = -1
+	}
+	for i := 0; allocated != m.Amount; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].Amount += unit
+		allocated += unit
+	}
+
+	return shares, nil
+}
+
+// Totals adds up the amounts currency by currency, since amounts of different
+// currencies can't be added together. The totals are sorted by currency.
+func Totals(ms []Money) ([]Money, error) {
+	sums := make(map[string]Money)
+	for _, m := range ms {
+		sum, exists := sums[m.Currency]
+		if !exists {
+			sums[m.Currency] = m
+			continue
+		}
+
+		var err error
+		if sums[m.Currency], err = sum.Add(m); err != nil {
+			return nil, err
+		}
+	}
+
+	totals := make([]Money, 0, len(sums))
+	for _, sum := range sums {
+		totals = append(totals, sum)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Currency < totals[j].Currency })
+
+	return totals, nil
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// IsPositive reports whether the amount is above zero.
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Decimal returns the amount as a decimal string in major units, like "12.34".
+func (m Money) Decimal() string {
+	exp, _ := Exponent(m.Currency)
+
+	s := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	if exp == 0 {
+		return sign + s
+	}
+
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+// String implements the fmt.Stringer interface.
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// MarshalJSON implements the json.Marshaler interface. The amount is encoded
+// as a decimal string so no precision is lost on clients using floats.
+func (m Money) MarshalJSON() ([]byte, error) {
+	jm := struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.Decimal(),
+		Currency: m.Currency,
+	}
+
+	return json.Marshal(jm)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The amount can be
+// provided either as a decimal string or as a JSON number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var jm struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return err
+	}
+
+	v, err := Parse(jm.Amount.String(), jm.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = v
+	return nil
+}
+
+// =============================================================================
+
+// digits reports whether the string only holds decimal digits.
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pow10 returns 10 to the power of n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundHalfEven rounds a rational number to the nearest integer, with ties
+// going to the nearest even integer.
+func roundHalfEven(r *big.Rat) (int64, error) {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	// Compare twice the remainder against the denominator to know which side
+	// of the half the fraction falls on.
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+
+	switch c := twice.Cmp(r.Denom()); {
+	case c > 0, c == 0 && q.Bit(0) == 1:
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+
+	return q.Int64(), nil
+}
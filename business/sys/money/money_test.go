@@ -0,0 +1,220 @@
+package money_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/sys/money"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Parse(t *testing.T) {
+	tt := []struct {
+		name     string
+		amount   string
+		currency string
+		exp      money.Money
+		err      error
+	}{
+		{name: "euros", amount: "12.34", currency: "EUR", exp: money.Money{Amount: 1234, Currency: "EUR"}},
+		{name: "wholeEuros", amount: "12", currency: "EUR", exp: money.Money{Amount: 1200, Currency: "EUR"}},
+		{name: "shortFraction", amount: "0.5", currency: "EUR", exp: money.Money{Amount: 50, Currency: "EUR"}},
+		{name: "negative", amount: "-7.05", currency: "EUR", exp: money.Money{Amount: -705, Currency: "EUR"}},
+		{name: "yen", amount: "1500", currency: "JPY", exp: money.Money{Amount: 1500, Currency: "JPY"}},
+		{name: "dinars", amount: "1.005", currency: "KWD", exp: money.Money{Amount: 1005, Currency: "KWD"}},
+		{name: "trailingZeros", amount: "3.100", currency: "EUR", exp: money.Money{Amount: 310, Currency: "EUR"}},
+		{name: "tooPrecise", amount: "1.5", currency: "JPY", err: money.ErrInvalidAmount},
+		{name: "notANumber", amount: "12,34", currency: "EUR", err: money.ErrInvalidAmount},
+		{name: "empty", amount: "", currency: "EUR", err: money.ErrInvalidAmount},
+		{name: "unknownCurrency", amount: "1", currency: "XXY", err: money.ErrUnknownCurrency},
+		{name: "overflow", amount: "92233720368547758.08", currency: "EUR", err: money.ErrOverflow},
+	}
+
+	t.Log("Given the need to parse decimal amounts into minor units.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen parsing %q %s.", testID, tst.amount, tst.currency)
+				{
+					got, err := money.Parse(tst.amount, tst.currency)
+					if !errors.Is(err, tst.err) {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected error: %v", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected amount. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected amount.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_Arithmetic(t *testing.T) {
+	eur := func(n int64) money.Money { return money.Money{Amount: n, Currency: "EUR"} }
+
+	t.Log("Given the need to do safe arithmetic on amounts.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen adding and subtracting amounts.", testID)
+		{
+			got, err := eur(150).Add(eur(275))
+			if err != nil || got != eur(425) {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to add amounts: %v %v", failed, testID, got, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to add amounts.", success, testID)
+
+			got, err = eur(150).Sub(eur(275))
+			if err != nil || got != eur(-125) {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to subtract amounts: %v %v", failed, testID, got, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to subtract amounts.", success, testID)
+
+			if _, err := eur(1).Add(money.Money{Amount: 1, Currency: "USD"}); !errors.Is(err, money.ErrCurrencyMismatch) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse to mix currencies: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse to mix currencies.", success, testID)
+
+			if _, err := eur(math.MaxInt64).Add(eur(1)); !errors.Is(err, money.ErrOverflow) {
+				t.Fatalf("\t%s\tTest %d:\tShould detect overflows: %v", failed, testID, err)
+			}
+			if _, err := eur(math.MaxInt64 / 2).Mul(3); !errors.Is(err, money.ErrOverflow) {
+				t.Fatalf("\t%s\tTest %d:\tShould detect overflows: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould detect overflows.", success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen allocating an amount.", testID)
+		{
+			got, err := eur(100).Allocate(1, 1, 1)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to allocate: %v", failed, testID, err)
+			}
+
+			exp := []money.Money{eur(34), eur(33), eur(33)}
+			if diff := cmp.Diff(exp, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould not lose a cent. Diff:\n%s", failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould not lose a cent.", success, testID)
+
+			got, err = eur(-5).Allocate(70, 30)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to allocate: %v", failed, testID, err)
+			}
+
+			exp = []money.Money{eur(-4), eur(-1)}
+			if diff := cmp.Diff(exp, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould allocate negative amounts. Diff:\n%s", failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould allocate negative amounts.", success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen adding up amounts of several currencies.", testID)
+		{
+			usd := func(n int64) money.Money { return money.Money{Amount: n, Currency: "USD"} }
+
+			got, err := money.Totals([]money.Money{usd(100), eur(250), usd(-30), eur(50)})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to add up amounts: %v", failed, testID, err)
+			}
+
+			exp := []money.Money{eur(300), usd(70)}
+			if diff := cmp.Diff(exp, got); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould get a total per currency. Diff:\n%s", failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get a total per currency.", success, testID)
+		}
+	}
+}
+
+func Test_Convert(t *testing.T) {
+	tt := []struct {
+		name string
+		from money.Money
+		to   string
+		rate string
+		exp  money.Money
+	}{
+		{name: "eurToUsd", from: money.Money{Amount: 1000, Currency: "EUR"}, to: "USD", rate: "1.145", exp: money.Money{Amount: 1145, Currency: "USD"}},
+		{name: "eurToJpy", from: money.Money{Amount: 1000, Currency: "EUR"}, to: "JPY", rate: "125.85", exp: money.Money{Amount: 1258, Currency: "JPY"}},
+		{name: "jpyToKwd", from: money.Money{Amount: 1000, Currency: "JPY"}, to: "KWD", rate: "0.0027", exp: money.Money{Amount: 2700, Currency: "KWD"}},
+		{name: "halfToEven", from: money.Money{Amount: 25, Currency: "EUR"}, to: "USD", rate: "0.5", exp: money.Money{Amount: 12, Currency: "USD"}},
+		{name: "halfToEvenUp", from: money.Money{Amount: 35, Currency: "EUR"}, to: "USD", rate: "0.5", exp: money.Money{Amount: 18, Currency: "USD"}},
+		{name: "negative", from: money.Money{Amount: -25, Currency: "EUR"}, to: "USD", rate: "0.5", exp: money.Money{Amount: -12, Currency: "USD"}},
+	}
+
+	t.Log("Given the need to convert amounts between currencies.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen converting %s to %s.", testID, tst.from, tst.to)
+				{
+					rate, _ := new(big.Rat).SetString(tst.rate)
+
+					got, err := tst.from.Convert(tst.to, rate)
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to convert: %v", failed, testID, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould be able to convert.", success, testID)
+
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected amount. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected amount.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_JSON(t *testing.T) {
+	t.Log("Given the need to encode amounts as decimal strings.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen marshaling and unmarshaling amounts.", testID)
+		{
+			data, err := json.Marshal(money.Money{Amount: -5, Currency: "KWD"})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to marshal: %v", failed, testID, err)
+			}
+
+			if exp := `{"amount":"-0.005","currency":"KWD"}`; string(data) != exp {
+				t.Fatalf("\t%s\tTest %d:\tShould get the expected document: got %s exp %s", failed, testID, data, exp)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get the expected document.", success, testID)
+
+			for _, doc := range []string{`{"amount":"12.30","currency":"EUR"}`, `{"amount":12.3,"currency":"EUR"}`} {
+				var m money.Money
+				if err := json.Unmarshal([]byte(doc), &m); err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to unmarshal %s: %v", failed, testID, doc, err)
+				}
+
+				if exp := (money.Money{Amount: 1230, Currency: "EUR"}); m != exp {
+					t.Fatalf("\t%s\tTest %d:\tShould get the expected amount: got %v exp %v", failed, testID, m, exp)
+				}
+			}
+			t.Logf("\t%s\tTest %d:\tShould accept strings and numbers.", success, testID)
+
+			var m money.Money
+			if err := json.Unmarshal([]byte(`{"amount":"1","currency":"ABC"}`), &m); !errors.Is(err, money.ErrUnknownCurrency) {
+				t.Fatalf("\t%s\tTest %d:\tShould reject unknown currencies: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould reject unknown currencies.", success, testID)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package order provides support for describing the ordering of data.
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Set of directions data can be ordered in.
+const (
+	ASC  = "ASC"
+	DESC = "DESC"
+)
+
+// Set of error variables for ordering.
+var (
+	ErrInvalidDirection = errors.New("direction must be either asc or desc")
+	ErrUnknownField     = errors.New("field can't be ordered by")
+)
+
+// By represents a field used to order by and its direction.
+type By struct {
+	Field     string
+	Direction string
+}
+
+// NewBy constructs a new By value with no checks.
+func NewBy(field string, direction string) By {
+	return By{
+		Field:     field,
+		Direction: direction,
+	}
+}
+
+// Parse reads an order like "amount" or "amount,desc". An empty value gives
+// back the default order. It is up to the stores to check the field is one
+// they can order by.
+func Parse(value string, defaultOrder By) (By, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultOrder, nil
+	}
+
+	field, direction, _ := strings.Cut(value, ",")
+	field = strings.TrimSpace(field)
+	direction = strings.ToUpper(strings.TrimSpace(direction))
+
+	if field == "" {
+		return By{}, fmt.Errorf("order[%s]: %w", value, ErrUnknownField)
+	}
+
+	switch direction {
+	case "":
+		direction = ASC
+	case ASC, DESC:
+	default:
+		return By{}, fmt.Errorf("direction[%s]: %w", direction, ErrInvalidDirection)
+	}
+
+	return NewBy(field, direction), nil
+}
+
+// Clause turns the order into the expression of an ORDER BY clause, using
+// columns to look up the column behind every field that can be ordered by.
+// The columns in tieBreak follow to keep the order stable between queries.
+func Clause(by By, columns map[string]string, tieBreak ...string) (string, error) {
+	column, exists := columns[by.Field]
+	if !exists {
+		return "", fmt.Errorf("field[%s]: %w", by.Field, ErrUnknownField)
+	}
+
+	if by.Direction != ASC && by.Direction != DESC {
+		return "", fmt.Errorf("direction[%s]: %w", by.Direction, ErrInvalidDirection)
+	}
+
+	exprs := []string{column + " " + by.Direction}
+	for _, col := range tieBreak {
+		if col != column {
+			exprs = append(exprs, col+" "+by.Direction)
+		}
+	}
+
+	return strings.Join(exprs, ", "), nil
+}
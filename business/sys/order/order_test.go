@@ -0,0 +1,104 @@
+package order_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gloompi/ultimate-service/business/sys/order"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_Parse(t *testing.T) {
+	def := order.NewBy("date_created", order.ASC)
+
+	tt := []struct {
+		name   string
+		value  string
+		exp    order.By
+		expErr error
+	}{
+		{name: "empty", value: "", exp: def},
+		{name: "field", value: "amount", exp: order.NewBy("amount", order.ASC)},
+		{name: "desc", value: "amount,desc", exp: order.NewBy("amount", order.DESC)},
+		{name: "spaced", value: " name , ASC ", exp: order.NewBy("name", order.ASC)},
+		{name: "badDirection", value: "amount,up", expErr: order.ErrInvalidDirection},
+		{name: "noField", value: ",desc", expErr: order.ErrUnknownField},
+	}
+
+	t.Log("Given the need to parse the order of a list.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s order.", testID, tst.name)
+				{
+					got, err := order.Parse(tst.value, def)
+					if tst.expErr != nil {
+						if !errors.Is(err, tst.expErr) {
+							t.Fatalf("\t%s\tTest %d:\tShould get error %q, got %v.", failed, testID, tst.expErr, err)
+						}
+						t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+						return
+					}
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to parse the order : %s.", failed, testID, err)
+					}
+					if got != tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get %+v, got %+v.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected order.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func Test_Clause(t *testing.T) {
+	columns := map[string]string{
+		"date_created": "e.date_created",
+		"amount":       "e.amount",
+	}
+
+	tt := []struct {
+		name   string
+		by     order.By
+		exp    string
+		expErr error
+	}{
+		{name: "known", by: order.NewBy("amount", order.DESC), exp: "e.amount DESC, e.expense_id DESC"},
+		{name: "unknown", by: order.NewBy("amount; DROP TABLE expenses", order.ASC), expErr: order.ErrUnknownField},
+		{name: "badDirection", by: order.NewBy("amount", "SIDEWAYS"), expErr: order.ErrInvalidDirection},
+	}
+
+	t.Log("Given the need to order only by whitelisted columns.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s field.", testID, tst.name)
+				{
+					got, err := order.Clause(tst.by, columns, "e.expense_id")
+					if tst.expErr != nil {
+						if !errors.Is(err, tst.expErr) {
+							t.Fatalf("\t%s\tTest %d:\tShould get error %q, got %v.", failed, testID, tst.expErr, err)
+						}
+						t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+						return
+					}
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to build the clause : %s.", failed, testID, err)
+					}
+					if got != tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get %q, got %q.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected clause.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package recurrence provides support for expanding the reoccurrence settings
+// stored on incomes and expenses into the set of dates they happen on.
+package recurrence
+
+import (
+	"time"
+)
+
+// Set of supported reoccurrence types.
+const (
+	TypeMonthly = "Monthly"
+	TypeDaily   = "Daily"
+	TypeOnce    = "Once"
+)
+
+// Set of supported duration types.
+const (
+	DurationMonths = "Months"
+	DurationDays   = "Days"
+	DurationNone   = "None"
+)
+
+// Rule describes when a recurring record happens. Start is the date of the
+// first occurrence, Interval is the number of units between occurrences and
+// Duration limits how long the rule keeps producing occurrences.
+type Rule struct {
+	Start        time.Time
+	Type         string
+	Interval     int
+	Duration     int
+	DurationType string
+}
+
+// End returns the moment the rule stops producing occurrences. The second
+// value is false when the rule never runs out.
+func (r Rule) End() (time.Time, bool) {
+	if r.Duration <= 0 {
+		return time.Time{}, false
+	}
+
+	start := day(r.Start)
+	switch r.DurationType {
+	case DurationMonths:
+		return addMonths(start, start.Day(), r.Duration), true
+	case DurationDays:
+		return start.AddDate(0, 0, r.Duration), true
+	}
+
+	return time.Time{}, false
+}
+
+// Between returns the occurrences of the rule that fall within [from, to).
+func (r Rule) Between(from time.Time, to time.Time) []time.Time {
+	start := day(r.Start)
+
+	if end, ok := r.End(); ok && end.Before(to) {
+		to = end
+	}
+
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	var dates []time.Time
+	for i := 0; ; i++ {
+		var next time.Time
+		switch r.Type {
+		case TypeDaily:
+			next = start.AddDate(0, 0, i*interval)
+		case TypeOnce:
+			if i > 0 {
+				return dates
+			}
+			next = start
+		default:
+			next = addMonths(start, start.Day(), i*interval)
+		}
+
+		if !next.Before(to) {
+			return dates
+		}
+		if !next.Before(from) {
+			dates = append(dates, next)
+		}
+	}
+}
+
+// =============================================================================
+
+// day truncates a time value to midnight UTC of the same date.
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// addMonths moves a date by the given number of months keeping the original
+// day of month, clamped to the last day of shorter months. Using AddDate for
+// this would push Jan 31 into March.
+func addMonths(t time.Time, dom int, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if dom > last {
+		dom = last
+	}
+	return time.Date(first.Year(), first.Month(), dom, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,89 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloompi/ultimate-service/business/sys/recurrence"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func Test_Between(t *testing.T) {
+	tt := []struct {
+		name string
+		rule recurrence.Rule
+		from time.Time
+		to   time.Time
+		exp  []time.Time
+	}{
+		{
+			name: "monthly",
+			rule: recurrence.Rule{Start: date(2022, time.January, 15), Type: recurrence.TypeMonthly, Interval: 1, DurationType: recurrence.DurationNone},
+			from: date(2022, time.January, 1),
+			to:   date(2022, time.April, 1),
+			exp:  []time.Time{date(2022, time.January, 15), date(2022, time.February, 15), date(2022, time.March, 15)},
+		},
+		{
+			name: "monthlyEndOfMonth",
+			rule: recurrence.Rule{Start: date(2022, time.January, 31), Type: recurrence.TypeMonthly, Interval: 1},
+			from: date(2022, time.January, 1),
+			to:   date(2022, time.April, 1),
+			exp:  []time.Time{date(2022, time.January, 31), date(2022, time.February, 28), date(2022, time.March, 31)},
+		},
+		{
+			name: "monthlyWithDuration",
+			rule: recurrence.Rule{Start: date(2022, time.January, 1), Type: recurrence.TypeMonthly, Interval: 1, Duration: 2, DurationType: recurrence.DurationMonths},
+			from: date(2022, time.January, 1),
+			to:   date(2023, time.January, 1),
+			exp:  []time.Time{date(2022, time.January, 1), date(2022, time.February, 1)},
+		},
+		{
+			name: "dailyEveryThird",
+			rule: recurrence.Rule{Start: date(2022, time.March, 1), Type: recurrence.TypeDaily, Interval: 3, Duration: 7, DurationType: recurrence.DurationDays},
+			from: date(2022, time.January, 1),
+			to:   date(2023, time.January, 1),
+			exp:  []time.Time{date(2022, time.March, 1), date(2022, time.March, 4), date(2022, time.March, 7)},
+		},
+		{
+			name: "once",
+			rule: recurrence.Rule{Start: date(2022, time.May, 5), Type: recurrence.TypeOnce},
+			from: date(2022, time.January, 1),
+			to:   date(2023, time.January, 1),
+			exp:  []time.Time{date(2022, time.May, 5)},
+		},
+		{
+			name: "outsideWindow",
+			rule: recurrence.Rule{Start: date(2022, time.May, 5), Type: recurrence.TypeMonthly, Interval: 1},
+			from: date(2022, time.January, 1),
+			to:   date(2022, time.May, 1),
+			exp:  nil,
+		},
+	}
+
+	t.Log("Given the need to expand reoccurrence rules into dates.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s rule.", testID, tst.name)
+				{
+					got := tst.rule.Between(tst.from, tst.to)
+					if diff := cmp.Diff(tst.exp, got); diff != "" {
+						t.Fatalf("\t%s\tTest %d:\tShould get the expected dates. Diff:\n%s", failed, testID, diff)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected dates.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
@@ -14,6 +14,16 @@ type FieldError struct {
 // FieldErrors represents a collection of field errors.
 type FieldErrors []FieldError
 
+// NewFieldsError creates a fields error for a single field.
+func NewFieldsError(field string, err error) error {
+	return FieldErrors{
+		{
+			Field: field,
+			Error: err.Error(),
+		},
+	}
+}
+
 // Error implments the error interface.
 func (fe FieldErrors) Error() string {
 	d, err := json.Marshal(fe)
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidIfMatch is returned when the If-Match header doesn't hold a single
+// entity tag given out by ETag.
+var ErrInvalidIfMatch = errors.New("If-Match must be a single entity tag or *")
+
+// ETag returns the entity tag of a record at the given version, to be set in
+// the ETag header of the response.
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ParseIfMatch reads the version a record must still be at from the value of
+// an If-Match header. It returns zero when the header is empty or *, which
+// any version matches.
+func ParseIfMatch(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(value, `"`) {
+		return 0, ErrInvalidIfMatch
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 1 {
+		return 0, ErrInvalidIfMatch
+	}
+
+	return version, nil
+}
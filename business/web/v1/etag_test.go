@@ -0,0 +1,58 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+
+	v1Web "github.com/gloompi/ultimate-service/business/web/v1"
+)
+
+// Success and failure markers.
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func Test_ParseIfMatch(t *testing.T) {
+	tt := []struct {
+		name   string
+		value  string
+		exp    int64
+		expErr error
+	}{
+		{name: "empty", value: "", exp: 0},
+		{name: "any", value: "*", exp: 0},
+		{name: "etag", value: v1Web.ETag(7), exp: 7},
+		{name: "unquoted", value: "7", expErr: v1Web.ErrInvalidIfMatch},
+		{name: "weak", value: `W/"7"`, expErr: v1Web.ErrInvalidIfMatch},
+		{name: "list", value: `"7", "8"`, expErr: v1Web.ErrInvalidIfMatch},
+		{name: "zero", value: `"0"`, expErr: v1Web.ErrInvalidIfMatch},
+	}
+
+	t.Log("Given the need to read the version a record must be at from If-Match.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				t.Logf("\tTest %d:\tWhen handling a %s header.", testID, tst.name)
+				{
+					got, err := v1Web.ParseIfMatch(tst.value)
+					if tst.expErr != nil {
+						if !errors.Is(err, tst.expErr) {
+							t.Fatalf("\t%s\tTest %d:\tShould get error %q, got %v.", failed, testID, tst.expErr, err)
+						}
+						t.Logf("\t%s\tTest %d:\tShould get the expected error.", success, testID)
+						return
+					}
+					if err != nil {
+						t.Fatalf("\t%s\tTest %d:\tShould be able to parse the header : %s.", failed, testID, err)
+					}
+					if got != tst.exp {
+						t.Fatalf("\t%s\tTest %d:\tShould get version %d, got %d.", failed, testID, tst.exp, got)
+					}
+					t.Logf("\t%s\tTest %d:\tShould get the expected version.", success, testID)
+				}
+			}
+			t.Run(tst.name, tf)
+		}
+	}
+}
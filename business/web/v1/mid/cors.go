@@ -16,7 +16,8 @@ func Cors(origin string) web.Middleware {
 			// Set the CORS headers to the response.
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, If-Match")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 			// Call the next handler.
 			return handler(ctx, w, r)
@@ -0,0 +1,55 @@
+// Package blob provides support for storing binary objects, like files
+// uploaded by users, on the local filesystem or in an S3 compatible service.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Set of error variables for blob operations.
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("blob key is not in its proper form")
+)
+
+// Store is the behavior a blob store has to provide. Keys are slash separated
+// paths made of letters, digits, dashes, dots and underscores.
+type Store interface {
+	// Put stores size bytes read from r under the key, replacing any blob
+	// stored under it before.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the blob stored under the key for reading. The caller has to
+	// close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob stored under the key. Removing a blob that
+	// doesn't exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// CheckKey validates a key can be used with any store.
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return ErrInvalidKey
+	}
+
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+		for _, c := range part {
+			switch {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			case c == '-', c == '.', c == '_':
+			default:
+				return ErrInvalidKey
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package blob
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// unsignedPayload is the payload hash sent when the body isn't signed, so
+// blobs can be streamed without reading them twice.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Config contains what is needed to reach a bucket of an S3 compatible
+// service.
+type S3Config struct {
+	Endpoint        string       // Base URL of the service, like https://s3.eu-west-1.amazonaws.com.
+	Region          string       // Region the bucket lives in.
+	Bucket          string       // Name of the bucket.
+	AccessKeyID     string       // ID of the access key requests are signed with.
+	SecretAccessKey string       // Secret of the access key.
+	Client          *http.Client // Client sending the requests, http.DefaultClient when nil.
+}
+
+// S3 stores blobs as objects of a bucket in an S3 compatible service, like
+// AWS S3 or MinIO. Objects are addressed in the path style so any service
+// works without DNS set up for the bucket.
+type S3 struct {
+	endpoint *url.URL
+	bucket   string
+	client   *http.Client
+	signer   Signer
+}
+
+// NewS3 constructs an S3 store for the bucket.
+func NewS3(cfg S3Config) (*S3, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", cfg.Endpoint)
+	}
+
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	s3 := S3{
+		endpoint: endpoint,
+		bucket:   cfg.Bucket,
+		client:   client,
+		signer: Signer{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			Region:          cfg.Region,
+		},
+	}
+
+	return &s3, nil
+}
+
+// Put uploads the blob as an object in a single request.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Get downloads the object of the blob, streaming it from the service.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// Delete removes the object of the blob.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// request constructs a request for the object of a blob.
+func (s *S3) request(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, err
+	}
+
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	return req, nil
+}
+
+// do signs and sends a request, turning the error responses of the service
+// into errors.
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	s.signer.Sign(req, unsignedPayload, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	// Error responses carry a code and a message in an XML document.
+	var doc struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&doc); err != nil || doc.Code == "" {
+		return nil, fmt.Errorf("s3: %s", resp.Status)
+	}
+
+	return nil, fmt.Errorf("s3: %s: %s: %s", resp.Status, doc.Code, doc.Message)
+}
@@ -3,6 +3,7 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"go.opentelemetry.io/otel"
@@ -43,3 +44,21 @@ func Respond(ctx context.Context, w http.ResponseWriter, data any, statusCode in
 
 	return nil
 }
+
+// RespondStream sends the content written by the function to the client,
+// without holding all of it in memory. Any header, like the content
+// disposition, has to be set before calling it.
+func RespondStream(ctx context.Context, w http.ResponseWriter, contentType string, statusCode int, write func(w io.Writer) error) error {
+	ctx, span := otel.GetTracerProvider().Tracer("").Start(ctx, "foundation.web.respondstream")
+	span.SetAttributes(attribute.Int("statusCode", statusCode))
+	defer span.End()
+
+	// Set the status code for the request logger middleware.
+	SetStatusCode(ctx, statusCode)
+
+	// Set the content type and write the status code before streaming.
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	return write(w)
+}